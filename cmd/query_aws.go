@@ -0,0 +1,459 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+var (
+	awsRegion     string
+	awsPageSize   int
+	awsPageNum    int
+	awsOutputType string
+	awsAccount    string
+	awsFetchAll   bool
+	awsStatus     string
+)
+
+// awsCmd AWS 查询命令组
+var awsCmd = &cobra.Command{
+	Use:   "aws",
+	Short: "查询 AWS 资源",
+	Long:  `查询 AWS 的 EC2 实例、RDS 数据库、S3 存储桶等资源信息。`,
+}
+
+// awsEC2Cmd EC2 命令组
+var awsEC2Cmd = &cobra.Command{
+	Use:   "ec2",
+	Short: "查询 EC2 实例",
+	Long:  `查询 AWS EC2 (弹性云服务器) 实例。`,
+}
+
+// awsEC2ListCmd 列出 EC2 实例
+var awsEC2ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出 EC2 实例",
+	Long:  `列出 AWS EC2 实例列表。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		p, awsConfig, err := initAWSProvider(awsAccount)
+		if err != nil {
+			return err
+		}
+
+		filters := map[string]string{}
+		if awsStatus != "" {
+			filters["status"] = awsStatus
+		}
+
+		var instances []*model.Instance
+
+		// 判断是否获取所有资源
+		if awsFetchAll {
+			pageNum := 1
+			pageSize := awsPageSize
+			if pageSize <= 0 {
+				pageSize = 100
+			}
+
+			logx.Info("Fetching all instances, account %s", awsConfig.Name)
+
+			for {
+				opts := &provider.QueryOptions{
+					Region:   awsRegion,
+					PageSize: pageSize,
+					PageNum:  pageNum,
+					Filters:  filters,
+				}
+
+				pageInstances, err := p.ListInstances(ctx, opts)
+				if err != nil {
+					return fmt.Errorf("failed to list instances (page %d): %w", pageNum, err)
+				}
+
+				instances = append(instances, pageInstances...)
+
+				if len(pageInstances) < pageSize {
+					break
+				}
+
+				pageNum++
+				logx.Debug("Fetching next page, page %d, current_total %d", pageNum, len(instances))
+			}
+		} else {
+			opts := &provider.QueryOptions{
+				Region:   awsRegion,
+				PageSize: awsPageSize,
+				PageNum:  awsPageNum,
+				Filters:  filters,
+			}
+
+			instances, err = p.ListInstances(ctx, opts)
+			if err != nil {
+				return fmt.Errorf("failed to list instances: %w", err)
+			}
+		}
+
+		// 输出结果
+		if awsOutputType == "json" {
+			data, _ := json.MarshalIndent(instances, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			rows := [][]string{}
+
+			for _, inst := range instances {
+				privateIP := ""
+				if len(inst.PrivateIP) > 0 {
+					privateIP = inst.PrivateIP[0]
+				}
+				publicIP := ""
+				if len(inst.PublicIP) > 0 {
+					publicIP = inst.PublicIP[0]
+				}
+				rows = append(rows, []string{
+					inst.ID, inst.Name, inst.Zone, inst.Status,
+					inst.InstanceType, privateIP, publicIP,
+				})
+			}
+
+			t := table.New().
+				Border(lipgloss.NormalBorder()).
+				BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("99"))).
+				Headers("ID", "Name", "Zone", "Status", "Instance Type", "Private IP", "Public IP").
+				Rows(rows...)
+
+			fmt.Println(t)
+			fmt.Println()
+			logx.Info("Query completed, count %d, account %s", len(instances), awsConfig.Name)
+		}
+
+		return nil
+	},
+}
+
+// awsEC2GetCmd 获取 EC2 实例详情
+var awsEC2GetCmd = &cobra.Command{
+	Use:   "get <instance-id>",
+	Short: "获取 EC2 实例详情",
+	Long:  `获取指定 EC2 实例的详细信息。`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		instanceID := args[0]
+		ctx := context.Background()
+
+		p, _, err := initAWSProvider(awsAccount)
+		if err != nil {
+			return err
+		}
+
+		instance, err := p.GetInstance(ctx, instanceID)
+		if err != nil {
+			return fmt.Errorf("failed to get instance: %w", err)
+		}
+
+		data, _ := json.MarshalIndent(instance, "", "  ")
+		fmt.Println(string(data))
+
+		return nil
+	},
+}
+
+// awsRDSCmd RDS 命令组
+var awsRDSCmd = &cobra.Command{
+	Use:   "rds",
+	Short: "查询 RDS 数据库",
+	Long:  `查询 AWS RDS (关系型数据库) 实例。`,
+}
+
+// awsRDSListCmd 列出 RDS 实例
+var awsRDSListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出 RDS 实例",
+	Long:  `列出 AWS RDS 数据库实例列表。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		p, awsConfig, err := initAWSProvider(awsAccount)
+		if err != nil {
+			return err
+		}
+
+		var databases []*model.Database
+
+		if awsFetchAll {
+			pageNum := 1
+			pageSize := awsPageSize
+			if pageSize <= 0 {
+				pageSize = 100
+			}
+
+			logx.Info("Fetching all databases, account %s", awsConfig.Name)
+
+			for {
+				opts := &provider.QueryOptions{
+					Region:   awsRegion,
+					PageSize: pageSize,
+					PageNum:  pageNum,
+				}
+
+				pageDatabases, err := p.ListDatabases(ctx, opts)
+				if err != nil {
+					return fmt.Errorf("failed to list databases (page %d): %w", pageNum, err)
+				}
+
+				databases = append(databases, pageDatabases...)
+
+				if len(pageDatabases) < pageSize {
+					break
+				}
+
+				pageNum++
+				logx.Debug("Fetching next page, page %d, current_total %d", pageNum, len(databases))
+			}
+		} else {
+			opts := &provider.QueryOptions{
+				Region:   awsRegion,
+				PageSize: awsPageSize,
+				PageNum:  awsPageNum,
+			}
+
+			databases, err = p.ListDatabases(ctx, opts)
+			if err != nil {
+				return fmt.Errorf("failed to list databases: %w", err)
+			}
+		}
+
+		if awsOutputType == "json" {
+			data, _ := json.MarshalIndent(databases, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			rows := [][]string{}
+
+			for _, db := range databases {
+				rows = append(rows, []string{
+					db.ID, db.Region, db.Engine,
+					db.EngineVersion, db.Status, db.Endpoint,
+				})
+			}
+
+			t := table.New().
+				Border(lipgloss.NormalBorder()).
+				BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("99"))).
+				Headers("Identifier", "Region", "Engine", "Version", "Status", "Endpoint").
+				Rows(rows...)
+
+			fmt.Println(t)
+			fmt.Println()
+			logx.Info("Query completed, count %d, account %s", len(databases), awsConfig.Name)
+		}
+
+		return nil
+	},
+}
+
+// awsRDSGetCmd 获取 RDS 实例详情
+var awsRDSGetCmd = &cobra.Command{
+	Use:   "get <db-instance-identifier>",
+	Short: "获取 RDS 实例详情",
+	Long:  `获取指定 RDS 实例的详细信息。`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbID := args[0]
+		ctx := context.Background()
+
+		p, _, err := initAWSProvider(awsAccount)
+		if err != nil {
+			return err
+		}
+
+		database, err := p.GetDatabase(ctx, dbID)
+		if err != nil {
+			return fmt.Errorf("failed to get database: %w", err)
+		}
+
+		data, _ := json.MarshalIndent(database, "", "  ")
+		fmt.Println(string(data))
+
+		return nil
+	},
+}
+
+// awsS3Cmd S3 命令组
+var awsS3Cmd = &cobra.Command{
+	Use:   "s3",
+	Short: "查询 S3 存储桶",
+	Long:  `查询 AWS S3 存储桶列表和详情。`,
+}
+
+// awsS3ListCmd 列出 S3 存储桶
+var awsS3ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出 S3 存储桶",
+	Long:  `列出 AWS S3 存储桶列表。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		p, awsConfig, err := initAWSProvider(awsAccount)
+		if err != nil {
+			return err
+		}
+
+		// S3 的 ListBuckets 是全局接口,Provider 内部已一次性拉取全部存储桶
+		opts := &provider.QueryOptions{}
+		if !awsFetchAll {
+			opts.PageSize = awsPageSize
+			opts.PageNum = awsPageNum
+		}
+
+		buckets, err := p.ListOSSBuckets(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list S3 buckets: %w", err)
+		}
+
+		if awsOutputType == "json" {
+			data, _ := json.MarshalIndent(buckets, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			rows := [][]string{}
+
+			for _, bucket := range buckets {
+				rows = append(rows, []string{
+					bucket.Name, bucket.Region, bucket.CreatedAt,
+				})
+			}
+
+			t := table.New().
+				Border(lipgloss.NormalBorder()).
+				BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("99"))).
+				Headers("Name", "Region", "Created At").
+				Rows(rows...)
+
+			fmt.Println(t)
+			fmt.Println()
+			logx.Info("Query completed, count %d, account %s", len(buckets), awsConfig.Name)
+		}
+
+		return nil
+	},
+}
+
+// awsS3GetCmd 获取 S3 存储桶详情
+var awsS3GetCmd = &cobra.Command{
+	Use:   "get <bucket-name>",
+	Short: "获取 S3 存储桶详情",
+	Long:  `获取指定 S3 存储桶的详细信息。`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bucketName := args[0]
+		ctx := context.Background()
+
+		p, _, err := initAWSProvider(awsAccount)
+		if err != nil {
+			return err
+		}
+
+		bucket, err := p.GetOSSBucket(ctx, bucketName)
+		if err != nil {
+			return fmt.Errorf("failed to get S3 bucket: %w", err)
+		}
+
+		data, _ := json.MarshalIndent(bucket, "", "  ")
+		fmt.Println(string(data))
+
+		return nil
+	},
+}
+
+// initAWSProvider 获取并初始化指定账号的 AWS Provider
+func initAWSProvider(accountName string) (provider.Provider, *config.ProviderConfig, error) {
+	awsConfig, err := getAWSConfig(accountName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, err := provider.GetProvider("aws")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get aws provider: %w", err)
+	}
+
+	providerConfig := map[string]any{
+		"access_key_id":     awsConfig.AK,
+		"secret_access_key": awsConfig.SK,
+		"regions":           interfaceSlice(awsConfig.Regions),
+		"endpoint":          awsConfig.Extra["endpoint"],
+	}
+
+	if err := p.Initialize(providerConfig); err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize aws provider: %w", err)
+	}
+
+	return p, awsConfig, nil
+}
+
+// getAWSConfig 获取指定名称的 AWS 账号配置
+func getAWSConfig(accountName string) (*config.ProviderConfig, error) {
+	if len(cfg.Providers.AWS) == 0 {
+		return nil, fmt.Errorf("no aws account configured")
+	}
+
+	// 如果未指定账号名称,使用第一个启用的账号
+	if accountName == "" {
+		for _, acc := range cfg.Providers.AWS {
+			if acc.Enabled {
+				return &acc, nil
+			}
+		}
+		// 如果没有启用的账号,返回第一个
+		return &cfg.Providers.AWS[0], nil
+	}
+
+	// 查找指定名称的账号
+	for _, acc := range cfg.Providers.AWS {
+		if acc.Name == accountName {
+			return &acc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("aws account '%s' not found", accountName)
+}
+
+func init() {
+	// 添加 AWS 命令到查询命令组
+	queryCmd.AddCommand(awsCmd)
+
+	// 添加 EC2 命令
+	awsCmd.AddCommand(awsEC2Cmd)
+	awsEC2Cmd.AddCommand(awsEC2ListCmd)
+	awsEC2Cmd.AddCommand(awsEC2GetCmd)
+
+	// 添加 RDS 命令
+	awsCmd.AddCommand(awsRDSCmd)
+	awsRDSCmd.AddCommand(awsRDSListCmd)
+	awsRDSCmd.AddCommand(awsRDSGetCmd)
+
+	// 添加 S3 命令
+	awsCmd.AddCommand(awsS3Cmd)
+	awsS3Cmd.AddCommand(awsS3ListCmd)
+	awsS3Cmd.AddCommand(awsS3GetCmd)
+
+	// 通用标志
+	awsCmd.PersistentFlags().StringVarP(&awsAccount, "account", "a", "", "指定账号名称 (默认: 使用第一个启用的账号)")
+	awsCmd.PersistentFlags().StringVarP(&awsRegion, "region", "r", "", "指定区域 (默认: 所有区域)")
+	awsCmd.PersistentFlags().IntVar(&awsPageSize, "page-size", 10, "分页大小")
+	awsCmd.PersistentFlags().IntVar(&awsPageNum, "page-num", 1, "页码")
+	awsCmd.PersistentFlags().BoolVar(&awsFetchAll, "all", true, "获取所有资源 (分页循环获取)")
+	awsCmd.PersistentFlags().StringVarP(&awsOutputType, "output", "o", "table", "输出格式 (table, json)")
+
+	// EC2 专用标志
+	awsEC2ListCmd.Flags().StringVar(&awsStatus, "status", "", "按实例状态过滤 (pending, running, stopped, terminated 等)")
+}
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/eryajf/zenops/internal/provider"
+	"github.com/eryajf/zenops/internal/provider/gitlab"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gitlabOutputType string
+	gitlabPageSize   int
+	gitlabPageNum    int
+	gitlabSearch     string
+	gitlabRef        string
+	gitlabStatus     string
+)
+
+// gitlabCmd GitLab 查询命令组
+var gitlabCmd = &cobra.Command{
+	Use:   "gitlab",
+	Short: "查询 GitLab CI 资源",
+	Long:  `查询 GitLab 的项目、流水线及作业信息。`,
+}
+
+// gitlabProjectCmd 项目命令组
+var gitlabProjectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "查询 GitLab 项目",
+	Long:  `查询 GitLab 项目信息。`,
+}
+
+// gitlabProjectListCmd 列出项目
+var gitlabProjectListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出项目",
+	Long:  `列出当前 Token 有权限访问的 GitLab 项目。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		p, err := initGitLabProvider()
+		if err != nil {
+			return err
+		}
+
+		opts := &provider.QueryOptions{
+			PageSize: gitlabPageSize,
+			PageNum:  gitlabPageNum,
+			Filters:  map[string]string{},
+		}
+		if gitlabSearch != "" {
+			opts.Filters["search"] = gitlabSearch
+		}
+
+		projects, err := p.ListJobs(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list projects: %w", err)
+		}
+
+		// 输出结果
+		if gitlabOutputType == "json" {
+			data, _ := json.MarshalIndent(projects, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			rows := [][]string{}
+
+			for _, project := range projects {
+				buildable := "✓"
+				if !project.Buildable {
+					buildable = "✗"
+				}
+
+				rows = append(rows, []string{
+					project.Name,
+					project.DisplayName,
+					buildable,
+				})
+			}
+
+			t := table.New().
+				Border(lipgloss.NormalBorder()).
+				BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("99"))).
+				Headers("Path", "Name", "CI Enabled").
+				Rows(rows...)
+
+			fmt.Println(t)
+			fmt.Println()
+			logx.Info("Query completed, count %d", len(projects))
+		}
+
+		return nil
+	},
+}
+
+// gitlabProjectGetCmd 获取项目详情
+var gitlabProjectGetCmd = &cobra.Command{
+	Use:   "get <project>",
+	Short: "获取项目详情",
+	Long:  `获取指定项目的详细信息及最近一次流水线。项目可以是完整路径 (如 "group/project") 或项目 ID。`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		p, err := initGitLabProvider()
+		if err != nil {
+			return err
+		}
+
+		project, err := p.GetJob(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get project: %w", err)
+		}
+
+		data, _ := json.MarshalIndent(project, "", "  ")
+		fmt.Println(string(data))
+
+		return nil
+	},
+}
+
+// gitlabPipelineCmd 流水线命令组
+var gitlabPipelineCmd = &cobra.Command{
+	Use:   "pipeline",
+	Short: "查询流水线",
+	Long:  `查询 GitLab 流水线信息。`,
+}
+
+// gitlabPipelineListCmd 列出流水线
+var gitlabPipelineListCmd = &cobra.Command{
+	Use:   "list <project>",
+	Short: "列出流水线",
+	Long:  `列出指定项目最近的流水线。`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectName := args[0]
+		ctx := context.Background()
+
+		p, err := initGitLabProvider()
+		if err != nil {
+			return err
+		}
+
+		opts := &provider.QueryOptions{
+			PageSize: gitlabPageSize,
+			PageNum:  gitlabPageNum,
+			Filters: map[string]string{
+				"ref":    gitlabRef,
+				"status": gitlabStatus,
+			},
+		}
+
+		pipelines, err := p.ListPipelines(ctx, projectName, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list pipelines: %w", err)
+		}
+
+		// 输出结果
+		if gitlabOutputType == "json" {
+			data, _ := json.MarshalIndent(pipelines, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			rows := [][]string{}
+
+			for _, pipeline := range pipelines {
+				commit := pipeline.Commit
+				if len(commit) > 8 {
+					commit = commit[:8]
+				}
+
+				rows = append(rows, []string{
+					fmt.Sprintf("#%d", pipeline.Number),
+					pipeline.Status,
+					pipeline.Ref,
+					commit,
+					pipeline.Timestamp.Format("2006-01-02 15:04:05"),
+				})
+			}
+
+			t := table.New().
+				Border(lipgloss.NormalBorder()).
+				BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("99"))).
+				Headers("Pipeline", "Status", "Ref", "Commit", "Timestamp").
+				Rows(rows...)
+
+			fmt.Println(t)
+			fmt.Println()
+			logx.Info("Query completed, project %s, count %d", projectName, len(pipelines))
+		}
+
+		return nil
+	},
+}
+
+// gitlabPipelineGetCmd 获取流水线详情
+var gitlabPipelineGetCmd = &cobra.Command{
+	Use:   "get <project> <pipeline-id>",
+	Short: "获取流水线详情",
+	Long:  `获取指定流水线的详情及其中各作业的阶段和状态。`,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectName := args[0]
+		pipelineID, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid pipeline id: %s", args[1])
+		}
+		ctx := context.Background()
+
+		p, err := initGitLabProvider()
+		if err != nil {
+			return err
+		}
+
+		pipeline, err := p.GetPipeline(ctx, projectName, pipelineID)
+		if err != nil {
+			return fmt.Errorf("failed to get pipeline: %w", err)
+		}
+
+		jobs, err := p.ListPipelineJobs(ctx, projectName, pipelineID)
+		if err != nil {
+			return fmt.Errorf("failed to list pipeline jobs: %w", err)
+		}
+
+		// 输出结果
+		if gitlabOutputType == "json" {
+			data, _ := json.MarshalIndent(map[string]any{
+				"pipeline": pipeline,
+				"jobs":     jobs,
+			}, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			fmt.Printf("Pipeline #%d  %s  %s  %s\n\n", pipeline.Number, pipeline.Status, pipeline.Ref, pipeline.URL)
+
+			rows := [][]string{}
+
+			for _, job := range jobs {
+				rows = append(rows, []string{
+					job.Stage,
+					job.Name,
+					job.Status,
+					(time.Duration(job.Duration) * time.Millisecond).String(),
+					job.FailureReason,
+				})
+			}
+
+			t := table.New().
+				Border(lipgloss.NormalBorder()).
+				BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("99"))).
+				Headers("Stage", "Job", "Status", "Duration", "Failure Reason").
+				Rows(rows...)
+
+			fmt.Println(t)
+			fmt.Println()
+			logx.Info("Query completed, pipeline %d, jobs %d", pipeline.Number, len(jobs))
+		}
+
+		return nil
+	},
+}
+
+// initGitLabProvider 获取并初始化 GitLab Provider
+func initGitLabProvider() (*gitlab.GitLabProvider, error) {
+	if cfg.CICD.GitLab.URL == "" {
+		return nil, fmt.Errorf("gitlab is not configured")
+	}
+
+	p, err := provider.GetCICDProvider("gitlab")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gitlab provider: %w", err)
+	}
+
+	providerConfig := map[string]any{
+		"url":   cfg.CICD.GitLab.URL,
+		"token": cfg.CICD.GitLab.Token,
+	}
+
+	if err := p.Initialize(providerConfig); err != nil {
+		return nil, fmt.Errorf("failed to initialize gitlab provider: %w", err)
+	}
+
+	gp, ok := p.(*gitlab.GitLabProvider)
+	if !ok {
+		return nil, fmt.Errorf("unexpected gitlab provider type %T", p)
+	}
+
+	return gp, nil
+}
+
+func init() {
+	// 添加 GitLab 命令到查询命令组
+	queryCmd.AddCommand(gitlabCmd)
+
+	// 添加项目命令
+	gitlabCmd.AddCommand(gitlabProjectCmd)
+	gitlabProjectCmd.AddCommand(gitlabProjectListCmd)
+	gitlabProjectCmd.AddCommand(gitlabProjectGetCmd)
+
+	// 添加流水线命令
+	gitlabCmd.AddCommand(gitlabPipelineCmd)
+	gitlabPipelineCmd.AddCommand(gitlabPipelineListCmd)
+	gitlabPipelineCmd.AddCommand(gitlabPipelineGetCmd)
+
+	// 通用标志
+	gitlabCmd.PersistentFlags().IntVar(&gitlabPageSize, "page-size", 10, "分页大小")
+	gitlabCmd.PersistentFlags().IntVar(&gitlabPageNum, "page-num", 1, "页码")
+	gitlabCmd.PersistentFlags().StringVarP(&gitlabOutputType, "output", "o", "table", "输出格式 (table, json)")
+
+	// 项目专用标志
+	gitlabProjectListCmd.Flags().StringVar(&gitlabSearch, "search", "", "按项目名称搜索")
+
+	// 流水线专用标志
+	gitlabPipelineListCmd.Flags().StringVar(&gitlabRef, "ref", "", "按分支或标签过滤")
+	gitlabPipelineListCmd.Flags().StringVar(&gitlabStatus, "status", "", "按状态过滤 (success, failed, running, canceled 等)")
+}
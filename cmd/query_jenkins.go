@@ -4,18 +4,25 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"cnb.cool/zhiqiangwang/pkg/logx"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
+	"github.com/eryajf/zenops/internal/config"
 	"github.com/eryajf/zenops/internal/provider"
+	"github.com/eryajf/zenops/internal/provider/jenkins"
 	"github.com/spf13/cobra"
 )
 
 var (
+	jenkinsInstance   string
 	jenkinsOutputType string
 	jenkinsPageSize   int
 	jenkinsPageNum    int
+	jenkinsFolder     string
+	jenkinsBranches   bool
 )
 
 // jenkinsCmd Jenkins 查询命令组
@@ -36,31 +43,24 @@ var jenkinsJobCmd = &cobra.Command{
 var jenkinsJobListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "列出所有 Job",
-	Long:  `列出 Jenkins 中的所有 Job。`,
+	Long:  `列出 Jenkins 中的所有 Job, 递归遍历文件夹, Job 名称为完整路径。`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 
 		// 获取 Jenkins Provider
-		p, err := provider.GetCICDProvider("jenkins")
+		p, jenkinsConfig, err := initJenkinsProvider(jenkinsInstance)
 		if err != nil {
-			return fmt.Errorf("failed to get jenkins provider: %w", err)
-		}
-
-		// 初始化 Provider
-		providerConfig := map[string]any{
-			"url":      cfg.CICD.Jenkins.URL,
-			"username": cfg.CICD.Jenkins.Username,
-			"token":    cfg.CICD.Jenkins.Token,
-		}
-
-		if err := p.Initialize(providerConfig); err != nil {
-			return fmt.Errorf("failed to initialize jenkins provider: %w", err)
+			return err
 		}
 
 		// 查询 Jobs
 		opts := &provider.QueryOptions{
 			PageSize: jenkinsPageSize,
 			PageNum:  jenkinsPageNum,
+			Filters: map[string]string{
+				"folder":   jenkinsFolder,
+				"branches": strconv.FormatBool(jenkinsBranches),
+			},
 		}
 
 		jobs, err := p.ListJobs(ctx, opts)
@@ -90,6 +90,8 @@ var jenkinsJobListCmd = &cobra.Command{
 				rows = append(rows, []string{
 					job.Name,
 					job.DisplayName,
+					job.Type,
+					job.Status,
 					lastBuild,
 					buildable,
 				})
@@ -98,12 +100,12 @@ var jenkinsJobListCmd = &cobra.Command{
 			t := table.New().
 				Border(lipgloss.NormalBorder()).
 				BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("99"))).
-				Headers("Name", "Display Name", "Last Build", "Buildable").
+				Headers("Name", "Display Name", "Type", "Status", "Last Build", "Buildable").
 				Rows(rows...)
 
 			fmt.Println(t)
 			fmt.Println()
-			logx.Info("Query completed, count %d", len(jobs))
+			logx.Info("Query completed, count %d, instance %s", len(jobs), jenkinsConfig.Name)
 		}
 
 		return nil
@@ -121,26 +123,15 @@ var jenkinsJobGetCmd = &cobra.Command{
 		ctx := context.Background()
 
 		// 获取 Jenkins Provider
-		p, err := provider.GetCICDProvider("jenkins")
+		p, jenkinsConfig, err := initJenkinsProvider(jenkinsInstance)
 		if err != nil {
-			return fmt.Errorf("failed to get jenkins provider: %w", err)
-		}
-
-		// 初始化 Provider
-		providerConfig := map[string]any{
-			"url":      cfg.CICD.Jenkins.URL,
-			"username": cfg.CICD.Jenkins.Username,
-			"token":    cfg.CICD.Jenkins.Token,
-		}
-
-		if err := p.Initialize(providerConfig); err != nil {
-			return fmt.Errorf("failed to initialize jenkins provider: %w", err)
+			return err
 		}
 
 		// 获取 Job 详情
 		job, err := p.GetJob(ctx, jobName)
 		if err != nil {
-			return fmt.Errorf("failed to get job: %w", err)
+			return fmt.Errorf("failed to get job from instance %s: %w", jenkinsConfig.Name, err)
 		}
 
 		// 输出结果
@@ -151,6 +142,195 @@ var jenkinsJobGetCmd = &cobra.Command{
 	},
 }
 
+// jenkinsJobSearchCmd 搜索 Job
+var jenkinsJobSearchCmd = &cobra.Command{
+	Use:   "search <keyword>",
+	Short: "搜索 Job",
+	Long:  `按名称或描述搜索 Job。未指定 --instance 时并发搜索所有启用的 Jenkins 实例。`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keyword := args[0]
+		ctx := context.Background()
+
+		var instances []jenkins.Instance
+		if jenkinsInstance != "" {
+			p, jenkinsConfig, err := initJenkinsProvider(jenkinsInstance)
+			if err != nil {
+				return err
+			}
+			instances = append(instances, jenkins.Instance{Name: jenkinsConfig.Name, Provider: p})
+		} else {
+			for _, instance := range cfg.CICD.Jenkins.AllInstances() {
+				if !instance.Enabled {
+					continue
+				}
+				p, err := jenkins.NewInstanceProvider(instance.URL, instance.Username, instance.Token)
+				if err != nil {
+					logx.Warn("Failed to initialize jenkins provider, instance %s, error %v", instance.Name, err)
+					continue
+				}
+				instances = append(instances, jenkins.Instance{Name: instance.Name, Provider: p})
+			}
+			if len(instances) == 0 {
+				return fmt.Errorf("no enabled jenkins instance")
+			}
+		}
+
+		results := jenkins.SearchJobsInInstances(ctx, instances, keyword)
+
+		// 输出结果
+		if jenkinsOutputType == "json" {
+			data, _ := json.MarshalIndent(results, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		rows := [][]string{}
+		total := 0
+		for _, r := range results {
+			if r.Error != "" {
+				logx.Warn("Search failed, instance %s, error %s", r.Instance, r.Error)
+				continue
+			}
+			for _, job := range r.Jobs {
+				lastBuild := "-"
+				if job.LastBuild != nil {
+					lastBuild = fmt.Sprintf("#%d", job.LastBuild.Number)
+				}
+				rows = append(rows, []string{r.Instance, job.Name, lastBuild, job.URL})
+				total++
+			}
+		}
+
+		t := table.New().
+			Border(lipgloss.NormalBorder()).
+			BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("99"))).
+			Headers("Instance", "Name", "Last Build", "URL").
+			Rows(rows...)
+
+		fmt.Println(t)
+		fmt.Println()
+		logx.Info("Search completed, keyword %s, count %d", keyword, total)
+
+		return nil
+	},
+}
+
+// jenkinsJobBranchesCmd 列出多分支项目的分支
+var jenkinsJobBranchesCmd = &cobra.Command{
+	Use:   "branches <job-name>",
+	Short: "列出多分支项目的分支",
+	Long:  `列出多分支项目 (Multibranch Pipeline) 的所有分支。支持文件夹路径,如 "folder/project"。`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jobName := args[0]
+		ctx := context.Background()
+
+		// 获取 Jenkins Provider
+		p, jenkinsConfig, err := initJenkinsProvider(jenkinsInstance)
+		if err != nil {
+			return err
+		}
+
+		branches, err := p.ListBranches(ctx, jobName)
+		if err != nil {
+			return fmt.Errorf("failed to list branches: %w", err)
+		}
+
+		// 输出结果
+		if jenkinsOutputType == "json" {
+			data, _ := json.MarshalIndent(branches, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		rows := [][]string{}
+		for _, branch := range branches {
+			lastBuild := "-"
+			if branch.LastBuild != nil {
+				lastBuild = fmt.Sprintf("#%d", branch.LastBuild.Number)
+			}
+			rows = append(rows, []string{branch.DisplayName, branch.Name, branch.Status, lastBuild})
+		}
+
+		t := table.New().
+			Border(lipgloss.NormalBorder()).
+			BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("99"))).
+			Headers("Branch", "Job Path", "Status", "Last Build").
+			Rows(rows...)
+
+		fmt.Println(t)
+		fmt.Println()
+		logx.Info("Query completed, job %s, count %d, instance %s", jobName, len(branches), jenkinsConfig.Name)
+
+		return nil
+	},
+}
+
+// jenkinsAgentCmd Agent 命令组
+var jenkinsAgentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "查询构建节点",
+	Long:  `查询 Jenkins 构建节点 (Agent) 信息。`,
+}
+
+// jenkinsAgentListCmd 列出构建节点
+var jenkinsAgentListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出构建节点",
+	Long:  `列出 Jenkins 构建节点的在线状态、执行器使用情况和标签。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		// 获取 Jenkins Provider
+		p, jenkinsConfig, err := initJenkinsProvider(jenkinsInstance)
+		if err != nil {
+			return err
+		}
+
+		agents, err := p.ListAgents(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list agents: %w", err)
+		}
+
+		// 输出结果
+		if jenkinsOutputType == "json" {
+			data, _ := json.MarshalIndent(agents, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		rows := [][]string{}
+		for _, agent := range agents {
+			status := "online"
+			if !agent.Online {
+				status = "offline"
+				if agent.OfflineReason != "" {
+					status += ": " + agent.OfflineReason
+				}
+			}
+			rows = append(rows, []string{
+				agent.Name,
+				status,
+				fmt.Sprintf("%d/%d", agent.BusyExecutors, agent.Executors),
+				strings.Join(agent.Labels, ","),
+			})
+		}
+
+		t := table.New().
+			Border(lipgloss.NormalBorder()).
+			BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("99"))).
+			Headers("Name", "Status", "Busy/Executors", "Labels").
+			Rows(rows...)
+
+		fmt.Println(t)
+		fmt.Println()
+		logx.Info("Query completed, count %d, instance %s", len(agents), jenkinsConfig.Name)
+
+		return nil
+	},
+}
+
 // jenkinsBuildCmd Build 命令组
 var jenkinsBuildCmd = &cobra.Command{
 	Use:   "build",
@@ -169,20 +349,9 @@ var jenkinsBuildListCmd = &cobra.Command{
 		ctx := context.Background()
 
 		// 获取 Jenkins Provider
-		p, err := provider.GetCICDProvider("jenkins")
+		p, jenkinsConfig, err := initJenkinsProvider(jenkinsInstance)
 		if err != nil {
-			return fmt.Errorf("failed to get jenkins provider: %w", err)
-		}
-
-		// 初始化 Provider
-		providerConfig := map[string]any{
-			"url":      cfg.CICD.Jenkins.URL,
-			"username": cfg.CICD.Jenkins.Username,
-			"token":    cfg.CICD.Jenkins.Token,
-		}
-
-		if err := p.Initialize(providerConfig); err != nil {
-			return fmt.Errorf("failed to initialize jenkins provider: %w", err)
+			return err
 		}
 
 		// 查询 Builds
@@ -225,7 +394,7 @@ var jenkinsBuildListCmd = &cobra.Command{
 
 			fmt.Println(t)
 			fmt.Println()
-			logx.Info("Query completed, job %s, count %d", jobName, len(builds))
+			logx.Info("Query completed, job %s, count %d, instance %s", jobName, len(builds), jenkinsConfig.Name)
 		}
 
 		return nil
@@ -240,13 +409,39 @@ func init() {
 	jenkinsCmd.AddCommand(jenkinsJobCmd)
 	jenkinsJobCmd.AddCommand(jenkinsJobListCmd)
 	jenkinsJobCmd.AddCommand(jenkinsJobGetCmd)
+	jenkinsJobCmd.AddCommand(jenkinsJobSearchCmd)
+	jenkinsJobCmd.AddCommand(jenkinsJobBranchesCmd)
+
+	// 添加 Agent 命令
+	jenkinsCmd.AddCommand(jenkinsAgentCmd)
+	jenkinsAgentCmd.AddCommand(jenkinsAgentListCmd)
 
 	// 添加 Build 命令
 	jenkinsCmd.AddCommand(jenkinsBuildCmd)
 	jenkinsBuildCmd.AddCommand(jenkinsBuildListCmd)
 
 	// 通用标志
+	jenkinsCmd.PersistentFlags().StringVarP(&jenkinsInstance, "instance", "i", "", "指定 Jenkins 实例名称 (默认: 使用第一个启用的实例)")
 	jenkinsCmd.PersistentFlags().IntVar(&jenkinsPageSize, "page-size", 10, "分页大小")
 	jenkinsCmd.PersistentFlags().IntVar(&jenkinsPageNum, "page-num", 1, "页码")
 	jenkinsCmd.PersistentFlags().StringVarP(&jenkinsOutputType, "output", "o", "table", "输出格式 (table, json)")
+
+	// Job 列表标志
+	jenkinsJobListCmd.Flags().StringVar(&jenkinsFolder, "folder", "", "只列出指定文件夹下的 Job")
+	jenkinsJobListCmd.Flags().BoolVar(&jenkinsBranches, "branches", false, "同时列出多分支项目的分支")
+}
+
+// initJenkinsProvider 获取并初始化指定实例的 Jenkins Provider
+func initJenkinsProvider(instance string) (*jenkins.JenkinsProvider, *config.JenkinsConfig, error) {
+	jenkinsConfig, err := config.FindJenkinsInstance(cfg.CICD.Jenkins.AllInstances(), instance)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, err := jenkins.NewInstanceProvider(jenkinsConfig.URL, jenkinsConfig.Username, jenkinsConfig.Token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize jenkins provider: %w", err)
+	}
+
+	return p, jenkinsConfig, nil
 }
@@ -14,9 +14,13 @@ import (
 	"github.com/eryajf/zenops/internal/imcp"
 	"github.com/eryajf/zenops/internal/mcpclient"
 	_ "github.com/eryajf/zenops/internal/provider/aliyun"  // 注册 aliyun provider
+	_ "github.com/eryajf/zenops/internal/provider/aws"     // 注册 aws provider
+	_ "github.com/eryajf/zenops/internal/provider/github"  // 注册 github provider
+	_ "github.com/eryajf/zenops/internal/provider/gitlab"  // 注册 gitlab provider
 	_ "github.com/eryajf/zenops/internal/provider/jenkins" // 注册 jenkins provider
 	_ "github.com/eryajf/zenops/internal/provider/tencent" // 注册 tencent provider
 	"github.com/eryajf/zenops/internal/server"
+	"github.com/eryajf/zenops/internal/service"
 	"github.com/spf13/cobra"
 )
 
@@ -218,7 +222,7 @@ var runCmd = &cobra.Command{
 			logx.Info("🔌 Starting MCP server...")
 			go func() {
 				// 使用已经注册了外部工具的 MCP 服务器
-				err := mcpServer.StartSSE()
+				err := mcpServer.StartHTTP()
 				if err != nil {
 					errCh <- fmt.Errorf("mcp server error: %w", err)
 				}
@@ -238,15 +242,26 @@ var runCmd = &cobra.Command{
 		case err := <-errCh:
 			logx.Error("Server error: %v", err)
 			cancel()
+			if startMCP {
+				shutdownMCPServer(mcpServer)
+			}
 			// 清理外部 MCP 客户端
 			mcpClientManager.CloseAll()
 			return err
 		}
 
+		// 停止 MCP 服务, 等待进行中的工具调用完成
+		if startMCP {
+			shutdownMCPServer(mcpServer)
+		}
+
 		// 清理外部 MCP 客户端
 		logx.Info("🧹 Cleaning up external MCP clients...")
 		mcpClientManager.CloseAll()
 
+		// 写入内存中累计的脱敏规则替换次数
+		service.FlushRedactionHits()
+
 		time.Sleep(2 * time.Second)
 		logx.Info("👋 Graceful Shutdown Complete.")
 
@@ -254,6 +269,17 @@ var runCmd = &cobra.Command{
 	},
 }
 
+// shutdownMCPServer 优雅停止 MCP 服务的 HTTP 传输
+func shutdownMCPServer(mcpServer *imcp.MCPServer) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	logx.Info("🔌 Shutting down MCP server...")
+	if err := mcpServer.Shutdown(ctx); err != nil {
+		logx.Warn("⚠️  Failed to shutdown MCP server gracefully: %v", err)
+	}
+}
+
 // listToolsCmd 列出所有已注册的工具
 var listToolsCmd = &cobra.Command{
 	Use:   "list-tools",
@@ -1,5 +1,10 @@
 package config
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Config 应用配置
 type Config struct {
 	Server           ServerConfig    `mapstructure:"server"`
@@ -18,6 +23,7 @@ type Config struct {
 type ProvidersConfig struct {
 	Aliyun  []ProviderConfig `mapstructure:"aliyun"`
 	Tencent []ProviderConfig `mapstructure:"tencent"`
+	AWS     []ProviderConfig `mapstructure:"aws"`
 }
 
 // ServerConfig 服务器配置
@@ -38,7 +44,12 @@ type MCPConfig struct {
 	Enabled                   bool   `mapstructure:"enabled"`
 	Port                      int    `mapstructure:"port"`
 	AutoRegisterExternalTools bool   `mapstructure:"auto_register_external_tools"` // 是否自动注册外部 MCP 工具
-	ToolNameFormat            string `mapstructure:"tool_name_format"`              // 工具命名格式,默认 "{prefix}{name}"
+	ToolNameFormat            string `mapstructure:"tool_name_format"`             // 工具命名格式,默认 "{prefix}{name}"
+	Transport                 string `mapstructure:"transport"`                    // 传输方式: sse, streamable_http, both,默认 both
+	StreamablePath            string `mapstructure:"streamable_path"`              // Streamable HTTP 端点路径,默认 "/mcp"
+	Stateless                 bool   `mapstructure:"stateless"`                    // Streamable HTTP 无状态模式,不分配和校验会话
+	HeartbeatInterval         int    `mapstructure:"heartbeat_interval"`           // Streamable HTTP 心跳间隔(秒),0 表示不发送
+	AllowAnonymous            bool   `mapstructure:"allow_anonymous"`              // 允许不携带令牌访问,默认所有请求都需要令牌
 }
 
 // ProviderConfig 云服务提供商配置
@@ -54,22 +65,102 @@ type ProviderConfig struct {
 // CICDConfig CI/CD 工具配置
 type CICDConfig struct {
 	Jenkins JenkinsConfig `mapstructure:"jenkins"`
+	GitLab  GitLabConfig  `mapstructure:"gitlab"`
+	GitHub  GitHubConfig  `mapstructure:"github"`
 }
 
 // JenkinsConfig Jenkins 配置
 type JenkinsConfig struct {
-	Enabled  bool   `mapstructure:"enabled"`
-	URL      string `mapstructure:"url"`
-	Username string `mapstructure:"username"`
-	Token    string `mapstructure:"token"`
+	Name      string          `mapstructure:"name"` // 实例名称, 为空时为 default
+	Enabled   bool            `mapstructure:"enabled"`
+	URL       string          `mapstructure:"url"`
+	Username  string          `mapstructure:"username"`
+	Token     string          `mapstructure:"token"`
+	Instances []JenkinsConfig `mapstructure:"instances"` // 其他 Jenkins 实例, 用于连接多个 Jenkins Master
+}
+
+// DefaultJenkinsInstance 未命名 Jenkins 实例的名称
+const DefaultJenkinsInstance = "default"
+
+// AllInstances 返回所有已配置地址的 Jenkins 实例, 顶层配置作为第一个实例
+func (c JenkinsConfig) AllInstances() []JenkinsConfig {
+	var instances []JenkinsConfig
+	if c.URL != "" {
+		top := c
+		top.Instances = nil
+		if top.Name == "" {
+			top.Name = DefaultJenkinsInstance
+		}
+		instances = append(instances, top)
+	}
+	for _, instance := range c.Instances {
+		if instance.URL == "" || instance.Name == "" {
+			continue
+		}
+		instance.Instances = nil
+		instances = append(instances, instance)
+	}
+	return instances
+}
+
+// FindJenkinsInstance 按名称查找 Jenkins 实例, 名称为空时返回第一个启用的实例
+func FindJenkinsInstance(instances []JenkinsConfig, name string) (*JenkinsConfig, error) {
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("jenkins is not configured")
+	}
+
+	if name == "" {
+		for i := range instances {
+			if instances[i].Enabled {
+				return &instances[i], nil
+			}
+		}
+		return nil, fmt.Errorf("jenkins is disabled")
+	}
+
+	names := make([]string, 0, len(instances))
+	for i := range instances {
+		if instances[i].Name == name {
+			if !instances[i].Enabled {
+				return nil, fmt.Errorf("jenkins instance '%s' is disabled", name)
+			}
+			return &instances[i], nil
+		}
+		names = append(names, instances[i].Name)
+	}
+
+	return nil, fmt.Errorf("jenkins instance '%s' not found, available: %s", name, strings.Join(names, ", "))
+}
+
+// GitLabConfig GitLab 配置
+type GitLabConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	URL     string `mapstructure:"url"`
+	Token   string `mapstructure:"token"` // Personal/Project Access Token, 需要 read_api 权限
+}
+
+// GitHubConfig GitHub Actions 配置
+type GitHubConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	URL     string `mapstructure:"url"`   // API 地址, 为空时使用 github.com, GitHub Enterprise 填写实例地址
+	Token   string `mapstructure:"token"` // 需要 actions:read 权限
 }
 
 // LLMConfig LLM 配置
 type LLMConfig struct {
-	Enabled bool   `mapstructure:"enabled"`
-	Model   string `mapstructure:"model"`
-	APIKey  string `mapstructure:"api_key"`
-	BaseURL string `mapstructure:"base_url"` // 自定义 API 端点
+	Enabled  bool   `mapstructure:"enabled"`
+	Provider string `mapstructure:"provider"` // 模型厂商: anthropic 使用原生 Messages API, 其他均按 OpenAI 兼容接口调用
+	Model    string `mapstructure:"model"`
+	APIKey   string `mapstructure:"api_key"`
+	BaseURL  string `mapstructure:"base_url"` // 自定义 API 端点
+	// 上下文 token 预算, 历史消息超出后较早的对话会被压缩为摘要, 为 0 时使用默认值
+	ContextTokens int `mapstructure:"context_tokens"`
+	// 同一轮工具调用的最大并发数与单个工具的超时时间(秒), 为 0 时使用默认值
+	ToolConcurrency int `mapstructure:"tool_concurrency"`
+	ToolTimeout     int `mapstructure:"tool_timeout"`
+	// 启用的工具超过 ToolTopK 个时, 只发送与用户消息最相关的 ToolTopK 个工具和固定工具, 模型可通过 search_tools 检索更多
+	ToolTopK    int      `mapstructure:"tool_top_k"`
+	PinnedTools []string `mapstructure:"pinned_tools"`
 }
 
 // DingTalkConfig 钉钉配置
@@ -79,6 +170,7 @@ type DingTalkConfig struct {
 	AppSecret      string `mapstructure:"app_secret"`
 	AgentID        string `mapstructure:"agent_id"`
 	CardTemplateID string `mapstructure:"card_template_id"` // AI 流式卡片模板 ID
+	LLM            string `mapstructure:"llm"`              // 使用的 LLM 配置名称, 为空时使用默认模型
 }
 
 // FeishuConfig 飞书配置
@@ -86,6 +178,7 @@ type FeishuConfig struct {
 	Enabled   bool   `mapstructure:"enabled"`
 	AppID     string `mapstructure:"app_id"`
 	AppSecret string `mapstructure:"app_secret"`
+	LLM       string `mapstructure:"llm"` // 使用的 LLM 配置名称, 为空时使用默认模型
 }
 
 // WecomConfig 企业微信配置
@@ -93,13 +186,56 @@ type WecomConfig struct {
 	Enabled        bool   `mapstructure:"enabled"`
 	Token          string `mapstructure:"token"`           // 企业微信AI机器人Token
 	EncodingAESKey string `mapstructure:"encoding_aes_key"` // 消息加密密钥
+	LLM            string `mapstructure:"llm"`              // 使用的 LLM 配置名称, 为空时使用默认模型
 }
 
 // AuthConfig 认证配置
 type AuthConfig struct {
-	Enabled bool     `mapstructure:"enabled"`
-	Type    string   `mapstructure:"type"` // token, basic, oauth2
-	Tokens  []string `mapstructure:"tokens"`
+	Enabled bool       `mapstructure:"enabled"`
+	Type    string     `mapstructure:"type"` // token, basic, oauth2
+	Tokens  []string   `mapstructure:"tokens"`
+	LDAP    LDAPConfig `mapstructure:"ldap"` // Web 控制台 LDAP 登录
+	OIDC    OIDCConfig `mapstructure:"oidc"` // Web 控制台 OIDC 单点登录
+}
+
+// GroupRole 外部认证源的用户组与 ZenOps 角色的映射
+type GroupRole struct {
+	Group string `mapstructure:"group"` // LDAP 为组的完整 DN, OIDC 为 groups 声明中的值, 不区分大小写
+	Role  string `mapstructure:"role"`  // admin, operator, viewer
+}
+
+// LDAPConfig LDAP 登录配置
+type LDAPConfig struct {
+	Enabled            bool        `mapstructure:"enabled"`
+	URL                string      `mapstructure:"url"`                  // ldap://host:389 或 ldaps://host:636
+	StartTLS           bool        `mapstructure:"start_tls"`            // 使用 ldap:// 时升级为 TLS 连接
+	InsecureSkipVerify bool        `mapstructure:"insecure_skip_verify"` // 跳过证书校验
+	BindDN             string      `mapstructure:"bind_dn"`              // 用于搜索用户的服务账号, 为空时匿名搜索
+	BindPassword       string      `mapstructure:"bind_password"`
+	BaseDN             string      `mapstructure:"base_dn"`            // 用户搜索的起始 DN
+	UserFilter         string      `mapstructure:"user_filter"`        // 用户搜索条件, %s 为登录名, 默认 (uid=%s)
+	UsernameAttribute  string      `mapstructure:"username_attribute"` // 作为 ZenOps 用户名的属性, 默认 uid
+	NicknameAttribute  string      `mapstructure:"nickname_attribute"` // 默认 cn
+	EmailAttribute     string      `mapstructure:"email_attribute"`    // 默认 mail
+	GroupBaseDN        string      `mapstructure:"group_base_dn"`      // 用户组搜索的起始 DN, 为空时使用用户的 memberOf 属性
+	GroupFilter        string      `mapstructure:"group_filter"`       // 用户组搜索条件, %s 为用户 DN, 默认 (member=%s)
+	GroupRoles         []GroupRole `mapstructure:"group_roles"`        // 配置后每次登录按用户组同步角色
+	DefaultRole        string      `mapstructure:"default_role"`       // 未匹配任何用户组时的角色, 为空时拒绝登录
+}
+
+// OIDCConfig OIDC 单点登录配置, 使用授权码模式 + PKCE
+type OIDCConfig struct {
+	Enabled       bool        `mapstructure:"enabled"`
+	Name          string      `mapstructure:"name"` // 登录页按钮显示的名称
+	Issuer        string      `mapstructure:"issuer"`
+	ClientID      string      `mapstructure:"client_id"`
+	ClientSecret  string      `mapstructure:"client_secret"`  // 公共客户端可为空
+	RedirectURL   string      `mapstructure:"redirect_url"`   // 前端的回调页面地址, 需与认证服务中登记的一致
+	Scopes        []string    `mapstructure:"scopes"`         // 默认 openid profile email
+	UsernameClaim string      `mapstructure:"username_claim"` // 作为 ZenOps 用户名的声明, 默认 preferred_username
+	GroupsClaim   string      `mapstructure:"groups_claim"`   // 用户组声明, 默认 groups
+	GroupRoles    []GroupRole `mapstructure:"group_roles"`    // 配置后每次登录按用户组同步角色
+	DefaultRole   string      `mapstructure:"default_role"`   // 未匹配任何用户组时的角色, 为空时拒绝登录
 }
 
 // CacheConfig 缓存配置
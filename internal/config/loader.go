@@ -102,6 +102,8 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.http.debug", true) // 默认开启 Debug 模式,适合本地开发
 	v.SetDefault("server.mcp.enabled", false)
 	v.SetDefault("server.mcp.port", 8081)
+	v.SetDefault("server.mcp.transport", "both")
+	v.SetDefault("server.mcp.streamable_path", "/mcp")
 
 	// Auth 默认配置
 	v.SetDefault("auth.enabled", false)
@@ -127,9 +129,21 @@ func expandEnvVars(config *Config) {
 		config.Providers.Tencent[i].SK = os.ExpandEnv(config.Providers.Tencent[i].SK)
 	}
 
+	// 展开 AWS 账号配置中的环境变量
+	for i := range config.Providers.AWS {
+		config.Providers.AWS[i].AK = os.ExpandEnv(config.Providers.AWS[i].AK)
+		config.Providers.AWS[i].SK = os.ExpandEnv(config.Providers.AWS[i].SK)
+	}
+
 	// 展开 CICD 配置中的环境变量
 	config.CICD.Jenkins.Username = os.ExpandEnv(config.CICD.Jenkins.Username)
 	config.CICD.Jenkins.Token = os.ExpandEnv(config.CICD.Jenkins.Token)
+	for i := range config.CICD.Jenkins.Instances {
+		config.CICD.Jenkins.Instances[i].Username = os.ExpandEnv(config.CICD.Jenkins.Instances[i].Username)
+		config.CICD.Jenkins.Instances[i].Token = os.ExpandEnv(config.CICD.Jenkins.Instances[i].Token)
+	}
+	config.CICD.GitLab.Token = os.ExpandEnv(config.CICD.GitLab.Token)
+	config.CICD.GitHub.Token = os.ExpandEnv(config.CICD.GitHub.Token)
 
 	// 展开 DingTalk 配置中的环境变量
 	config.DingTalk.AppKey = os.ExpandEnv(config.DingTalk.AppKey)
@@ -140,4 +154,6 @@ func expandEnvVars(config *Config) {
 	for i, token := range config.Auth.Tokens {
 		config.Auth.Tokens[i] = os.ExpandEnv(token)
 	}
+	config.Auth.LDAP.BindPassword = os.ExpandEnv(config.Auth.LDAP.BindPassword)
+	config.Auth.OIDC.ClientSecret = os.ExpandEnv(config.Auth.OIDC.ClientSecret)
 }
@@ -9,6 +9,7 @@ import (
 	"cnb.cool/zhiqiangwang/pkg/logx"
 	"github.com/eryajf/zenops/internal/config"
 	"github.com/eryajf/zenops/internal/imcp"
+	"github.com/eryajf/zenops/internal/intent"
 	"github.com/eryajf/zenops/internal/llm"
 	"github.com/eryajf/zenops/internal/service"
 	"github.com/google/uuid"
@@ -18,7 +19,7 @@ import (
 // MessageHandler 消息处理器
 type MessageHandler struct {
 	client         *Client
-	parser         *IntentParser
+	parser         *intent.Parser
 	mcpServer      *imcp.MCPServer
 	config         *config.Config
 	streamMgr      *StreamManager
@@ -61,7 +62,7 @@ type MessageHandler struct {
 // 	return &MessageHandler{
 // 		client:    client,
 // 		crypto:    crypto,
-// 		parser:    NewIntentParser(),
+// 		parser:    intent.NewParser(),
 // 		mcpServer: mcpServer,
 // 		config:    cfg,
 // 		streamMgr: NewStreamManager(client),
@@ -91,6 +92,18 @@ func (h *MessageHandler) HandleMessage(ctx context.Context, msg *CallbackMessage
 
 	// 如果启用了 LLM,使用 LLM 处理
 	if h.config.LLM.Enabled && h.llmClient != nil {
+		// 记录发起人,用于工具调用审计
+		username := msg.SenderNick
+		if username == "" {
+			username = msg.SenderStaffID
+		}
+		ctx = service.WithCaller(ctx, username, "dingtalk")
+		ctx = service.WithIMChannel(ctx)
+		ctx = llm.WithToolProgress(ctx)
+		if msg.ConversationType == "2" {
+			ctx = service.WithChatGroup(ctx, msg.ConversationID)
+		}
+
 		// 如果启用了流式卡片,使用卡片流式交互
 		if h.config.DingTalk.CardTemplateID != "" {
 			go h.processLLMWithStreamCard(ctx, msg, userMessage)
@@ -115,7 +128,7 @@ func (h *MessageHandler) HandleMessage(ctx context.Context, msg *CallbackMessage
 }
 
 // processQueryAsync 异步处理查询
-func (h *MessageHandler) processQueryAsync(ctx context.Context, msg *CallbackMessage, intent *Intent) {
+func (h *MessageHandler) processQueryAsync(ctx context.Context, msg *CallbackMessage, intent *intent.Intent) {
 	logx.Info("Processing query asynchronously mcp_tool %s, params %v",
 		intent.MCPTool,
 		intent.Params)
@@ -143,13 +156,13 @@ func (h *MessageHandler) processQueryAsync(ctx context.Context, msg *CallbackMes
 }
 
 // callMCPTool 调用 MCP 工具
-func (h *MessageHandler) callMCPTool(ctx context.Context, intent *Intent) (string, error) {
+func (h *MessageHandler) callMCPTool(ctx context.Context, intent *intent.Intent) (string, error) {
 	logx.Debug("Calling MCP tool: tool %s, params %v",
 		intent.MCPTool,
 		intent.Params)
 
 	// 使用 MCP Server 的 CallTool 方法
-	result, err := h.mcpServer.CallTool(ctx, intent.MCPTool, h.convertParams(intent.Params))
+	result, err := h.mcpServer.CallTool(service.WithIMChannel(ctx), intent.MCPTool, h.convertParams(intent.Params))
 	if err != nil {
 		return "", fmt.Errorf("failed to call MCP tool: %w", err)
 	}
@@ -174,7 +187,7 @@ func (h *MessageHandler) convertParams(params map[string]string) map[string]any
 }
 
 // formatResult 格式化查询结果
-func (h *MessageHandler) formatResult(intent *Intent, result string) string {
+func (h *MessageHandler) formatResult(intent *intent.Intent, result string) string {
 	var builder strings.Builder
 
 	// 添加头部
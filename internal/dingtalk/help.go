@@ -0,0 +1,29 @@
+package dingtalk
+
+// GetHelpMessage 获取帮助消息
+func GetHelpMessage() string {
+	return `👋 你好!我是 ZenOps 运维助手,可以帮你查询云资源和 CI/CD 信息。
+
+**支持的查询:**
+
+📦 **阿里云**
+• 列出 ECS 实例: "查询阿里云杭州的 ECS"
+• 搜索 IP: "找一下 IP 为 192.168.1.1 的服务器"
+• 搜索名称: "查询名为 web-server 的实例"
+• 数据库: "列出阿里云 RDS 数据库"
+
+📦 **腾讯云**
+• 列出 CVM: "查询腾讯云广州的 CVM"
+• 搜索 IP: "找腾讯云 IP 10.0.0.1 的机器"
+• 数据库: "列出腾讯云 CDB"
+
+🔧 **Jenkins**
+• 列出任务: "看一下 Jenkins 任务列表"
+• 构建历史: "查询 deploy-prod 的构建历史"
+• 失败分析: "分析 deploy-prod #12 构建失败"
+
+**提示:**
+• 可以在群里 @我 或私聊我
+• 描述越详细,查询越准确
+• 支持中文和英文关键词`
+}
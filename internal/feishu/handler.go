@@ -10,11 +10,13 @@ import (
 	"cnb.cool/zhiqiangwang/pkg/logx"
 	"github.com/eryajf/zenops/internal/config"
 	"github.com/eryajf/zenops/internal/imcp"
+	"github.com/eryajf/zenops/internal/intent"
 	"github.com/eryajf/zenops/internal/llm"
 	"github.com/eryajf/zenops/internal/model"
 	"github.com/eryajf/zenops/internal/service"
 	larkcontact "github.com/larksuite/oapi-sdk-go/v3/service/contact/v3"
 	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // MessageHandler 飞书消息处理器
@@ -23,6 +25,7 @@ type MessageHandler struct {
 	config         *config.Config
 	mcpServer      *imcp.MCPServer
 	llmClient      *llm.Client
+	parser         *intent.Parser
 	chatLogService *service.ChatLogService
 }
 
@@ -31,36 +34,11 @@ func NewMessageHandler(cfg *config.Config, mcpServer *imcp.MCPServer) (*MessageH
 	client := NewClient(cfg.Feishu.AppID, cfg.Feishu.AppSecret)
 
 	// 初始化 LLM 客户端
-	// 优先从数据库读取 LLM 配置，如果数据库没有配置则使用 config.yaml
-	var llmClient *llm.Client
-	configService := service.NewConfigService()
-	dbLLMConfig, err := configService.GetDefaultLLMConfig()
-
-	var llmEnabled bool
-	var llmConfig *llm.Config
-
-	if err == nil && dbLLMConfig != nil && dbLLMConfig.Enabled {
-		// 使用数据库配置
-		llmEnabled = true
-		llmConfig = &llm.Config{
-			Model:   dbLLMConfig.Model,
-			APIKey:  dbLLMConfig.APIKey,
-			BaseURL: dbLLMConfig.BaseURL,
-		}
-		logx.Info("⚗️ Using LLM Config from Database: %s (Model: %s)", dbLLMConfig.Name, dbLLMConfig.Model)
-	} else if cfg.LLM.Enabled {
-		// 降级使用 config.yaml 配置
-		llmEnabled = true
-		llmConfig = &llm.Config{
-			Model:   cfg.LLM.Model,
-			APIKey:  cfg.LLM.APIKey,
-			BaseURL: cfg.LLM.BaseURL,
-		}
-		logx.Info("⚗️ Using LLM Config from config.yaml (Model: %s)", cfg.LLM.Model)
-	}
-
-	if llmEnabled {
-		llmClient = llm.NewClient(llmConfig, mcpServer)
+	// 优先使用飞书指定的模型, 数据库中其余启用的模型作为备用; 数据库没有配置时使用 config.yaml
+	llmClient, err := llm.LoadClient(cfg.Feishu.LLM, cfg.LLM, mcpServer)
+	if err != nil {
+		logx.Warn("⚗️ LLM Client Not Initialized For Feishu: %v", err)
+	} else {
 		logx.Info("⚗️ LLM Client Initialized For Feishu")
 	}
 
@@ -69,6 +47,7 @@ func NewMessageHandler(cfg *config.Config, mcpServer *imcp.MCPServer) (*MessageH
 		config:         cfg,
 		mcpServer:      mcpServer,
 		llmClient:      llmClient,
+		parser:         intent.NewParser(),
 		chatLogService: service.NewChatLogService(),
 	}, nil
 }
@@ -102,9 +81,14 @@ func (h *MessageHandler) HandleTextMessage(ctx context.Context, event *larkim.P2
 		source = "群聊"
 	}
 
-	// 保存用户消息到数据库
+	// 保存用户消息到数据库, 私聊按用户、群聊按群关联到同一个会话
 	username := *event.Event.Sender.SenderId.OpenId
-	userLog, err := h.chatLogService.CreateUserMessage(username, source, userMessage)
+	chatID := username
+	if *event.Event.Message.ChatType == "group" {
+		chatID = *event.Event.Message.ChatId
+	}
+	conversationID := service.ChannelConversation("feishu", chatID, username, "飞书"+source)
+	userLog, err := h.chatLogService.CreateUserMessageWithConversation(username, source, userMessage, conversationID)
 	if err != nil {
 		logx.Error("Failed to save user message to database: %v", err)
 	}
@@ -115,11 +99,16 @@ func (h *MessageHandler) HandleTextMessage(ctx context.Context, event *larkim.P2
 	}
 
 	// 如果启用了 LLM,使用 LLM 处理
-	if h.config.LLM.Enabled && h.llmClient != nil {
+	if h.llmClient != nil {
 		return h.processLLMMessage(ctx, event, userMessage, username, source, userLog)
 	}
 
-	// 否则返回默认消息
+	// 否则按意图解析, 识别出的查询 (如构建失败分析) 直接调用 MCP 工具并以卡片回复
+	if parsed, err := h.parser.Parse(userMessage); err == nil && parsed.MCPTool != "" {
+		return h.processQueryMessage(ctx, event, userMessage, username, source, userLog, parsed)
+	}
+
+	// 无法识别时返回默认消息
 	receiveIDType := "open_id"
 	receiveID := *event.Event.Sender.SenderId.OpenId
 	if *event.Event.Message.ChatType == "group" {
@@ -140,8 +129,19 @@ func (h *MessageHandler) processLLMMessage(ctx context.Context, event *larkim.P2
 		receiveID = *event.Event.Message.ChatId
 	}
 
-	// 调用 LLM 流式对话
-	responseCh, err := h.llmClient.ChatWithToolsAndStream(ctx, userMessage)
+	// 调用 LLM 流式对话, 记录发起人用于工具调用审计, 群聊记录会话ID用于匹配提示词配置
+	ctx = service.WithCaller(ctx, username, "feishu")
+	ctx = service.WithIMChannel(ctx)
+	if *event.Event.Message.ChatType == "group" {
+		ctx = service.WithChatGroup(ctx, *event.Event.Message.ChatId)
+	}
+	if userLog != nil {
+		ctx = service.WithChatLog(ctx, userLog.ID)
+		ctx = service.WithConversation(ctx, userLog.ConversationID)
+	}
+	ctx = llm.WithToolProgress(ctx)
+	ctx, answer := llm.WithAnswer(ctx)
+	responseCh, err := h.llmClient.ChatWithToolsAndStreamMessages(ctx, llm.ConversationMessages(userLog, userMessage))
 	if err != nil {
 		logx.Error("Failed to call LLM: %v", err)
 		return h.client.SendTextMessage(ctx, receiveIDType, receiveID,
@@ -199,7 +199,7 @@ func (h *MessageHandler) processLLMMessage(ctx context.Context, event *larkim.P2
 
 				// 保存AI响应到数据库
 				if userLog != nil && aiResponse.Len() > 0 {
-					_, err := h.chatLogService.CreateAIMessage(username, source, aiResponse.String(), userLog.ID)
+					_, err := h.chatLogService.CreateAIMessageWithModel(username, source, aiResponse.String(), answer.Model, userLog.ID, userLog.ConversationID)
 					if err != nil {
 						logx.Error("Failed to save AI response to database: %v", err)
 					}
@@ -228,6 +228,67 @@ func (h *MessageHandler) processLLMMessage(ctx context.Context, event *larkim.P2
 	}
 }
 
+// processQueryMessage 调用意图对应的 MCP 工具, 先发送卡片提示查询中, 完成后将结果更新到卡片
+func (h *MessageHandler) processQueryMessage(ctx context.Context, event *larkim.P2MessageReceiveV1, userMessage, username, source string, userLog *model.ChatLog, parsed *intent.Intent) error {
+	receiveIDType := "open_id"
+	receiveID := *event.Event.Sender.SenderId.OpenId
+	if *event.Event.Message.ChatType == "group" {
+		receiveIDType = "chat_id"
+		receiveID = *event.Event.Message.ChatId
+	}
+
+	// 记录发起人用于工具调用审计
+	ctx = service.WithCaller(ctx, username, "feishu")
+	ctx = service.WithIMChannel(ctx)
+	ctxWithTimestamp := context.WithValue(ctx, "timestamp", time.Now().UnixNano())
+
+	cardID, err := h.client.CreateStreamingCard(ctxWithTimestamp, fmt.Sprintf("问题: %s", userMessage), "⏳ 正在查询,请稍候...")
+	if err != nil {
+		logx.Error("Failed to create query card: %v", err)
+		return h.client.SendTextMessage(ctx, receiveIDType, receiveID,
+			fmt.Sprintf("创建卡片失败: %v", err))
+	}
+	if _, err := h.client.SendCardMessage(ctx, receiveIDType, receiveID, cardID); err != nil {
+		logx.Error("Failed to send query card: %v", err)
+		return err
+	}
+
+	logx.Info("Calling MCP tool from Feishu, tool %s, params %v", parsed.MCPTool, parsed.Params)
+	params := make(map[string]any, len(parsed.Params))
+	for k, v := range parsed.Params {
+		params[k] = v
+	}
+
+	var content string
+	result, err := h.mcpServer.CallTool(ctx, parsed.MCPTool, params)
+	switch {
+	case err != nil:
+		logx.Error("Failed to call MCP tool %s: %v", parsed.MCPTool, err)
+		content = fmt.Sprintf("❌ **查询失败**\n\n错误: %s", err.Error())
+	case len(result.Content) > 0:
+		if text, ok := result.Content[0].(mcp.TextContent); ok {
+			content = text.Text
+		}
+	}
+	if content == "" {
+		content = "查询完成,但未返回结果"
+	}
+
+	finalContent := content + fmt.Sprintf("\n\n---\n⏰ *%s*", time.Now().Format("2006-01-02 15:04:05"))
+	if err := h.client.UpdateCardElement(ctxWithTimestamp, cardID, "markdown_content", finalContent, 1); err != nil {
+		logx.Error("Failed to update query card: %v", err)
+	}
+
+	// 保存查询结果到数据库
+	if userLog != nil {
+		_, saveErr := h.chatLogService.CreateAIMessageWithConversation(username, source, content, userLog.ID, userLog.ConversationID)
+		if saveErr != nil {
+			logx.Error("Failed to save query result to database: %v", saveErr)
+		}
+	}
+	return nil
+}
+
 // sendHelpMessage 发送帮助消息
 func (h *MessageHandler) sendHelpMessage(ctx context.Context, event *larkim.P2MessageReceiveV1, username, source string, userLog *model.ChatLog) error {
 	receiveIDType := "open_id"
@@ -242,7 +303,7 @@ func (h *MessageHandler) sendHelpMessage(ctx context.Context, event *larkim.P2Me
 
 	// 保存帮助消息到数据库
 	if userLog != nil {
-		_, saveErr := h.chatLogService.CreateAIMessage(username, source, helpText, userLog.ID)
+		_, saveErr := h.chatLogService.CreateAIMessageWithConversation(username, source, helpText, userLog.ID, userLog.ConversationID)
 		if saveErr != nil {
 			logx.Error("Failed to save help message to database: %v", saveErr)
 		}
@@ -291,7 +352,7 @@ ZenOps 是一个运维工具集成平台,支持通过飞书机器人与云平台
 支持查询以下云平台资源:
 - 阿里云: ECS、RDS 等
 - 腾讯云: CVM、CDB 等
-- Jenkins: 构建任务、Job 状态等
+- Jenkins: 构建任务、Job 状态、构建失败分析 (如 "分析 deploy-prod #12 构建失败") 等
 
 ## 使用提示
 - 发送 "帮助" 或 "help" 查看此帮助信息
@@ -0,0 +1,151 @@
+package imcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type tokenKey struct{}
+
+// withToken 在上下文中记录本次请求使用的 MCP 访问令牌
+func withToken(ctx context.Context, token *model.MCPToken) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+// tokenFromContext 获取本次请求使用的 MCP 访问令牌
+// 未携带令牌 (开启了匿名访问或使用 auth.tokens 全局令牌) 时返回 nil, 不限制工具范围
+func tokenFromContext(ctx context.Context) *model.MCPToken {
+	token, _ := ctx.Value(tokenKey{}).(*model.MCPToken)
+	return token
+}
+
+// globalTokensTTL auth.tokens 全局令牌的缓存有效期, 避免每个 MCP 请求都查询数据库
+const globalTokensTTL = 30 * time.Second
+
+// globalTokens 获取 auth.tokens 中的全局令牌, 缓存 globalTokensTTL
+func (s *MCPServer) globalTokens() []string {
+	s.tokensMu.Lock()
+	defer s.tokensMu.Unlock()
+	if s.tokens == nil || time.Since(s.tokensLoadedAt) >= globalTokensTTL {
+		tokens := service.NewConfigService().AuthTokens(s.config.Auth.Tokens)
+		s.tokens = make([]string, 0, len(tokens))
+		for _, token := range tokens {
+			if token != "" {
+				s.tokens = append(s.tokens, token)
+			}
+		}
+		s.tokensLoadedAt = time.Now()
+	}
+	return s.tokens
+}
+
+// hasAccessTokens 判断是否配置了任何可用于 MCP 端点的令牌
+func (s *MCPServer) hasAccessTokens() bool {
+	if len(s.globalTokens()) > 0 {
+		return true
+	}
+	hasTokens, err := service.NewMCPTokenService().HasTokens()
+	if err != nil {
+		logx.Warn("Failed to check mcp tokens: %v", err)
+		return false
+	}
+	return hasTokens
+}
+
+// authenticate MCP 端点的 Bearer Token 认证
+// 默认所有请求都必须携带令牌, 只有显式开启 server.mcp.allow_anonymous 时才允许匿名访问;
+// 数据库中的令牌按其工具范围限制可见和可调用的工具, auth.tokens 中的全局令牌可使用全部工具
+func (s *MCPServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret, hasToken := bearerToken(r.Header.Get("Authorization"))
+		if !hasToken {
+			if !s.config.Server.MCP.AllowAnonymous {
+				unauthorized(w, "missing bearer token")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		token, err := service.NewMCPTokenService().Authenticate(secret)
+		switch {
+		case err == nil:
+			ctx = withToken(ctx, token)
+			ctx = service.WithCaller(ctx, token.Name, "mcp")
+		case errors.Is(err, service.ErrInvalidMCPToken):
+			if !matchToken(secret, s.globalTokens()) {
+				unauthorized(w, "invalid bearer token")
+				return
+			}
+		default:
+			logx.Error("Failed to authenticate mcp token: %v", err)
+			http.Error(w, "failed to authenticate token", http.StatusInternalServerError)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// bearerToken 从 Authorization 头中提取 Bearer Token
+func bearerToken(authHeader string) (string, bool) {
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" || strings.TrimSpace(parts[1]) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(parts[1]), true
+}
+
+// matchToken 以固定时间比较令牌是否在列表中
+func matchToken(secret string, tokens []string) bool {
+	for _, token := range tokens {
+		if token != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// unauthorized 返回 401
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="zenops-mcp"`)
+	http.Error(w, message, http.StatusUnauthorized)
+}
+
+// filterToolsByToken 只向客户端列出令牌允许调用的工具
+func filterToolsByToken(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	token := tokenFromContext(ctx)
+	if token == nil {
+		return tools
+	}
+	allowed := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if token.AllowsTool(tool.Name) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
+
+// enforceTokenScope 拒绝调用令牌工具范围之外的工具
+func enforceTokenScope(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		token := tokenFromContext(ctx)
+		if token != nil && !token.AllowsTool(request.Params.Name) {
+			logx.Warn("MCP token %s is not allowed to call tool %s", token.Name, request.Params.Name)
+			return mcp.NewToolResultError(fmt.Sprintf("tool %s is not allowed for token %s", request.Params.Name, token.Name)), nil
+		}
+		return next(ctx, request)
+	}
+}
@@ -0,0 +1,113 @@
+package imcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "zenops-imcp-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("ZENOPS_DB_PATH", filepath.Join(dir, "zenops.db"))
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// serveMCP 以指定 Bearer Token 请求经过认证的端点, 返回状态码和处理器看到的令牌
+func serveMCP(s *MCPServer, bearer string) (int, *model.MCPToken) {
+	var seen *model.MCPToken
+	handler := s.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = tokenFromContext(r.Context())
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	if bearer != "" {
+		r.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w.Code, seen
+}
+
+func TestAuthenticateRequiresTokenByDefault(t *testing.T) {
+	s := &MCPServer{config: &config.Config{}}
+
+	if code, _ := serveMCP(s, ""); code != http.StatusUnauthorized {
+		t.Fatalf("request without token: status = %d, want 401", code)
+	}
+	if code, _ := serveMCP(s, "zmcp_unknown"); code != http.StatusUnauthorized {
+		t.Fatalf("request with unknown token: status = %d, want 401", code)
+	}
+}
+
+func TestAuthenticateAllowAnonymous(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.MCP.AllowAnonymous = true
+	s := &MCPServer{config: cfg}
+
+	if code, _ := serveMCP(s, ""); code != http.StatusOK {
+		t.Fatalf("anonymous request: status = %d, want 200", code)
+	}
+	if code, _ := serveMCP(s, "zmcp_unknown"); code != http.StatusUnauthorized {
+		t.Fatalf("request with unknown token: status = %d, want 401", code)
+	}
+}
+
+func TestAuthenticateGlobalToken(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Auth.Tokens = []string{"", "global-secret"}
+	s := &MCPServer{config: cfg}
+
+	code, token := serveMCP(s, "global-secret")
+	if code != http.StatusOK || token != nil {
+		t.Fatalf("global token: status = %d, token = %v, want 200 without token scope", code, token)
+	}
+	if code, _ := serveMCP(s, ""); code != http.StatusUnauthorized {
+		t.Fatalf("empty token must not match: status = %d, want 401", code)
+	}
+}
+
+func TestAuthenticateDatabaseToken(t *testing.T) {
+	s := &MCPServer{config: &config.Config{}}
+	tokenService := service.NewMCPTokenService()
+
+	token := &model.MCPToken{Name: "ci", Tools: model.StringArray{"list_jenkins_*"}, Enabled: true}
+	secret, err := tokenService.CreateToken(token)
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+
+	code, seen := serveMCP(s, secret)
+	if code != http.StatusOK || seen == nil || seen.ID != token.ID {
+		t.Fatalf("database token: status = %d, token = %v, want 200 with token %d", code, seen, token.ID)
+	}
+	if !seen.AllowsTool("list_jenkins_jobs") || seen.AllowsTool("trigger_jenkins_build") {
+		t.Fatalf("token scope not applied: %v", seen.Tools)
+	}
+
+	// 禁用和删除后缓存立即失效
+	disabled := *token
+	disabled.Enabled = false
+	if err := tokenService.UpdateToken(token, &disabled); err != nil {
+		t.Fatalf("UpdateToken() error = %v", err)
+	}
+	if code, _ := serveMCP(s, secret); code != http.StatusUnauthorized {
+		t.Fatalf("disabled token: status = %d, want 401", code)
+	}
+	if err := tokenService.DeleteToken(token.ID); err != nil {
+		t.Fatalf("DeleteToken() error = %v", err)
+	}
+	if code, _ := serveMCP(s, secret); code != http.StatusUnauthorized {
+		t.Fatalf("deleted token: status = %d, want 401", code)
+	}
+}
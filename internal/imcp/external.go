@@ -102,8 +102,9 @@ func (s *MCPServer) registerProxyTool(ctx context.Context, mcpClient *mcpclient.
 		return result, nil
 	}
 
-	// 注册到本地 MCP Server
+	// 注册到本地 MCP Server, 并记录来源用于调用日志
 	s.mcpServer.AddTool(proxyTool, handler)
+	s.recordOrigin(toolName, ToolOrigin{Server: clientName, Tool: originalToolName})
 
 	logx.Debug("📝 Registered proxy tool: %s -> %s.%s",
 		toolName, clientName, originalToolName)
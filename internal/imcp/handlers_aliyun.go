@@ -35,11 +35,12 @@ func (s *MCPServer) handleSearchECSByIP(ctx context.Context, request mcp.CallToo
 	// 使用增强的 IP 查询功能
 	instance, err := client.GetECSInstanceByIP(ctx, ip, ipType)
 	if err != nil {
-		return mcp.NewToolResultText(fmt.Sprintf("未找到 IP 为 %s 的 ECS 实例: %v", ip, err)), nil
+		result := fmt.Sprintf("未找到 IP 为 %s 的 ECS 实例: %v", ip, err)
+		return mcp.NewToolResultText(s.appendPodIPMatches(ctx, ip, result)), nil
 	}
 
 	result := formatInstances([]*model.Instance{instance}, aliyunConfig.Name)
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultText(s.appendPodIPMatches(ctx, ip, result)), nil
 }
 
 // handleSearchECSByName 处理根据名称搜索 ECS 的请求
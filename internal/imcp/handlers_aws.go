@@ -0,0 +1,404 @@
+package imcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ==================== AWS EC2 处理函数 ====================
+
+// handleSearchEC2ByIP 处理根据 IP 搜索 AWS EC2 的请求
+func (s *MCPServer) handleSearchEC2ByIP(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	ip, ok := args["ip"].(string)
+	if !ok || ip == "" {
+		return mcp.NewToolResultError("ip parameter is required"), nil
+	}
+
+	accountName, _ := args["account"].(string)
+	region, _ := args["region"].(string)
+
+	p, awsConfig, err := s.getAWSProvider(accountName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	instances, err := listAllAWSInstances(ctx, p, region)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("查询 EC2 实例失败: %v", err)), nil
+	}
+
+	var matchedInstances []*model.Instance
+	for _, inst := range instances {
+		if containsString(inst.PrivateIP, ip) || containsString(inst.PublicIP, ip) {
+			matchedInstances = append(matchedInstances, inst)
+		}
+	}
+
+	if len(matchedInstances) == 0 {
+		result := fmt.Sprintf("未找到 IP 为 %s 的 AWS EC2 实例", ip)
+		return mcp.NewToolResultText(s.appendPodIPMatches(ctx, ip, result)), nil
+	}
+
+	result := formatInstances(matchedInstances, awsConfig.Name)
+	return mcp.NewToolResultText(s.appendPodIPMatches(ctx, ip, result)), nil
+}
+
+// handleSearchEC2ByName 处理根据名称搜索 AWS EC2 的请求
+func (s *MCPServer) handleSearchEC2ByName(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return mcp.NewToolResultError("name parameter is required"), nil
+	}
+
+	accountName, _ := args["account"].(string)
+	region, _ := args["region"].(string)
+
+	p, awsConfig, err := s.getAWSProvider(accountName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	instances, err := listAllAWSInstances(ctx, p, region)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("查询 EC2 实例失败: %v", err)), nil
+	}
+
+	var matchedInstances []*model.Instance
+	for _, inst := range instances {
+		if inst.Name == name {
+			matchedInstances = append(matchedInstances, inst)
+		}
+	}
+
+	if len(matchedInstances) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("未找到名称为 %s 的 AWS EC2 实例", name)), nil
+	}
+
+	result := formatInstances(matchedInstances, awsConfig.Name)
+	return mcp.NewToolResultText(result), nil
+}
+
+// handleListEC2 处理列出 AWS EC2 实例的请求
+func (s *MCPServer) handleListEC2(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		args = make(map[string]any)
+	}
+
+	accountName, _ := args["account"].(string)
+	region, _ := args["region"].(string)
+	status, _ := args["status"].(string)
+
+	p, awsConfig, err := s.getAWSProvider(accountName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var allInstances []*model.Instance
+	pageNum := 1
+	pageSize := 100
+
+	for {
+		opts := &provider.QueryOptions{
+			Region:   region,
+			PageSize: pageSize,
+			PageNum:  pageNum,
+			Filters:  map[string]string{},
+		}
+		if status != "" {
+			opts.Filters["status"] = strings.ToLower(status)
+		}
+
+		instances, err := p.ListInstances(ctx, opts)
+		if err != nil {
+			logx.Error("Failed to list instances: %v", err)
+			break
+		}
+
+		allInstances = append(allInstances, instances...)
+
+		if len(instances) < pageSize {
+			break
+		}
+		pageNum++
+	}
+
+	result := formatInstances(allInstances, awsConfig.Name)
+	return mcp.NewToolResultText(result), nil
+}
+
+// handleGetEC2 处理获取 AWS EC2 实例详情的请求
+func (s *MCPServer) handleGetEC2(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	instanceID, ok := args["instance_id"].(string)
+	if !ok || instanceID == "" {
+		return mcp.NewToolResultError("instance_id parameter is required"), nil
+	}
+
+	accountName, _ := args["account"].(string)
+
+	p, awsConfig, err := s.getAWSProvider(accountName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	instance, err := p.GetInstance(ctx, instanceID)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("未找到实例 ID 为 %s 的 AWS EC2 实例: %v", instanceID, err)), nil
+	}
+
+	result := formatInstances([]*model.Instance{instance}, awsConfig.Name)
+	return mcp.NewToolResultText(result), nil
+}
+
+// ==================== AWS RDS 处理函数 ====================
+
+// handleListAWSRDS 处理列出 AWS RDS 实例的请求
+func (s *MCPServer) handleListAWSRDS(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		args = make(map[string]any)
+	}
+
+	accountName, _ := args["account"].(string)
+	region, _ := args["region"].(string)
+
+	p, awsConfig, err := s.getAWSProvider(accountName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	databases, err := listAllAWSDatabases(ctx, p, region)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("查询 RDS 实例失败: %v", err)), nil
+	}
+
+	result := formatDatabases(databases, awsConfig.Name)
+	return mcp.NewToolResultText(result), nil
+}
+
+// handleSearchAWSRDSByName 处理根据名称搜索 AWS RDS 的请求
+func (s *MCPServer) handleSearchAWSRDSByName(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return mcp.NewToolResultError("name parameter is required"), nil
+	}
+
+	accountName, _ := args["account"].(string)
+
+	p, awsConfig, err := s.getAWSProvider(accountName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	databases, err := listAllAWSDatabases(ctx, p, "")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("查询 RDS 实例失败: %v", err)), nil
+	}
+
+	var matchedDatabases []*model.Database
+	for _, db := range databases {
+		if db.Name == name {
+			matchedDatabases = append(matchedDatabases, db)
+		}
+	}
+
+	if len(matchedDatabases) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("未找到名称为 %s 的 AWS RDS 实例", name)), nil
+	}
+
+	result := formatDatabases(matchedDatabases, awsConfig.Name)
+	return mcp.NewToolResultText(result), nil
+}
+
+// ==================== AWS S3 处理函数 ====================
+
+// handleListS3 处理列出 S3 存储桶的请求
+func (s *MCPServer) handleListS3(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		args = make(map[string]any)
+	}
+
+	accountName, _ := args["account"].(string)
+
+	p, awsConfig, err := s.getAWSProvider(accountName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var allBuckets []*model.OSSBucket
+	pageNum := 1
+	pageSize := 100
+
+	for {
+		opts := &provider.QueryOptions{
+			PageSize: pageSize,
+			PageNum:  pageNum,
+		}
+
+		buckets, err := p.ListOSSBuckets(ctx, opts)
+		if err != nil {
+			logx.Error("Failed to list S3 buckets: %v", err)
+			break
+		}
+
+		allBuckets = append(allBuckets, buckets...)
+
+		if len(buckets) < pageSize {
+			break
+		}
+		pageNum++
+	}
+
+	result := formatS3Buckets(allBuckets, awsConfig.Name)
+	return mcp.NewToolResultText(result), nil
+}
+
+// handleGetS3 处理获取 S3 存储桶详情的请求
+func (s *MCPServer) handleGetS3(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	bucketName, ok := args["bucket_name"].(string)
+	if !ok || bucketName == "" {
+		return mcp.NewToolResultError("bucket_name parameter is required"), nil
+	}
+
+	accountName, _ := args["account"].(string)
+
+	p, awsConfig, err := s.getAWSProvider(accountName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	bucket, err := p.GetOSSBucket(ctx, bucketName)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("未找到存储桶 %s: %v", bucketName, err)), nil
+	}
+
+	result := formatS3Buckets([]*model.OSSBucket{bucket}, awsConfig.Name)
+	return mcp.NewToolResultText(result), nil
+}
+
+// listAllAWSInstances 分页拉取 AWS EC2 全部实例
+func listAllAWSInstances(ctx context.Context, p provider.Provider, region string) ([]*model.Instance, error) {
+	var allInstances []*model.Instance
+	pageNum := 1
+	pageSize := 100
+
+	for {
+		instances, err := p.ListInstances(ctx, &provider.QueryOptions{
+			Region:   region,
+			PageSize: pageSize,
+			PageNum:  pageNum,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		allInstances = append(allInstances, instances...)
+
+		if len(instances) < pageSize {
+			break
+		}
+		pageNum++
+	}
+
+	return allInstances, nil
+}
+
+// listAllAWSDatabases 分页拉取 AWS RDS 全部实例
+func listAllAWSDatabases(ctx context.Context, p provider.Provider, region string) ([]*model.Database, error) {
+	var allDatabases []*model.Database
+	pageNum := 1
+	pageSize := 100
+
+	for {
+		databases, err := p.ListDatabases(ctx, &provider.QueryOptions{
+			Region:   region,
+			PageSize: pageSize,
+			PageNum:  pageNum,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		allDatabases = append(allDatabases, databases...)
+
+		if len(databases) < pageSize {
+			break
+		}
+		pageNum++
+	}
+
+	return allDatabases, nil
+}
+
+// containsString 判断切片中是否包含指定字符串
+func containsString(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
+
+// formatS3Buckets 格式化 S3 存储桶列表
+func formatS3Buckets(buckets []*model.OSSBucket, accountName string) string {
+	if len(buckets) == 0 {
+		return "未找到任何 S3 存储桶"
+	}
+
+	result := fmt.Sprintf("## AWS S3 存储桶列表 (账号: %s)\n\n", accountName)
+	result += fmt.Sprintf("总数: %d\n\n", len(buckets))
+
+	for _, bucket := range buckets {
+		result += fmt.Sprintf("### %s\n", bucket.Name)
+		if bucket.Region != "" {
+			result += fmt.Sprintf("- **区域**: %s\n", bucket.Region)
+		}
+		result += fmt.Sprintf("- **创建时间**: %s\n", bucket.CreatedAt)
+		if bucket.ACL != "" {
+			result += fmt.Sprintf("- **访问控制**: %s\n", bucket.ACL)
+		}
+		if versioning, ok := bucket.Metadata["versioning"]; ok {
+			result += fmt.Sprintf("- **版本控制**: %v\n", versioning)
+		}
+
+		if bucket.ConsoleURL != "" {
+			result += fmt.Sprintf("- **控制台**: %s\n", bucket.ConsoleURL)
+		}
+		result += "\n"
+	}
+
+	return result
+}
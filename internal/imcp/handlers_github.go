@@ -0,0 +1,210 @@
+package imcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ==================== GitHub Actions 处理函数 ====================
+
+// handleListGitHubWorkflows 处理列出仓库工作流的请求
+func (s *MCPServer) handleListGitHubWorkflows(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	repo, ok := args["repo"].(string)
+	if !ok || repo == "" {
+		return mcp.NewToolResultError("repo parameter is required"), nil
+	}
+
+	p, err := s.getGitHubProvider()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var allWorkflows []*model.Job
+	pageNum := 1
+	pageSize := 100
+
+	for {
+		opts := &provider.QueryOptions{
+			PageSize: pageSize,
+			PageNum:  pageNum,
+			Filters:  map[string]string{"repo": repo},
+		}
+
+		workflows, err := p.ListJobs(ctx, opts)
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("获取仓库 '%s' 的工作流失败: %v", repo, err)), nil
+		}
+
+		allWorkflows = append(allWorkflows, workflows...)
+
+		if len(workflows) < pageSize {
+			break
+		}
+		pageNum++
+	}
+
+	result := formatWorkflows(allWorkflows, repo)
+	return mcp.NewToolResultText(result), nil
+}
+
+// handleGetGitHubWorkflow 处理获取工作流详情的请求
+func (s *MCPServer) handleGetGitHubWorkflow(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	workflow, ok := args["workflow"].(string)
+	if !ok || workflow == "" {
+		return mcp.NewToolResultError("workflow parameter is required"), nil
+	}
+
+	p, err := s.getGitHubProvider()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	job, err := p.GetJob(ctx, workflow)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("未找到工作流 '%s': %v", workflow, err)), nil
+	}
+
+	result := formatWorkflows([]*model.Job{job}, "")
+	if job.LastBuild != nil {
+		result += "最近一次运行:\n" + formatWorkflowRun(job.LastBuild)
+	}
+	return mcp.NewToolResultText(result), nil
+}
+
+// handleListGitHubRuns 处理列出工作流运行记录的请求
+func (s *MCPServer) handleListGitHubRuns(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	target, ok := args["target"].(string)
+	if !ok || target == "" {
+		return mcp.NewToolResultError("target parameter is required"), nil
+	}
+
+	// 获取可选的 limit 参数,默认为 10
+	limit := 10
+	if limitArg, ok := args["limit"].(float64); ok && limitArg > 0 {
+		limit = int(limitArg)
+	}
+
+	filters := map[string]string{}
+	for _, key := range []string{"branch", "status", "event"} {
+		if v, _ := args[key].(string); v != "" {
+			filters[key] = v
+		}
+	}
+
+	p, err := s.getGitHubProvider()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	runs, err := p.ListRuns(ctx, target, &provider.QueryOptions{
+		PageSize: limit,
+		PageNum:  1,
+		Filters:  filters,
+	})
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取 '%s' 的运行记录失败: %v", target, err)), nil
+	}
+
+	if len(runs) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("'%s' 没有匹配的运行记录", target)), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("'%s' 的运行记录 (共 %d 条):\n\n", target, len(runs)))
+	for i, run := range runs {
+		sb.WriteString(fmt.Sprintf("Run %d:\n", i+1))
+		sb.WriteString(formatWorkflowRun(run))
+		sb.WriteString("\n")
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// ==================== GitHub Actions 格式化函数 ====================
+
+// formatWorkflows 格式化工作流列表为文本输出
+func formatWorkflows(workflows []*model.Job, repo string) string {
+	if len(workflows) == 0 {
+		return fmt.Sprintf("仓库 '%s' 没有工作流", repo)
+	}
+
+	var sb strings.Builder
+	if repo != "" {
+		sb.WriteString(fmt.Sprintf("仓库 '%s' 的工作流 (共 %d 个):\n\n", repo, len(workflows)))
+	}
+
+	for i, workflow := range workflows {
+		sb.WriteString(fmt.Sprintf("Workflow %d:\n", i+1))
+		sb.WriteString(fmt.Sprintf("  标识: %s\n", workflow.Name))
+		sb.WriteString(fmt.Sprintf("  名称: %s\n", workflow.DisplayName))
+		if workflow.Description != "" {
+			sb.WriteString(fmt.Sprintf("  文件: %s\n", workflow.Description))
+		}
+
+		state := "启用"
+		if !workflow.Buildable {
+			state = "禁用"
+		}
+		sb.WriteString(fmt.Sprintf("  状态: %s\n", state))
+		if workflow.URL != "" {
+			sb.WriteString(fmt.Sprintf("  URL: %s\n", workflow.URL))
+		}
+
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// formatWorkflowRun 格式化单次运行记录
+func formatWorkflowRun(run *model.Build) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("  运行号: #%d\n", run.Number))
+	sb.WriteString(fmt.Sprintf("  状态: %s\n", run.Status))
+	if run.Result != "" {
+		sb.WriteString(fmt.Sprintf("  结论: %s\n", run.Result))
+	}
+	if run.Ref != "" {
+		sb.WriteString(fmt.Sprintf("  分支: %s\n", run.Ref))
+	}
+	if run.Commit != "" {
+		sb.WriteString(fmt.Sprintf("  提交: %s\n", shortSHA(run.Commit)))
+	}
+	if run.Actor != "" {
+		sb.WriteString(fmt.Sprintf("  触发人: %s\n", run.Actor))
+	}
+	if run.Trigger != "" {
+		sb.WriteString(fmt.Sprintf("  触发事件: %s\n", run.Trigger))
+	}
+	if !run.Timestamp.IsZero() {
+		sb.WriteString(fmt.Sprintf("  开始时间: %s\n", run.Timestamp.Format("2006-01-02 15:04:05")))
+	}
+	if run.Duration > 0 {
+		sb.WriteString(fmt.Sprintf("  时长: %s\n", time.Duration(run.Duration)*time.Millisecond))
+	}
+	if run.URL != "" {
+		sb.WriteString(fmt.Sprintf("  URL: %s\n", run.URL))
+	}
+	return sb.String()
+}
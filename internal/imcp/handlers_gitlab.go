@@ -0,0 +1,196 @@
+package imcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ==================== GitLab 处理函数 ====================
+
+// handleListGitLabPipelines 处理列出 GitLab 流水线的请求
+func (s *MCPServer) handleListGitLabPipelines(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	project, ok := args["project"].(string)
+	if !ok || project == "" {
+		return mcp.NewToolResultError("project parameter is required"), nil
+	}
+
+	// 获取可选的 limit 参数,默认为 10
+	limit := 10
+	if limitArg, ok := args["limit"].(float64); ok && limitArg > 0 {
+		limit = int(limitArg)
+	}
+
+	filters := map[string]string{}
+	if ref, _ := args["ref"].(string); ref != "" {
+		filters["ref"] = ref
+	}
+	if status, _ := args["status"].(string); status != "" {
+		filters["status"] = status
+	}
+
+	p, err := s.getGitLabProvider()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	pipelines, err := p.ListPipelines(ctx, project, &provider.QueryOptions{
+		PageSize: limit,
+		PageNum:  1,
+		Filters:  filters,
+	})
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取项目 '%s' 的流水线失败: %v", project, err)), nil
+	}
+
+	if len(pipelines) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("项目 '%s' 没有匹配的流水线", project)), nil
+	}
+
+	result := formatPipelines(pipelines, project)
+	return mcp.NewToolResultText(result), nil
+}
+
+// handleGetGitLabPipeline 处理获取 GitLab 流水线详情的请求, 包含各阶段作业状态
+func (s *MCPServer) handleGetGitLabPipeline(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	project, ok := args["project"].(string)
+	if !ok || project == "" {
+		return mcp.NewToolResultError("project parameter is required"), nil
+	}
+
+	pipelineID, ok := args["pipeline_id"].(float64)
+	if !ok || pipelineID <= 0 {
+		return mcp.NewToolResultError("pipeline_id parameter is required"), nil
+	}
+
+	p, err := s.getGitLabProvider()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	pipeline, err := p.GetPipeline(ctx, project, int(pipelineID))
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("未找到流水线 #%d: %v", int(pipelineID), err)), nil
+	}
+
+	jobs, err := p.ListPipelineJobs(ctx, project, int(pipelineID))
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取流水线 #%d 的作业失败: %v", int(pipelineID), err)), nil
+	}
+
+	result := formatPipelineDetail(pipeline, jobs, project)
+	return mcp.NewToolResultText(result), nil
+}
+
+// ==================== GitLab 格式化函数 ====================
+
+// formatPipelines 格式化 GitLab 流水线列表为文本输出
+func formatPipelines(pipelines []*model.Build, project string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("项目 '%s' 的流水线 (共 %d 条):\n\n", project, len(pipelines)))
+
+	for i, pipeline := range pipelines {
+		sb.WriteString(fmt.Sprintf("Pipeline %d:\n", i+1))
+		sb.WriteString(fmt.Sprintf("  ID: #%d\n", pipeline.Number))
+		sb.WriteString(fmt.Sprintf("  状态: %s\n", pipeline.Status))
+		if pipeline.Ref != "" {
+			sb.WriteString(fmt.Sprintf("  分支: %s\n", pipeline.Ref))
+		}
+		if pipeline.Commit != "" {
+			sb.WriteString(fmt.Sprintf("  提交: %s\n", shortSHA(pipeline.Commit)))
+		}
+		if !pipeline.Timestamp.IsZero() {
+			sb.WriteString(fmt.Sprintf("  时间: %s\n", pipeline.Timestamp.Format("2006-01-02 15:04:05")))
+		}
+		if pipeline.URL != "" {
+			sb.WriteString(fmt.Sprintf("  URL: %s\n", pipeline.URL))
+		}
+
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// formatPipelineDetail 格式化 GitLab 流水线详情, 作业按阶段分组输出
+func formatPipelineDetail(pipeline *model.Build, jobs []*model.PipelineJob, project string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("项目 '%s' 的流水线 #%d:\n\n", project, pipeline.Number))
+	sb.WriteString(fmt.Sprintf("  状态: %s\n", pipeline.Status))
+	if pipeline.Ref != "" {
+		sb.WriteString(fmt.Sprintf("  分支: %s\n", pipeline.Ref))
+	}
+	if pipeline.Commit != "" {
+		sb.WriteString(fmt.Sprintf("  提交: %s\n", shortSHA(pipeline.Commit)))
+	}
+	if !pipeline.Timestamp.IsZero() {
+		sb.WriteString(fmt.Sprintf("  开始时间: %s\n", pipeline.Timestamp.Format("2006-01-02 15:04:05")))
+	}
+	if pipeline.Duration > 0 {
+		sb.WriteString(fmt.Sprintf("  时长: %s\n", time.Duration(pipeline.Duration)*time.Millisecond))
+	}
+	if pipeline.URL != "" {
+		sb.WriteString(fmt.Sprintf("  URL: %s\n", pipeline.URL))
+	}
+
+	if len(jobs) == 0 {
+		sb.WriteString("\n该流水线没有作业\n")
+		return sb.String()
+	}
+
+	// 按阶段出现的顺序分组
+	var stages []string
+	stageJobs := make(map[string][]*model.PipelineJob)
+	for _, job := range jobs {
+		if _, ok := stageJobs[job.Stage]; !ok {
+			stages = append(stages, job.Stage)
+		}
+		stageJobs[job.Stage] = append(stageJobs[job.Stage], job)
+	}
+
+	sb.WriteString(fmt.Sprintf("\n作业 (共 %d 个):\n", len(jobs)))
+	for _, stage := range stages {
+		sb.WriteString(fmt.Sprintf("\n[阶段 %s]\n", stage))
+		for _, job := range stageJobs[stage] {
+			line := fmt.Sprintf("  - %s: %s", job.Name, job.Status)
+			if job.Duration > 0 {
+				line += fmt.Sprintf(" (%s)", time.Duration(job.Duration)*time.Millisecond)
+			}
+			if job.AllowFailure {
+				line += " [允许失败]"
+			}
+			if job.FailureReason != "" {
+				line += fmt.Sprintf(" 失败原因: %s", job.FailureReason)
+			}
+			sb.WriteString(line + "\n")
+			if job.URL != "" {
+				sb.WriteString(fmt.Sprintf("    URL: %s\n", job.URL))
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// shortSHA 返回提交 SHA 的前 8 位
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -3,11 +3,18 @@ package imcp
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/llm"
 	"github.com/eryajf/zenops/internal/model"
 	"github.com/eryajf/zenops/internal/provider"
+	"github.com/eryajf/zenops/internal/provider/jenkins"
+	"github.com/eryajf/zenops/internal/service"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -15,36 +22,35 @@ import (
 
 // handleListJenkinsJobs 处理列出所有 Jenkins Job 的请求
 func (s *MCPServer) handleListJenkinsJobs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	p, err := s.getJenkinsProvider()
+	// 获取可选的 instance、folder、include_branches 参数
+	var instance, folder string
+	var includeBranches bool
+	if args, ok := request.Params.Arguments.(map[string]any); ok {
+		instance, _ = args["instance"].(string)
+		folder, _ = args["folder"].(string)
+		includeBranches, _ = args["include_branches"].(bool)
+	}
+
+	p, jenkinsConfig, err := s.getJenkinsProvider(instance)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	var allJobs []*model.Job
-	pageNum := 1
-	pageSize := 100
-
-	for {
-		opts := &provider.QueryOptions{
-			PageSize: pageSize,
-			PageNum:  pageNum,
-		}
-
-		jobs, err := p.ListJobs(ctx, opts)
-		if err != nil {
-			logx.Error("Failed to list jobs: %v", err)
-			break
-		}
-
-		allJobs = append(allJobs, jobs...)
+	// 递归遍历需要逐个查询文件夹, 一次取回全部 Job, 不再分页
+	opts := &provider.QueryOptions{
+		Filters: map[string]string{
+			"folder":   folder,
+			"branches": strconv.FormatBool(includeBranches),
+		},
+	}
 
-		if len(jobs) < pageSize {
-			break
-		}
-		pageNum++
+	jobs, err := p.ListJobs(ctx, opts)
+	if err != nil {
+		logx.Error("Failed to list jobs: %v", err)
+		return mcp.NewToolResultText(fmt.Sprintf("获取 Jenkins Job 列表失败: %v", err)), nil
 	}
 
-	result := formatJobs(allJobs)
+	result := fmt.Sprintf("Jenkins 实例: %s\n", jenkinsConfig.Name) + formatJobs(jobs)
 	return mcp.NewToolResultText(result), nil
 }
 
@@ -60,7 +66,8 @@ func (s *MCPServer) handleGetJenkinsJob(ctx context.Context, request mcp.CallToo
 		return mcp.NewToolResultError("job_name parameter is required"), nil
 	}
 
-	p, err := s.getJenkinsProvider()
+	instance, _ := args["instance"].(string)
+	p, _, err := s.getJenkinsProvider(instance)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -92,7 +99,8 @@ func (s *MCPServer) handleListJenkinsBuilds(ctx context.Context, request mcp.Cal
 		limit = int(limitArg)
 	}
 
-	p, err := s.getJenkinsProvider()
+	instance, _ := args["instance"].(string)
+	p, _, err := s.getJenkinsProvider(instance)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -110,6 +118,524 @@ func (s *MCPServer) handleListJenkinsBuilds(ctx context.Context, request mcp.Cal
 	return mcp.NewToolResultText(result), nil
 }
 
+// handleTriggerJenkinsBuild 处理触发 Jenkins 构建的请求
+func (s *MCPServer) handleTriggerJenkinsBuild(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	startTime := time.Now()
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	result := s.triggerJenkinsBuild(ctx, args)
+	auditToolCall(ctx, "trigger_jenkins_build", args, result, startTime)
+	return result, nil
+}
+
+// triggerJenkinsBuild 校验参数并触发构建
+func (s *MCPServer) triggerJenkinsBuild(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+	jobName, ok := args["job_name"].(string)
+	if !ok || jobName == "" {
+		return mcp.NewToolResultError("job_name parameter is required")
+	}
+
+	params := make(map[string]string)
+	if raw, ok := args["parameters"].(map[string]any); ok {
+		for key, value := range raw {
+			params[key] = fmt.Sprint(value)
+		}
+	}
+
+	// 获取可选的 wait_seconds 参数,默认等待 10 秒,最多 60 秒
+	wait := jenkins.DefaultTriggerWait
+	if waitArg, ok := getIntArg(args, "wait_seconds"); ok && waitArg >= 0 {
+		wait = min(time.Duration(waitArg)*time.Second, time.Minute)
+	}
+
+	instance, _ := args["instance"].(string)
+	p, _, err := s.getJenkinsProvider(instance)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error())
+	}
+
+	item, err := p.TriggerBuild(ctx, jobName, params, wait)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("触发 Job '%s' 失败: %v", jobName, err))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("已触发 Job '%s' 的构建:\n\n", jobName))
+	sb.WriteString(formatQueueItem(item))
+	if item.BuildNumber == 0 && !item.Cancelled {
+		sb.WriteString("\n构建仍在排队中,可稍后通过 get_jenkins_queue 或 list_jenkins_builds 查看构建号\n")
+	}
+	return mcp.NewToolResultText(sb.String())
+}
+
+// handleAbortJenkinsBuild 处理中止 Jenkins 构建的请求
+func (s *MCPServer) handleAbortJenkinsBuild(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	startTime := time.Now()
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	result := s.abortJenkinsBuild(ctx, args)
+	auditToolCall(ctx, "abort_jenkins_build", args, result, startTime)
+	return result, nil
+}
+
+// abortJenkinsBuild 中止运行中的构建, 或取消尚在排队的构建
+func (s *MCPServer) abortJenkinsBuild(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+	instance, _ := args["instance"].(string)
+	p, _, err := s.getJenkinsProvider(instance)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error())
+	}
+
+	// 指定 queue_id 时取消排队中的构建
+	if queueID, ok := getIntArg(args, "queue_id"); ok && queueID > 0 {
+		if err := p.CancelQueueItem(ctx, int64(queueID)); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("取消队列项 %d 失败: %v", queueID, err))
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("已取消队列项 %d", queueID))
+	}
+
+	jobName, ok := args["job_name"].(string)
+	if !ok || jobName == "" {
+		return mcp.NewToolResultError("job_name parameter is required")
+	}
+
+	buildNumber, ok := getIntArg(args, "build_number")
+	if !ok || buildNumber <= 0 {
+		return mcp.NewToolResultError("build_number parameter is required")
+	}
+
+	build, err := p.AbortBuild(ctx, jobName, buildNumber)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("中止 Job '%s' 的构建 #%d 失败: %v", jobName, buildNumber, err))
+	}
+
+	result := fmt.Sprintf("已中止 Job '%s' 的构建 #%d\n", jobName, build.Number)
+	if build.URL != "" {
+		result += fmt.Sprintf("URL: %s\n", build.URL)
+	}
+	return mcp.NewToolResultText(result)
+}
+
+// handleGetJenkinsQueue 处理查询 Jenkins 构建队列的请求
+func (s *MCPServer) handleGetJenkinsQueue(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// 获取可选的 job_name 参数,用于过滤
+	var jobName, instance string
+	if args, ok := request.Params.Arguments.(map[string]any); ok {
+		jobName, _ = args["job_name"].(string)
+		instance, _ = args["instance"].(string)
+	}
+
+	p, _, err := s.getJenkinsProvider(instance)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	items, err := p.GetQueue(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取 Jenkins 构建队列失败: %v", err)), nil
+	}
+
+	if jobName != "" {
+		filtered := items[:0]
+		for _, item := range items {
+			if item.JobName == jobName {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
+	if len(items) == 0 {
+		return mcp.NewToolResultText("Jenkins 构建队列为空"), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Jenkins 构建队列 (共 %d 项):\n\n", len(items)))
+	for i, item := range items {
+		sb.WriteString(fmt.Sprintf("Item %d:\n", i+1))
+		sb.WriteString(formatQueueItem(item))
+		sb.WriteString("\n")
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// handleListJenkinsBranches 处理列出多分支项目分支的请求
+func (s *MCPServer) handleListJenkinsBranches(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	jobName, ok := args["job_name"].(string)
+	if !ok || jobName == "" {
+		return mcp.NewToolResultError("job_name parameter is required"), nil
+	}
+
+	instance, _ := args["instance"].(string)
+	p, _, err := s.getJenkinsProvider(instance)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	branches, err := p.ListBranches(ctx, jobName)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取 Job '%s' 的分支失败: %v", jobName, err)), nil
+	}
+
+	if len(branches) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("多分支项目 '%s' 没有分支", jobName)), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("多分支项目 '%s' 共 %d 个分支:\n\n", jobName, len(branches)))
+	for i, branch := range branches {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, branch.DisplayName))
+		sb.WriteString(fmt.Sprintf("   Job 路径: %s\n", branch.Name))
+		if branch.Status != "" {
+			sb.WriteString(fmt.Sprintf("   状态: %s\n", branch.Status))
+		}
+		if branch.LastBuild != nil {
+			sb.WriteString(fmt.Sprintf("   最后构建: #%d\n", branch.LastBuild.Number))
+		}
+	}
+	sb.WriteString("\n查询分支的构建历史或日志时,使用 Job 路径作为 job_name\n")
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// handleListJenkinsAgents 处理列出 Jenkins 构建节点的请求
+func (s *MCPServer) handleListJenkinsAgents(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// 获取可选的 instance、label、status 参数
+	var instance, label, status string
+	if args, ok := request.Params.Arguments.(map[string]any); ok {
+		instance, _ = args["instance"].(string)
+		label, _ = args["label"].(string)
+		status, _ = args["status"].(string)
+	}
+
+	p, jenkinsConfig, err := s.getJenkinsProvider(instance)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	agents, err := p.ListAgents(ctx)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取 Jenkins 构建节点失败: %v", err)), nil
+	}
+
+	filtered := agents[:0]
+	for _, agent := range agents {
+		if label != "" && !slices.Contains(agent.Labels, label) {
+			continue
+		}
+		switch strings.ToLower(status) {
+		case "online":
+			if !agent.Online {
+				continue
+			}
+		case "offline":
+			if agent.Online {
+				continue
+			}
+		}
+		filtered = append(filtered, agent)
+	}
+	agents = filtered
+
+	if len(agents) == 0 {
+		return mcp.NewToolResultText("未找到匹配的 Jenkins 构建节点"), nil
+	}
+
+	online, busy, executors := 0, 0, 0
+	for _, agent := range agents {
+		if agent.Online {
+			online++
+			executors += agent.Executors
+		}
+		busy += agent.BusyExecutors
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Jenkins 实例 %s 共 %d 个构建节点 (在线 %d, 离线 %d, 执行器忙碌 %d/%d):\n\n",
+		jenkinsConfig.Name, len(agents), online, len(agents)-online, busy, executors))
+	for i, agent := range agents {
+		state := "在线"
+		if !agent.Online {
+			state = "离线"
+			if agent.TemporarilyOffline {
+				state = "离线(手动标记)"
+			}
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s (%s)\n", i+1, agent.Name, state))
+		if agent.OfflineReason != "" {
+			sb.WriteString(fmt.Sprintf("   离线原因: %s\n", agent.OfflineReason))
+		}
+		sb.WriteString(fmt.Sprintf("   执行器: 忙碌 %d / 共 %d\n", agent.BusyExecutors, agent.Executors))
+		if len(agent.Labels) > 0 {
+			sb.WriteString(fmt.Sprintf("   标签: %s\n", strings.Join(agent.Labels, ", ")))
+		}
+		for _, build := range agent.RunningBuilds {
+			sb.WriteString(fmt.Sprintf("   运行中: %s\n", build))
+		}
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// ==================== Jenkins 多实例 ====================
+
+// handleListJenkinsInstances 处理列出 Jenkins 实例的请求
+func (s *MCPServer) handleListJenkinsInstances(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	instances := service.NewConfigService().ListJenkinsInstances(s.config.CICD.Jenkins)
+	if len(instances) == 0 {
+		return mcp.NewToolResultText("未配置任何 Jenkins 实例"), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("共 %d 个 Jenkins 实例:\n\n", len(instances)))
+	for i, instance := range instances {
+		status := "启用"
+		if !instance.Enabled {
+			status = "禁用"
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s (%s)\n", i+1, instance.Name, status))
+		sb.WriteString(fmt.Sprintf("   URL: %s\n", instance.URL))
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// handleSearchJenkinsJob 处理搜索 Jenkins Job 的请求
+// 未指定实例时并发搜索所有启用的实例
+func (s *MCPServer) handleSearchJenkinsJob(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	keyword, ok := args["keyword"].(string)
+	if !ok || keyword == "" {
+		return mcp.NewToolResultError("keyword parameter is required"), nil
+	}
+
+	var instances []jenkins.Instance
+	if instance, _ := args["instance"].(string); instance != "" {
+		p, jenkinsConfig, err := s.getJenkinsProvider(instance)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		instances = append(instances, jenkins.Instance{Name: jenkinsConfig.Name, Provider: p})
+	} else {
+		instances = s.getEnabledJenkinsInstances()
+		if len(instances) == 0 {
+			return mcp.NewToolResultError("no enabled jenkins instance"), nil
+		}
+	}
+
+	results := jenkins.SearchJobsInInstances(ctx, instances, keyword)
+
+	total := 0
+	for _, r := range results {
+		total += len(r.Jobs)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("在 %d 个 Jenkins 实例中搜索 '%s', 共找到 %d 个 Job:\n\n", len(results), keyword, total))
+	for _, r := range results {
+		sb.WriteString(fmt.Sprintf("### 实例: %s\n", r.Instance))
+		switch {
+		case r.Error != "":
+			sb.WriteString(fmt.Sprintf("搜索失败: %s\n\n", r.Error))
+		case len(r.Jobs) == 0:
+			sb.WriteString("未找到匹配的 Job\n\n")
+		default:
+			for _, job := range r.Jobs {
+				sb.WriteString(fmt.Sprintf("- %s", job.Name))
+				if job.LastBuild != nil {
+					sb.WriteString(fmt.Sprintf(" (最后构建 #%d)", job.LastBuild.Number))
+				}
+				sb.WriteString(fmt.Sprintf("\n  URL: %s\n", job.URL))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// ==================== Jenkins 日志与失败分析 ====================
+
+// failureAnalysisPrompt 构建失败分析的系统提示词
+const failureAnalysisPrompt = `你是一名资深的 CI/CD 与运维工程师,请根据 Jenkins 构建日志中的错误片段分析构建失败的根本原因。
+输出要求:
+1. **根因**: 用一两句话说明失败的直接原因
+2. **依据**: 引用最关键的 1-3 行日志
+3. **建议**: 给出可执行的修复建议
+使用简洁的中文 Markdown,不要编造日志中没有的信息;如果日志不足以判断,请明确说明还需要哪些信息。`
+
+const (
+	// analysisLogLines 失败分析时拉取的日志行数
+	analysisLogLines = 3000
+	// analysisRegionLines 发送给 LLM 的错误片段行数上限
+	analysisRegionLines = 150
+	// analysisSnippetLines 结果中展示的关键日志行数
+	analysisSnippetLines = 30
+)
+
+// handleGetJenkinsBuildLog 处理获取 Jenkins 构建日志的请求
+func (s *MCPServer) handleGetJenkinsBuildLog(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	jobName, ok := args["job_name"].(string)
+	if !ok || jobName == "" {
+		return mcp.NewToolResultError("job_name parameter is required"), nil
+	}
+
+	// build_number 可选,默认最后一次构建
+	buildNumber, _ := getIntArg(args, "build_number")
+
+	opts := jenkins.BuildLogOptions{}
+	if n, ok := getIntArg(args, "tail_lines"); ok {
+		opts.TailLines = n
+	}
+	if n, ok := getIntArg(args, "max_bytes"); ok {
+		opts.MaxBytes = n
+	}
+	opts.Grep, _ = args["grep"].(string)
+
+	instance, _ := args["instance"].(string)
+	p, _, err := s.getJenkinsProvider(instance)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	buildLog, err := p.GetBuildLog(ctx, jobName, buildNumber, opts)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取 Job '%s' 的构建日志失败: %v", jobName, err)), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Job '%s' 构建 #%d 的日志 (状态: %s, 共 %d 行, 返回 %d 行",
+		jobName, buildLog.Number, buildLog.Status, buildLog.TotalLines, buildLog.Lines))
+	if opts.Grep != "" {
+		sb.WriteString(fmt.Sprintf(", 过滤条件: %s", opts.Grep))
+	}
+	if buildLog.Truncated {
+		sb.WriteString(", 已截断")
+	}
+	sb.WriteString("):\n\n")
+
+	if buildLog.Content == "" {
+		sb.WriteString("(无匹配的日志)\n")
+	} else {
+		sb.WriteString("```\n")
+		sb.WriteString(buildLog.Content)
+		sb.WriteString("\n```\n")
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// handleAnalyzeJenkinsFailure 处理 Jenkins 构建失败分析的请求
+// 拉取构建日志并提取错误片段, 交由配置的 LLM 总结根因
+func (s *MCPServer) handleAnalyzeJenkinsFailure(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	jobName, ok := args["job_name"].(string)
+	if !ok || jobName == "" {
+		return mcp.NewToolResultError("job_name parameter is required"), nil
+	}
+
+	// build_number 可选,默认最后一次构建
+	buildNumber, _ := getIntArg(args, "build_number")
+
+	instance, _ := args["instance"].(string)
+	p, _, err := s.getJenkinsProvider(instance)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	buildLog, err := p.GetBuildLog(ctx, jobName, buildNumber, jenkins.BuildLogOptions{
+		TailLines: analysisLogLines,
+		MaxBytes:  jenkins.MaxLogMaxBytes,
+	})
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("获取 Job '%s' 的构建日志失败: %v", jobName, err)), nil
+	}
+
+	switch buildLog.Status {
+	case "SUCCESS":
+		return mcp.NewToolResultText(fmt.Sprintf("Job '%s' 的构建 #%d 已成功,无需分析失败原因", jobName, buildLog.Number)), nil
+	case "BUILDING":
+		return mcp.NewToolResultText(fmt.Sprintf("Job '%s' 的构建 #%d 仍在运行中,请在构建结束后再分析", jobName, buildLog.Number)), nil
+	}
+
+	region := jenkins.ExtractErrorRegion(buildLog.Content, analysisRegionLines)
+
+	var sb strings.Builder
+	sb.WriteString("## Jenkins 构建失败分析\n\n")
+	sb.WriteString(fmt.Sprintf("- Job: %s\n", jobName))
+	sb.WriteString(fmt.Sprintf("- 构建号: #%d\n", buildLog.Number))
+	sb.WriteString(fmt.Sprintf("- 结果: %s\n", buildLog.Status))
+	if buildLog.URL != "" {
+		sb.WriteString(fmt.Sprintf("- URL: %s\n", buildLog.URL))
+	}
+	sb.WriteString("\n")
+
+	summary, err := s.summarizeBuildFailure(ctx, jobName, buildLog, region)
+	if err != nil {
+		logx.Warn("Failed to analyze build failure, job %s, build %d, error %v", jobName, buildLog.Number, err)
+		sb.WriteString(fmt.Sprintf("⚠️ 未能生成根因分析: %v\n\n", err))
+	} else {
+		sb.WriteString("### 根因分析\n\n")
+		sb.WriteString(strings.TrimSpace(summary))
+		sb.WriteString("\n\n")
+	}
+
+	// 展示错误片段的末尾部分作为依据
+	snippet := strings.Split(region, "\n")
+	if len(snippet) > analysisSnippetLines {
+		snippet = snippet[len(snippet)-analysisSnippetLines:]
+	}
+	sb.WriteString("### 关键日志\n\n```\n")
+	sb.WriteString(strings.Join(snippet, "\n"))
+	sb.WriteString("\n```\n")
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// summarizeBuildFailure 调用 LLM 总结构建失败的根因
+func (s *MCPServer) summarizeBuildFailure(ctx context.Context, jobName string, buildLog *model.BuildLog, region string) (string, error) {
+	client, err := s.getLLMClient()
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	userPrompt := fmt.Sprintf("Job: %s\n构建号: #%d\n构建结果: %s\n日志总行数: %d\n\n错误相关日志片段:\n```\n%s\n```",
+		jobName, buildLog.Number, buildLog.Status, buildLog.TotalLines, region)
+
+	return client.Complete(ctx, []llm.Message{
+		{Role: "system", Content: failureAnalysisPrompt},
+		{Role: "user", Content: userPrompt},
+	})
+}
+
 // ==================== 格式化函数 ====================
 
 // formatJobs 格式化 Jenkins Job 列表为文本输出
@@ -130,6 +656,12 @@ func formatJobs(jobs []*model.Job) string {
 		if job.Description != "" {
 			sb.WriteString(fmt.Sprintf("  描述: %s\n", job.Description))
 		}
+		if job.Type != "" {
+			sb.WriteString(fmt.Sprintf("  类型: %s\n", job.Type))
+		}
+		if job.Status != "" {
+			sb.WriteString(fmt.Sprintf("  状态: %s\n", job.Status))
+		}
 		sb.WriteString(fmt.Sprintf("  URL: %s\n", job.URL))
 
 		buildable := "是"
@@ -184,3 +716,48 @@ func formatBuilds(builds []*model.Build, jobName string) string {
 
 	return sb.String()
 }
+
+// formatQueueItem 格式化单个队列项
+func formatQueueItem(item *model.QueueItem) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("  队列 ID: %d\n", item.ID))
+	sb.WriteString(fmt.Sprintf("  Job: %s\n", item.JobName))
+
+	switch {
+	case item.Cancelled:
+		sb.WriteString("  状态: 已取消\n")
+	case item.BuildNumber > 0:
+		sb.WriteString(fmt.Sprintf("  状态: 已开始构建 #%d\n", item.BuildNumber))
+	case item.Stuck:
+		sb.WriteString("  状态: 卡住\n")
+	case item.Blocked:
+		sb.WriteString("  状态: 阻塞\n")
+	default:
+		sb.WriteString("  状态: 排队中\n")
+	}
+
+	if item.Why != "" && item.BuildNumber == 0 {
+		sb.WriteString(fmt.Sprintf("  原因: %s\n", item.Why))
+	}
+	if len(item.Params) > 0 {
+		keys := make([]string, 0, len(item.Params))
+		for key := range item.Params {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		sb.WriteString("  参数:\n")
+		for _, key := range keys {
+			sb.WriteString(fmt.Sprintf("    %s = %s\n", key, item.Params[key]))
+		}
+	}
+	if !item.InQueueSince.IsZero() {
+		sb.WriteString(fmt.Sprintf("  入队时间: %s\n", item.InQueueSince.Format("2006-01-02 15:04:05")))
+		if item.BuildNumber == 0 && !item.Cancelled {
+			sb.WriteString(fmt.Sprintf("  已等待: %s\n", time.Since(item.InQueueSince).Truncate(time.Second)))
+		}
+	}
+	if item.BuildURL != "" {
+		sb.WriteString(fmt.Sprintf("  构建 URL: %s\n", item.BuildURL))
+	}
+	return sb.String()
+}
@@ -0,0 +1,124 @@
+package imcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// fakeJenkins 模拟 Jenkins API, 记录收到的写请求
+type fakeJenkins struct {
+	*httptest.Server
+	mu    sync.Mutex
+	posts []string
+}
+
+func newFakeJenkins(t *testing.T) *fakeJenkins {
+	t.Helper()
+	f := &fakeJenkins{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			f.mu.Lock()
+			f.posts = append(f.posts, strings.ReplaceAll(r.URL.Path, "//", "/")+"?"+r.URL.RawQuery)
+			f.mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Jenkins 返回的 url 以 / 结尾, gojenkins 拼接构建地址时会出现 //
+		var body any
+		switch strings.TrimSuffix(strings.ReplaceAll(r.URL.Path, "//", "/"), "/") {
+		case "/api/json":
+			body = map[string]any{"nodeName": "built-in", "jobs": []any{}}
+		case "/job/deploy/api/json":
+			body = map[string]any{"name": "deploy", "fullName": "deploy", "url": f.URL + "/job/deploy/", "buildable": true}
+		case "/job/deploy/7/api/json":
+			body = map[string]any{"number": 7, "building": true, "url": f.URL + "/job/deploy/7/"}
+		case "/queue/item/42/api/json":
+			body = map[string]any{"id": 42, "task": map[string]any{"name": "deploy"}}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// posted 判断是否收到指定路径的 POST 请求
+func (f *fakeJenkins) posted(prefix string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, post := range f.posts {
+		if strings.HasPrefix(post, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func newJenkinsTestServer(url string) *MCPServer {
+	cfg := &config.Config{}
+	cfg.CICD.Jenkins = config.JenkinsConfig{Enabled: true, URL: url, Username: "admin", Token: "token"}
+	return &MCPServer{config: cfg}
+}
+
+// resultText 返回工具结果的文本内容
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	if len(result.Content) == 0 {
+		t.Fatalf("empty tool result")
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("tool result content = %T, want text", result.Content[0])
+	}
+	return text.Text
+}
+
+func TestAbortJenkinsBuildAcceptsIntArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+		post string
+	}{
+		{name: "build number as number", args: map[string]any{"job_name": "deploy", "build_number": float64(7)}, want: "已中止", post: "/job/deploy/7/stop"},
+		{name: "build number as string", args: map[string]any{"job_name": "deploy", "build_number": "#7"}, want: "已中止", post: "/job/deploy/7/stop"},
+		{name: "queue id as string", args: map[string]any{"queue_id": "42"}, want: "已取消队列项 42", post: "/queue/cancelItem?id=42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jenkins := newFakeJenkins(t)
+			s := newJenkinsTestServer(jenkins.URL)
+
+			result := s.abortJenkinsBuild(context.Background(), tt.args)
+			text := resultText(t, result)
+			if result.IsError || !strings.Contains(text, tt.want) {
+				t.Fatalf("abortJenkinsBuild() = %q, want %q", text, tt.want)
+			}
+			if !jenkins.posted(tt.post) {
+				t.Errorf("expected POST %s, got %v", tt.post, jenkins.posts)
+			}
+		})
+	}
+}
+
+func TestAbortJenkinsBuildRequiresBuildNumber(t *testing.T) {
+	s := newJenkinsTestServer(newFakeJenkins(t).URL)
+
+	result := s.abortJenkinsBuild(context.Background(), map[string]any{"job_name": "deploy", "build_number": "latest"})
+	if !result.IsError || !strings.Contains(resultText(t, result), "build_number") {
+		t.Fatalf("abortJenkinsBuild() with invalid build_number = %+v, want an error", result)
+	}
+}
@@ -0,0 +1,416 @@
+package imcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider/kubernetes"
+	"github.com/eryajf/zenops/internal/service"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// podIPSearchTimeout 在 IP 搜索中附带查询 Pod 的超时时间, 避免不可达的集群拖慢云主机查询
+const podIPSearchTimeout = 10 * time.Second
+
+// ==================== Kubernetes 处理函数 ====================
+
+// handleListK8sClusters 处理列出 Kubernetes 集群的请求
+func (s *MCPServer) handleListK8sClusters(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	clusters, err := service.NewConfigService().ListKubernetesClusters()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("查询集群配置失败: %v", err)), nil
+	}
+
+	if len(clusters) == 0 {
+		return mcp.NewToolResultText("未配置任何 Kubernetes 集群"), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("共 %d 个 Kubernetes 集群:\n\n", len(clusters)))
+	for i, cluster := range clusters {
+		status := "启用"
+		if !cluster.Enabled {
+			status = "禁用"
+		}
+		result.WriteString(fmt.Sprintf("%d. %s (%s)\n", i+1, cluster.Name, status))
+		if cluster.Context != "" {
+			result.WriteString(fmt.Sprintf("   Context: %s\n", cluster.Context))
+		}
+		if cluster.DefaultNamespace != "" {
+			result.WriteString(fmt.Sprintf("   默认命名空间: %s\n", cluster.DefaultNamespace))
+		}
+		if cluster.Description != "" {
+			result.WriteString(fmt.Sprintf("   描述: %s\n", cluster.Description))
+		}
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// handleListK8sNamespaces 处理列出命名空间的请求
+func (s *MCPServer) handleListK8sNamespaces(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		args = make(map[string]any)
+	}
+
+	clusterName, _ := args["cluster"].(string)
+
+	client, err := s.getK8sClient(clusterName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	namespaces, err := client.ListNamespaces(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if len(namespaces) == 0 {
+		return mcp.NewToolResultText("未找到任何命名空间"), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("找到 %d 个命名空间 (集群: %s):\n\n", len(namespaces), client.Cluster))
+	for _, ns := range namespaces {
+		result.WriteString(fmt.Sprintf("- %s [%s] 创建于 %s\n", ns.Name, ns.Status, ns.CreatedAt.Format("2006-01-02 15:04:05")))
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// handleListK8sDeployments 处理列出 Deployment 的请求
+func (s *MCPServer) handleListK8sDeployments(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		args = make(map[string]any)
+	}
+
+	clusterName, _ := args["cluster"].(string)
+	namespace, _ := args["namespace"].(string)
+	labelSelector, _ := args["label_selector"].(string)
+
+	client, err := s.getK8sClient(clusterName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	workloads, err := client.ListDeployments(ctx, namespace, labelSelector)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(formatK8sWorkloads(workloads, "Deployment", client.Cluster)), nil
+}
+
+// handleListK8sStatefulSets 处理列出 StatefulSet 的请求
+func (s *MCPServer) handleListK8sStatefulSets(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		args = make(map[string]any)
+	}
+
+	clusterName, _ := args["cluster"].(string)
+	namespace, _ := args["namespace"].(string)
+	labelSelector, _ := args["label_selector"].(string)
+
+	client, err := s.getK8sClient(clusterName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	workloads, err := client.ListStatefulSets(ctx, namespace, labelSelector)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(formatK8sWorkloads(workloads, "StatefulSet", client.Cluster)), nil
+}
+
+// handleListK8sPods 处理列出 Pod 的请求
+func (s *MCPServer) handleListK8sPods(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		args = make(map[string]any)
+	}
+
+	clusterName, _ := args["cluster"].(string)
+	namespace, _ := args["namespace"].(string)
+	labelSelector, _ := args["label_selector"].(string)
+	status, _ := args["status"].(string)
+
+	client, err := s.getK8sClient(clusterName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	pods, err := client.ListPods(ctx, namespace, labelSelector)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// 按状态过滤 (不区分大小写), 如 CrashLoopBackOff
+	if status != "" {
+		var filtered []*model.K8sPod
+		for _, pod := range pods {
+			if strings.EqualFold(pod.Status, status) {
+				filtered = append(filtered, pod)
+			}
+		}
+		pods = filtered
+	}
+
+	return mcp.NewToolResultText(formatK8sPods(pods, client.Cluster)), nil
+}
+
+// handleListK8sServices 处理列出 Service 的请求
+func (s *MCPServer) handleListK8sServices(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		args = make(map[string]any)
+	}
+
+	clusterName, _ := args["cluster"].(string)
+	namespace, _ := args["namespace"].(string)
+	labelSelector, _ := args["label_selector"].(string)
+
+	client, err := s.getK8sClient(clusterName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	services, err := client.ListServices(ctx, namespace, labelSelector)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(formatK8sServices(services, client.Cluster)), nil
+}
+
+// handleSearchPodByIP 处理根据 IP 搜索 Pod 的请求
+func (s *MCPServer) handleSearchPodByIP(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	ip, ok := args["ip"].(string)
+	if !ok || ip == "" {
+		return mcp.NewToolResultError("ip parameter is required"), nil
+	}
+
+	clusterName, _ := args["cluster"].(string)
+
+	// 指定集群时只查询该集群
+	if clusterName != "" {
+		client, err := s.getK8sClient(clusterName)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		pods, err := client.FindPodsByIP(ctx, ip)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		if len(pods) == 0 {
+			return mcp.NewToolResultText(fmt.Sprintf("未在集群 %s 中找到 IP 为 %s 的 Pod", clusterName, ip)), nil
+		}
+		return mcp.NewToolResultText(formatK8sPods(pods, clusterName)), nil
+	}
+
+	pods := s.searchPodsByIP(ctx, ip)
+	if len(pods) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("未在任何 Kubernetes 集群中找到 IP 为 %s 的 Pod", ip)), nil
+	}
+
+	return mcp.NewToolResultText(formatK8sPods(pods, "所有集群")), nil
+}
+
+// handleGetPodLogs 处理获取 Pod 日志的请求
+func (s *MCPServer) handleGetPodLogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]any)
+	if !ok {
+		return mcp.NewToolResultError("invalid arguments type"), nil
+	}
+
+	podName, ok := args["pod"].(string)
+	if !ok || podName == "" {
+		return mcp.NewToolResultError("pod parameter is required"), nil
+	}
+
+	clusterName, _ := args["cluster"].(string)
+	namespace, _ := args["namespace"].(string)
+	container, _ := args["container"].(string)
+	previous, _ := args["previous"].(bool)
+
+	tailLines := kubernetes.DefaultTailLines
+	if v, ok := args["tail_lines"].(float64); ok && v > 0 {
+		tailLines = int64(v)
+	}
+	if tailLines > kubernetes.MaxTailLines {
+		tailLines = kubernetes.MaxTailLines
+	}
+
+	client, err := s.getK8sClient(clusterName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if namespace == "" && client.DefaultNamespace == "" {
+		return mcp.NewToolResultError("namespace parameter is required"), nil
+	}
+
+	logs, err := client.GetPodLogs(ctx, namespace, podName, container, tailLines, previous)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if strings.TrimSpace(logs) == "" {
+		return mcp.NewToolResultText(fmt.Sprintf("Pod %s 没有日志输出", podName)), nil
+	}
+
+	header := fmt.Sprintf("Pod %s 最后 %d 行日志 (集群: %s):\n\n", podName, tailLines, client.Cluster)
+	return mcp.NewToolResultText(header + logs), nil
+}
+
+// searchPodsByIP 在所有启用的集群中查找 Pod IP 匹配的 Pod
+// 单个集群查询失败只记录日志, 不影响其他集群
+func (s *MCPServer) searchPodsByIP(ctx context.Context, ip string) []*model.K8sPod {
+	clusters, err := service.NewConfigService().ListKubernetesClusters()
+	if err != nil {
+		logx.Warn("Failed to load kubernetes clusters: %v", err)
+		return nil
+	}
+
+	var matched []*model.K8sPod
+	for _, cluster := range clusters {
+		if !cluster.Enabled {
+			continue
+		}
+
+		client, err := kubernetes.NewClient(cluster.Name, cluster.Kubeconfig, cluster.Context, cluster.DefaultNamespace)
+		if err != nil {
+			logx.Warn("Failed to create kubernetes client, cluster %s, error %v", cluster.Name, err)
+			continue
+		}
+
+		pods, err := client.FindPodsByIP(ctx, ip)
+		if err != nil {
+			logx.Warn("Failed to search pods by ip, cluster %s, error %v", cluster.Name, err)
+			continue
+		}
+
+		matched = append(matched, pods...)
+	}
+
+	return matched
+}
+
+// appendPodIPMatches 在云主机 IP 搜索结果后追加匹配的 Kubernetes Pod
+// 未配置集群或没有匹配时原样返回
+func (s *MCPServer) appendPodIPMatches(ctx context.Context, ip, result string) string {
+	ctx, cancel := context.WithTimeout(ctx, podIPSearchTimeout)
+	defer cancel()
+
+	pods := s.searchPodsByIP(ctx, ip)
+	if len(pods) == 0 {
+		return result
+	}
+
+	return strings.TrimRight(result, "\n") + "\n\n同时匹配到以下 Kubernetes Pod:\n" + formatK8sPods(pods, "所有集群")
+}
+
+// ==================== Kubernetes 格式化函数 ====================
+
+// formatK8sWorkloads 格式化工作负载列表
+func formatK8sWorkloads(workloads []*model.K8sWorkload, kind, clusterName string) string {
+	if len(workloads) == 0 {
+		return fmt.Sprintf("未找到任何 %s", kind)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("找到 %d 个 %s (集群: %s):\n\n", len(workloads), kind, clusterName))
+
+	for i, w := range workloads {
+		result.WriteString(fmt.Sprintf("【%s %d】\n", kind, i+1))
+		result.WriteString(fmt.Sprintf("  名称: %s\n", w.Name))
+		result.WriteString(fmt.Sprintf("  命名空间: %s\n", w.Namespace))
+		result.WriteString(fmt.Sprintf("  副本: 期望 %d / 就绪 %d / 可用 %d / 已更新 %d\n",
+			w.Replicas, w.ReadyReplicas, w.AvailableReplicas, w.UpdatedReplicas))
+		if len(w.Images) > 0 {
+			result.WriteString(fmt.Sprintf("  镜像: %s\n", strings.Join(w.Images, ", ")))
+		}
+		result.WriteString(fmt.Sprintf("  创建时间: %s\n", w.CreatedAt.Format("2006-01-02 15:04:05")))
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
+
+// formatK8sPods 格式化 Pod 列表
+func formatK8sPods(pods []*model.K8sPod, clusterName string) string {
+	if len(pods) == 0 {
+		return "未找到任何 Pod"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("找到 %d 个 Pod (集群: %s):\n\n", len(pods), clusterName))
+
+	for i, pod := range pods {
+		result.WriteString(fmt.Sprintf("【Pod %d】\n", i+1))
+		result.WriteString(fmt.Sprintf("  名称: %s\n", pod.Name))
+		result.WriteString(fmt.Sprintf("  集群: %s\n", pod.Cluster))
+		result.WriteString(fmt.Sprintf("  命名空间: %s\n", pod.Namespace))
+		result.WriteString(fmt.Sprintf("  状态: %s\n", pod.Status))
+		result.WriteString(fmt.Sprintf("  就绪: %s\n", pod.Ready))
+		result.WriteString(fmt.Sprintf("  重启次数: %d\n", pod.Restarts))
+		if pod.PodIP != "" {
+			result.WriteString(fmt.Sprintf("  Pod IP: %s\n", pod.PodIP))
+		}
+		if pod.NodeName != "" {
+			result.WriteString(fmt.Sprintf("  节点: %s (%s)\n", pod.NodeName, pod.HostIP))
+		}
+		if pod.Owner != "" {
+			result.WriteString(fmt.Sprintf("  所属: %s\n", pod.Owner))
+		}
+		if len(pod.Containers) > 0 {
+			result.WriteString(fmt.Sprintf("  容器: %s\n", strings.Join(pod.Containers, ", ")))
+		}
+		result.WriteString(fmt.Sprintf("  创建时间: %s\n", pod.CreatedAt.Format("2006-01-02 15:04:05")))
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
+
+// formatK8sServices 格式化 Service 列表
+func formatK8sServices(services []*model.K8sService, clusterName string) string {
+	if len(services) == 0 {
+		return "未找到任何 Service"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("找到 %d 个 Service (集群: %s):\n\n", len(services), clusterName))
+
+	for i, svc := range services {
+		result.WriteString(fmt.Sprintf("【Service %d】\n", i+1))
+		result.WriteString(fmt.Sprintf("  名称: %s\n", svc.Name))
+		result.WriteString(fmt.Sprintf("  命名空间: %s\n", svc.Namespace))
+		result.WriteString(fmt.Sprintf("  类型: %s\n", svc.Type))
+		result.WriteString(fmt.Sprintf("  Cluster IP: %s\n", svc.ClusterIP))
+		if len(svc.ExternalIPs) > 0 {
+			result.WriteString(fmt.Sprintf("  外部地址: %s\n", strings.Join(svc.ExternalIPs, ", ")))
+		}
+		if len(svc.Ports) > 0 {
+			result.WriteString(fmt.Sprintf("  端口: %s\n", strings.Join(svc.Ports, ", ")))
+		}
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
@@ -73,11 +73,12 @@ func (s *MCPServer) handleSearchCVMByIP(ctx context.Context, request mcp.CallToo
 	}
 
 	if len(matchedInstances) == 0 {
-		return mcp.NewToolResultText(fmt.Sprintf("未找到 IP 为 %s 的腾讯云 CVM 实例", ip)), nil
+		result := fmt.Sprintf("未找到 IP 为 %s 的腾讯云 CVM 实例", ip)
+		return mcp.NewToolResultText(s.appendPodIPMatches(ctx, ip, result)), nil
 	}
 
 	result := formatInstances(matchedInstances, tencentConfig.Name)
-	return mcp.NewToolResultText(result), nil
+	return mcp.NewToolResultText(s.appendPodIPMatches(ctx, ip, result)), nil
 }
 
 // handleSearchCVMByName 处理根据名称搜索腾讯云 CVM 的请求
@@ -1,15 +1,24 @@
 package imcp
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"cnb.cool/zhiqiangwang/pkg/logx"
 	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/llm"
 	"github.com/eryajf/zenops/internal/model"
 	"github.com/eryajf/zenops/internal/provider"
 	"github.com/eryajf/zenops/internal/provider/aliyun"
+	"github.com/eryajf/zenops/internal/provider/github"
+	"github.com/eryajf/zenops/internal/provider/gitlab"
+	"github.com/eryajf/zenops/internal/provider/jenkins"
+	"github.com/eryajf/zenops/internal/provider/kubernetes"
 	"github.com/eryajf/zenops/internal/service"
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // ==================== Provider 辅助函数 ====================
@@ -92,26 +101,203 @@ func (s *MCPServer) getTencentProvider(accountName string) (provider.Provider, *
 	return p, tencentConfig, nil
 }
 
-// getJenkinsProvider 获取 Jenkins Provider
-func (s *MCPServer) getJenkinsProvider() (provider.CICDProvider, error) {
+// getAWSProvider 获取 AWS Provider
+func (s *MCPServer) getAWSProvider(accountName string) (provider.Provider, *config.ProviderConfig, error) {
+	// 从数据库或配置获取账号配置
+	awsConfig, err := s.getAWSConfigByNameFromDB(accountName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 创建 Provider
+	p, err := provider.GetProvider("aws")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get provider: %w", err)
+	}
+
+	// 初始化 Provider
+	providerConfig := map[string]any{
+		"access_key_id":     awsConfig.AK,
+		"secret_access_key": awsConfig.SK,
+		"regions":           interfaceSlice(awsConfig.Regions),
+		"endpoint":          awsConfig.Extra["endpoint"],
+	}
+
+	if err := p.Initialize(providerConfig); err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize provider for account %s: %w", accountName, err)
+	}
+
+	return p, awsConfig, nil
+}
+
+// getK8sClient 获取 Kubernetes 客户端
+// 集群配置只保存在数据库中, 未指定集群时使用第一个启用的集群
+func (s *MCPServer) getK8sClient(clusterName string) (*kubernetes.Client, error) {
+	configService := service.NewConfigService()
+
+	var cluster *model.KubernetesCluster
+	if clusterName != "" {
+		c, err := configService.GetKubernetesClusterByName(clusterName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubernetes cluster: %w", err)
+		}
+		if c == nil {
+			return nil, fmt.Errorf("kubernetes cluster '%s' not found", clusterName)
+		}
+		cluster = c
+	} else {
+		clusters, err := configService.ListKubernetesClusters()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubernetes clusters: %w", err)
+		}
+		for i := range clusters {
+			if clusters[i].Enabled {
+				cluster = &clusters[i]
+				break
+			}
+		}
+		if cluster == nil {
+			return nil, fmt.Errorf("no kubernetes cluster configured")
+		}
+	}
+
+	if !cluster.Enabled {
+		return nil, fmt.Errorf("kubernetes cluster '%s' is disabled", cluster.Name)
+	}
+
+	client, err := kubernetes.NewClient(cluster.Name, cluster.Kubeconfig, cluster.Context, cluster.DefaultNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client for cluster %s: %w", cluster.Name, err)
+	}
+
+	return client, nil
+}
+
+// getJenkinsProvider 获取指定 Jenkins 实例的 Provider
+// 实例名称为空时使用第一个启用的实例; 优先使用数据库中的 CICD 配置, 不存在时回退到配置文件
+func (s *MCPServer) getJenkinsProvider(instance string) (*jenkins.JenkinsProvider, *config.JenkinsConfig, error) {
+	instances := service.NewConfigService().ListJenkinsInstances(s.config.CICD.Jenkins)
+	jenkinsConfig, err := config.FindJenkinsInstance(instances, instance)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, err := jenkins.NewInstanceProvider(jenkinsConfig.URL, jenkinsConfig.Username, jenkinsConfig.Token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize jenkins provider for instance %s: %w", jenkinsConfig.Name, err)
+	}
+
+	return p, jenkinsConfig, nil
+}
+
+// getEnabledJenkinsInstances 获取所有启用的 Jenkins 实例, 初始化失败的实例会被跳过
+func (s *MCPServer) getEnabledJenkinsInstances() []jenkins.Instance {
+	var result []jenkins.Instance
+	for _, instance := range service.NewConfigService().ListJenkinsInstances(s.config.CICD.Jenkins) {
+		if !instance.Enabled {
+			continue
+		}
+
+		p, err := jenkins.NewInstanceProvider(instance.URL, instance.Username, instance.Token)
+		if err != nil {
+			logx.Warn("Failed to initialize jenkins provider, instance %s, error %v", instance.Name, err)
+			continue
+		}
+		result = append(result, jenkins.Instance{Name: instance.Name, Provider: p})
+	}
+	return result
+}
+
+// getGitLabProvider 获取 GitLab Provider
+// 优先使用数据库中的 CICD 配置, 不存在时回退到配置文件
+func (s *MCPServer) getGitLabProvider() (*gitlab.GitLabProvider, error) {
+	gitlabConfig := s.config.CICD.GitLab
+	dbConfig, err := service.NewConfigService().GetCICDConfig("gitlab")
+	if err != nil {
+		logx.Warn("Failed to load gitlab config from database: %v", err)
+	} else if dbConfig != nil {
+		gitlabConfig = config.GitLabConfig{
+			Enabled: dbConfig.Enabled,
+			URL:     dbConfig.URL,
+			Token:   dbConfig.Token,
+		}
+	}
+
+	if gitlabConfig.URL == "" {
+		return nil, fmt.Errorf("gitlab is not configured")
+	}
+	if !gitlabConfig.Enabled {
+		return nil, fmt.Errorf("gitlab is disabled")
+	}
+
+	// 创建 Provider
+	p, err := provider.GetCICDProvider("gitlab")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gitlab provider: %w", err)
+	}
+
+	// 初始化 Provider
+	providerConfig := map[string]any{
+		"url":   gitlabConfig.URL,
+		"token": gitlabConfig.Token,
+	}
+
+	if err := p.Initialize(providerConfig); err != nil {
+		return nil, fmt.Errorf("failed to initialize gitlab provider: %w", err)
+	}
+
+	gp, ok := p.(*gitlab.GitLabProvider)
+	if !ok {
+		return nil, fmt.Errorf("unexpected gitlab provider type %T", p)
+	}
+
+	return gp, nil
+}
+
+// getGitHubProvider 获取 GitHub Provider
+// 优先使用数据库中的 CICD 配置, 不存在时回退到配置文件
+func (s *MCPServer) getGitHubProvider() (*github.GitHubProvider, error) {
+	githubConfig := s.config.CICD.GitHub
+	dbConfig, err := service.NewConfigService().GetCICDConfig("github")
+	if err != nil {
+		logx.Warn("Failed to load github config from database: %v", err)
+	} else if dbConfig != nil {
+		githubConfig = config.GitHubConfig{
+			Enabled: dbConfig.Enabled,
+			URL:     dbConfig.URL,
+			Token:   dbConfig.Token,
+		}
+	}
+
+	if githubConfig.Token == "" {
+		return nil, fmt.Errorf("github is not configured")
+	}
+	if !githubConfig.Enabled {
+		return nil, fmt.Errorf("github is disabled")
+	}
+
 	// 创建 Provider
-	p, err := provider.GetCICDProvider("jenkins")
+	p, err := provider.GetCICDProvider("github")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get jenkins provider: %w", err)
+		return nil, fmt.Errorf("failed to get github provider: %w", err)
 	}
 
 	// 初始化 Provider
 	providerConfig := map[string]any{
-		"url":      s.config.CICD.Jenkins.URL,
-		"username": s.config.CICD.Jenkins.Username,
-		"token":    s.config.CICD.Jenkins.Token,
+		"url":   githubConfig.URL,
+		"token": githubConfig.Token,
 	}
 
 	if err := p.Initialize(providerConfig); err != nil {
-		return nil, fmt.Errorf("failed to initialize jenkins provider: %w", err)
+		return nil, fmt.Errorf("failed to initialize github provider: %w", err)
 	}
 
-	return p, nil
+	gp, ok := p.(*github.GitHubProvider)
+	if !ok {
+		return nil, fmt.Errorf("unexpected github provider type %T", p)
+	}
+
+	return gp, nil
 }
 
 // getAliyunConfigByNameFromDB 从数据库或配置获取阿里云账号配置
@@ -272,6 +458,75 @@ func getTencentConfigByName(cfg *config.Config, accountName string) (*config.Pro
 	return nil, fmt.Errorf("tencent account '%s' not found", accountName)
 }
 
+// getAWSConfigByNameFromDB 从数据库或配置获取 AWS 账号配置
+func (s *MCPServer) getAWSConfigByNameFromDB(accountName string) (*config.ProviderConfig, error) {
+	// 先尝试从数据库加载
+	configService := service.NewConfigService()
+	accounts, err := configService.ListProviderAccounts("aws")
+	if err == nil && len(accounts) > 0 {
+		logx.Debug("Loading aws config from database, account count %d", len(accounts))
+
+		toProviderConfig := func(acc model.ProviderAccount) *config.ProviderConfig {
+			return &config.ProviderConfig{
+				Name:    acc.Name,
+				Enabled: acc.Enabled,
+				AK:      acc.AccessKey,
+				SK:      acc.SecretKey,
+				Regions: acc.Regions,
+				Extra:   map[string]string{"endpoint": acc.Endpoint},
+			}
+		}
+
+		// 如果没有指定账号名,返回第一个启用的账号
+		if accountName == "" {
+			for _, acc := range accounts {
+				if acc.Enabled {
+					return toProviderConfig(acc), nil
+				}
+			}
+			// 如果没有启用的,返回第一个
+			return toProviderConfig(accounts[0]), nil
+		}
+
+		// 根据名称查找
+		for _, acc := range accounts {
+			if acc.Name == accountName {
+				return toProviderConfig(acc), nil
+			}
+		}
+
+		return nil, fmt.Errorf("aws account '%s' not found in database", accountName)
+	}
+
+	// 如果数据库没有配置,回退到 YAML 配置
+	logx.Debug("No aws config in database, falling back to YAML config")
+	return getAWSConfigByName(s.config, accountName)
+}
+
+// getAWSConfigByName 根据名称获取 AWS 账号配置(从YAML)
+func getAWSConfigByName(cfg *config.Config, accountName string) (*config.ProviderConfig, error) {
+	if len(cfg.Providers.AWS) == 0 {
+		return nil, fmt.Errorf("no aws account configured")
+	}
+
+	if accountName == "" {
+		for _, acc := range cfg.Providers.AWS {
+			if acc.Enabled {
+				return &acc, nil
+			}
+		}
+		return &cfg.Providers.AWS[0], nil
+	}
+
+	for _, acc := range cfg.Providers.AWS {
+		if acc.Name == accountName {
+			return &acc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("aws account '%s' not found", accountName)
+}
+
 // interfaceSlice 将 []string 转换为 []any
 func interfaceSlice(s []string) []any {
 	result := make([]any, len(s))
@@ -355,3 +610,60 @@ func formatDatabases(databases []*model.Database, accountName string) string {
 
 	return result.String()
 }
+
+// getLLMClient 获取用于日志分析等场景的 LLM 客户端
+// 优先使用数据库中的默认模型配置, 其余启用的模型作为备用, 不存在时回退到配置文件
+func (s *MCPServer) getLLMClient() (*llm.Client, error) {
+	return llm.LoadClient("", s.config.LLM, s)
+}
+
+// getIntArg 读取整数参数, 兼容 JSON 数字和字符串 (意图解析传入的参数均为字符串)
+func getIntArg(args map[string]any, key string) (int, bool) {
+	switch v := args[key].(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	case string:
+		n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(v), "#"))
+		if err != nil {
+			return 0, false
+		}
+		return n, true
+	}
+	return 0, false
+}
+
+// ==================== 审计辅助函数 ====================
+
+// auditToolCall 记录写操作类工具的调用日志
+// 经由 LLM 调用时已在 llm 包中统一记录, 此处跳过以避免重复
+func auditToolCall(ctx context.Context, toolName string, args map[string]any, result *mcp.CallToolResult, startTime time.Time) {
+	if service.IsAudited(ctx) {
+		return
+	}
+
+	identity := service.IdentityFromContext(ctx, service.Caller{Username: "mcp_client", Source: "mcp"})
+
+	logParams := &service.MCPLogParams{
+		ServerName:     BuiltinServerName,
+		ToolName:       toolName,
+		Username:       identity.Username,
+		Source:         identity.Source,
+		ChatLogID:      identity.ChatLogID,
+		ConversationID: identity.ConversationID,
+		Request:        args,
+		Response:       result,
+		Latency:        time.Since(startTime).Milliseconds(),
+		Success:        result != nil && !result.IsError,
+	}
+	if result != nil && result.IsError && len(result.Content) > 0 {
+		if text, ok := result.Content[0].(mcp.TextContent); ok {
+			logParams.ErrorMessage = text.Text
+		}
+	}
+
+	if _, err := service.NewMCPLogService().CreateMCPLog(logParams); err != nil {
+		logx.Warn("Failed to save MCP log: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package imcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eryajf/zenops/internal/service"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// BuiltinServerName 内置工具所属的服务器名称
+const BuiltinServerName = "zenops"
+
+// ToolOrigin 已注册工具的来源
+type ToolOrigin struct {
+	Server string // 来源服务器, 内置工具为 zenops, 外部 MCP 工具为其配置名称
+	Tool   string // 在来源服务器中的原始工具名 (不含前缀)
+}
+
+// recordOrigin 记录工具的来源服务器
+func (s *MCPServer) recordOrigin(name string, origin ToolOrigin) {
+	s.originsMu.Lock()
+	defer s.originsMu.Unlock()
+	s.origins[name] = origin
+}
+
+// recordBuiltinOrigins 将当前已注册的工具全部记录为内置工具
+func (s *MCPServer) recordBuiltinOrigins() {
+	for name := range s.mcpServer.ListTools() {
+		s.recordOrigin(name, ToolOrigin{Server: BuiltinServerName, Tool: name})
+	}
+}
+
+// ToolOrigin 返回已注册工具的来源服务器和原始工具名, 未注册的工具返回 ok=false
+func (s *MCPServer) ToolOrigin(name string) (server, tool string, ok bool) {
+	s.originsMu.RLock()
+	defer s.originsMu.RUnlock()
+	origin, ok := s.origins[name]
+	return origin.Server, origin.Tool, ok
+}
+
+// addWriteTool 注册有写操作的内置工具, 调用方需拥有 resource 的 write 权限才能经 CallTool 调用
+func (s *MCPServer) addWriteTool(resource string, tool mcp.Tool, handler server.ToolHandlerFunc) {
+	s.mcpServer.AddTool(tool, handler)
+	s.writeTools[tool.Name] = resource
+}
+
+// checkToolPermission 校验调用方是否有权调用写操作工具, 规则同 /api/v1 下的路由
+// 上下文中没有调用方权限时拒绝, IM 渠道的调用需用 service.WithIMChannel 显式标记
+func (s *MCPServer) checkToolPermission(ctx context.Context, toolName string) error {
+	resource, ok := s.writeTools[toolName]
+	if !ok {
+		return nil
+	}
+	permissions, ok := service.PermissionsFromContext(ctx)
+	if ok && service.PermissionsAllow(permissions, resource, service.ActionWrite) {
+		return nil
+	}
+	return fmt.Errorf("permission denied: tool %s requires %s:%s permission", toolName, resource, service.ActionWrite)
+}
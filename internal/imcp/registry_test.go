@@ -0,0 +1,63 @@
+package imcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/service"
+)
+
+func TestCheckToolPermission(t *testing.T) {
+	s := NewMCPServer(&config.Config{})
+
+	tests := []struct {
+		name        string
+		permissions *service.Permissions
+		tool        string
+		wantErr     bool
+	}{
+		{name: "read tool for viewer", permissions: &service.Permissions{Roles: "viewer"}, tool: "list_jenkins_jobs"},
+		{name: "write tool for viewer", permissions: &service.Permissions{Roles: "viewer"}, tool: "trigger_jenkins_build", wantErr: true},
+		{name: "write tool for operator", permissions: &service.Permissions{Roles: "operator"}, tool: "trigger_jenkins_build"},
+		{name: "shared api key", permissions: &service.Permissions{Scopes: []string{"*:read"}, ServiceAccount: true}, tool: "abort_jenkins_build", wantErr: true},
+		{name: "personal token without cicd scope", permissions: &service.Permissions{Roles: "admin", Scopes: []string{"chat:*"}}, tool: "abort_jenkins_build", wantErr: true},
+		{name: "personal token above user role", permissions: &service.Permissions{Roles: "viewer", Scopes: []string{"*"}}, tool: "trigger_jenkins_build", wantErr: true},
+		{name: "service account with cicd scope", permissions: &service.Permissions{Scopes: []string{"chat:write", "cicd:write"}, ServiceAccount: true}, tool: "trigger_jenkins_build"},
+		{name: "service account without cicd scope", permissions: &service.Permissions{Scopes: []string{"chat:write"}, ServiceAccount: true}, tool: "trigger_jenkins_build", wantErr: true},
+		{name: "im channel", permissions: &service.Permissions{IMChannel: true}, tool: "trigger_jenkins_build"},
+		{name: "no permissions", tool: "trigger_jenkins_build", wantErr: true},
+		{name: "read tool without permissions", tool: "list_jenkins_jobs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.permissions != nil {
+				ctx = service.WithPermissions(ctx, *tt.permissions)
+			}
+			err := s.checkToolPermission(ctx, tt.tool)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkToolPermission() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCallToolDeniesWriteToolWithoutPermissions(t *testing.T) {
+	jenkins := newFakeJenkins(t)
+	s := NewMCPServer(&config.Config{})
+	s.config.CICD.Jenkins = config.JenkinsConfig{Enabled: true, URL: jenkins.URL, Username: "admin", Token: "token"}
+
+	result, err := s.CallTool(context.Background(), "abort_jenkins_build", map[string]any{"job_name": "deploy", "build_number": float64(7)})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if !result.IsError || !strings.Contains(resultText(t, result), "permission denied") {
+		t.Fatalf("CallTool() = %+v, want permission denied", result)
+	}
+	if jenkins.posted("/job/deploy/7/stop") {
+		t.Errorf("write tool was called without permissions")
+	}
+}
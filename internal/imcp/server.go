@@ -3,6 +3,9 @@ package imcp
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"sync"
+	"time"
 
 	"cnb.cool/zhiqiangwang/pkg/logx"
 	"github.com/eryajf/zenops/internal/config"
@@ -15,7 +18,25 @@ import (
 type MCPServer struct {
 	config    *config.Config
 	mcpServer *server.MCPServer
-	sseServer *server.SSEServer
+
+	// HTTP 传输 (SSE / Streamable HTTP), 见 transport.go
+	transportMu      sync.Mutex
+	httpServer       *http.Server
+	sseServer        *server.SSEServer
+	streamableServer *server.StreamableHTTPServer
+	closeStreams     context.CancelFunc
+
+	// 工具名称到来源服务器的映射, 用于 MCP 调用日志
+	originsMu sync.RWMutex
+	origins   map[string]ToolOrigin
+
+	// 有写操作的内置工具及其所需的资源权限, 见 registry.go
+	writeTools map[string]string
+
+	// auth.tokens 全局令牌缓存, 见 auth.go
+	tokensMu       sync.Mutex
+	tokens         []string
+	tokensLoadedAt time.Time
 }
 
 // NewMCPServer 创建基于 mcp-go 库的 MCP 服务器
@@ -25,15 +46,21 @@ func NewMCPServer(cfg *config.Config) *MCPServer {
 		"zenops",
 		"1.0.0",
 		server.WithToolCapabilities(true),
+		// 按 MCP 访问令牌的工具范围过滤和拦截, 见 auth.go
+		server.WithToolFilter(filterToolsByToken),
+		server.WithToolHandlerMiddleware(enforceTokenScope),
 	)
 
 	s := &MCPServer{
-		config:    cfg,
-		mcpServer: mcpServer,
+		config:     cfg,
+		mcpServer:  mcpServer,
+		origins:    make(map[string]ToolOrigin),
+		writeTools: make(map[string]string),
 	}
 
 	// 注册工具
 	s.registerTools()
+	s.recordBuiltinOrigins()
 
 	return s
 }
@@ -289,12 +316,307 @@ func (s *MCPServer) registerTools() {
 		s.handleGetCOS,
 	)
 
+	// ==================== AWS EC2 工具 ====================
+
+	// search_ec2_by_ip - 根据 IP 搜索 AWS EC2
+	s.mcpServer.AddTool(
+		mcp.NewTool("search_ec2_by_ip",
+			mcp.WithDescription("根据 IP 地址搜索 AWS EC2 实例(支持私网 IP 和公网 IP)"),
+			mcp.WithString("ip",
+				mcp.Required(),
+				mcp.Description("要搜索的 IP 地址"),
+			),
+			mcp.WithString("account",
+				mcp.Description("AWS 账号名称(可选,默认使用第一个启用的账号)"),
+			),
+			mcp.WithString("region",
+				mcp.Description("区域(可选,如 us-east-1,默认查询所有配置的区域)"),
+			),
+		),
+		s.handleSearchEC2ByIP,
+	)
+
+	// search_ec2_by_name - 根据名称搜索 AWS EC2
+	s.mcpServer.AddTool(
+		mcp.NewTool("search_ec2_by_name",
+			mcp.WithDescription("根据实例名称(Name 标签)精确搜索 AWS EC2 实例"),
+			mcp.WithString("name",
+				mcp.Required(),
+				mcp.Description("实例名称"),
+			),
+			mcp.WithString("account",
+				mcp.Description("AWS 账号名称(可选)"),
+			),
+			mcp.WithString("region",
+				mcp.Description("区域(可选)"),
+			),
+		),
+		s.handleSearchEC2ByName,
+	)
+
+	// list_ec2 - 列出 AWS EC2 实例
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_ec2",
+			mcp.WithDescription("列出 AWS EC2 实例,支持按状态筛选"),
+			mcp.WithString("account",
+				mcp.Description("AWS 账号名称(可选)"),
+			),
+			mcp.WithString("region",
+				mcp.Description("区域(可选)"),
+			),
+			mcp.WithString("status",
+				mcp.Description("实例状态(可选): pending, running, stopping, stopped, shutting-down, terminated"),
+			),
+		),
+		s.handleListEC2,
+	)
+
+	// get_ec2 - 获取 AWS EC2 实例详情
+	s.mcpServer.AddTool(
+		mcp.NewTool("get_ec2",
+			mcp.WithDescription("获取指定 AWS EC2 实例的详细信息"),
+			mcp.WithString("instance_id",
+				mcp.Required(),
+				mcp.Description("实例 ID,如 i-0123456789abcdef0"),
+			),
+			mcp.WithString("account",
+				mcp.Description("AWS 账号名称(可选)"),
+			),
+		),
+		s.handleGetEC2,
+	)
+
+	// ==================== AWS RDS 工具 ====================
+
+	// list_aws_rds - 列出 AWS RDS 实例
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_aws_rds",
+			mcp.WithDescription("列出所有 AWS RDS 数据库实例"),
+			mcp.WithString("account",
+				mcp.Description("AWS 账号名称(可选)"),
+			),
+			mcp.WithString("region",
+				mcp.Description("区域(可选)"),
+			),
+		),
+		s.handleListAWSRDS,
+	)
+
+	// search_aws_rds_by_name - 根据名称搜索 AWS RDS
+	s.mcpServer.AddTool(
+		mcp.NewTool("search_aws_rds_by_name",
+			mcp.WithDescription("根据实例标识符搜索 AWS RDS 数据库实例"),
+			mcp.WithString("name",
+				mcp.Required(),
+				mcp.Description("RDS 实例标识符(DB Instance Identifier)"),
+			),
+			mcp.WithString("account",
+				mcp.Description("AWS 账号名称(可选)"),
+			),
+		),
+		s.handleSearchAWSRDSByName,
+	)
+
+	// ==================== AWS S3 工具 ====================
+
+	// list_s3 - 列出 S3 存储桶
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_s3",
+			mcp.WithDescription("列出所有 AWS S3 存储桶"),
+			mcp.WithString("account",
+				mcp.Description("AWS 账号名称(可选)"),
+			),
+		),
+		s.handleListS3,
+	)
+
+	// get_s3 - 获取 S3 存储桶详情
+	s.mcpServer.AddTool(
+		mcp.NewTool("get_s3",
+			mcp.WithDescription("获取指定 AWS S3 存储桶的详细信息"),
+			mcp.WithString("bucket_name",
+				mcp.Required(),
+				mcp.Description("存储桶名称"),
+			),
+			mcp.WithString("account",
+				mcp.Description("AWS 账号名称(可选)"),
+			),
+		),
+		s.handleGetS3,
+	)
+
+	// ==================== Kubernetes 工具 ====================
+
+	// list_k8s_clusters - 列出 Kubernetes 集群
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_k8s_clusters",
+			mcp.WithDescription("列出已配置的 Kubernetes 集群"),
+		),
+		s.handleListK8sClusters,
+	)
+
+	// list_k8s_namespaces - 列出命名空间
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_k8s_namespaces",
+			mcp.WithDescription("列出 Kubernetes 集群中的命名空间"),
+			mcp.WithString("cluster",
+				mcp.Description("集群名称(可选,默认使用第一个启用的集群)"),
+			),
+		),
+		s.handleListK8sNamespaces,
+	)
+
+	// list_k8s_deployments - 列出 Deployment
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_k8s_deployments",
+			mcp.WithDescription("列出 Kubernetes Deployment 及其副本状态"),
+			mcp.WithString("cluster",
+				mcp.Description("集群名称(可选,默认使用第一个启用的集群)"),
+			),
+			mcp.WithString("namespace",
+				mcp.Description("命名空间(可选,默认使用集群配置的默认命名空间,未配置时查询所有命名空间)"),
+			),
+			mcp.WithString("label_selector",
+				mcp.Description("标签选择器(可选,如 app=nginx)"),
+			),
+		),
+		s.handleListK8sDeployments,
+	)
+
+	// list_k8s_statefulsets - 列出 StatefulSet
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_k8s_statefulsets",
+			mcp.WithDescription("列出 Kubernetes StatefulSet 及其副本状态"),
+			mcp.WithString("cluster",
+				mcp.Description("集群名称(可选,默认使用第一个启用的集群)"),
+			),
+			mcp.WithString("namespace",
+				mcp.Description("命名空间(可选,默认使用集群配置的默认命名空间,未配置时查询所有命名空间)"),
+			),
+			mcp.WithString("label_selector",
+				mcp.Description("标签选择器(可选,如 app=mysql)"),
+			),
+		),
+		s.handleListK8sStatefulSets,
+	)
+
+	// list_k8s_pods - 列出 Pod
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_k8s_pods",
+			mcp.WithDescription("列出 Kubernetes Pod,包含状态、重启次数、Pod IP 和所在节点"),
+			mcp.WithString("cluster",
+				mcp.Description("集群名称(可选,默认使用第一个启用的集群)"),
+			),
+			mcp.WithString("namespace",
+				mcp.Description("命名空间(可选,默认使用集群配置的默认命名空间,未配置时查询所有命名空间)"),
+			),
+			mcp.WithString("label_selector",
+				mcp.Description("标签选择器(可选,如 app=nginx)"),
+			),
+			mcp.WithString("status",
+				mcp.Description("按状态过滤(可选,如 Running, Pending, CrashLoopBackOff)"),
+			),
+		),
+		s.handleListK8sPods,
+	)
+
+	// list_k8s_services - 列出 Service
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_k8s_services",
+			mcp.WithDescription("列出 Kubernetes Service"),
+			mcp.WithString("cluster",
+				mcp.Description("集群名称(可选,默认使用第一个启用的集群)"),
+			),
+			mcp.WithString("namespace",
+				mcp.Description("命名空间(可选,默认使用集群配置的默认命名空间,未配置时查询所有命名空间)"),
+			),
+			mcp.WithString("label_selector",
+				mcp.Description("标签选择器(可选)"),
+			),
+		),
+		s.handleListK8sServices,
+	)
+
+	// search_pod_by_ip - 根据 IP 搜索 Pod
+	s.mcpServer.AddTool(
+		mcp.NewTool("search_pod_by_ip",
+			mcp.WithDescription("根据 Pod IP 搜索 Kubernetes Pod,未指定集群时搜索所有启用的集群"),
+			mcp.WithString("ip",
+				mcp.Required(),
+				mcp.Description("要搜索的 Pod IP 地址"),
+			),
+			mcp.WithString("cluster",
+				mcp.Description("集群名称(可选)"),
+			),
+		),
+		s.handleSearchPodByIP,
+	)
+
+	// get_pod_logs - 获取 Pod 日志
+	s.mcpServer.AddTool(
+		mcp.NewTool("get_pod_logs",
+			mcp.WithDescription("获取 Kubernetes Pod 最后 N 行日志"),
+			mcp.WithString("pod",
+				mcp.Required(),
+				mcp.Description("Pod 名称"),
+			),
+			mcp.WithString("namespace",
+				mcp.Description("命名空间(未配置集群默认命名空间时必填)"),
+			),
+			mcp.WithString("cluster",
+				mcp.Description("集群名称(可选,默认使用第一个启用的集群)"),
+			),
+			mcp.WithString("container",
+				mcp.Description("容器名称(可选,多容器 Pod 时指定)"),
+			),
+			mcp.WithNumber("tail_lines",
+				mcp.Description("返回的日志行数(可选,默认 100,最大 2000)"),
+			),
+			mcp.WithBoolean("previous",
+				mcp.Description("是否获取上一次退出容器的日志(可选,用于排查重启原因)"),
+			),
+		),
+		s.handleGetPodLogs,
+	)
+
 	// ==================== Jenkins 工具 ====================
 
+	// list_jenkins_instances - 列出 Jenkins 实例
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_jenkins_instances",
+			mcp.WithDescription("列出已配置的 Jenkins 实例"),
+		),
+		s.handleListJenkinsInstances,
+	)
+
+	// search_jenkins_job - 跨实例搜索 Jenkins Job
+	s.mcpServer.AddTool(
+		mcp.NewTool("search_jenkins_job",
+			mcp.WithDescription("按名称或描述搜索 Jenkins Job,未指定实例时并发搜索所有启用的 Jenkins 实例"),
+			mcp.WithString("keyword",
+				mcp.Required(),
+				mcp.Description("搜索关键字"),
+			),
+			mcp.WithString("instance",
+				mcp.Description("Jenkins 实例名称(可选,不指定时搜索所有实例)"),
+			),
+		),
+		s.handleSearchJenkinsJob,
+	)
+
 	// 13. list_jenkins_jobs - 列出 Jenkins Jobs
 	s.mcpServer.AddTool(
 		mcp.NewTool("list_jenkins_jobs",
-			mcp.WithDescription("列出所有 Jenkins Job"),
+			mcp.WithDescription("列出所有 Jenkins Job,递归遍历文件夹,Job 名称为完整路径(如 folder/sub/job)"),
+			mcp.WithString("folder",
+				mcp.Description("文件夹路径(可选,只列出该文件夹下的 Job)"),
+			),
+			mcp.WithBoolean("include_branches",
+				mcp.Description("是否同时列出多分支项目的分支(可选,默认 false)"),
+			),
+			mcp.WithString("instance",
+				mcp.Description("Jenkins 实例名称(可选,默认使用第一个启用的实例)"),
+			),
 		),
 		s.handleListJenkinsJobs,
 	)
@@ -305,7 +627,10 @@ func (s *MCPServer) registerTools() {
 			mcp.WithDescription("获取指定 Jenkins Job 的详细信息"),
 			mcp.WithString("job_name",
 				mcp.Required(),
-				mcp.Description("Job 名称"),
+				mcp.Description("Job 名称,文件夹中的 Job 使用 folder/sub/job 形式"),
+			),
+			mcp.WithString("instance",
+				mcp.Description("Jenkins 实例名称(可选,默认使用第一个启用的实例)"),
 			),
 		),
 		s.handleGetJenkinsJob,
@@ -317,47 +642,244 @@ func (s *MCPServer) registerTools() {
 			mcp.WithDescription("列出指定 Jenkins Job 的构建历史"),
 			mcp.WithString("job_name",
 				mcp.Required(),
-				mcp.Description("Job 名称"),
+				mcp.Description("Job 名称,文件夹中的 Job 使用 folder/job 形式,多分支项目的分支使用 project/branch 形式"),
 			),
 			mcp.WithNumber("limit",
 				mcp.Description("限制返回的构建数量(默认 20)"),
 			),
+			mcp.WithString("instance",
+				mcp.Description("Jenkins 实例名称(可选,默认使用第一个启用的实例)"),
+			),
 		),
 		s.handleListJenkinsBuilds,
 	)
-}
 
-// Start 启动 MCP 服务器 (stdio 模式)
-func (s *MCPServer) Start() error {
-	logx.Info("Starting MCP server in stdio mode (using mcp-go library)")
-	return server.ServeStdio(s.mcpServer)
-}
+	// list_jenkins_branches - 列出多分支项目的分支
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_jenkins_branches",
+			mcp.WithDescription("列出 Jenkins 多分支项目(Multibranch Pipeline)的所有分支及其最近构建状态"),
+			mcp.WithString("job_name",
+				mcp.Required(),
+				mcp.Description("多分支项目的完整路径,如 folder/project"),
+			),
+			mcp.WithString("instance",
+				mcp.Description("Jenkins 实例名称(可选,默认使用第一个启用的实例)"),
+			),
+		),
+		s.handleListJenkinsBranches,
+	)
+
+	// list_jenkins_agents - 列出 Jenkins 构建节点
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_jenkins_agents",
+			mcp.WithDescription("列出 Jenkins 构建节点(Agent),包含在线状态、离线原因、执行器忙碌情况和标签"),
+			mcp.WithString("label",
+				mcp.Description("按标签过滤(可选)"),
+			),
+			mcp.WithString("status",
+				mcp.Description("按状态过滤(可选): online 或 offline"),
+			),
+			mcp.WithString("instance",
+				mcp.Description("Jenkins 实例名称(可选,默认使用第一个启用的实例)"),
+			),
+		),
+		s.handleListJenkinsAgents,
+	)
+
+	// trigger_jenkins_build - 触发 Jenkins 构建
+	s.addWriteTool(service.ResourceCICD,
+		mcp.NewTool("trigger_jenkins_build",
+			mcp.WithDescription("触发指定 Jenkins Job 的构建,参数会按 Job 的参数定义校验,返回队列项及构建号"),
+			mcp.WithString("job_name",
+				mcp.Required(),
+				mcp.Description("Job 名称,文件夹中的 Job 使用 folder/job 形式"),
+			),
+			mcp.WithObject("parameters",
+				mcp.Description("构建参数(可选),键为参数名,未传入的参数使用 Job 中配置的默认值"),
+			),
+			mcp.WithNumber("wait_seconds",
+				mcp.Description("等待分配构建号的秒数(可选,默认 10,最大 60)"),
+			),
+			mcp.WithString("instance",
+				mcp.Description("Jenkins 实例名称(可选,默认使用第一个启用的实例)"),
+			),
+		),
+		s.handleTriggerJenkinsBuild,
+	)
+
+	// abort_jenkins_build - 中止 Jenkins 构建
+	s.addWriteTool(service.ResourceCICD,
+		mcp.NewTool("abort_jenkins_build",
+			mcp.WithDescription("中止正在运行的 Jenkins 构建,或通过 queue_id 取消仍在排队的构建"),
+			mcp.WithString("job_name",
+				mcp.Description("Job 名称(中止运行中的构建时必填)"),
+			),
+			mcp.WithNumber("build_number",
+				mcp.Description("构建号(中止运行中的构建时必填)"),
+			),
+			mcp.WithNumber("queue_id",
+				mcp.Description("队列项 ID(可选,指定时取消排队中的构建)"),
+			),
+			mcp.WithString("instance",
+				mcp.Description("Jenkins 实例名称(可选,默认使用第一个启用的实例)"),
+			),
+		),
+		s.handleAbortJenkinsBuild,
+	)
+
+	// get_jenkins_queue - 查询 Jenkins 构建队列
+	s.mcpServer.AddTool(
+		mcp.NewTool("get_jenkins_queue",
+			mcp.WithDescription("查询 Jenkins 构建队列中等待执行的构建,包含等待原因和已等待时长"),
+			mcp.WithString("job_name",
+				mcp.Description("Job 名称(可选,只返回该 Job 的队列项)"),
+			),
+			mcp.WithString("instance",
+				mcp.Description("Jenkins 实例名称(可选,默认使用第一个启用的实例)"),
+			),
+		),
+		s.handleGetJenkinsQueue,
+	)
+
+	// get_jenkins_build_log - 获取 Jenkins 构建日志
+	s.mcpServer.AddTool(
+		mcp.NewTool("get_jenkins_build_log",
+			mcp.WithDescription("获取 Jenkins 构建的控制台日志,支持只看末尾若干行或按关键字过滤"),
+			mcp.WithString("job_name",
+				mcp.Required(),
+				mcp.Description("Job 名称,文件夹中的 Job 使用 folder/job 形式"),
+			),
+			mcp.WithNumber("build_number",
+				mcp.Description("构建号(可选,默认最后一次构建)"),
+			),
+			mcp.WithNumber("tail_lines",
+				mcp.Description("返回末尾的日志行数(可选,默认 200,最大 5000);指定 grep 时为最后若干个匹配行"),
+			),
+			mcp.WithString("grep",
+				mcp.Description("只返回匹配的行(可选,支持正则表达式,不区分大小写),返回结果带行号"),
+			),
+			mcp.WithNumber("max_bytes",
+				mcp.Description("返回内容的大小上限(可选,默认 65536,最大 1048576),超出时保留末尾部分"),
+			),
+			mcp.WithString("instance",
+				mcp.Description("Jenkins 实例名称(可选,默认使用第一个启用的实例)"),
+			),
+		),
+		s.handleGetJenkinsBuildLog,
+	)
+
+	// analyze_jenkins_failure - 分析 Jenkins 构建失败原因
+	s.mcpServer.AddTool(
+		mcp.NewTool("analyze_jenkins_failure",
+			mcp.WithDescription("分析 Jenkins 构建失败的根本原因:拉取构建日志,提取错误片段并由大模型总结根因和修复建议。返回结果已是完整的分析报告,可直接展示给用户"),
+			mcp.WithString("job_name",
+				mcp.Required(),
+				mcp.Description("Job 名称,文件夹中的 Job 使用 folder/job 形式"),
+			),
+			mcp.WithNumber("build_number",
+				mcp.Description("构建号(可选,默认最后一次构建)"),
+			),
+			mcp.WithString("instance",
+				mcp.Description("Jenkins 实例名称(可选,默认使用第一个启用的实例)"),
+			),
+		),
+		s.handleAnalyzeJenkinsFailure,
+	)
+
+	// ==================== GitLab 工具 ====================
+
+	// list_gitlab_pipelines - 列出 GitLab 流水线
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_gitlab_pipelines",
+			mcp.WithDescription("列出指定 GitLab 项目最近的流水线"),
+			mcp.WithString("project",
+				mcp.Required(),
+				mcp.Description("项目完整路径(如 group/project)或项目 ID"),
+			),
+			mcp.WithString("ref",
+				mcp.Description("分支或标签名称(可选)"),
+			),
+			mcp.WithString("status",
+				mcp.Description("流水线状态(可选,如 success, failed, running, canceled)"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("限制返回的流水线数量(默认 10)"),
+			),
+		),
+		s.handleListGitLabPipelines,
+	)
+
+	// get_gitlab_pipeline - 获取 GitLab 流水线详情
+	s.mcpServer.AddTool(
+		mcp.NewTool("get_gitlab_pipeline",
+			mcp.WithDescription("获取 GitLab 流水线详情,包含各阶段作业的状态和失败原因"),
+			mcp.WithString("project",
+				mcp.Required(),
+				mcp.Description("项目完整路径(如 group/project)或项目 ID"),
+			),
+			mcp.WithNumber("pipeline_id",
+				mcp.Required(),
+				mcp.Description("流水线 ID"),
+			),
+		),
+		s.handleGetGitLabPipeline,
+	)
 
-// StartSSE 启动 MCP 服务器 (SSE 模式)
-func (s *MCPServer) StartSSE() error {
-	addr := fmt.Sprintf("0.0.0.0:%d", s.config.Server.MCP.Port)
+	// ==================== GitHub Actions 工具 ====================
 
-	// 记录工具数量
-	tools := s.mcpServer.ListTools()
-	logx.Info("🧰 Starting MCP Server In SSE Mode, Listening On %s (Total tools: %d)", addr, len(tools))
+	// list_github_workflows - 列出 GitHub 工作流
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_github_workflows",
+			mcp.WithDescription("列出 GitHub 仓库中的 Actions 工作流"),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("仓库名称,格式为 owner/repo"),
+			),
+		),
+		s.handleListGitHubWorkflows,
+	)
 
-	// 创建 SSE 服务器
-	s.sseServer = server.NewSSEServer(
-		s.mcpServer,
-		server.WithSSEEndpoint("/sse"),
-		server.WithMessageEndpoint("/message"),
+	// get_github_workflow - 获取 GitHub 工作流详情
+	s.mcpServer.AddTool(
+		mcp.NewTool("get_github_workflow",
+			mcp.WithDescription("获取 GitHub Actions 工作流详情及最近一次运行"),
+			mcp.WithString("workflow",
+				mcp.Required(),
+				mcp.Description("工作流标识,格式为 owner/repo/workflow,workflow 为工作流文件名(如 ci.yml)或 ID"),
+			),
+		),
+		s.handleGetGitHubWorkflow,
 	)
 
-	// 启动服务器
-	return s.sseServer.Start(addr)
+	// list_github_runs - 列出 GitHub 工作流运行记录
+	s.mcpServer.AddTool(
+		mcp.NewTool("list_github_runs",
+			mcp.WithDescription("列出 GitHub Actions 运行记录,包含结论、时长和触发人"),
+			mcp.WithString("target",
+				mcp.Required(),
+				mcp.Description("owner/repo 查询仓库所有工作流,owner/repo/workflow 查询指定工作流"),
+			),
+			mcp.WithString("branch",
+				mcp.Description("分支名称(可选)"),
+			),
+			mcp.WithString("status",
+				mcp.Description("状态或结论(可选,如 success, failure, in_progress, queued)"),
+			),
+			mcp.WithString("event",
+				mcp.Description("触发事件(可选,如 push, pull_request, schedule)"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("限制返回的运行记录数量(默认 10)"),
+			),
+		),
+		s.handleListGitHubRuns,
+	)
 }
 
-// StopSSE 停止 SSE 服务器
-func (s *MCPServer) StopSSE(ctx context.Context) error {
-	if s.sseServer != nil {
-		return s.sseServer.Shutdown(ctx)
-	}
-	return nil
+// Start 启动 MCP 服务器 (stdio 模式)
+func (s *MCPServer) Start() error {
+	logx.Info("Starting MCP server in stdio mode (using mcp-go library)")
+	return server.ServeStdio(s.mcpServer)
 }
 
 // CallTool 调用 MCP 工具(公开方法,供其他包使用)
@@ -369,6 +891,12 @@ func (s *MCPServer) CallTool(ctx context.Context, toolName string, arguments map
 		},
 	}
 
+	// 写操作工具按调用方权限校验
+	if err := s.checkToolPermission(ctx, toolName); err != nil {
+		logx.Warn("Tool call denied, tool %s, error %v", toolName, err)
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	// 根据工具名称调用对应的处理函数
 	switch toolName {
 	// 阿里云 ECS
@@ -415,13 +943,85 @@ func (s *MCPServer) CallTool(ctx context.Context, toolName string, arguments map
 	case "get_cos":
 		return s.handleGetCOS(ctx, request)
 
+	// AWS EC2
+	case "search_ec2_by_ip":
+		return s.handleSearchEC2ByIP(ctx, request)
+	case "search_ec2_by_name":
+		return s.handleSearchEC2ByName(ctx, request)
+	case "list_ec2":
+		return s.handleListEC2(ctx, request)
+	case "get_ec2":
+		return s.handleGetEC2(ctx, request)
+
+	// AWS RDS
+	case "list_aws_rds":
+		return s.handleListAWSRDS(ctx, request)
+	case "search_aws_rds_by_name":
+		return s.handleSearchAWSRDSByName(ctx, request)
+
+	// AWS S3
+	case "list_s3":
+		return s.handleListS3(ctx, request)
+	case "get_s3":
+		return s.handleGetS3(ctx, request)
+
+	// Kubernetes
+	case "list_k8s_clusters":
+		return s.handleListK8sClusters(ctx, request)
+	case "list_k8s_namespaces":
+		return s.handleListK8sNamespaces(ctx, request)
+	case "list_k8s_deployments":
+		return s.handleListK8sDeployments(ctx, request)
+	case "list_k8s_statefulsets":
+		return s.handleListK8sStatefulSets(ctx, request)
+	case "list_k8s_pods":
+		return s.handleListK8sPods(ctx, request)
+	case "list_k8s_services":
+		return s.handleListK8sServices(ctx, request)
+	case "search_pod_by_ip":
+		return s.handleSearchPodByIP(ctx, request)
+	case "get_pod_logs":
+		return s.handleGetPodLogs(ctx, request)
+
 	// Jenkins
+	case "list_jenkins_instances":
+		return s.handleListJenkinsInstances(ctx, request)
+	case "search_jenkins_job":
+		return s.handleSearchJenkinsJob(ctx, request)
 	case "list_jenkins_jobs":
 		return s.handleListJenkinsJobs(ctx, request)
 	case "get_jenkins_job":
 		return s.handleGetJenkinsJob(ctx, request)
 	case "list_jenkins_builds":
 		return s.handleListJenkinsBuilds(ctx, request)
+	case "list_jenkins_branches":
+		return s.handleListJenkinsBranches(ctx, request)
+	case "list_jenkins_agents":
+		return s.handleListJenkinsAgents(ctx, request)
+	case "trigger_jenkins_build":
+		return s.handleTriggerJenkinsBuild(ctx, request)
+	case "abort_jenkins_build":
+		return s.handleAbortJenkinsBuild(ctx, request)
+	case "get_jenkins_queue":
+		return s.handleGetJenkinsQueue(ctx, request)
+	case "get_jenkins_build_log":
+		return s.handleGetJenkinsBuildLog(ctx, request)
+	case "analyze_jenkins_failure":
+		return s.handleAnalyzeJenkinsFailure(ctx, request)
+
+	// GitLab
+	case "list_gitlab_pipelines":
+		return s.handleListGitLabPipelines(ctx, request)
+	case "get_gitlab_pipeline":
+		return s.handleGetGitLabPipeline(ctx, request)
+
+	// GitHub Actions
+	case "list_github_workflows":
+		return s.handleListGitHubWorkflows(ctx, request)
+	case "get_github_workflow":
+		return s.handleGetGitHubWorkflow(ctx, request)
+	case "list_github_runs":
+		return s.handleListGitHubRuns(ctx, request)
 
 	default:
 		// 尝试从底层 MCP Server 调用工具(用于外部 MCP 工具,如 CNB)
@@ -0,0 +1,152 @@
+package imcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// MCP 服务器的 HTTP 传输方式
+const (
+	TransportSSE            = "sse"             // 旧版 HTTP+SSE, 端点 /sse 和 /message
+	TransportStreamableHTTP = "streamable_http" // Streamable HTTP, 端点由 streamable_path 指定
+	TransportBoth           = "both"            // 同一端口同时提供两种传输
+)
+
+const (
+	sseEndpoint           = "/sse"
+	messageEndpoint       = "/message"
+	defaultStreamablePath = "/mcp"
+	defaultTransport      = TransportBoth
+)
+
+// StartHTTP 启动 MCP 服务器的 HTTP 传输 (阻塞), 按配置提供 SSE 和/或 Streamable HTTP
+// 调用 Shutdown 后返回 nil
+func (s *MCPServer) StartHTTP() error {
+	mcpCfg := s.config.Server.MCP
+	addr := fmt.Sprintf("0.0.0.0:%d", mcpCfg.Port)
+
+	transport := mcpCfg.Transport
+	if transport == "" {
+		transport = defaultTransport
+	}
+	enableSSE := transport == TransportSSE || transport == TransportBoth
+	enableStreamable := transport == TransportStreamableHTTP || transport == TransportBoth
+	if !enableSSE && !enableStreamable {
+		return fmt.Errorf("invalid mcp transport %q, must be one of sse, streamable_http, both", transport)
+	}
+
+	streamablePath := defaultStreamablePath
+	if mcpCfg.StreamablePath != "" {
+		streamablePath = "/" + strings.Trim(mcpCfg.StreamablePath, "/")
+	}
+	if enableSSE && enableStreamable && (streamablePath == sseEndpoint || streamablePath == messageEndpoint) {
+		return fmt.Errorf("mcp streamable_path %s conflicts with the SSE endpoints", streamablePath)
+	}
+
+	mux := http.NewServeMux()
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: s.authenticate(mux),
+	}
+	streamsCtx, closeStreams := context.WithCancel(context.Background())
+
+	s.transportMu.Lock()
+	if s.httpServer != nil {
+		s.transportMu.Unlock()
+		closeStreams()
+		return fmt.Errorf("mcp http server already started")
+	}
+	s.httpServer = httpServer
+	s.closeStreams = closeStreams
+
+	var endpoints []string
+	if enableSSE {
+		s.sseServer = server.NewSSEServer(
+			s.mcpServer,
+			server.WithSSEEndpoint(sseEndpoint),
+			server.WithMessageEndpoint(messageEndpoint),
+			server.WithHTTPServer(httpServer),
+		)
+		mux.Handle(sseEndpoint, s.sseServer)
+		mux.Handle(messageEndpoint, s.sseServer)
+		endpoints = append(endpoints, "SSE "+sseEndpoint)
+	}
+	if enableStreamable {
+		// 默认有状态: initialize 时分配 Mcp-Session-Id, 后续请求校验会话, DELETE 结束会话
+		opts := []server.StreamableHTTPOption{
+			server.WithEndpointPath(streamablePath),
+			server.WithStreamableHTTPServer(httpServer),
+			server.WithStateful(!mcpCfg.Stateless),
+			server.WithStateLess(mcpCfg.Stateless),
+		}
+		if mcpCfg.HeartbeatInterval > 0 {
+			opts = append(opts, server.WithHeartbeatInterval(time.Duration(mcpCfg.HeartbeatInterval)*time.Second))
+		}
+		s.streamableServer = server.NewStreamableHTTPServer(s.mcpServer, opts...)
+		mux.Handle(streamablePath, closeOnShutdown(s.streamableServer, streamsCtx))
+		endpoints = append(endpoints, "Streamable HTTP "+streamablePath)
+	}
+	s.transportMu.Unlock()
+
+	tools := s.mcpServer.ListTools()
+	logx.Info("🧰 Starting MCP Server, Listening On %s, Endpoints %s (Total tools: %d)", addr, strings.Join(endpoints, ", "), len(tools))
+	if mcpCfg.AllowAnonymous {
+		logx.Warn("⚠️  MCP server allows anonymous access (server.mcp.allow_anonymous), endpoints are open to anyone who can reach port %d", mcpCfg.Port)
+	} else if !s.hasAccessTokens() {
+		logx.Warn("⚠️  MCP server has no access tokens, all requests will be rejected until a token is created in /api/v1/mcp/tokens")
+	}
+
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown 优雅停止 HTTP 传输
+// 先关闭 SSE 会话和 Streamable HTTP 的 GET 通知流, 再等待进行中的工具调用完成, 直到 ctx 超时
+func (s *MCPServer) Shutdown(ctx context.Context) error {
+	s.transportMu.Lock()
+	defer s.transportMu.Unlock()
+
+	if s.httpServer == nil {
+		return nil
+	}
+	s.closeStreams()
+
+	var err error
+	if s.sseServer != nil {
+		// SSEServer.Shutdown 会关闭所有 SSE 会话并停止共享的 http.Server
+		err = s.sseServer.Shutdown(ctx)
+	} else {
+		err = s.httpServer.Shutdown(ctx)
+	}
+
+	s.httpServer = nil
+	s.sseServer = nil
+	s.streamableServer = nil
+	s.closeStreams = nil
+	return err
+}
+
+// closeOnShutdown 在服务停止时结束 GET 长连接
+// Streamable HTTP 的 GET 通知流只在请求上下文结束时返回, 不处理会阻塞 http.Server.Shutdown
+func closeOnShutdown(next http.Handler, streamsCtx context.Context) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		stop := context.AfterFunc(streamsCtx, cancel)
+		defer stop()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -1,4 +1,6 @@
-package dingtalk
+// Package intent 将 IM 机器人收到的自然语言消息解析为 MCP 工具调用,
+// 用于未启用 LLM 时的查询模式, 钉钉和飞书共用同一份意图表
+package intent
 
 import (
 	"fmt"
@@ -17,8 +19,8 @@ type Intent struct {
 	MCPTool  string            // 对应的 MCP 工具名称
 }
 
-// IntentParser 意图解析器
-type IntentParser struct {
+// Parser 意图解析器
+type Parser struct {
 	patterns []intentPattern
 }
 
@@ -30,9 +32,9 @@ type intentPattern struct {
 	extractor func([]string) map[string]string
 }
 
-// NewIntentParser 创建意图解析器
-func NewIntentParser() *IntentParser {
-	parser := &IntentParser{
+// NewParser 创建意图解析器
+func NewParser() *Parser {
+	parser := &Parser{
 		patterns: make([]intentPattern, 0),
 	}
 
@@ -43,7 +45,7 @@ func NewIntentParser() *IntentParser {
 }
 
 // registerPatterns 注册意图匹配模式
-func (p *IntentParser) registerPatterns() {
+func (p *Parser) registerPatterns() {
 	// ==================== 阿里云 ECS ====================
 
 	// 按 IP 搜索 ECS
@@ -191,6 +193,21 @@ func (p *IntentParser) registerPatterns() {
 
 	// ==================== Jenkins ====================
 
+	// 分析构建失败原因, 如 "分析 order-service #12 构建失败"
+	p.patterns = append(p.patterns, intentPattern{
+		regex:    regexp.MustCompile(`(?i)(分析|诊断)\s*([\w\-\./]+)\s*(#(\d+))?.*(失败|报错|fail)`),
+		provider: "jenkins",
+		resource: "build",
+		action:   "analyze",
+		extractor: func(matches []string) map[string]string {
+			params := map[string]string{"job_name": matches[2]}
+			if matches[4] != "" {
+				params["build_number"] = matches[4]
+			}
+			return params
+		},
+	})
+
 	// 列出 Jenkins Job
 	p.patterns = append(p.patterns, intentPattern{
 		regex:    regexp.MustCompile(`(?i)(列出|查询?|看).*(jenkins|Jenkins).*(job|Job|任务)`),
@@ -237,7 +254,7 @@ func (p *IntentParser) registerPatterns() {
 }
 
 // Parse 解析用户消息
-func (p *IntentParser) Parse(message string) (*Intent, error) {
+func (p *Parser) Parse(message string) (*Intent, error) {
 	logx.Debug("Parsing intent, message %s", message)
 
 	// 遍历所有模式
@@ -272,7 +289,7 @@ func (p *IntentParser) Parse(message string) (*Intent, error) {
 }
 
 // mapToMCPTool 将意图映射到 MCP 工具
-func (p *IntentParser) mapToMCPTool(intent *Intent) string {
+func (p *Parser) mapToMCPTool(intent *Intent) string {
 	key := fmt.Sprintf("%s_%s_%s", intent.Provider, intent.Resource, intent.Action)
 
 	mapping := map[string]string{
@@ -297,9 +314,10 @@ func (p *IntentParser) mapToMCPTool(intent *Intent) string {
 		"tencent_cdb_search_name": "search_cdb_by_name",
 
 		// Jenkins
-		"jenkins_job_list":   "list_jenkins_jobs",
-		"jenkins_job_get":    "get_jenkins_job",
-		"jenkins_build_list": "list_jenkins_builds",
+		"jenkins_job_list":      "list_jenkins_jobs",
+		"jenkins_job_get":       "get_jenkins_job",
+		"jenkins_build_list":    "list_jenkins_builds",
+		"jenkins_build_analyze": "analyze_jenkins_failure",
 	}
 
 	if tool, ok := mapping[key]; ok {
@@ -308,30 +326,3 @@ func (p *IntentParser) mapToMCPTool(intent *Intent) string {
 
 	return ""
 }
-
-// GetHelpMessage 获取帮助消息
-func GetHelpMessage() string {
-	return `👋 你好!我是 ZenOps 运维助手,可以帮你查询云资源和 CI/CD 信息。
-
-**支持的查询:**
-
-📦 **阿里云**
-• 列出 ECS 实例: "查询阿里云杭州的 ECS"
-• 搜索 IP: "找一下 IP 为 192.168.1.1 的服务器"
-• 搜索名称: "查询名为 web-server 的实例"
-• 数据库: "列出阿里云 RDS 数据库"
-
-📦 **腾讯云**
-• 列出 CVM: "查询腾讯云广州的 CVM"
-• 搜索 IP: "找腾讯云 IP 10.0.0.1 的机器"
-• 数据库: "列出腾讯云 CDB"
-
-🔧 **Jenkins**
-• 列出任务: "看一下 Jenkins 任务列表"
-• 构建历史: "查询 deploy-prod 的构建历史"
-
-**提示:**
-• 可以在群里 @我 或私聊我
-• 描述越详细,查询越准确
-• 支持中文和英文关键词`
-}
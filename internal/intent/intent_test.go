@@ -0,0 +1,45 @@
+package intent
+
+import "testing"
+
+func TestParseJenkinsFailureAnalysis(t *testing.T) {
+	parser := NewParser()
+
+	tests := []struct {
+		message string
+		params  map[string]string
+	}{
+		{message: "分析 deploy-prod #12 构建失败", params: map[string]string{"job_name": "deploy-prod", "build_number": "12"}},
+		{message: "诊断 folder/order-service 为什么 fail", params: map[string]string{"job_name": "folder/order-service"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			got, err := parser.Parse(tt.message)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.MCPTool != "analyze_jenkins_failure" {
+				t.Fatalf("MCPTool = %q, want analyze_jenkins_failure", got.MCPTool)
+			}
+			if len(got.Params) != len(tt.params) {
+				t.Fatalf("Params = %v, want %v", got.Params, tt.params)
+			}
+			for k, v := range tt.params {
+				if got.Params[k] != v {
+					t.Errorf("Params[%s] = %q, want %q", k, got.Params[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestParseJenkinsJobListIsNotAnalysis(t *testing.T) {
+	got, err := NewParser().Parse("看一下 Jenkins 任务列表")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got.MCPTool != "list_jenkins_jobs" {
+		t.Fatalf("MCPTool = %q, want list_jenkins_jobs", got.MCPTool)
+	}
+}
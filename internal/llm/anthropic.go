@@ -0,0 +1,461 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+)
+
+const (
+	// defaultAnthropicBaseURL Anthropic 官方 API 地址
+	defaultAnthropicBaseURL = "https://api.anthropic.com"
+	// anthropicVersion Messages API 版本
+	anthropicVersion = "2023-06-01"
+	// defaultAnthropicMaxTokens 单次回复的最大 token 数, Messages API 要求必填
+	defaultAnthropicMaxTokens = 8192
+	// maxSSELineBytes SSE 单行的最大长度
+	maxSSELineBytes = 1024 * 1024
+)
+
+// AnthropicClient Anthropic 原生 Messages API 客户端
+// 工具调用使用 tool_use / tool_result 内容块, 避免经过 OpenAI 兼容代理时丢失信息
+type AnthropicClient struct {
+	config     *Config
+	endpoint   string
+	httpClient *http.Client
+}
+
+// anthropicRequest Messages API 请求
+type anthropicRequest struct {
+	Model         string             `json:"model"`
+	MaxTokens     int                `json:"max_tokens"`
+	System        string             `json:"system,omitempty"`
+	Messages      []anthropicMessage `json:"messages"`
+	Tools         []anthropicTool    `json:"tools,omitempty"`
+	ToolChoice    map[string]any     `json:"tool_choice,omitempty"`
+	Temperature   *float32           `json:"temperature,omitempty"`
+	StopSequences []string           `json:"stop_sequences,omitempty"`
+	Stream        bool               `json:"stream,omitempty"`
+}
+
+// anthropicMessage Messages API 消息, 内容统一使用内容块数组
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+// anthropicContentBlock 内容块, 根据 Type 使用不同字段
+// text: Text; tool_use: ID/Name/Input; tool_result: ToolUseID/Content/IsError
+type anthropicContentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+}
+
+// anthropicTool 工具定义
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+// anthropicUsage token 用量, 命中或写入提示缓存的 token 不计入 input_tokens
+type anthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// promptTokens 返回包含缓存部分的输入 token 数
+func (u *anthropicUsage) promptTokens() int {
+	return u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+}
+
+// anthropicResponse 非流式响应
+type anthropicResponse struct {
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      anthropicUsage          `json:"usage"`
+}
+
+// anthropicError 错误响应, 流式的 error 事件使用相同结构
+type anthropicError struct {
+	Type  string `json:"type"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// anthropicStreamEvent 流式响应事件
+type anthropicStreamEvent struct {
+	Type         string                 `json:"type"`
+	Index        int                    `json:"index"`
+	ContentBlock *anthropicContentBlock `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Message *struct {
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"`
+	Usage *anthropicUsage `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewAnthropicClient 创建 Anthropic 客户端
+// BaseURL 为空时使用官方地址, 可填写 https://api.anthropic.com 或带 /v1 的地址
+func NewAnthropicClient(config *Config) *AnthropicClient {
+	baseURL := strings.TrimRight(config.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultAnthropicBaseURL
+	}
+	endpoint := baseURL + "/v1/messages"
+	if strings.HasSuffix(baseURL, "/v1") {
+		endpoint = baseURL + "/messages"
+	}
+
+	logx.Info("Anthropic client initialized, model %s", config.Model)
+
+	return &AnthropicClient{
+		config:     config,
+		endpoint:   endpoint,
+		httpClient: newHTTPClient(),
+	}
+}
+
+// StreamChatWithTools 使用流式 Messages API 进行对话(支持工具调用)
+func (c *AnthropicClient) StreamChatWithTools(ctx context.Context, messages []Message, tools []Tool, responseCh chan<- string) (*StreamResult, error) {
+	req := c.buildRequest(messages, tools)
+	req.Stream = true
+	applyAnthropicOptions(req, optionsFromContext(ctx))
+
+	logx.Debug("Creating anthropic streaming message with tools")
+	resp, err := c.doRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &StreamResult{
+		Content:   "",
+		ToolCalls: []ToolCall{},
+	}
+
+	// 工具调用累积器 (key: 内容块索引, value: 累积的工具调用)
+	toolCallsAccumulator := make(map[int]*ToolCall)
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSSELineBytes)
+
+	// 事件类型已包含在 data 中, 只需处理 data 行
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "" {
+			continue
+		}
+
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			logx.Warn("Failed to parse anthropic stream event: %v", err)
+			continue
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				result.Usage.PromptTokens = event.Message.Usage.promptTokens()
+				result.Usage.CompletionTokens = event.Message.Usage.OutputTokens
+			}
+		case "content_block_start":
+			if event.ContentBlock != nil && event.ContentBlock.Type == "tool_use" {
+				toolCall := &ToolCall{
+					ID:   event.ContentBlock.ID,
+					Type: "function",
+				}
+				toolCall.Function.Name = event.ContentBlock.Name
+				toolCallsAccumulator[event.Index] = toolCall
+			}
+		case "content_block_delta":
+			switch event.Delta.Type {
+			case "text_delta":
+				if event.Delta.Text != "" {
+					result.Content += event.Delta.Text
+					responseCh <- event.Delta.Text // 实时推送内容
+				}
+			case "input_json_delta":
+				if toolCall, exists := toolCallsAccumulator[event.Index]; exists {
+					toolCall.Function.Arguments += event.Delta.PartialJSON
+				}
+			}
+		case "message_delta":
+			// message_delta 中的 output_tokens 为累计值
+			if event.Usage != nil {
+				result.Usage.CompletionTokens = event.Usage.OutputTokens
+			}
+			if event.Delta.StopReason != "" {
+				logx.Debug("Anthropic stream finished, reason: %s", event.Delta.StopReason)
+				result.FinishReason = anthropicFinishReason(event.Delta.StopReason)
+			}
+		case "message_stop":
+			return c.finishStream(result, toolCallsAccumulator), nil
+		case "error":
+			if event.Error != nil {
+				return result, fmt.Errorf("stream error: %w", &APIError{Type: event.Error.Type, Message: event.Error.Message})
+			}
+			return result, fmt.Errorf("stream error: %s", data)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("stream error: %w", err)
+	}
+
+	// 未收到 message_stop 时连接被提前关闭, 已推送的内容无法撤回, 按中断处理
+	return result, fmt.Errorf("stream error: %w", io.ErrUnexpectedEOF)
+}
+
+// finishStream 将累积的工具调用按内容块顺序写入结果
+func (c *AnthropicClient) finishStream(result *StreamResult, toolCallsAccumulator map[int]*ToolCall) *StreamResult {
+	if len(toolCallsAccumulator) == 0 {
+		return result
+	}
+
+	indices := make([]int, 0, len(toolCallsAccumulator))
+	for idx := range toolCallsAccumulator {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+
+	for _, idx := range indices {
+		toolCall := toolCallsAccumulator[idx]
+		// 无参数的工具不会产生 input_json_delta
+		if strings.TrimSpace(toolCall.Function.Arguments) == "" {
+			toolCall.Function.Arguments = "{}"
+		}
+		result.ToolCalls = append(result.ToolCalls, *toolCall)
+	}
+
+	logx.Info("Accumulated %d tool calls", len(result.ToolCalls))
+	return result
+}
+
+// Complete 不带工具的单轮对话, 返回完整回复与 token 用量
+func (c *AnthropicClient) Complete(ctx context.Context, messages []Message) (string, Usage, error) {
+	resp, err := c.doRequest(ctx, c.buildRequest(messages, nil))
+	if err != nil {
+		return "", Usage{}, err
+	}
+	defer resp.Body.Close()
+
+	var result anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", Usage{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	var builder strings.Builder
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			builder.WriteString(block.Text)
+		}
+	}
+
+	usage := Usage{
+		PromptTokens:     result.Usage.promptTokens(),
+		CompletionTokens: result.Usage.OutputTokens,
+	}
+	return builder.String(), usage, nil
+}
+
+// doRequest 发送 Messages API 请求, 非 2xx 响应时解析错误信息
+func (c *AnthropicClient) doRequest(ctx context.Context, req *anthropicRequest) (*http.Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", c.config.APIKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+	if req.Stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call anthropic api: %w", err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		var errResp anthropicError
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error.Message != "" {
+			apiErr.Type = errResp.Error.Type
+			apiErr.Message = errResp.Error.Message
+		}
+		return nil, fmt.Errorf("anthropic %w", apiErr)
+	}
+
+	return resp, nil
+}
+
+// buildRequest 将 OpenAI 风格的消息与工具转换为 Messages API 请求
+func (c *AnthropicClient) buildRequest(messages []Message, tools []Tool) *anthropicRequest {
+	system, anthropicMessages := convertMessagesToAnthropic(messages)
+
+	req := &anthropicRequest{
+		Model:     c.config.Model,
+		MaxTokens: defaultAnthropicMaxTokens,
+		System:    system,
+		Messages:  anthropicMessages,
+	}
+
+	for _, tool := range tools {
+		schema := tool.Function.Parameters
+		// input_schema 必须是 object 类型
+		if schema == nil {
+			schema = map[string]any{
+				"type":       "object",
+				"properties": map[string]any{},
+			}
+		}
+		req.Tools = append(req.Tools, anthropicTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: schema,
+		})
+	}
+
+	return req
+}
+
+// applyAnthropicOptions 将客户端指定的生成参数转换为 Messages API 的对应字段
+func applyAnthropicOptions(req *anthropicRequest, options ChatOptions) {
+	if options.MaxTokens > 0 {
+		req.MaxTokens = options.MaxTokens
+	}
+	req.Temperature = options.Temperature
+	req.StopSequences = options.Stop
+
+	if len(req.Tools) == 0 || options.ToolChoice == nil {
+		return
+	}
+	switch {
+	case options.toolChoiceFunction() != "":
+		req.ToolChoice = map[string]any{"type": "tool", "name": options.toolChoiceFunction()}
+	case options.ToolChoice == "required":
+		req.ToolChoice = map[string]any{"type": "any"}
+	case options.ToolChoice == "none":
+		req.ToolChoice = map[string]any{"type": "none"}
+	default:
+		req.ToolChoice = map[string]any{"type": "auto"}
+	}
+}
+
+// anthropicFinishReason 将 stop_reason 转换为 OpenAI 的 finish_reason
+func anthropicFinishReason(stopReason string) string {
+	switch stopReason {
+	case "max_tokens":
+		return FinishReasonLength
+	case "tool_use":
+		return FinishReasonToolCalls
+	default:
+		return FinishReasonStop
+	}
+}
+
+// convertMessagesToAnthropic 转换消息格式
+// system 消息合并为顶层 system 字段; assistant 的工具调用转换为 tool_use 内容块;
+// tool 消息转换为 user 消息中的 tool_result 内容块。Messages API 要求 user/assistant 交替出现,
+// 相邻的同角色消息 (如多个工具结果) 合并为一条
+func convertMessagesToAnthropic(messages []Message) (string, []anthropicMessage) {
+	var systemParts []string
+	var result []anthropicMessage
+
+	for _, msg := range messages {
+		content := convertContent(msg.Content)
+
+		var role string
+		var blocks []anthropicContentBlock
+
+		switch msg.Role {
+		case "system":
+			if content != "" {
+				systemParts = append(systemParts, content)
+			}
+			continue
+		case "assistant":
+			role = "assistant"
+			if content != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: content})
+			}
+			for _, tc := range msg.ToolCalls {
+				input := json.RawMessage(tc.Function.Arguments)
+				if !json.Valid(input) {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(blocks, anthropicContentBlock{
+					Type:  "tool_use",
+					ID:    tc.ID,
+					Name:  tc.Function.Name,
+					Input: input,
+				})
+			}
+		case "tool":
+			role = "user"
+			blocks = append(blocks, anthropicContentBlock{
+				Type:      "tool_result",
+				ToolUseID: msg.ToolCallID,
+				Content:   content,
+				IsError:   strings.HasPrefix(content, "Error: "),
+			})
+		default:
+			role = "user"
+			if content != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: content})
+			}
+		}
+
+		// 空文本块会被接口拒绝
+		if len(blocks) == 0 {
+			continue
+		}
+
+		if n := len(result); n > 0 && result[n-1].Role == role {
+			result[n-1].Content = append(result[n-1].Content, blocks...)
+			continue
+		}
+		result = append(result, anthropicMessage{Role: role, Content: blocks})
+	}
+
+	return strings.Join(systemParts, "\n\n"), result
+}
@@ -0,0 +1,218 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newFakeAnthropic 模拟 Messages API 的 SSE 流式接口, 依次发送 events 并记录收到的请求
+func newFakeAnthropic(t *testing.T, events []string, got *anthropicRequest) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("request path = %s, want /v1/messages", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("missing anthropic headers: %v", r.Header)
+		}
+		if got != nil {
+			if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+				t.Errorf("decode request error = %v", err)
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		for _, event := range events {
+			var payload struct {
+				Type string `json:"type"`
+			}
+			json.Unmarshal([]byte(event), &payload)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", payload.Type, event)
+			flusher.Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// streamAnthropic 调用流式接口, 返回推送的文本增量和累积结果
+func streamAnthropic(t *testing.T, baseURL string, messages []Message, tools []Tool) ([]string, *StreamResult, error) {
+	t.Helper()
+	client := NewAnthropicClient(&Config{Provider: ProviderAnthropic, Model: "claude-test", APIKey: "test-key", BaseURL: baseURL})
+
+	responseCh := make(chan string, 100)
+	result, err := client.StreamChatWithTools(context.Background(), messages, tools, responseCh)
+	close(responseCh)
+
+	var chunks []string
+	for chunk := range responseCh {
+		chunks = append(chunks, chunk)
+	}
+	return chunks, result, err
+}
+
+func TestAnthropicStreamTextAndToolUse(t *testing.T) {
+	events := []string{
+		`{"type":"message_start","message":{"usage":{"input_tokens":20,"cache_read_input_tokens":5,"output_tokens":1}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"正在查询"}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" ECS"}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_01","name":"list_ecs","input":{}}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":""}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"region\": "}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"cn-hangzhou\"}"}}`,
+		`{"type":"content_block_stop","index":1}`,
+		`{"type":"content_block_start","index":2,"content_block":{"type":"tool_use","id":"toolu_02","name":"list_jenkins_jobs","input":{}}}`,
+		`{"type":"content_block_stop","index":2}`,
+		`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":42}}`,
+		`{"type":"message_stop"}`,
+	}
+	var request anthropicRequest
+	server := newFakeAnthropic(t, events, &request)
+
+	previous := ToolCall{ID: "toolu_00", Type: "function"}
+	previous.Function.Name = "list_regions"
+	previous.Function.Arguments = "{}"
+	messages := []Message{
+		{Role: "system", Content: "你是运维助手"},
+		{Role: "user", Content: "查一下杭州的 ECS"},
+		{Role: "assistant", ToolCalls: []ToolCall{previous}},
+		{Role: "tool", ToolCallID: "toolu_00", Name: "list_regions", Content: "cn-hangzhou"},
+	}
+	tools := []Tool{{Type: "function", Function: Function{Name: "list_ecs", Description: "列出 ECS", Parameters: map[string]any{"type": "object"}}}}
+
+	chunks, result, err := streamAnthropic(t, server.URL, messages, tools)
+	if err != nil {
+		t.Fatalf("StreamChatWithTools() error = %v", err)
+	}
+
+	// 请求: system 提升为顶层字段, 工具调用与工具结果转换为内容块
+	if !request.Stream || request.Model != "claude-test" || request.System != "你是运维助手" {
+		t.Errorf("request = stream %v, model %q, system %q", request.Stream, request.Model, request.System)
+	}
+	if len(request.Tools) != 1 || request.Tools[0].Name != "list_ecs" {
+		t.Errorf("request tools = %+v, want list_ecs", request.Tools)
+	}
+	if len(request.Messages) != 3 || request.Messages[1].Content[0].Type != "tool_use" || request.Messages[2].Content[0].ToolUseID != "toolu_00" {
+		t.Errorf("request messages = %+v, want user, tool_use, tool_result", request.Messages)
+	}
+
+	// 响应: 文本增量实时推送, 工具调用按内容块顺序转换为 OpenAI 格式
+	if strings.Join(chunks, "|") != "正在查询| ECS" {
+		t.Errorf("chunks = %q, want text deltas in order", chunks)
+	}
+	if result.Content != "正在查询 ECS" {
+		t.Errorf("content = %q", result.Content)
+	}
+	if len(result.ToolCalls) != 2 {
+		t.Fatalf("tool calls = %+v, want 2", result.ToolCalls)
+	}
+	first := result.ToolCalls[0]
+	if first.ID != "toolu_01" || first.Type != "function" || first.Function.Name != "list_ecs" || first.Function.Arguments != `{"region": "cn-hangzhou"}` {
+		t.Errorf("first tool call = %+v", first)
+	}
+	if second := result.ToolCalls[1]; second.Function.Name != "list_jenkins_jobs" || second.Function.Arguments != "{}" {
+		t.Errorf("tool call without input = %+v, want arguments {}", second)
+	}
+	if result.FinishReason != FinishReasonToolCalls {
+		t.Errorf("finish reason = %q, want %q", result.FinishReason, FinishReasonToolCalls)
+	}
+	if result.Usage.PromptTokens != 25 || result.Usage.CompletionTokens != 42 {
+		t.Errorf("usage = %+v, want prompt 25 completion 42", result.Usage)
+	}
+}
+
+func TestAnthropicStreamStopReasons(t *testing.T) {
+	tests := []struct {
+		stopReason string
+		want       string
+	}{
+		{stopReason: "end_turn", want: FinishReasonStop},
+		{stopReason: "max_tokens", want: FinishReasonLength},
+		{stopReason: "stop_sequence", want: FinishReasonStop},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stopReason, func(t *testing.T) {
+			server := newFakeAnthropic(t, []string{
+				`{"type":"message_start","message":{"usage":{"input_tokens":3,"output_tokens":1}}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"ok"}}`,
+				fmt.Sprintf(`{"type":"message_delta","delta":{"stop_reason":%q},"usage":{"output_tokens":2}}`, tt.stopReason),
+				`{"type":"message_stop"}`,
+			}, nil)
+
+			_, result, err := streamAnthropic(t, server.URL, []Message{{Role: "user", Content: "hi"}}, nil)
+			if err != nil {
+				t.Fatalf("StreamChatWithTools() error = %v", err)
+			}
+			if result.FinishReason != tt.want {
+				t.Errorf("finish reason = %q, want %q", result.FinishReason, tt.want)
+			}
+			if len(result.ToolCalls) != 0 {
+				t.Errorf("tool calls = %+v, want none", result.ToolCalls)
+			}
+		})
+	}
+}
+
+func TestAnthropicStreamErrorEvent(t *testing.T) {
+	server := newFakeAnthropic(t, []string{
+		`{"type":"message_start","message":{"usage":{"input_tokens":3,"output_tokens":1}}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"部分回答"}}`,
+		`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+	}, nil)
+
+	chunks, result, err := streamAnthropic(t, server.URL, []Message{{Role: "user", Content: "hi"}}, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("StreamChatWithTools() error = %v, want APIError", err)
+	}
+	if apiErr.Type != "overloaded_error" || apiErr.Message != "Overloaded" || apiErr.StatusCode != 0 {
+		t.Errorf("api error = %+v", apiErr)
+	}
+	// 出错前已推送的内容同时返回, 调用方据此判断能否切换模型
+	if result == nil {
+		t.Fatalf("StreamChatWithTools() result = nil, want partial content")
+	}
+	if len(chunks) != 1 || result.Content != "部分回答" {
+		t.Errorf("partial content = %q, chunks %q", result.Content, chunks)
+	}
+}
+
+func TestAnthropicStreamUnexpectedEOF(t *testing.T) {
+	server := newFakeAnthropic(t, []string{
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"半截"}}`,
+	}, nil)
+
+	_, _, err := streamAnthropic(t, server.URL, []Message{{Role: "user", Content: "hi"}}, nil)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("StreamChatWithTools() error = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestAnthropicHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, `{"type":"error","error":{"type":"rate_limit_error","message":"Too many requests"}}`)
+	}))
+	defer server.Close()
+
+	_, _, err := streamAnthropic(t, server.URL, []Message{{Role: "user", Content: "hi"}}, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("StreamChatWithTools() error = %v, want APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Type != "rate_limit_error" {
+		t.Errorf("api error = %+v", apiErr)
+	}
+}
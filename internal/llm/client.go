@@ -18,19 +18,31 @@ type MCPServer interface {
 	ListTools(ctx context.Context) (*mcp.ListToolsResult, error)
 	ListEnabledTools(ctx context.Context) (*mcp.ListToolsResult, error)
 	CallTool(ctx context.Context, name string, arguments map[string]any) (*mcp.CallToolResult, error)
+	// ToolOrigin 返回工具的来源服务器和原始工具名, 用于 MCP 调用日志
+	ToolOrigin(name string) (server, tool string, ok bool)
 }
 
 // Client LLM 客户端
 type Client struct {
 	config    *Config
+	fallbacks []*Config // 备用模型, 首选模型不可用时按顺序尝试
 	mcpServer MCPServer
+
+	toolOptions ToolOptions // 工具调用的并发与超时, 未设置时使用默认值
 }
 
 // Config LLM 配置
 type Config struct {
-	Model   string `mapstructure:"model"`
-	APIKey  string `mapstructure:"api_key"`
-	BaseURL string `mapstructure:"base_url"`
+	Name     string `mapstructure:"name"` // 配置名称, 用于日志和记录实际回答的模型
+	Provider string `mapstructure:"provider"`
+	Model    string `mapstructure:"model"`
+	APIKey   string `mapstructure:"api_key"`
+	BaseURL  string `mapstructure:"base_url"`
+
+	InputPrice  float64 `mapstructure:"input_price"`  // 每百万输入 token 价格, 用于计算费用
+	OutputPrice float64 `mapstructure:"output_price"` // 每百万输出 token 价格
+
+	ContextTokens int `mapstructure:"context_tokens"` // 上下文 token 预算, 为 0 时使用 DefaultContextTokens
 }
 
 // NewClient 创建 LLM 客户端
@@ -48,6 +60,7 @@ type Message struct {
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // 用于 assistant 角色的工具调用
 	ToolCallID string     `json:"tool_call_id,omitempty"` // 用于 tool 角色的响应
 	Name       string     `json:"name,omitempty"`         // 用于 tool 角色的函数名
+	ChatLogID  uint       `json:"-"`                      // 来自已保存对话日志的消息ID, 用于关联会话摘要
 }
 
 // ToolCall 工具调用
@@ -102,6 +115,7 @@ type ChatResponse struct {
 		} `json:"delta"`
 		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
+	Usage Usage `json:"usage"`
 }
 
 // Chat 与 LLM 对话 (非流式)
@@ -253,37 +267,30 @@ func (c *Client) executeToolCall(ctx context.Context, toolCall ToolCall) (string
 	// 记录调用开始时间
 	startTime := time.Now()
 
-	// 调用 MCP 工具
-	result, err := c.mcpServer.CallTool(ctx, toolCall.Function.Name, params)
+	// 调用 MCP 工具, 本次调用由此处统一记录日志
+	result, err := c.mcpServer.CallTool(service.WithAudited(ctx), toolCall.Function.Name, params)
 	latency := time.Since(startTime).Milliseconds()
 
-	// 解析 server_name 和 tool_name
-	// 外部 MCP 工具格式: "prefix_toolname"，例如 "aliyun-ack_list_clusters"
-	// 内置工具没有前缀，例如 "search_ecs_by_ip"
-	serverName := "zenops" // 默认为内置工具
-	toolName := toolCall.Function.Name
-
-	// 尝试从工具名中提取前缀（外部 MCP 工具）
-	if idx := strings.Index(toolCall.Function.Name, "_"); idx > 0 {
-		// 可能是外部工具，检查前缀是否包含连字符（如 "aliyun-ack"）
-		prefix := toolCall.Function.Name[:idx]
-		if strings.Contains(prefix, "-") {
-			serverName = prefix
-			toolName = toolCall.Function.Name[idx+1:]
-		}
+	// 从工具注册表获取来源服务器和原始工具名, 外部 MCP 工具带有前缀
+	serverName, toolName, ok := c.mcpServer.ToolOrigin(toolCall.Function.Name)
+	if !ok {
+		serverName, toolName = "unknown", toolCall.Function.Name
 	}
 
-	// 记录 MCP 调用日志
+	// 记录 MCP 调用日志, 发起人、会话和消息记录由各接入渠道写入上下文
+	identity := service.IdentityFromContext(ctx, service.Caller{Username: "llm", Source: "llm"})
 	mcpLogService := service.NewMCPLogService()
 	logParams := &service.MCPLogParams{
-		ServerName: serverName,
-		ToolName:   toolName,
-		Username:   "llm", // LLM 自动调用，用户信息需要从上下文传递
-		Source:     "llm",
-		Request:    params,
-		Response:   result,
-		Latency:    latency,
-		Success:    err == nil,
+		ServerName:     serverName,
+		ToolName:       toolName,
+		Username:       identity.Username,
+		Source:         identity.Source,
+		ChatLogID:      identity.ChatLogID,
+		ConversationID: identity.ConversationID,
+		Request:        params,
+		Response:       result,
+		Latency:        latency,
+		Success:        err == nil,
 	}
 	if err != nil {
 		logParams.ErrorMessage = err.Error()
@@ -364,21 +371,32 @@ func (c *Client) convertMCPSchemaToOpenAI(schema any) map[string]any {
 
 // buildSystemPrompt 构建系统提示词
 func (c *Client) buildSystemPrompt() string {
-	var builder strings.Builder
-
-	builder.WriteString("你是一个智能运维助手,可以帮助用户查询和管理云资源、CI/CD 任务等。\n\n")
-	builder.WriteString("你可以使用以下工具来获取信息:\n")
+	var tools []Tool
 
 	// 获取可用的工具列表
 	if c.mcpServer != nil {
-		tools, err := c.mcpServer.ListTools(context.Background())
+		toolList, err := c.mcpServer.ListTools(context.Background())
 		if err == nil {
-			for _, tool := range tools.Tools {
-				builder.WriteString(fmt.Sprintf("- %s: %s\n", tool.Name, tool.Description))
+			for _, tool := range toolList.Tools {
+				tools = append(tools, Tool{
+					Type:     "function",
+					Function: Function{Name: tool.Name, Description: tool.Description},
+				})
 			}
 		}
 	}
 
+	return buildSystemPromptWithTools(tools)
+}
+
+// buildSystemPromptWithTools 构建列出指定工具的系统提示词
+func buildSystemPromptWithTools(tools []Tool) string {
+	var builder strings.Builder
+
+	builder.WriteString("你是一个智能运维助手,可以帮助用户查询和管理云资源、CI/CD 任务等。\n\n")
+	builder.WriteString("你可以使用以下工具来获取信息:\n")
+	builder.WriteString(formatToolList(tools))
+
 	builder.WriteString("\n当用户询问相关信息时,请主动调用相应的工具来获取准确的数据。")
 	builder.WriteString("回复时请简洁明了,使用 Markdown 格式化输出。")
 
@@ -0,0 +1,296 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
+)
+
+const (
+	// DefaultContextTokens 未配置上下文预算时使用的默认值
+	DefaultContextTokens = 32000
+
+	// elidedToolResultRunes 较早轮次的工具结果超出该长度时只保留开头部分
+	elidedToolResultRunes = 500
+	// transcriptMessageRunes 生成摘要时单条消息保留的最大长度
+	transcriptMessageRunes = 2000
+)
+
+const summaryPrompt = `你是运维对话的摘要助手。请将下面的对话压缩为一段简洁的摘要, 供后续对话继续使用。
+要求:
+1. 保留用户的目标、已确认的事实和结论 (如实例 ID、IP、集群、Job 名称、错误信息等关键标识)
+2. 保留尚未解决的问题和下一步计划
+3. 省略寒暄和重复内容, 不要编造对话中没有的信息
+4. 直接输出摘要正文, 不超过 800 字`
+
+// EstimateTokens 粗略估算文本的 token 数
+// ASCII 字符约 4 个一个 token, 中文等非 ASCII 字符按每字一个 token 计算
+func EstimateTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
+
+// estimateMessages 估算消息列表的 token 数, 每条消息额外计入少量格式开销
+func estimateMessages(messages []Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += 4 + EstimateTokens(messageText(msg.Content))
+		for _, toolCall := range msg.ToolCalls {
+			total += EstimateTokens(toolCall.Function.Name) + EstimateTokens(toolCall.Function.Arguments)
+		}
+	}
+	return total
+}
+
+// messageText 返回消息内容的文本形式
+func messageText(content any) string {
+	switch v := content.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+}
+
+// redactMessages 返回按脱敏规则处理后的消息副本, 只处理文本内容
+func redactMessages(messages []Message) []Message {
+	redacted := slices.Clone(messages)
+	for i := range redacted {
+		if text, ok := redacted[i].Content.(string); ok {
+			redacted[i].Content, _ = service.Redact(model.RedactionStageLLM, text)
+		}
+	}
+	return redacted
+}
+
+// contextBudget 返回首选模型的上下文 token 预算
+func (c *Client) contextBudget() int {
+	if c.config.ContextTokens > 0 {
+		return c.config.ContextTokens
+	}
+	return DefaultContextTokens
+}
+
+// elideToolResults 将 keepFrom 之前的大段工具结果替换为开头摘录, 返回被省略的条数
+// 较早轮次的工具结果模型通常已经消化, 保留开头足以让模型知道调用过什么
+func elideToolResults(messages []Message, keepFrom int) int {
+	elided := 0
+	for i := 0; i < keepFrom && i < len(messages); i++ {
+		if messages[i].Role != "tool" {
+			continue
+		}
+		text := messageText(messages[i].Content)
+		runes := []rune(text)
+		if len(runes) <= elidedToolResultRunes {
+			continue
+		}
+		messages[i].Content = fmt.Sprintf("%s\n...[已省略 %d 字符]", string(runes[:elidedToolResultRunes]), len(runes)-elidedToolResultRunes)
+		elided++
+	}
+	return elided
+}
+
+// splitRecent 返回需要保留的近期消息的起始下标
+// 只在用户消息处切分以保持对话轮次完整, 近期消息超出 keepTokens 时至少保留最后一轮
+func splitRecent(messages []Message, keepTokens int) int {
+	split := -1
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != "user" {
+			continue
+		}
+		if split >= 0 && estimateMessages(messages[i:]) > keepTokens {
+			break
+		}
+		split = i
+	}
+	if split < 0 {
+		return 0
+	}
+	return split
+}
+
+// HistoryFromLogs 将已保存的对话日志转换为消息历史, 消息携带对话日志ID用于关联会话摘要
+func HistoryFromLogs(logs []model.ChatLog) []Message {
+	messages := make([]Message, 0, len(logs))
+	for _, log := range logs {
+		if log.Content == "" {
+			continue
+		}
+		role := "user"
+		if log.ChatType == 2 {
+			role = "assistant"
+		}
+		messages = append(messages, Message{
+			Role:      role,
+			Content:   log.Content,
+			ChatLogID: log.ID,
+		})
+	}
+	return messages
+}
+
+// ConversationMessages 返回用户消息所在会话截至该消息的消息历史
+// 用户消息未保存或加载失败时只发送当前消息
+func ConversationMessages(userLog *model.ChatLog, userMessage string) []Message {
+	current := []Message{{Role: "user", Content: userMessage}}
+	if userLog == nil {
+		return current
+	}
+	logs, err := service.NewChatLogService().GetConversationContext(userLog.ID)
+	if err != nil {
+		logx.Warn("Failed to load conversation history, chat log %d, error %v", userLog.ID, err)
+		return current
+	}
+	if history := HistoryFromLogs(logs); len(history) > 0 {
+		return history
+	}
+	return current
+}
+
+// compactHistory 构建不超出上下文预算的消息历史
+// 会话已有摘要时用摘要替换已覆盖的对话日志; 仍超出预算时先省略较早轮次的大段工具结果,
+// 再将较早的对话交给模型压缩为摘要, 摘要按已覆盖的最后一条对话日志ID保存到会话, 返回摘要 + 近期对话
+// 不带对话日志ID的消息 (如客户端自行维护的历史) 只在本次请求内压缩, 不保存摘要
+func (c *Client) compactHistory(ctx context.Context, systemPrompt string, history []Message) []Message {
+	budget := c.contextBudget()
+
+	var summary string
+	var summarizedUntil uint
+	var conversationService *service.ConversationService
+	conversationID := service.ConversationFromContext(ctx)
+	if conversationID > 0 {
+		conversationService = service.NewConversationService()
+		conversation, err := conversationService.GetConversation(conversationID)
+		if err != nil {
+			logx.Warn("Failed to load conversation summary, conversation %d, error %v", conversationID, err)
+		} else if conversation != nil && conversation.Summary != "" {
+			summary, summarizedUntil = conversation.Summary, conversation.SummarizedUntil
+		}
+	}
+
+	// 去掉摘要已覆盖的对话日志, 同时复制一份, 避免省略工具结果时修改调用方的消息
+	recent := make([]Message, 0, len(history))
+	for _, msg := range history {
+		if msg.ChatLogID > 0 && msg.ChatLogID <= summarizedUntil {
+			continue
+		}
+		recent = append(recent, msg)
+	}
+	if len(recent) == len(history) {
+		// 本次历史中没有摘要覆盖的消息, 摘要与历史无关
+		summary, summarizedUntil = "", 0
+	}
+	// 复制一份, 避免省略工具结果时修改调用方的消息
+	fixed := EstimateTokens(systemPrompt) + EstimateTokens(summary)
+
+	if fixed+estimateMessages(recent) > budget {
+		if elided := elideToolResults(recent, splitRecent(recent, 0)); elided > 0 {
+			logx.Info("Context over budget, elided %d earlier tool results", elided)
+		}
+	}
+
+	if total := fixed + estimateMessages(recent); total > budget {
+		if split := splitRecent(recent, budget/2); split > 0 {
+			logx.Info("Context over budget, summarizing %d earlier messages, estimated %d tokens, budget %d",
+				split, total, budget)
+			newSummary, err := c.summarize(ctx, summary, recent[:split])
+			if err != nil {
+				// 摘要失败时直接丢弃较早的对话, 避免超出模型上下文窗口
+				logx.Warn("Failed to summarize conversation, dropping %d earlier messages, error %v", split, err)
+			} else {
+				summary = newSummary
+				if until := lastChatLogID(recent[:split]); conversationService != nil && until > summarizedUntil {
+					summarizedUntil = until
+					if err := conversationService.UpdateSummary(conversationID, summary, summarizedUntil); err != nil {
+						logx.Warn("Failed to save conversation summary, conversation %d, error %v", conversationID, err)
+					}
+				}
+			}
+			recent = recent[split:]
+		}
+	}
+
+	if summary == "" {
+		return recent
+	}
+	messages := make([]Message, 0, len(recent)+1)
+	messages = append(messages, Message{
+		Role:    "system",
+		Content: "以下是本次会话较早对话的摘要, 请结合摘要理解后续对话:\n\n" + summary,
+	})
+	return append(messages, recent...)
+}
+
+// lastChatLogID 返回消息中最大的对话日志ID, 消息都不来自对话日志时返回 0
+func lastChatLogID(messages []Message) uint {
+	var last uint
+	for _, msg := range messages {
+		last = max(last, msg.ChatLogID)
+	}
+	return last
+}
+
+// summarize 将已有摘要与较早的对话合并压缩为新的摘要
+func (c *Client) summarize(ctx context.Context, summary string, messages []Message) (string, error) {
+	var transcript strings.Builder
+	if summary != "" {
+		transcript.WriteString("【已有摘要】\n")
+		transcript.WriteString(summary)
+		transcript.WriteString("\n\n【后续对话】\n")
+	}
+	for _, msg := range messages {
+		text := messageText(msg.Content)
+		if runes := []rune(text); len(runes) > transcriptMessageRunes {
+			text = string(runes[:transcriptMessageRunes]) + "...[已截断]"
+		}
+
+		switch msg.Role {
+		case "user":
+			fmt.Fprintf(&transcript, "用户: %s\n", text)
+		case "assistant":
+			for _, toolCall := range msg.ToolCalls {
+				fmt.Fprintf(&transcript, "助手调用工具 %s: %s\n", toolCall.Function.Name, toolCall.Function.Arguments)
+			}
+			if text != "" {
+				fmt.Fprintf(&transcript, "助手: %s\n", text)
+			}
+		case "tool":
+			fmt.Fprintf(&transcript, "工具 %s 返回: %s\n", msg.Name, text)
+		default:
+			fmt.Fprintf(&transcript, "%s: %s\n", msg.Role, text)
+		}
+	}
+
+	content, err := c.Complete(ctx, []Message{
+		{Role: "system", Content: summaryPrompt},
+		{Role: "user", Content: transcript.String()},
+	})
+	if err != nil {
+		return "", err
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", fmt.Errorf("empty summary")
+	}
+	return content, nil
+}
@@ -0,0 +1,136 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "zenops-llm-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("ZENOPS_DB_PATH", filepath.Join(dir, "zenops.db"))
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// createConversation 创建会话并按顺序保存一问一答, 返回会话和每条对话日志
+func createConversation(t *testing.T, turns ...string) (*model.Conversation, []*model.ChatLog) {
+	t.Helper()
+	conversation, err := service.NewConversationService().CreateConversation("alice", "test")
+	if err != nil {
+		t.Fatalf("CreateConversation() error = %v", err)
+	}
+
+	chatLogService := service.NewChatLogService()
+	var logs []*model.ChatLog
+	for i, content := range turns {
+		var log *model.ChatLog
+		if i%2 == 0 {
+			log, err = chatLogService.CreateUserMessageWithConversation("alice", "API", content, conversation.ID)
+		} else {
+			log, err = chatLogService.CreateAIMessageWithConversation("alice", "API", content, logs[i-1].ID, conversation.ID)
+		}
+		if err != nil {
+			t.Fatalf("create chat log error = %v", err)
+		}
+		logs = append(logs, log)
+	}
+	return conversation, logs
+}
+
+func TestConversationMessages(t *testing.T) {
+	_, logs := createConversation(t, "q1", "a1", "q2", "a2", "q3")
+
+	// 会话中截至第二个问题的消息, 不包含之后的回答
+	messages := ConversationMessages(logs[2], "q2")
+	if len(messages) != 3 {
+		t.Fatalf("ConversationMessages() returned %d messages, want 3", len(messages))
+	}
+	wantRoles := []string{"user", "assistant", "user"}
+	for i, msg := range messages {
+		if msg.Role != wantRoles[i] || msg.ChatLogID != logs[i].ID {
+			t.Errorf("message %d = %s/%d, want %s/%d", i, msg.Role, msg.ChatLogID, wantRoles[i], logs[i].ID)
+		}
+	}
+
+	// 未保存用户消息时只发送当前消息
+	messages = ConversationMessages(nil, "hello")
+	if len(messages) != 1 || messages[0].Content != "hello" || messages[0].ChatLogID != 0 {
+		t.Fatalf("ConversationMessages(nil) = %+v, want the current message only", messages)
+	}
+}
+
+func TestCompactHistoryAppliesStoredSummary(t *testing.T) {
+	conversation, logs := createConversation(t, "q1", "a1", "q2", "a2", "q3")
+	if err := service.NewConversationService().UpdateSummary(conversation.ID, "earlier summary", logs[1].ID); err != nil {
+		t.Fatalf("UpdateSummary() error = %v", err)
+	}
+
+	c := &Client{config: &Config{Model: "test", ContextTokens: 100000}}
+	ctx := service.WithConversation(context.Background(), conversation.ID)
+
+	history := ConversationMessages(logs[4], "q3")
+	messages := c.compactHistory(ctx, "system", history)
+	if len(messages) != 4 {
+		t.Fatalf("compactHistory() returned %d messages, want summary + 3 recent", len(messages))
+	}
+	if !strings.Contains(messageText(messages[0].Content), "earlier summary") {
+		t.Errorf("first message = %v, want the stored summary", messages[0].Content)
+	}
+	if messages[1].ChatLogID != logs[2].ID {
+		t.Errorf("first recent message chat log = %d, want %d", messages[1].ChatLogID, logs[2].ID)
+	}
+
+	// 客户端自行维护的历史不带对话日志ID, 无法与摘要对应, 不使用摘要
+	clientHistory := []Message{{Role: "user", Content: "q1"}, {Role: "assistant", Content: "a1"}, {Role: "user", Content: "q2"}}
+	messages = c.compactHistory(ctx, "system", clientHistory)
+	if len(messages) != len(clientHistory) {
+		t.Fatalf("compactHistory() on client history returned %d messages, want %d", len(messages), len(clientHistory))
+	}
+}
+
+func TestCompactHistorySavesSummarizedChatLogID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":      "chatcmpl-test",
+			"object":  "chat.completion",
+			"choices": []map[string]any{{"index": 0, "message": map[string]any{"role": "assistant", "content": "new summary"}, "finish_reason": "stop"}},
+		})
+	}))
+	defer server.Close()
+
+	long := strings.Repeat("x", 4000)
+	conversation, logs := createConversation(t, "q1 "+long, "a1 "+long, "q2 "+long, "a2 "+long, "q3")
+
+	c := &Client{config: &Config{Model: "test", BaseURL: server.URL, APIKey: "test", ContextTokens: 2000}}
+	ctx := service.WithConversation(context.Background(), conversation.ID)
+
+	messages := c.compactHistory(ctx, "system", ConversationMessages(logs[4], "q3"))
+	if len(messages) == 0 || !strings.Contains(messageText(messages[0].Content), "new summary") {
+		t.Fatalf("compactHistory() = %+v, want the new summary first", messages)
+	}
+
+	saved, err := service.NewConversationService().GetConversation(conversation.ID)
+	if err != nil {
+		t.Fatalf("GetConversation() error = %v", err)
+	}
+	if saved.Summary != "new summary" {
+		t.Errorf("saved summary = %q, want %q", saved.Summary, "new summary")
+	}
+	if saved.SummarizedUntil != logs[3].ID {
+		t.Errorf("summarized until = %d, want chat log %d", saved.SummarizedUntil, logs[3].ID)
+	}
+}
@@ -0,0 +1,175 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// ErrNotConfigured 没有可用的 LLM 配置
+var ErrNotConfigured = errors.New("llm is not configured")
+
+// APIError 模型接口返回的错误
+type APIError struct {
+	StatusCode int    // HTTP 状态码, 流式响应中途出错时为 0
+	Type       string // 厂商返回的错误类型, 如 rate_limit_error、overloaded_error
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode > 0 {
+		return fmt.Sprintf("api error: %d %s, %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Type, e.Message)
+	}
+	return fmt.Sprintf("api error: %s: %s", e.Type, e.Message)
+}
+
+// IsRetryable 判断错误是否应切换到备用模型重试
+// 服务端错误 (5xx)、限流 (429) 和超时可重试, 参数错误、鉴权失败等重试无意义
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode > 0 {
+			return isRetryableStatus(apiErr.StatusCode)
+		}
+		switch apiErr.Type {
+		case "rate_limit_error", "overloaded_error", "api_error", "timeout_error":
+			return true
+		}
+		return false
+	}
+
+	var openaiErr *openai.APIError
+	if errors.As(err, &openaiErr) {
+		return isRetryableStatus(openaiErr.HTTPStatusCode)
+	}
+	var requestErr *openai.RequestError
+	if errors.As(err, &requestErr) {
+		return isRetryableStatus(requestErr.HTTPStatusCode)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isRetryableStatus 限流和服务端错误可重试
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// Answer 实际回答本次对话的模型
+type Answer struct {
+	Name  string // LLM 配置名称
+	Model string // 模型名称
+	Usage Usage  // 本次对话所有模型调用的累计用量
+
+	FinishReason string     // 结束原因, 取值见 FinishReasonStop 等常量
+	ToolCalls    []ToolCall // 模型调用了客户端工具时, 需要返回给客户端执行的工具调用
+}
+
+type answerKey struct{}
+
+// WithAnswer 在上下文中记录实际回答的模型
+// 响应通道关闭后即可从返回的 Answer 中读取, 发生模型切换时为最后回答的模型
+func WithAnswer(ctx context.Context) (context.Context, *Answer) {
+	answer := &Answer{}
+	return context.WithValue(ctx, answerKey{}, answer), answer
+}
+
+// recordAnswer 将回答的模型写入上下文中的 Answer
+func recordAnswer(ctx context.Context, config *Config) {
+	if answer, ok := ctx.Value(answerKey{}).(*Answer); ok {
+		answer.Name = config.Name
+		answer.Model = config.Model
+	}
+}
+
+// chain 返回按顺序尝试的模型配置, 第一个为首选模型
+func (c *Client) chain() []*Config {
+	return append([]*Config{c.config}, c.fallbacks...)
+}
+
+// nextProvider 在首选模型失败且错误可重试时切换到下一个备用模型
+// 返回切换后的模型序号, 无法切换时返回 -1
+func (c *Client) nextProvider(ctx context.Context, chain []*Config, current int, err error) int {
+	if ctx.Err() != nil || current+1 >= len(chain) || !IsRetryable(err) {
+		return -1
+	}
+
+	logx.Warn("LLM call failed, falling back, from %s (%s) to %s (%s), error %v",
+		chain[current].Name, chain[current].Model, chain[current+1].Name, chain[current+1].Model, err)
+	return current + 1
+}
+
+// NewClientWithFallbacks 创建带备用模型的 LLM 客户端
+// configs 第一个为首选模型, 其余按顺序在前一个模型不可用时依次尝试
+func NewClientWithFallbacks(configs []*Config, mcpServer MCPServer) *Client {
+	client := NewClient(configs[0], mcpServer)
+	client.fallbacks = configs[1:]
+	return client
+}
+
+// LoadClient 从数据库加载 LLM 客户端, 其余启用的模型按顺序作为备用
+// name 为 LLM 配置名称 (兼容模型名称), 为空时使用第一个启用的模型;
+// 数据库中没有启用的模型时回退到配置文件
+func LoadClient(name string, fallback config.LLMConfig, mcpServer MCPServer) (*Client, error) {
+	llmConfigs, err := service.NewConfigService().GetLLMChain(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(llmConfigs) > 0 {
+		configs := make([]*Config, 0, len(llmConfigs))
+		for i := range llmConfigs {
+			configs = append(configs, configFromModel(&llmConfigs[i]))
+		}
+		client := NewClientWithFallbacks(configs, mcpServer)
+		client.SetToolOptions(LoadToolOptions(fallback))
+		return client, nil
+	}
+
+	if fallback.Enabled {
+		client := NewClient(&Config{
+			Name:     "config.yaml",
+			Provider: fallback.Provider,
+			Model:    fallback.Model,
+			APIKey:   fallback.APIKey,
+			BaseURL:  fallback.BaseURL,
+
+			ContextTokens: fallback.ContextTokens,
+		}, mcpServer)
+		client.SetToolOptions(LoadToolOptions(fallback))
+		return client, nil
+	}
+
+	return nil, ErrNotConfigured
+}
+
+// configFromModel 将数据库中的 LLM 配置转换为客户端配置
+func configFromModel(llmConfig *model.LLMConfig) *Config {
+	return &Config{
+		Name:        llmConfig.Name,
+		Provider:    llmConfig.Provider,
+		Model:       llmConfig.Model,
+		APIKey:      llmConfig.APIKey,
+		BaseURL:     llmConfig.BaseURL,
+		InputPrice:  llmConfig.InputPrice,
+		OutputPrice: llmConfig.OutputPrice,
+
+		ContextTokens: llmConfig.ContextTokens,
+	}
+}
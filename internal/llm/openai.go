@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"sort"
 	"time"
 
@@ -34,6 +35,20 @@ func NewOpenAIClient(config *Config) *OpenAIClient {
 		logx.Debug("OpenAI client BaseURL: %s", config.BaseURL)
 	}
 
+	clientConfig.HTTPClient = newHTTPClient()
+
+	client := openai.NewClientWithConfig(clientConfig)
+
+	logx.Info("OpenAI client initialized, model %s", config.Model)
+
+	return &OpenAIClient{
+		config: config,
+		client: client,
+	}
+}
+
+// newHTTPClient 创建调用模型接口使用的 HTTP 客户端
+func newHTTPClient() *http.Client {
 	// 配置 HTTP 客户端 - 参考 chatgpt-dingtalk 的实现
 	// 关键:禁用 HTTP/2,强制使用 HTTP/1.1 以避免 INTERNAL_ERROR
 	transport := &http.Transport{
@@ -44,19 +59,10 @@ func NewOpenAIClient(config *Config) *OpenAIClient {
 		TLSNextProto: make(map[string]func(authority string, c *tls.Conn) http.RoundTripper),
 	}
 
-	clientConfig.HTTPClient = &http.Client{
+	return &http.Client{
 		Transport: transport,
 		Timeout:   600 * time.Second,
 	}
-
-	client := openai.NewClientWithConfig(clientConfig)
-
-	logx.Info("OpenAI client initialized, model %s", config.Model)
-
-	return &OpenAIClient{
-		config: config,
-		client: client,
-	}
 }
 
 // convertContent 转换 any 内容为字符串
@@ -344,9 +350,49 @@ func (c *OpenAIClient) Chat(ctx context.Context, req *ChatRequest) (*ChatRespons
 				FinishReason: string(resp.Choices[0].FinishReason),
 			},
 		},
+		Usage: Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+		},
 	}, nil
 }
 
+// Complete 实现 ChatProvider 接口, 不带工具的单轮对话
+func (c *OpenAIClient) Complete(ctx context.Context, messages []Message) (string, Usage, error) {
+	resp, err := c.Chat(ctx, &ChatRequest{
+		Model:    c.config.Model,
+		Messages: messages,
+	})
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	return resp.Choices[0].Message.Content, resp.Usage, nil
+}
+
+// Complete 不带工具的单轮对话, 返回完整回复
+// 用于日志分析等由程序组装好上下文、只需要模型总结的场景
+// 首选模型不可用时依次尝试备用模型
+func (c *Client) Complete(ctx context.Context, messages []Message) (string, error) {
+	if err := c.checkQuota(ctx); err != nil {
+		return "", err
+	}
+
+	chain := c.chain()
+	for current := 0; ; {
+		content, usage, err := NewProvider(chain[current]).Complete(ctx, messages)
+		if err == nil {
+			recordAnswer(ctx, chain[current])
+			c.recordUsage(ctx, chain[current], usage)
+			return content, nil
+		}
+
+		if current = c.nextProvider(ctx, chain, current, err); current < 0 {
+			return "", fmt.Errorf("failed to call LLM: %w", err)
+		}
+	}
+}
+
 // ChatWithToolsAndStream 支持工具调用的流式对话(Client 方法)
 func (c *Client) ChatWithToolsAndStream(ctx context.Context, userMessage string) (<-chan string, error) {
 	// 为了向后兼容，将单个消息转换为消息列表
@@ -366,18 +412,14 @@ func (c *Client) ChatWithToolsAndStreamMessages(ctx context.Context, historyMess
 	go func() {
 		defer close(responseCh)
 
-		// 构建完整的消息历史，在最前面添加系统提示
-		messages := []Message{
-			{
-				Role:    "system",
-				Content: c.buildSystemPrompt(),
-			},
+		// 调用模型前检查用户配额
+		if err := c.checkQuota(ctx); err != nil {
+			responseCh <- quotaMessage(err)
+			return
 		}
-		// 添加历史消息
-		messages = append(messages, historyMessages...)
 
-		// 创建 OpenAI 客户端
-		openaiClient := NewOpenAIClient(c.config)
+		// 用户消息和客户端回传的工具结果同样按脱敏规则处理后再发送给模型
+		historyMessages = redactMessages(historyMessages)
 
 		// 获取工具列表
 		tools, err := c.getMCPTools(ctx)
@@ -386,66 +428,139 @@ func (c *Client) ChatWithToolsAndStreamMessages(ctx context.Context, historyMess
 			tools = nil
 		}
 
+		// 按用户、群聊和平台匹配提示词配置, 配置限定了工具时只保留允许的工具
+		profile := resolvePromptProfile(ctx)
+		tools = filterAllowedTools(tools, profile)
+		allowed := allowedToolSet(profile)
+
+		// 工具较多时只发送与用户消息相关的工具, 模型可通过 search_tools 检索更多
+		selection := c.selectTools(tools, toolQuery(historyMessages))
+		if selection != nil {
+			tools = selection.tools()
+		}
+
+		// 构建完整的消息历史，在最前面添加系统提示
+		systemPrompt := renderSystemPrompt(ctx, profile, tools)
+		messages := []Message{
+			{
+				Role:    "system",
+				Content: systemPrompt,
+			},
+		}
+		// 添加历史消息, 超出上下文预算时较早的对话压缩为摘要
+		messages = append(messages, c.compactHistory(ctx, systemPrompt, historyMessages)...)
+
+		// 客户端通过 OpenAI 兼容接口传入的工具与 ZenOps 工具一并发送给模型
+		options := optionsFromContext(ctx)
+		if len(options.Tools) > 0 {
+			logx.Info("Merged %d client tools into LLM tools", len(options.Tools))
+		}
+
+		// 根据配置的厂商创建客户端, 首选模型不可用时切换到备用模型
+		chain := c.chain()
+		current := 0
+		chatProvider := NewProvider(chain[current])
+
 		maxIterations := 10
+		budget := c.contextBudget()
+		roundStart := len(messages)
 		for i := 0; i < maxIterations; i++ {
+			// 多轮工具调用后超出预算时, 省略之前轮次的大段工具结果
+			if estimateMessages(messages) > budget {
+				if elided := elideToolResults(messages, roundStart); elided > 0 {
+					logx.Info("Context over budget in tool loop, elided %d earlier tool results", elided)
+				}
+			}
+
+			// search_tools 检索到的工具在下一轮加入工具列表
+			if selection != nil {
+				tools = selection.tools()
+			}
+			roundTools := append(slices.Clone(tools), options.Tools...)
+
 			// 使用流式 API (支持工具调用)
-			result, hasToolCalls, err := c.streamChatWithTools(ctx, openaiClient, messages, tools, responseCh)
+			result, err := chatProvider.StreamChatWithTools(ctx, messages, roundTools, responseCh)
 			if err != nil {
+				// 已推送部分内容时无法撤回, 不再切换模型
+				if result == nil || result.Content == "" {
+					if next := c.nextProvider(ctx, chain, current, err); next > 0 {
+						current = next
+						chatProvider = NewProvider(chain[current])
+						i--
+						continue
+					}
+				}
 				responseCh <- fmt.Sprintf("❌ LLM 调用失败: %v", err)
 				return
 			}
+			recordAnswer(ctx, chain[current])
+			// 工具调用循环中的每次模型调用都单独记录用量
+			c.recordUsage(ctx, chain[current], result.Usage)
 
 			// 如果没有工具调用,说明对话结束
-			if !hasToolCalls {
+			if len(result.ToolCalls) == 0 {
+				recordFinish(ctx, result.FinishReason, nil)
 				return
 			}
 
+			// 模型调用了客户端工具时结束对话, 由客户端执行后携带结果再次请求
+			if clientCalls := clientToolCalls(result.ToolCalls, options); len(clientCalls) > 0 {
+				if dropped := len(result.ToolCalls) - len(clientCalls); dropped > 0 {
+					logx.Warn("Returning %d client tool calls, dropped %d ZenOps tool calls in the same round", len(clientCalls), dropped)
+				}
+				recordFinish(ctx, FinishReasonToolCalls, clientCalls)
+				return
+			}
+
+			// 客户端指定必须调用的工具只作用于第一轮, 否则模型无法在拿到工具结果后作答
+			if options.ToolChoice != nil && !options.toolChoiceNone() {
+				options.ToolChoice = nil
+				ctx = WithOptions(ctx, options)
+			}
+
 			// 有工具调用,添加 assistant 消息到历史
+			roundStart = len(messages)
 			messages = append(messages, Message{
 				Role:      "assistant",
 				Content:   result.Content,
 				ToolCalls: result.ToolCalls,
 			})
 
-			// 执行所有工具调用
-			for _, toolCall := range result.ToolCalls {
-				responseCh <- fmt.Sprintf("\n> 🔧 调用工具: **%s**\n", toolCall.Function.Name)
-
-				// 执行工具调用
-				toolResult, err := c.executeToolCall(ctx, toolCall)
-				if err != nil {
-					responseCh <- fmt.Sprintf("❌ 工具调用失败: %v\n\n", err)
-					toolResult = fmt.Sprintf("Error: %v", err)
-				}
-
-				// 添加工具结果到历史
-				messages = append(messages, Message{
-					Role:       "tool",
-					Content:    toolResult,
-					ToolCallID: toolCall.ID,
-					Name:       toolCall.Function.Name,
-				})
-
-				responseCh <- "✅ 工具执行完成\n\n"
+			// 并发执行本轮所有工具调用, 结果按原始顺序添加到历史
+			messages = append(messages, c.executeToolCalls(ctx, result.ToolCalls, selection, allowed, responseCh)...)
+			if ctx.Err() != nil {
+				logx.Warn("Request context ended during tool calls: %v", ctx.Err())
+				return
 			}
 			// 继续循环,让 LLM 处理工具结果
 		}
 
 		responseCh <- "\n\n⚠️ 达到最大工具调用次数限制"
+		recordFinish(ctx, FinishReasonLength, nil)
 	}()
 
 	return responseCh, nil
 }
 
-// streamChatWithTools 使用流式 API 进行对话(支持工具调用)
-// 返回: (累积的消息内容, 是否有工具调用, 错误)
-func (c *Client) streamChatWithTools(
+// clientToolCalls 返回工具调用中由客户端提供的部分
+func clientToolCalls(toolCalls []ToolCall, options ChatOptions) []ToolCall {
+	var clientCalls []ToolCall
+	for _, toolCall := range toolCalls {
+		if options.isClientTool(toolCall.Function.Name) {
+			clientCalls = append(clientCalls, toolCall)
+		}
+	}
+	return clientCalls
+}
+
+// StreamChatWithTools 使用流式 API 进行对话(支持工具调用)
+// 返回: (累积的消息内容与工具调用, 错误), 流式响应中途出错时同时返回已累积的内容
+func (c *OpenAIClient) StreamChatWithTools(
 	ctx context.Context,
-	openaiClient *OpenAIClient,
 	messages []Message,
 	tools []Tool,
 	responseCh chan<- string,
-) (*StreamResult, bool, error) {
+) (*StreamResult, error) {
 	// 构建 OpenAI 请求
 	openaiMessages := make([]openai.ChatCompletionMessage, 0, len(messages))
 	for _, msg := range messages {
@@ -502,6 +617,8 @@ func (c *Client) streamChatWithTools(
 		Model:    c.config.Model,
 		Messages: openaiMessages,
 		Stream:   true,
+		// 流式响应默认不返回用量, 开启后在最后一个数据块中返回
+		StreamOptions: &openai.StreamOptions{IncludeUsage: true},
 	}
 
 	if len(openaiTools) > 0 {
@@ -511,10 +628,25 @@ func (c *Client) streamChatWithTools(
 		openaiReq.ToolChoice = "auto"
 	}
 
+	// 客户端通过 OpenAI 兼容接口指定的生成参数
+	options := optionsFromContext(ctx)
+	if options.MaxTokens > 0 {
+		openaiReq.MaxTokens = options.MaxTokens
+	}
+	if options.Temperature != nil {
+		openaiReq.Temperature = *options.Temperature
+	}
+	if len(options.Stop) > 0 {
+		openaiReq.Stop = options.Stop
+	}
+	if options.ToolChoice != nil && len(openaiTools) > 0 {
+		openaiReq.ToolChoice = options.ToolChoice
+	}
+
 	logx.Debug("Creating streaming chat completion with tools")
-	stream, err := openaiClient.client.CreateChatCompletionStream(ctx, openaiReq)
+	stream, err := c.client.CreateChatCompletionStream(ctx, openaiReq)
 	if err != nil {
-		return nil, false, fmt.Errorf("failed to create stream: %w", err)
+		return nil, fmt.Errorf("failed to create stream: %w", err)
 	}
 	defer func() { _ = stream.Close() }()
 
@@ -535,7 +667,15 @@ func (c *Client) streamChatWithTools(
 			break
 		}
 		if err != nil {
-			return nil, false, fmt.Errorf("stream error: %w", err)
+			return result, fmt.Errorf("stream error: %w", err)
+		}
+
+		// 用量在单独的数据块中返回, 该数据块没有 choices
+		if response.Usage != nil {
+			result.Usage = Usage{
+				PromptTokens:     response.Usage.PromptTokens,
+				CompletionTokens: response.Usage.CompletionTokens,
+			}
 		}
 
 		if len(response.Choices) == 0 {
@@ -587,10 +727,10 @@ func (c *Client) streamChatWithTools(
 			}
 		}
 
-		// 检查是否结束
+		// 结束后继续读取, 直到收到用量数据块和结束标记
 		if response.Choices[0].FinishReason != "" {
 			logx.Debug("Stream finished, reason: %s", response.Choices[0].FinishReason)
-			break
+			result.FinishReason = string(response.Choices[0].FinishReason)
 		}
 	}
 
@@ -609,17 +749,9 @@ func (c *Client) streamChatWithTools(
 		}
 
 		logx.Info("Accumulated %d tool calls", len(result.ToolCalls))
-		return result, true, nil
 	}
 
-	// 没有工具调用,对话结束
-	return result, false, nil
-}
-
-// StreamResult 流式响应的累积结果
-type StreamResult struct {
-	Content   string
-	ToolCalls []ToolCall
+	return result, nil
 }
 
 // SetProxy 设置代理
@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"context"
+	"slices"
+)
+
+// 结束原因, 与 OpenAI finish_reason 取值一致
+const (
+	FinishReasonStop      = "stop"
+	FinishReasonLength    = "length"
+	FinishReasonToolCalls = "tool_calls"
+)
+
+// ChatOptions 调用方指定的生成参数, 用于 OpenAI 兼容接口透传客户端请求
+// 未设置的项使用模型厂商的默认值
+type ChatOptions struct {
+	MaxTokens   int      // 单次回复的最大 token 数
+	Temperature *float32 // 采样温度
+	Stop        []string // 停止序列
+	ToolChoice  any      // 工具调用策略, "auto"、"none"、"required" 或指定函数的对象
+	Tools       []Tool   // 客户端自带的工具, 模型调用时直接返回给客户端执行
+}
+
+type optionsKey struct{}
+
+type toolProgressKey struct{}
+
+// WithOptions 在上下文中设置本次对话的生成参数
+func WithOptions(ctx context.Context, options ChatOptions) context.Context {
+	return context.WithValue(ctx, optionsKey{}, options)
+}
+
+// optionsFromContext 获取上下文中的生成参数
+func optionsFromContext(ctx context.Context) ChatOptions {
+	options, _ := ctx.Value(optionsKey{}).(ChatOptions)
+	return options
+}
+
+// WithToolProgress 在回复中推送工具调用进度, 供 IM 渠道在消息中展示
+// 未设置时不推送, 避免进度文本混入 OpenAI 兼容接口的回答和保存的对话记录
+func WithToolProgress(ctx context.Context) context.Context {
+	return context.WithValue(ctx, toolProgressKey{}, true)
+}
+
+// toolProgressEnabled 判断是否推送工具调用进度
+func toolProgressEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(toolProgressKey{}).(bool)
+	return enabled
+}
+
+// isClientTool 判断工具是否由客户端提供
+func (o ChatOptions) isClientTool(name string) bool {
+	return slices.ContainsFunc(o.Tools, func(tool Tool) bool {
+		return tool.Function.Name == name
+	})
+}
+
+// toolChoiceNone 判断客户端是否禁止调用工具
+func (o ChatOptions) toolChoiceNone() bool {
+	choice, ok := o.ToolChoice.(string)
+	return ok && choice == "none"
+}
+
+// toolChoiceFunction 返回客户端指定必须调用的函数名称, 未指定时返回空
+func (o ChatOptions) toolChoiceFunction() string {
+	choice, ok := o.ToolChoice.(map[string]any)
+	if !ok {
+		return ""
+	}
+	function, _ := choice["function"].(map[string]any)
+	name, _ := function["name"].(string)
+	return name
+}
+
+// recordFinish 将结束原因和需要客户端执行的工具调用写入上下文中的 Answer
+func recordFinish(ctx context.Context, reason string, toolCalls []ToolCall) {
+	if answer, ok := ctx.Value(answerKey{}).(*Answer); ok {
+		answer.FinishReason = reason
+		answer.ToolCalls = toolCalls
+	}
+}
@@ -0,0 +1,155 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
+)
+
+// PromptData 提示词模板变量
+type PromptData struct {
+	User           string   // 发起人
+	Source         string   // 来源, 如 dingtalk、feishu、wecom、api
+	Group          string   // IM 群聊会话ID, 私聊时为空
+	Date           string   // 当前日期, 如 2006-01-02
+	Time           string   // 当前时间, 如 2006-01-02 15:04
+	DefaultAccount string   // 默认云账号
+	DefaultRegion  string   // 默认地域
+	Tools          string   // 可用工具列表, 每行一个 "- 名称: 描述"
+	ToolNames      []string // 可用工具名称
+}
+
+// RenderPrompt 使用提示词配置渲染系统提示词
+func RenderPrompt(profile *model.PromptProfile, data PromptData) (string, error) {
+	tmpl, err := template.New(profile.Name).Option("missingkey=zero").Parse(profile.Content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+	return builder.String(), nil
+}
+
+// PreviewPrompt 预览指定提示词配置对某个用户生效时的系统提示词
+// profile 为 nil 时预览内置的默认提示词
+func PreviewPrompt(ctx context.Context, mcpServer MCPServer, profile *model.PromptProfile) (string, error) {
+	client := NewClient(&Config{}, mcpServer)
+	tools, err := client.getMCPTools(ctx)
+	if err != nil {
+		return "", err
+	}
+	tools = filterAllowedTools(tools, profile)
+
+	if profile == nil {
+		return buildSystemPromptWithTools(tools), nil
+	}
+	return RenderPrompt(profile, newPromptData(ctx, profile, tools))
+}
+
+// resolvePromptProfile 按发起人、群聊和来源查找生效的提示词配置, 未配置时返回 nil
+func resolvePromptProfile(ctx context.Context) *model.PromptProfile {
+	caller, _ := service.CallerFromContext(ctx)
+	groupID := service.ChatGroupFromContext(ctx)
+	if caller.Username == "" && caller.Source == "" && groupID == "" {
+		return nil
+	}
+
+	profile, err := service.NewPromptService().ResolveProfile(caller.Source, groupID, caller.Username)
+	if err != nil {
+		logx.Warn("Failed to resolve prompt profile, user %s, source %s, group %s, error %v",
+			caller.Username, caller.Source, groupID, err)
+		return nil
+	}
+	return profile
+}
+
+// newPromptData 构建提示词模板变量
+func newPromptData(ctx context.Context, profile *model.PromptProfile, tools []Tool) PromptData {
+	caller, _ := service.CallerFromContext(ctx)
+	now := time.Now()
+
+	data := PromptData{
+		User:   caller.Username,
+		Source: caller.Source,
+		Group:  service.ChatGroupFromContext(ctx),
+		Date:   now.Format(time.DateOnly),
+		Time:   now.Format("2006-01-02 15:04"),
+		Tools:  formatToolList(tools),
+	}
+	if profile != nil {
+		data.DefaultAccount = profile.DefaultAccount
+		data.DefaultRegion = profile.DefaultRegion
+	}
+	for _, tool := range tools {
+		if tool.Function.Name != searchToolsName {
+			data.ToolNames = append(data.ToolNames, tool.Function.Name)
+		}
+	}
+	return data
+}
+
+// renderSystemPrompt 构建系统提示词, 有生效的提示词配置时使用配置的模板, 渲染失败时回退到内置提示词
+func renderSystemPrompt(ctx context.Context, profile *model.PromptProfile, tools []Tool) string {
+	if profile == nil {
+		return buildSystemPromptWithTools(tools)
+	}
+
+	prompt, err := RenderPrompt(profile, newPromptData(ctx, profile, tools))
+	if err != nil {
+		logx.Warn("Failed to render prompt profile %s, using default prompt: %v", profile.Name, err)
+		return buildSystemPromptWithTools(tools)
+	}
+	return prompt
+}
+
+// filterAllowedTools 只保留提示词配置允许使用的工具, 未限制时原样返回
+func filterAllowedTools(tools []Tool, profile *model.PromptProfile) []Tool {
+	if profile == nil || len(profile.AllowedTools) == 0 {
+		return tools
+	}
+	return slices.DeleteFunc(slices.Clone(tools), func(tool Tool) bool {
+		return !slices.Contains(profile.AllowedTools, tool.Function.Name)
+	})
+}
+
+// allowedToolSet 返回提示词配置允许调用的工具集合, 未限制时返回 nil
+func allowedToolSet(profile *model.PromptProfile) map[string]bool {
+	if profile == nil || len(profile.AllowedTools) == 0 {
+		return nil
+	}
+	allowed := make(map[string]bool, len(profile.AllowedTools)+1)
+	for _, name := range profile.AllowedTools {
+		allowed[name] = true
+	}
+	allowed[searchToolsName] = true
+	return allowed
+}
+
+// formatToolList 将工具列表格式化为提示词文本, 只发送了部分工具时提示模型使用 search_tools
+func formatToolList(tools []Tool) string {
+	var builder strings.Builder
+
+	searchable := false
+	for _, tool := range tools {
+		if tool.Function.Name == searchToolsName {
+			searchable = true
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("- %s: %s\n", tool.Function.Name, tool.Function.Description))
+	}
+	if searchable {
+		builder.WriteString(fmt.Sprintf("\n以上只列出了与当前问题最相关的工具, 如果无法满足需求, 请先调用 %s 按关键词检索更多工具。\n", searchToolsName))
+	}
+
+	return builder.String()
+}
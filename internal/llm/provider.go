@@ -0,0 +1,52 @@
+package llm
+
+import (
+	"context"
+	"strings"
+)
+
+// 支持的模型厂商
+const (
+	ProviderOpenAI    = "openai"    // OpenAI 及所有兼容 OpenAI 接口的厂商
+	ProviderAnthropic = "anthropic" // Anthropic 原生 Messages API
+)
+
+// ChatProvider 模型厂商接口
+// 消息与工具统一使用 OpenAI 风格的结构, 由各厂商实现自行转换为原生请求格式
+type ChatProvider interface {
+	// StreamChatWithTools 流式对话, 文本增量实时写入 responseCh, 返回累积的内容与工具调用
+	// 流式响应中途出错时同时返回已累积的内容, 调用方据此判断是否还能切换模型
+	StreamChatWithTools(ctx context.Context, messages []Message, tools []Tool, responseCh chan<- string) (*StreamResult, error)
+	// Complete 不带工具的单轮对话, 返回完整回复与 token 用量
+	Complete(ctx context.Context, messages []Message) (string, Usage, error)
+}
+
+// NewProvider 根据配置中的厂商创建对应的实现
+// 未识别的厂商 (如 deepseek、custom) 均按 OpenAI 兼容接口处理
+func NewProvider(config *Config) ChatProvider {
+	switch strings.ToLower(strings.TrimSpace(config.Provider)) {
+	case ProviderAnthropic, "claude":
+		return NewAnthropicClient(config)
+	default:
+		return NewOpenAIClient(config)
+	}
+}
+
+// StreamResult 流式响应的累积结果
+type StreamResult struct {
+	Content      string
+	ToolCalls    []ToolCall
+	Usage        Usage
+	FinishReason string // 已转换为 OpenAI 的 finish_reason 取值
+}
+
+// Usage 一次模型调用的 token 用量
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// TotalTokens 返回总 token 数
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
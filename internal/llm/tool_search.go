@@ -0,0 +1,329 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/service"
+)
+
+const (
+	// DefaultToolTopK 每次对话默认发送给模型的工具数量 (不含固定工具和 search_tools)
+	DefaultToolTopK = 15
+
+	// searchToolsName 用于检索更多工具的元工具名称
+	searchToolsName = "search_tools"
+	// searchToolsLimit search_tools 默认返回的工具数量
+	searchToolsLimit = 5
+
+	// BM25 参数
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// toolIndex 基于 BM25 的工具检索索引
+// 对工具名称、描述、参数说明和所属 MCP 服务器的标签建立索引, 不依赖外部服务
+type toolIndex struct {
+	tools  []Tool
+	docs   []map[string]int // 每个工具的词频
+	lens   []int
+	df     map[string]int // 包含某个词的工具数量
+	avgLen float64
+}
+
+// newToolIndex 创建工具检索索引, tags 为工具名称到所属服务器标签的映射
+func newToolIndex(tools []Tool, tags map[string][]string) *toolIndex {
+	index := &toolIndex{
+		tools: tools,
+		docs:  make([]map[string]int, len(tools)),
+		lens:  make([]int, len(tools)),
+		df:    make(map[string]int),
+	}
+
+	total := 0
+	for i, tool := range tools {
+		// 名称权重更高, 重复计入一次
+		terms := tokenize(tool.Function.Name)
+		terms = append(terms, terms...)
+		terms = append(terms, tokenize(tool.Function.Description)...)
+		terms = append(terms, tokenize(schemaText(tool.Function.Parameters))...)
+		for _, tag := range tags[tool.Function.Name] {
+			terms = append(terms, tokenize(tag)...)
+		}
+
+		tf := make(map[string]int, len(terms))
+		for _, term := range terms {
+			tf[term]++
+		}
+		for term := range tf {
+			index.df[term]++
+		}
+		index.docs[i] = tf
+		index.lens[i] = len(terms)
+		total += len(terms)
+	}
+	if len(tools) > 0 {
+		index.avgLen = float64(total) / float64(len(tools))
+	}
+
+	return index
+}
+
+// search 返回与查询最相关的工具, 只返回得分大于 0 的工具, 按得分从高到低排列
+func (idx *toolIndex) search(query string, limit int) []Tool {
+	terms := tokenize(query)
+	if len(terms) == 0 || len(idx.tools) == 0 {
+		return nil
+	}
+	slices.Sort(terms)
+	terms = slices.Compact(terms)
+
+	type scored struct {
+		index int
+		score float64
+	}
+	var results []scored
+	n := float64(len(idx.tools))
+	for i, tf := range idx.docs {
+		score := 0.0
+		for _, term := range terms {
+			freq := float64(tf[term])
+			if freq == 0 {
+				continue
+			}
+			df := float64(idx.df[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := freq + bm25K1*(1-bm25B+bm25B*float64(idx.lens[i])/idx.avgLen)
+			score += idf * freq * (bm25K1 + 1) / norm
+		}
+		if score > 0 {
+			results = append(results, scored{index: i, score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	tools := make([]Tool, 0, len(results))
+	for _, result := range results {
+		tools = append(tools, idx.tools[result.index])
+	}
+	return tools
+}
+
+// tokenize 将文本切分为检索词
+// 英文和数字按单词切分 (下划线、连字符均视为分隔符), 中文按单字和相邻双字切分
+func tokenize(text string) []string {
+	var tokens []string
+	var word, han []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, stem(string(word)))
+			word = word[:0]
+		}
+	}
+	flushHan := func() {
+		for i := range han {
+			tokens = append(tokens, string(han[i]))
+			if i+1 < len(han) {
+				tokens = append(tokens, string(han[i:i+2]))
+			}
+		}
+		han = han[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+
+	return tokens
+}
+
+// stem 去掉英文复数后缀, 使 instances 与 instance 匹配
+func stem(word string) string {
+	if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// schemaText 提取参数定义中的参数名和说明, 用于检索
+func schemaText(schema map[string]any) string {
+	properties, ok := schema["properties"].(map[string]any)
+	if !ok {
+		return ""
+	}
+
+	var builder strings.Builder
+	for name, property := range properties {
+		builder.WriteString(name)
+		builder.WriteString(" ")
+		if p, ok := property.(map[string]any); ok {
+			if description, ok := p["description"].(string); ok {
+				builder.WriteString(description)
+				builder.WriteString(" ")
+			}
+		}
+	}
+	return builder.String()
+}
+
+// mcpToolTags 从数据库读取外部 MCP 服务器的名称、描述和标签, 作为其工具的检索词
+func mcpToolTags() map[string][]string {
+	servers, err := service.NewConfigService().ListMCPServers()
+	if err != nil {
+		logx.Warn("Failed to load MCP server tags for tool search: %v", err)
+		return nil
+	}
+
+	tags := make(map[string][]string)
+	for _, server := range servers {
+		serverTags := append([]string{server.Name, server.Description, server.Provider}, server.Tags...)
+		for _, tool := range server.Tools {
+			tags[server.ToolPrefix+tool.Name] = serverTags
+		}
+	}
+	return tags
+}
+
+// toolQuery 取最近两条用户消息作为检索词, 兼顾 "那腾讯云呢" 这类追问
+func toolQuery(messages []Message) string {
+	var parts []string
+	for i := len(messages) - 1; i >= 0 && len(parts) < 2; i-- {
+		if messages[i].Role == "user" {
+			parts = append(parts, messageText(messages[i].Content))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// toolSelection 本次对话发送给模型的工具集合
+// 初始为固定工具与检索得分最高的工具, 模型调用 search_tools 后追加检索到的工具
+type toolSelection struct {
+	mu     sync.Mutex
+	index  *toolIndex
+	active []Tool
+	names  map[string]bool
+}
+
+// selectTools 根据用户消息挑选相关工具, 工具总数不超过 TopK 时返回 nil, 表示发送全部工具
+func (c *Client) selectTools(tools []Tool, query string) *toolSelection {
+	options := c.toolOptions.withDefaults()
+	if len(tools) <= options.TopK {
+		return nil
+	}
+
+	selection := &toolSelection{
+		index: newToolIndex(tools, mcpToolTags()),
+		names: make(map[string]bool),
+	}
+
+	// 固定工具始终发送, 不占用 TopK 名额
+	for _, tool := range tools {
+		if slices.Contains(options.PinnedTools, tool.Function.Name) {
+			selection.add(tool)
+		}
+	}
+	pinned := len(selection.active)
+	for _, tool := range selection.index.search(query, options.TopK+pinned) {
+		if len(selection.active)-pinned >= options.TopK {
+			break
+		}
+		selection.add(tool)
+	}
+
+	logx.Info("Selected %d of %d tools for LLM, pinned %d", len(selection.active), len(tools), pinned)
+	return selection
+}
+
+// add 将工具加入已选集合, 调用方需持有锁或尚未并发访问
+func (s *toolSelection) add(tool Tool) {
+	if s.names[tool.Function.Name] {
+		return
+	}
+	s.names[tool.Function.Name] = true
+	s.active = append(s.active, tool)
+}
+
+// tools 返回当前发送给模型的工具列表, 末尾附加 search_tools 元工具
+func (s *toolSelection) tools() []Tool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tools := slices.Clone(s.active)
+	return append(tools, Tool{
+		Type: "function",
+		Function: Function{
+			Name:        searchToolsName,
+			Description: "按关键词检索更多可用工具。当前工具列表无法满足需求时调用, 检索到的工具会在下一轮加入可用工具列表",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{
+						"type":        "string",
+						"description": "检索关键词, 如 \"腾讯云 CVM\"、\"jenkins build log\"",
+					},
+					"limit": map[string]any{
+						"type":        "integer",
+						"description": fmt.Sprintf("返回的工具数量, 默认 %d", searchToolsLimit),
+					},
+				},
+				"required": []string{"query"},
+			},
+		},
+	})
+}
+
+// search 执行 search_tools 调用, 将检索到的工具加入已选集合并返回工具说明
+func (s *toolSelection) search(arguments string) (string, error) {
+	var params struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &params); err != nil {
+		return "", fmt.Errorf("failed to parse tool arguments: %w", err)
+	}
+	if params.Limit <= 0 {
+		params.Limit = searchToolsLimit
+	}
+
+	found := s.index.search(params.Query, params.Limit)
+	if len(found) == 0 {
+		return fmt.Sprintf("没有找到与 %q 相关的工具, 请换用其他关键词", params.Query), nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var builder strings.Builder
+	builder.WriteString("找到以下工具, 已加入可用工具列表, 可直接调用:\n")
+	for _, tool := range found {
+		s.add(tool)
+		fmt.Fprintf(&builder, "- %s: %s\n", tool.Function.Name, tool.Function.Description)
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testTool 创建带有一个参数的工具定义
+func testTool(name, description, param, paramDescription string) Tool {
+	return Tool{
+		Type: "function",
+		Function: Function{
+			Name:        name,
+			Description: description,
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					param: map[string]any{"type": "string", "description": paramDescription},
+				},
+			},
+		},
+	}
+}
+
+// testTools 固定的工具集合, 中英文描述混合
+var testTools = []Tool{
+	testTool("list_ecs", "列出阿里云 ECS 云服务器实例", "region", "地域"),
+	testTool("list_cvm", "列出腾讯云 CVM 云服务器实例", "region", "地域"),
+	testTool("list_ec2", "List AWS EC2 instances", "region", "AWS region"),
+	testTool("list_s3", "List AWS S3 buckets", "region", "AWS region"),
+	testTool("list_rds", "列出阿里云 RDS 数据库实例", "region", "地域"),
+	testTool("list_k8s_pods", "列出 Kubernetes 集群中的 Pod", "namespace", "命名空间"),
+	testTool("get_pod_logs", "获取 Pod 的日志", "pod_name", "Pod 名称"),
+	testTool("list_jenkins_jobs", "列出 Jenkins 任务", "folder", "文件夹"),
+	testTool("get_jenkins_build_log", "Get the console log of a Jenkins build", "job_name", "Job name"),
+	testTool("list_github_runs", "List GitHub Actions workflow runs", "target", "owner/repo or owner/repo/workflow"),
+}
+
+// toolNames 返回工具名称列表
+func toolNames(tools []Tool) []string {
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Function.Name)
+	}
+	return names
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "snake case name", text: "list_jenkins_jobs", want: []string{"list", "jenkin", "job"}},
+		{name: "short words are not stemmed", text: "ECS has", want: []string{"ecs", "has"}},
+		{name: "chinese unigrams and bigrams", text: "云服务", want: []string{"云", "云服", "服", "服务", "务"}},
+		{name: "mixed text", text: "ECS实例", want: []string{"ecs", "实", "实例", "例"}},
+		{name: "punctuation only", text: " ,/-", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenize(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolIndexSearch(t *testing.T) {
+	index := newToolIndex(testTools, map[string][]string{"list_github_runs": {"ci", "持续集成"}})
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "english name", query: "jenkins build log", want: "get_jenkins_build_log"},
+		{name: "english description", query: "aws s3 buckets", want: "list_s3"},
+		{name: "plural matches singular", query: "workflow run", want: "list_github_runs"},
+		{name: "chinese description", query: "腾讯云的服务器", want: "list_cvm"},
+		{name: "chinese and english", query: "查看 pod 日志", want: "get_pod_logs"},
+		{name: "chinese word", query: "数据库", want: "list_rds"},
+		{name: "server tags", query: "持续集成", want: "list_github_runs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := index.search(tt.query, 3)
+			if len(got) == 0 || got[0].Function.Name != tt.want {
+				t.Errorf("search(%q) = %v, want %s first", tt.query, toolNames(got), tt.want)
+			}
+		})
+	}
+
+	if got := index.search("unrelated", 3); len(got) != 0 {
+		t.Errorf("search(unrelated) = %v, want no tools", toolNames(got))
+	}
+	if got := index.search("list", 2); len(got) != 2 {
+		t.Errorf("search(list, 2) returned %d tools, want 2", len(got))
+	}
+}
+
+func TestSelectTools(t *testing.T) {
+	client := &Client{toolOptions: ToolOptions{TopK: 2, PinnedTools: []string{"list_jenkins_jobs", "missing_tool"}}}
+
+	selection := client.selectTools(testTools, "aws ec2 instances")
+	if selection == nil {
+		t.Fatalf("selectTools() = nil, want a selection")
+	}
+
+	// 固定工具不占用 TopK 名额, 末尾附加 search_tools
+	names := toolNames(selection.tools())
+	want := []string{"list_jenkins_jobs", "list_ec2"}
+	if len(names) != 4 || !slices.Equal(names[:2], want) || names[3] != searchToolsName {
+		t.Fatalf("selected tools = %v, want %v, one more and %s", names, want, searchToolsName)
+	}
+
+	// search_tools 检索到的工具加入已选集合
+	result, err := selection.search(`{"query": "jenkins build log", "limit": 1}`)
+	if err != nil {
+		t.Fatalf("search() error = %v", err)
+	}
+	if !strings.Contains(result, "get_jenkins_build_log") {
+		t.Errorf("search() = %q, want get_jenkins_build_log", result)
+	}
+	if names := toolNames(selection.tools()); !slices.Contains(names, "get_jenkins_build_log") || len(names) != 5 {
+		t.Errorf("tools after search = %v, want get_jenkins_build_log added", names)
+	}
+
+	// 没有检索结果时不改变已选集合
+	if result, err := selection.search(`{"query": "unrelated"}`); err != nil || !strings.Contains(result, "没有找到") {
+		t.Errorf("search(unrelated) = %q, %v", result, err)
+	}
+	if len(selection.tools()) != 5 {
+		t.Errorf("tools after empty search = %v", toolNames(selection.tools()))
+	}
+	if _, err := selection.search(`not json`); err == nil {
+		t.Errorf("search() with invalid arguments error = nil")
+	}
+
+	// 工具总数不超过 TopK 时发送全部工具
+	if selection := client.selectTools(testTools[:2], "aws"); selection != nil {
+		t.Errorf("selectTools() with %d tools = %v, want nil", 2, toolNames(selection.tools()))
+	}
+}
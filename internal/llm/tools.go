@@ -0,0 +1,192 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
+)
+
+const (
+	// DefaultToolConcurrency 同一轮工具调用默认的最大并发数
+	DefaultToolConcurrency = 4
+	// DefaultToolTimeout 单个工具调用默认的超时时间
+	DefaultToolTimeout = 60 * time.Second
+)
+
+// ToolOptions 工具调用的并发、超时与工具筛选设置
+type ToolOptions struct {
+	Concurrency int           // 同一轮工具调用的最大并发数
+	Timeout     time.Duration // 单个工具调用的超时时间
+	TopK        int           // 工具较多时每次对话发送给模型的工具数量
+	PinnedTools []string      // 始终发送给模型的工具名称
+}
+
+// LoadToolOptions 加载工具调用设置
+// 优先使用数据库系统配置 (llm.tool_concurrency / llm.tool_timeout / llm.tool_top_k / llm.pinned_tools), 未配置时使用配置文件
+func LoadToolOptions(fallback config.LLMConfig) ToolOptions {
+	options := ToolOptions{
+		Concurrency: fallback.ToolConcurrency,
+		Timeout:     time.Duration(fallback.ToolTimeout) * time.Second,
+		TopK:        fallback.ToolTopK,
+		PinnedTools: fallback.PinnedTools,
+	}
+
+	configService := service.NewConfigService()
+	if value := systemConfigInt(configService, model.ConfigKeyLLMToolConcurrency); value > 0 {
+		options.Concurrency = value
+	}
+	if value := systemConfigInt(configService, model.ConfigKeyLLMToolTimeout); value > 0 {
+		options.Timeout = time.Duration(value) * time.Second
+	}
+	if value := systemConfigInt(configService, model.ConfigKeyLLMToolTopK); value > 0 {
+		options.TopK = value
+	}
+	if systemConfig, err := configService.GetSystemConfig(model.ConfigKeyLLMPinnedTools); err == nil && systemConfig != nil {
+		// 逗号分隔的工具名称
+		options.PinnedTools = nil
+		for _, name := range strings.Split(systemConfig.ConfigValue, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				options.PinnedTools = append(options.PinnedTools, name)
+			}
+		}
+	}
+
+	return options.withDefaults()
+}
+
+// systemConfigInt 读取整数类型的系统配置, 未配置或无法解析时返回 0
+func systemConfigInt(configService *service.ConfigService, key string) int {
+	systemConfig, err := configService.GetSystemConfig(key)
+	if err != nil || systemConfig == nil {
+		return 0
+	}
+	value, err := strconv.Atoi(systemConfig.ConfigValue)
+	if err != nil {
+		logx.Warn("Invalid system config, key %s, value %s", key, systemConfig.ConfigValue)
+		return 0
+	}
+	return value
+}
+
+// withDefaults 未设置的项使用默认值
+func (o ToolOptions) withDefaults() ToolOptions {
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultToolConcurrency
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultToolTimeout
+	}
+	if o.TopK <= 0 {
+		o.TopK = DefaultToolTopK
+	}
+	return o
+}
+
+// SetToolOptions 设置工具调用选项
+func (c *Client) SetToolOptions(options ToolOptions) {
+	c.toolOptions = options
+}
+
+// executeToolCalls 并发执行模型同一轮返回的工具调用, 结果按原始顺序返回
+// 开启了 WithToolProgress 时每个工具开始和结束时分别推送进度, 请求上下文结束时未完成的工具随之取消;
+// selection 不为空时由其处理 search_tools 调用, allowed 不为空时拒绝调用其之外的工具
+func (c *Client) executeToolCalls(ctx context.Context, toolCalls []ToolCall, selection *toolSelection, allowed map[string]bool, responseCh chan<- string) []Message {
+	options := c.toolOptions.withDefaults()
+	results := make([]Message, len(toolCalls))
+	sem := make(chan struct{}, options.Concurrency)
+
+	var wg sync.WaitGroup
+	for i, toolCall := range toolCalls {
+		results[i] = Message{
+			Role:       "tool",
+			ToolCallID: toolCall.ID,
+			Name:       toolCall.Function.Name,
+		}
+
+		// 等待空闲的并发名额, 请求已结束时不再启动新的工具
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Content = fmt.Sprintf("Error: %v", ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, toolCall ToolCall) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			sendProgress(ctx, responseCh, fmt.Sprintf("\n> 🔧 调用工具: **%s**\n", toolCall.Function.Name))
+			startTime := time.Now()
+
+			var toolResult string
+			var err error
+			if allowed != nil && !allowed[toolCall.Function.Name] {
+				err = fmt.Errorf("tool %s is not allowed by the current prompt profile", toolCall.Function.Name)
+			} else if selection != nil && toolCall.Function.Name == searchToolsName {
+				toolResult, err = selection.search(toolCall.Function.Arguments)
+			} else {
+				toolResult, err = c.executeToolCallWithTimeout(ctx, toolCall, options.Timeout)
+			}
+			if err != nil {
+				sendProgress(ctx, responseCh, fmt.Sprintf("❌ 工具 **%s** 调用失败: %v\n\n", toolCall.Function.Name, err))
+				toolResult = fmt.Sprintf("Error: %v", err)
+			} else {
+				sendProgress(ctx, responseCh, fmt.Sprintf("✅ 工具 **%s** 执行完成 (%.1fs)\n\n",
+					toolCall.Function.Name, time.Since(startTime).Seconds()))
+			}
+			// 工具结果会发送给第三方模型, 先按脱敏规则去除密钥等敏感信息
+			results[i].Content, _ = service.Redact(model.RedactionStageLLM, toolResult)
+		}(i, toolCall)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// executeToolCallWithTimeout 带超时执行单个工具调用
+// 工具未响应取消信号时也会在超时后返回, 避免一个工具拖住整轮对话
+func (c *Client) executeToolCallWithTimeout(ctx context.Context, toolCall ToolCall, timeout time.Duration) (string, error) {
+	toolCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type toolResult struct {
+		content string
+		err     error
+	}
+	resultCh := make(chan toolResult, 1)
+	go func() {
+		content, err := c.executeToolCall(toolCtx, toolCall)
+		resultCh <- toolResult{content: content, err: err}
+	}()
+
+	select {
+	case result := <-resultCh:
+		return result.content, result.err
+	case <-toolCtx.Done():
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		logx.Warn("Tool call timed out, tool %s, timeout %s", toolCall.Function.Name, timeout)
+		return "", fmt.Errorf("tool %s timed out after %s", toolCall.Function.Name, timeout)
+	}
+}
+
+// sendProgress 推送工具执行进度, 未通过 WithToolProgress 开启或请求已结束时丢弃
+func sendProgress(ctx context.Context, responseCh chan<- string, message string) {
+	if !toolProgressEnabled(ctx) {
+		return
+	}
+	select {
+	case responseCh <- message:
+	case <-ctx.Done():
+	}
+}
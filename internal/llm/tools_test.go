@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// fakeMCPServer 返回固定结果的 MCP 服务器
+type fakeMCPServer struct {
+	tools []mcp.Tool
+}
+
+func (f *fakeMCPServer) ListTools(ctx context.Context) (*mcp.ListToolsResult, error) {
+	return &mcp.ListToolsResult{Tools: f.tools}, nil
+}
+
+func (f *fakeMCPServer) ListEnabledTools(ctx context.Context) (*mcp.ListToolsResult, error) {
+	return f.ListTools(ctx)
+}
+
+func (f *fakeMCPServer) CallTool(ctx context.Context, name string, arguments map[string]any) (*mcp.CallToolResult, error) {
+	return mcp.NewToolResultText("result of " + name), nil
+}
+
+func (f *fakeMCPServer) ToolOrigin(name string) (string, string, bool) {
+	return "zenops", name, true
+}
+
+func TestExecuteToolCallsProgress(t *testing.T) {
+	client := &Client{mcpServer: &fakeMCPServer{}}
+	toolCall := ToolCall{ID: "call_1", Type: "function"}
+	toolCall.Function.Name = "list_ecs"
+	toolCall.Function.Arguments = "{}"
+	toolCalls := []ToolCall{toolCall}
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		wantProgress bool
+	}{
+		{name: "im channel", ctx: WithToolProgress(context.Background()), wantProgress: true},
+		{name: "openai compatible api", ctx: context.Background()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responseCh := make(chan string, 10)
+			results := client.executeToolCalls(tt.ctx, toolCalls, nil, nil, responseCh)
+			close(responseCh)
+
+			var progress strings.Builder
+			for chunk := range responseCh {
+				progress.WriteString(chunk)
+			}
+			if got := progress.Len() > 0; got != tt.wantProgress {
+				t.Errorf("progress = %q, want progress %v", progress.String(), tt.wantProgress)
+			}
+			if tt.wantProgress && !strings.Contains(progress.String(), "list_ecs") {
+				t.Errorf("progress = %q, want tool name", progress.String())
+			}
+			if len(results) != 1 || results[0].Content != "result of list_ecs" {
+				t.Errorf("executeToolCalls() = %+v", results)
+			}
+		})
+	}
+}
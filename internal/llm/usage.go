@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"errors"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/service"
+)
+
+// checkQuota 调用模型前检查发起人的 token 配额
+// 仅在超出配额时返回错误, 配额查询失败时不阻断对话
+func (c *Client) checkQuota(ctx context.Context) error {
+	caller, ok := service.CallerFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	err := service.NewUsageService().CheckQuota(caller.Username)
+	var quotaErr *service.QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		logx.Warn("LLM quota exceeded, user %s, period %s, used %d, limit %d",
+			caller.Username, quotaErr.Period, quotaErr.Used, quotaErr.Limit)
+		return err
+	}
+	if err != nil {
+		logx.Warn("Failed to check LLM quota, user %s, error %v", caller.Username, err)
+	}
+	return nil
+}
+
+// quotaMessage 返回超出配额时展示给用户的提示
+func quotaMessage(err error) string {
+	var quotaErr *service.QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		return quotaErr.Message()
+	}
+	return err.Error()
+}
+
+// recordUsage 记录一次模型调用的 token 用量, 发起人和所属会话从上下文中获取
+func (c *Client) recordUsage(ctx context.Context, config *Config, usage Usage) {
+	if answer, ok := ctx.Value(answerKey{}).(*Answer); ok {
+		answer.Usage.PromptTokens += usage.PromptTokens
+		answer.Usage.CompletionTokens += usage.CompletionTokens
+	}
+
+	// 部分兼容接口不返回用量
+	if usage.TotalTokens() == 0 {
+		return
+	}
+
+	caller, ok := service.CallerFromContext(ctx)
+	if !ok {
+		caller = service.Caller{Username: "llm", Source: "llm"}
+	}
+
+	err := service.NewUsageService().RecordUsage(&service.UsageParams{
+		Username:         caller.Username,
+		Source:           caller.Source,
+		ConversationID:   service.ConversationFromContext(ctx),
+		LLMName:          config.Name,
+		Model:            config.Model,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		InputPrice:       config.InputPrice,
+		OutputPrice:      config.OutputPrice,
+	})
+	if err != nil {
+		logx.Warn("Failed to save LLM usage: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/eryajf/zenops/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyMiddleware OpenAI 兼容接口的认证中间件
+// 接受 Authorization: Bearer <key> 中 zk_ 开头的 API Key、登录后获取的 JWT Token,
+// 以及 keys 返回的共享 API Key (auth.tokens, 不区分调用方, 对话中只能调用只读工具)。
+// 认证失败时按 OpenAI 的错误格式返回, 便于客户端展示
+func APIKeyMiddleware(keys func() []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, hasToken := bearerToken(c.GetHeader("Authorization"))
+		if !hasToken {
+			abortOpenAIUnauthorized(c, "Missing API key, please provide it in the Authorization header as Bearer <key>")
+			return
+		}
+
+		// API Key 或登录用户的 JWT Token
+		if err := authenticateRequest(c, token); err == nil {
+			c.Next()
+			return
+		}
+		if service.IsAPIKey(token) {
+			abortOpenAIUnauthorized(c, "Invalid or expired API key")
+			return
+		}
+		for _, key := range keys() {
+			if key != "" && subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+				c.Set("api_key", true)
+				c.Next()
+				return
+			}
+		}
+		abortOpenAIUnauthorized(c, "Invalid API key")
+	}
+}
+
+// bearerToken 从 Authorization 头中提取 Bearer Token
+func bearerToken(authHeader string) (string, bool) {
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" || strings.TrimSpace(parts[1]) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(parts[1]), true
+}
+
+// abortOpenAIUnauthorized 以 OpenAI 错误格式返回 401
+func abortOpenAIUnauthorized(c *gin.Context, message string) {
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"error": gin.H{
+			"message": message,
+			"type":    "invalid_request_error",
+			"code":    "invalid_api_key",
+		},
+	})
+}
@@ -1,48 +1,65 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// JWT 密钥，实际生产环境应该从配置文件中读取
-var JWTSecret = []byte("zenops-jwt-secret-key-change-in-production")
+// ErrInvalidToken 令牌无效、已过期或所属会话已被吊销
+var ErrInvalidToken = errors.New("invalid or expired token")
 
 // Claims JWT 声明
 type Claims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
-	Roles    string `json:"roles"`
+	UserID             uint   `json:"user_id"`
+	Username           string `json:"username"`
+	Roles              string `json:"roles"`
+	MustChangePassword bool   `json:"must_change_password,omitempty"` // 需先修改密码, 期间只能访问 /user 下的接口
+	SessionID          string `json:"sid"`                            // 所属登录会话, 会话吊销后令牌立即失效
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成 JWT Token
-func GenerateToken(userID uint, username, roles string) (string, error) {
+// GenerateToken 为用户的登录会话生成访问令牌
+// 使用当前启用的签名密钥签名, 令牌头中的 kid 标识所用密钥
+func GenerateToken(user *model.User, sessionID string) (string, error) {
+	kid, secret, err := service.NewJWTKeyService().SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
 	claims := Claims{
-		UserID:   userID,
-		Username: username,
-		Roles:    roles,
+		UserID:             user.ID,
+		Username:           user.Username,
+		Roles:              user.Roles,
+		MustChangePassword: user.MustChangePassword,
+		SessionID:          sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 24小时过期
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(service.AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "zenops",
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(JWTSecret)
+	token.Header["kid"] = kid
+	return token.SignedString(secret)
 }
 
-// ParseToken 解析 JWT Token
+// ParseToken 解析 JWT Token, 按令牌头中的 kid 选择校验密钥
 func ParseToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return JWTSecret, nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		return service.NewJWTKeyService().VerificationKey(kid)
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return nil, err
@@ -55,6 +72,63 @@ func ParseToken(tokenString string) (*Claims, error) {
 	return nil, jwt.ErrSignatureInvalid
 }
 
+// authenticateToken 解析访问令牌并校验所属会话未被吊销
+func authenticateToken(tokenString string) (*Claims, error) {
+	claims, err := ParseToken(tokenString)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := service.NewSessionService().ValidateSession(claims.SessionID, claims.UserID); err != nil {
+		if !errors.Is(err, service.ErrInvalidSession) {
+			logx.Error("Failed to validate session %s: %v", claims.SessionID, err)
+		}
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// setClaims 将用户信息存储到上下文中
+func setClaims(c *gin.Context, claims *Claims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("roles", claims.Roles)
+	c.Set("must_change_password", claims.MustChangePassword)
+	c.Set("session_id", claims.SessionID)
+}
+
+// setAPIKeyIdentity 将 API Key 对应的身份和权限范围存储到上下文中
+func setAPIKeyIdentity(c *gin.Context, identity *service.APIKeyIdentity) {
+	c.Set("user_id", identity.UserID)
+	c.Set("username", identity.Username)
+	c.Set("roles", identity.Roles)
+	c.Set("must_change_password", identity.MustChangePassword)
+	c.Set("api_key_id", identity.Key.ID)
+	c.Set("api_key_scopes", []string(identity.Key.Scopes))
+}
+
+// authenticateRequest 校验 Bearer Token 并将身份写入上下文
+// zk_ 开头的为个人访问令牌或服务账号的 API Key, 其余按登录后获取的 JWT Token 处理
+func authenticateRequest(c *gin.Context, token string) error {
+	if !service.IsAPIKey(token) {
+		claims, err := authenticateToken(token)
+		if err != nil {
+			return err
+		}
+		setClaims(c, claims)
+		return nil
+	}
+
+	identity, err := service.NewAPIKeyService().Authenticate(token, c.ClientIP())
+	if err != nil {
+		if !errors.Is(err, service.ErrInvalidAPIKey) {
+			logx.Error("Failed to authenticate api key: %v", err)
+		}
+		return ErrInvalidToken
+	}
+	setAPIKeyIdentity(c, identity)
+	return nil
+}
+
 // AuthMiddleware JWT 认证中间件
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -80,9 +154,8 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 解析 token
-		claims, err := ParseToken(parts[1])
-		if err != nil {
+		// 解析 token 并校验会话或 API Key
+		if err := authenticateRequest(c, parts[1]); err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code":    401,
 				"message": "认证令牌无效或已过期",
@@ -91,11 +164,6 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 将用户信息存储到上下文中
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("roles", claims.Roles)
-
 		c.Next()
 	}
 }
@@ -111,12 +179,24 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) == 2 && parts[0] == "Bearer" {
-			claims, err := ParseToken(parts[1])
-			if err == nil {
-				c.Set("user_id", claims.UserID)
-				c.Set("username", claims.Username)
-				c.Set("roles", claims.Roles)
-			}
+			_ = authenticateRequest(c, parts[1])
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSession 只允许登录会话访问的中间件, 需在 AuthMiddleware 之后使用
+// 用于修改密码、管理 API Key 等操作, 防止泄露的 API Key 被用来创建新的 Key
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("session_id") == "" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    403,
+				"message": "该操作需要登录后进行, 不支持使用 API Key",
+			})
+			c.Abort()
+			return
 		}
 
 		c.Next()
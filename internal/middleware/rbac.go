@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// sharedAPIKeyScopes 共享 API Key 的权限范围, 不区分调用方, 对话中只能调用只读工具
+var sharedAPIKeyScopes = []string{"*:" + service.ActionRead}
+
+// RequestPermissions 获取当前请求调用方的权限, 需在 AuthMiddleware 或 APIKeyMiddleware 之后使用
+// 用于在请求之外校验权限, 如对话中由模型发起的工具调用; 共享 API Key 只有只读权限
+func RequestPermissions(c *gin.Context) service.Permissions {
+	if c.GetBool("api_key") {
+		return service.Permissions{Scopes: sharedAPIKeyScopes, ServiceAccount: true}
+	}
+	permissions := service.Permissions{Roles: c.GetString("roles")}
+	if scopes, ok := c.Get("api_key_scopes"); ok {
+		permissions.Scopes = scopes.([]string)
+		permissions.ServiceAccount = c.GetUint("user_id") == 0
+	}
+	return permissions
+}
+
+// IsAdmin 判断当前登录用户是否为管理员
+func IsAdmin(c *gin.Context) bool {
+	return service.HasRole(c.GetString("roles"), model.RoleAdmin)
+}
+
+// requestAction 根据请求方法判断操作类型
+func requestAction(method string) string {
+	if method == http.MethodGet || method == http.MethodHead {
+		return service.ActionRead
+	}
+	return service.ActionWrite
+}
+
+// RequirePermission 资源权限校验中间件, 需在 AuthMiddleware 或 APIKeyMiddleware 之后使用
+// 操作类型由请求方法决定; 使用共享 API Key 调用 OpenAI 兼容接口时不区分角色; 需修改初始密码的用户一律拒绝。
+// 使用 zk_ API Key 时还需 Key 的权限范围允许, 个人访问令牌的权限不超过所属用户的角色, 服务账号只看权限范围
+func RequirePermission(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("api_key") {
+			c.Next()
+			return
+		}
+
+		if c.GetBool("must_change_password") {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    403,
+				"message": "请先修改初始密码",
+			})
+			c.Abort()
+			return
+		}
+
+		action := requestAction(c.Request.Method)
+		if scopes, ok := c.Get("api_key_scopes"); ok {
+			if !service.ScopesAllow(scopes.([]string), resource, action) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"code":    403,
+					"message": "API Key 权限不足: 需要 " + resource + ":" + action + " 权限",
+				})
+				c.Abort()
+				return
+			}
+			if c.GetUint("user_id") == 0 {
+				c.Next()
+				return
+			}
+		}
+
+		if !service.HasPermission(c.GetString("roles"), resource, action) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    403,
+				"message": "权限不足: 需要 " + resource + ":" + action + " 权限",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// APIKey HTTP API 访问密钥, 属于某个用户 (个人访问令牌) 或某个服务账号
+// 只保存 Key 的 SHA-256 摘要, 明文仅在创建时返回一次
+type APIKey struct {
+	ID             uint        `gorm:"primaryKey" json:"id"`
+	Name           string      `gorm:"size:100;not null" json:"name"`
+	Description    string      `gorm:"size:500" json:"description"`
+	KeyHash        string      `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	KeyPrefix      string      `gorm:"size:20" json:"key_prefix"`             // 明文的前几位, 用于在列表中辨认 Key
+	UserID         uint        `gorm:"index" json:"user_id"`                  // 所属用户, 服务账号的 Key 为 0
+	ServiceAccount string      `gorm:"size:100;index" json:"service_account"` // 服务账号名称, 个人访问令牌为空
+	Scopes         StringArray `gorm:"type:text" json:"scopes"`               // 允许的权限, 格式为 resource:action, 如 cloud:read、*:read, "*" 表示全部
+	ExpiresAt      *time.Time  `json:"expires_at"`                            // 为空表示永不过期
+	LastUsedAt     *time.Time  `json:"last_used_at"`
+	LastUsedIP     string      `gorm:"size:64" json:"last_used_ip"`
+	RevokedAt      *time.Time  `json:"revoked_at"`
+	CreatedBy      string      `gorm:"size:100" json:"created_by"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Active 判断 Key 是否未吊销且未过期
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
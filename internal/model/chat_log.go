@@ -14,6 +14,7 @@ type ChatLog struct {
 	ParentContent  uint       `json:"parent_content"`             // 父消息ID
 	ConversationID uint       `json:"conversation_id" gorm:"index"` // 所属会话ID
 	Content        string     `json:"content" gorm:"type:text"`
+	Model          string     `json:"model" gorm:"size:100"` // 实际回答的模型, 仅 AI 回答记录
 }
 
 // TableName 指定表名
@@ -7,7 +7,8 @@ import (
 // CICDConfig CICD配置模型
 type CICDConfig struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
-	Platform  string    `gorm:"size:50;not null;uniqueIndex" json:"platform"` // jenkins
+	Platform  string    `gorm:"size:50;not null;uniqueIndex:idx_cicd_platform_name" json:"platform"`              // jenkins, gitlab, github
+	Name      string    `gorm:"size:100;not null;default:default;uniqueIndex:idx_cicd_platform_name" json:"name"` // 实例名称, 同一平台可配置多个实例
 	Enabled   bool      `gorm:"default:true" json:"enabled"`
 	URL       string    `gorm:"type:text;not null" json:"url"`
 	Username  string    `gorm:"size:100" json:"username"`
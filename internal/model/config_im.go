@@ -13,6 +13,7 @@ type IMConfig struct {
 	AppKey     string    `gorm:"column:app_key;size:200" json:"app_key"`         // 应用Key/Secret
 	AgentID    string    `gorm:"column:agent_id;size:200" json:"agent_id"`       // Agent ID
 	TemplateID string    `gorm:"column:template_id;size:200" json:"template_id"` // 模板ID
+	LLMName    string    `gorm:"column:llm_name;size:100" json:"llm_name"`       // 使用的 LLM 配置名称, 为空时使用默认模型
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
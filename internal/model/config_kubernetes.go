@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+)
+
+// KubernetesCluster Kubernetes 集群配置模型
+type KubernetesCluster struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	Name             string    `gorm:"size:100;not null;uniqueIndex" json:"name"`
+	Enabled          bool      `gorm:"default:true" json:"enabled"`
+	Kubeconfig       string    `gorm:"type:text;not null" json:"kubeconfig"`
+	Context          string    `gorm:"size:200" json:"context"`           // kubeconfig 中的 context, 为空时使用 current-context
+	DefaultNamespace string    `gorm:"size:100" json:"default_namespace"` // 未指定命名空间时使用, 为空表示所有命名空间
+	Description      string    `gorm:"type:text" json:"description"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (KubernetesCluster) TableName() string {
+	return "kubernetes_clusters"
+}
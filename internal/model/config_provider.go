@@ -47,12 +47,13 @@ func (sa StringArray) Value() (driver.Value, error) {
 // ProviderAccount 云厂商账号配置模型
 type ProviderAccount struct {
 	ID        uint        `gorm:"primaryKey" json:"id"`
-	Provider  string      `gorm:"size:50;not null;index:idx_provider_name" json:"provider"` // aliyun, tencent
+	Provider  string      `gorm:"size:50;not null;index:idx_provider_name" json:"provider"` // aliyun, tencent, aws
 	Name      string      `gorm:"size:100;not null;index:idx_provider_name" json:"name"`
 	Enabled   bool        `gorm:"default:true" json:"enabled"`
 	AccessKey string      `gorm:"type:text;not null" json:"access_key"`
 	SecretKey string      `gorm:"type:text;not null" json:"secret_key"`
 	Regions   StringArray `gorm:"type:text" json:"regions"`
+	Endpoint  string      `gorm:"size:255" json:"endpoint"` // 自定义 API 地址(可选),如 LocalStack
 	CreatedAt time.Time   `json:"created_at"`
 	UpdatedAt time.Time   `json:"updated_at"`
 }
@@ -30,7 +30,13 @@ const (
 	ConfigKeyAuthEnabled                           = "auth.enabled"
 	ConfigKeyAuthType                              = "auth.type"
 	ConfigKeyAuthTokens                            = "auth.tokens"
+	ConfigKeyAuthLDAP                              = "auth.ldap"
+	ConfigKeyAuthOIDC                              = "auth.oidc"
 	ConfigKeyCacheEnabled                          = "cache.enabled"
 	ConfigKeyCacheType                             = "cache.type"
 	ConfigKeyCacheTTL                              = "cache.ttl"
+	ConfigKeyLLMToolConcurrency                    = "llm.tool_concurrency"
+	ConfigKeyLLMToolTimeout                        = "llm.tool_timeout"
+	ConfigKeyLLMToolTopK                           = "llm.tool_top_k"
+	ConfigKeyLLMPinnedTools                        = "llm.pinned_tools"
 )
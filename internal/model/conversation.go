@@ -4,13 +4,16 @@ import "time"
 
 // Conversation 会话模型
 type Conversation struct {
-	ID            uint       `gorm:"primaryKey" json:"id"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
-	DeletedAt     *time.Time `json:"deleted_at" gorm:"index"`
-	Username      string     `json:"username" gorm:"index;size:100"` // 所属用户
-	Title         string     `json:"title" gorm:"size:255"`          // 会话标题
-	LastMessageAt time.Time  `json:"last_message_at" gorm:"index"`   // 最后消息时间，用于排序
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at" gorm:"index"`
+	Username        string     `json:"username" gorm:"index;size:100"`    // 所属用户
+	Title           string     `json:"title" gorm:"size:255"`             // 会话标题
+	LastMessageAt   time.Time  `json:"last_message_at" gorm:"index"`      // 最后消息时间，用于排序
+	ChannelKey      string     `json:"channel_key" gorm:"index;size:200"` // IM 渠道会话标识, 如 feishu:<会话ID>, Web 会话为空
+	Summary         string     `json:"summary" gorm:"type:text"`          // 较早对话的摘要, 历史超出上下文预算时生成
+	SummarizedUntil uint       `json:"summarized_until"`                  // 摘要已覆盖的最后一条对话日志ID
 }
 
 // TableName 指定表名
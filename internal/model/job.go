@@ -2,17 +2,32 @@ package model
 
 import "time"
 
-// Job Jenkins 任务模型
+// Job CI/CD 任务模型 (Jenkins Job / GitLab 项目)
 type Job struct {
 	Name        string `json:"name"`
 	DisplayName string `json:"display_name"`
 	URL         string `json:"url"`
 	Description string `json:"description"`
 	Buildable   bool   `json:"buildable"`
+	Type        string `json:"type,omitempty"`   // 任务类型, 如 Pipeline, FreeStyle, MultiBranch
+	Status      string `json:"status,omitempty"` // 最近一次构建状态, 如 Success, Failed
 	LastBuild   *Build `json:"last_build,omitempty"`
 }
 
-// Build 构建模型
+// Agent 构建节点 (Jenkins Agent)
+type Agent struct {
+	Name               string   `json:"name"`
+	Description        string   `json:"description,omitempty"`
+	Online             bool     `json:"online"`
+	TemporarilyOffline bool     `json:"temporarily_offline"` // 被手动标记为离线
+	OfflineReason      string   `json:"offline_reason,omitempty"`
+	Executors          int      `json:"executors"`
+	BusyExecutors      int      `json:"busy_executors"`
+	Labels             []string `json:"labels"`
+	RunningBuilds      []string `json:"running_builds,omitempty"`
+}
+
+// Build 构建模型 (Jenkins Build / GitLab Pipeline)
 type Build struct {
 	Number    int       `json:"number"`
 	Status    string    `json:"status"`
@@ -20,6 +35,62 @@ type Build struct {
 	Timestamp time.Time `json:"timestamp"`
 	Duration  int64     `json:"duration"` // 毫秒
 	URL       string    `json:"url"`
+	Ref       string    `json:"ref,omitempty"`     // 分支或标签
+	Commit    string    `json:"commit,omitempty"`  // 提交 SHA
+	Actor     string    `json:"actor,omitempty"`   // 触发人
+	Trigger   string    `json:"trigger,omitempty"` // 触发事件, 如 push, schedule
+}
+
+// PipelineJob 流水线中的单个作业 (GitLab Pipeline Job)
+type PipelineJob struct {
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	Stage         string    `json:"stage"`
+	Status        string    `json:"status"`
+	AllowFailure  bool      `json:"allow_failure"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	Runner        string    `json:"runner,omitempty"`
+	StartedAt     time.Time `json:"started_at"`
+	Duration      int64     `json:"duration"` // 毫秒
+	URL           string    `json:"url"`
+}
+
+// BuildLog 构建控制台日志
+type BuildLog struct {
+	JobName    string `json:"job_name"`
+	Number     int    `json:"number"`
+	Status     string `json:"status"`
+	URL        string `json:"url"`
+	Content    string `json:"content"`
+	TotalLines int    `json:"total_lines"` // 完整日志行数
+	TotalBytes int64  `json:"total_bytes"` // 完整日志字节数
+	Lines      int    `json:"lines"`       // 返回的行数
+	Truncated  bool   `json:"truncated"`   // 是否因行数或大小限制被截断
+}
+
+// JobParameter 任务参数定义 (Jenkins 参数化构建)
+type JobParameter struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"` // 如 StringParameterDefinition, ChoiceParameterDefinition
+	Description string   `json:"description,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Choices     []string `json:"choices,omitempty"` // 仅选项参数
+}
+
+// QueueItem 构建队列项 (Jenkins Queue Item)
+type QueueItem struct {
+	ID           int64             `json:"id"`
+	JobName      string            `json:"job_name"`
+	URL          string            `json:"url,omitempty"`
+	Why          string            `json:"why,omitempty"` // 排队原因
+	Blocked      bool              `json:"blocked"`
+	Buildable    bool              `json:"buildable"`
+	Stuck        bool              `json:"stuck"`
+	Cancelled    bool              `json:"cancelled"`
+	InQueueSince time.Time         `json:"in_queue_since"`
+	Params       map[string]string `json:"params,omitempty"`
+	BuildNumber  int               `json:"build_number,omitempty"` // 已开始执行时的构建号
+	BuildURL     string            `json:"build_url,omitempty"`
 }
 
 // JobList 任务列表
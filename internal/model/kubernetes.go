@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// K8sNamespace Kubernetes 命名空间
+type K8sNamespace struct {
+	Name      string            `json:"name"`
+	Cluster   string            `json:"cluster"`
+	Status    string            `json:"status"`
+	Labels    map[string]string `json:"labels"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// K8sWorkload Kubernetes 工作负载 (Deployment/StatefulSet)
+type K8sWorkload struct {
+	Kind              string            `json:"kind"` // Deployment, StatefulSet
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	Cluster           string            `json:"cluster"`
+	Replicas          int32             `json:"replicas"`
+	ReadyReplicas     int32             `json:"ready_replicas"`
+	UpdatedReplicas   int32             `json:"updated_replicas"`
+	AvailableReplicas int32             `json:"available_replicas"`
+	Images            []string          `json:"images"`
+	Labels            map[string]string `json:"labels"`
+	CreatedAt         time.Time         `json:"created_at"`
+}
+
+// K8sPod Kubernetes Pod
+type K8sPod struct {
+	Name       string            `json:"name"`
+	Namespace  string            `json:"namespace"`
+	Cluster    string            `json:"cluster"`
+	Status     string            `json:"status"` // 与 kubectl get pods 的 STATUS 列一致, 如 Running, CrashLoopBackOff
+	Ready      string            `json:"ready"`  // 就绪容器数/容器总数, 如 1/2
+	Restarts   int32             `json:"restarts"`
+	PodIP      string            `json:"pod_ip"`
+	PodIPs     []string          `json:"pod_ips"`
+	HostIP     string            `json:"host_ip"`
+	NodeName   string            `json:"node_name"`
+	Owner      string            `json:"owner"` // 所属控制器, 如 ReplicaSet/web-5d8f7
+	Containers []string          `json:"containers"`
+	Labels     map[string]string `json:"labels"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// K8sService Kubernetes Service
+type K8sService struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Cluster     string            `json:"cluster"`
+	Type        string            `json:"type"`
+	ClusterIP   string            `json:"cluster_ip"`
+	ExternalIPs []string          `json:"external_ips"`
+	Ports       []string          `json:"ports"` // 如 80:30080/TCP
+	Selector    map[string]string `json:"selector"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
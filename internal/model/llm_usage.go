@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// LLMUsage 大模型调用的 token 用量记录, 工具调用循环中的每次模型调用记录一条
+type LLMUsage struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt        time.Time `gorm:"index" json:"created_at"`
+	Username         string    `gorm:"size:100;index" json:"username"`
+	Source           string    `gorm:"size:50;index" json:"source"` // 与 MCPLog.Source 取值一致, 如 "dingtalk", "feishu", "wecom", "api"
+	ConversationID   uint      `gorm:"index" json:"conversation_id"`
+	LLMName          string    `gorm:"column:llm_name;size:100" json:"llm_name"` // LLM 配置名称
+	Model            string    `gorm:"size:100;index" json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	Cost             float64   `json:"cost"` // 按调用时的模型价格计算的费用
+}
+
+// TableName 指定表名
+func (LLMUsage) TableName() string {
+	return "llm_usage"
+}
+
+// LLMQuota 用户 token 配额
+// Username 为空的记录是所有用户的默认配额, 配额为 0 表示不限制
+type LLMQuota struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Username      string    `gorm:"size:100;uniqueIndex" json:"username"`
+	DailyTokens   int64     `json:"daily_tokens"`   // 每日 token 上限
+	MonthlyTokens int64     `json:"monthly_tokens"` // 每月 token 上限
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (LLMQuota) TableName() string {
+	return "llm_quota"
+}
@@ -13,6 +13,7 @@ type MCPLog struct {
 	Username     string    `json:"username" gorm:"index;size:100"` // 调用用户
 	Source       string    `json:"source" gorm:"size:50"` // 调用来源: "admin_test", "dingtalk", "feishu", "wecom", "llm"
 	ChatLogID    uint      `json:"chat_log_id" gorm:"index"` // 关联的对话记录ID（如果是从LLM调用）
+	ConversationID uint      `json:"conversation_id" gorm:"index"` // 关联的会话ID（如果是从Web对话调用）
 	Request      string    `json:"request" gorm:"type:text"` // 请求参数 JSON
 	Response     string    `json:"response" gorm:"type:text"` // 响应结果 JSON
 	ErrorMessage string    `json:"error_message" gorm:"type:text"` // 错误信息（如果失败）
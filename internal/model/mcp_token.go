@@ -0,0 +1,43 @@
+package model
+
+import (
+	"path"
+	"time"
+)
+
+// MCPToken MCP Server 访问令牌
+// 只保存令牌的 SHA-256 摘要, 明文仅在创建时返回一次
+type MCPToken struct {
+	ID          uint        `gorm:"primaryKey" json:"id"`
+	Name        string      `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Description string      `gorm:"size:500" json:"description"`
+	TokenHash   string      `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	TokenPrefix string      `gorm:"size:20" json:"token_prefix"` // 明文的前几位, 用于在列表中辨认令牌
+	Tools       StringArray `gorm:"type:text" json:"tools"`      // 允许调用的工具名或通配符, 如 list_jenkins_*, "*" 表示全部工具
+	Enabled     bool        `gorm:"default:true" json:"enabled"`
+	ExpiresAt   *time.Time  `json:"expires_at"` // 为空表示永不过期
+	LastUsedAt  *time.Time  `json:"last_used_at"`
+	CreatedBy   string      `gorm:"size:100" json:"created_by"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (MCPToken) TableName() string {
+	return "mcp_tokens"
+}
+
+// Expired 判断令牌是否已过期
+func (t *MCPToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// AllowsTool 判断令牌是否允许调用指定工具, 通配符语法同 path.Match
+func (t *MCPToken) AllowsTool(name string) bool {
+	for _, pattern := range t.Tools {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
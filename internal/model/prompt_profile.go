@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// 提示词绑定范围, 优先级: 用户 > 群聊 > 平台
+const (
+	PromptScopePlatform = "platform" // IM 平台或来源, 如 dingtalk、feishu、wecom、api
+	PromptScopeGroup    = "group"    // 钉钉/飞书/企微群聊会话ID
+	PromptScopeUser     = "user"     // 来源和用户名, 格式为 source:username, 如 dingtalk:alice
+)
+
+// PromptProfile 系统提示词配置
+// Content 为 Go text/template 模板, 可使用 {{.User}}、{{.Source}}、{{.Date}}、
+// {{.DefaultAccount}}、{{.DefaultRegion}}、{{.Tools}} 等变量
+type PromptProfile struct {
+	ID             uint        `gorm:"primaryKey" json:"id"`
+	Name           string      `gorm:"size:100;not null;uniqueIndex" json:"name"`
+	Description    string      `gorm:"type:text" json:"description"`
+	Content        string      `gorm:"type:text;not null" json:"content"`
+	DefaultAccount string      `gorm:"size:100" json:"default_account"` // 默认云账号, 用户未指定时使用
+	DefaultRegion  string      `gorm:"size:100" json:"default_region"`  // 默认地域
+	AllowedTools   StringArray `gorm:"type:text" json:"allowed_tools"`  // 允许使用的工具, 为空时不限制
+	Enabled        bool        `gorm:"default:true" json:"enabled"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (PromptProfile) TableName() string {
+	return "prompt_profiles"
+}
+
+// PromptBinding 提示词配置的适用范围
+type PromptBinding struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ProfileID uint      `gorm:"index;not null" json:"profile_id"`
+	Scope     string    `gorm:"size:20;not null;uniqueIndex:idx_prompt_scope_target" json:"scope"` // platform | group | user
+	Target    string    `gorm:"size:255;not null;uniqueIndex:idx_prompt_scope_target" json:"target"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (PromptBinding) TableName() string {
+	return "prompt_bindings"
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// 脱敏规则的生效阶段
+const (
+	RedactionStageLLM     = "llm"     // 发送给模型前 (工具结果、对话消息)
+	RedactionStageStorage = "storage" // 写入数据库前 (MCP 调用日志、对话记录)
+	RedactionStageBoth    = "both"    // 两个阶段都生效
+)
+
+// RedactionRule 敏感信息脱敏规则
+// 内置规则在数据库初始化时创建, 只能修改生效阶段、替换内容和启用状态
+type RedactionRule struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Description string    `gorm:"size:500" json:"description"`
+	Pattern     string    `gorm:"type:text;not null" json:"pattern"` // 正则表达式 (RE2 语法)
+	Replacement string    `gorm:"size:200" json:"replacement"`       // 替换内容, 支持 ${1} 引用分组, 为空时使用 [REDACTED]
+	Stage       string    `gorm:"size:20;not null" json:"stage"`     // llm | storage | both
+	Builtin     bool      `json:"builtin"`
+	Enabled     bool      `gorm:"default:true" json:"enabled"`
+	HitCount    int64     `json:"hit_count"` // 累计替换次数
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (RedactionRule) TableName() string {
+	return "redaction_rules"
+}
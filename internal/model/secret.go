@@ -0,0 +1,63 @@
+package model
+
+// maskedSecret 脱敏后的密钥占位符
+const maskedSecret = "******"
+
+// MaskSecret 隐藏密钥, 只保留末尾 4 位便于辨认
+func MaskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	if len(value) <= 8 {
+		return maskedSecret
+	}
+	return maskedSecret + value[len(value)-4:]
+}
+
+// MaskSecrets 隐藏云厂商账号的 AK/SK
+func (a *ProviderAccount) MaskSecrets() {
+	a.AccessKey = MaskSecret(a.AccessKey)
+	a.SecretKey = MaskSecret(a.SecretKey)
+}
+
+// MaskSecrets 隐藏 LLM 的 API Key
+func (c *LLMConfig) MaskSecrets() {
+	c.APIKey = MaskSecret(c.APIKey)
+}
+
+// MaskSecrets 隐藏 IM 应用的 Key/Secret
+func (c *IMConfig) MaskSecrets() {
+	c.AppKey = MaskSecret(c.AppKey)
+}
+
+// MaskSecrets 隐藏 CICD 平台的访问令牌
+func (c *CICDConfig) MaskSecrets() {
+	c.Token = MaskSecret(c.Token)
+}
+
+// MaskSecrets 隐藏 kubeconfig, 其中包含集群证书和令牌
+func (c *KubernetesCluster) MaskSecrets() {
+	if c.Kubeconfig != "" {
+		c.Kubeconfig = maskedSecret
+	}
+}
+
+// MaskSecrets 隐藏外部 MCP 服务器的环境变量和请求头的值
+func (s *MCPServer) MaskSecrets() {
+	for key := range s.Env {
+		s.Env[key] = maskedSecret
+	}
+	for key := range s.Headers {
+		s.Headers[key] = maskedSecret
+	}
+}
+
+// MaskSecrets 隐藏系统配置中的访问令牌和 LDAP、OIDC 配置 (含绑定密码、客户端密钥)
+func (c *SystemConfig) MaskSecrets() {
+	switch c.ConfigKey {
+	case ConfigKeyAuthTokens, ConfigKeyAuthLDAP, ConfigKeyAuthOIDC:
+		if c.ConfigValue != "" {
+			c.ConfigValue = maskedSecret
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// JWTKey JWT 签名密钥, 令牌头中的 kid 对应 KID
+// 同一时间只有一个启用的签名密钥, 轮换后旧密钥仍保留一段时间用于校验未过期的访问令牌
+type JWTKey struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	KID       string     `gorm:"size:32;uniqueIndex;not null" json:"kid"`
+	Secret    string     `gorm:"size:128;not null" json:"-"`
+	Active    bool       `gorm:"index" json:"active"`
+	RetiredAt *time.Time `json:"retired_at"` // 轮换下线的时间, 启用中的密钥为空
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (JWTKey) TableName() string {
+	return "jwt_keys"
+}
+
+// UserSession 用户登录会话, 每次登录创建一个
+// 访问令牌中携带 SessionID, 会话被吊销后访问令牌和刷新令牌立即失效; 刷新令牌只保存 SHA-256 摘要
+type UserSession struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	SessionID        string     `gorm:"size:64;uniqueIndex;not null" json:"session_id"`
+	UserID           uint       `gorm:"index;not null" json:"user_id"`
+	Username         string     `gorm:"size:50" json:"username"`
+	RefreshTokenHash string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	ClientIP         string     `gorm:"size:64" json:"client_ip"`
+	UserAgent        string     `gorm:"size:255" json:"user_agent"`
+	ExpiresAt        time.Time  `json:"expires_at"` // 刷新令牌的过期时间, 每次刷新后顺延
+	LastUsedAt       time.Time  `json:"last_used_at"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// Active 判断会话是否有效 (未吊销且未过期)
+func (s *UserSession) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
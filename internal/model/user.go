@@ -1,12 +1,28 @@
 package model
 
 import (
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+// 用户角色, User.Roles 中以逗号分隔保存
+const (
+	RoleAdmin    = "admin"    // 管理员, 拥有全部权限
+	RoleOperator = "operator" // 运维, 可查看全部资源 (密钥脱敏), 可触发构建等操作
+	RoleViewer   = "viewer"   // 只读, 可查询云资源和构建信息、使用 AI 对话
+	RoleUser     = "user"     // 旧版本的默认角色, 权限等同 viewer
+)
+
+// 用户来源, 外部认证源的用户首次登录时自动创建, 密码由认证源管理
+const (
+	UserSourceLocal = "local"
+	UserSourceLDAP  = "ldap"
+	UserSourceOIDC  = "oidc"
+)
+
 // User 用户模型
 type User struct {
 	ID        uint           `gorm:"primarykey" json:"id"`
@@ -21,6 +37,12 @@ type User struct {
 	Avatar   string `gorm:"size:255" json:"avatar,omitempty"`
 	Roles    string `gorm:"size:255;default:'user'" json:"roles"` // 角色列表，逗号分隔
 	Enabled  bool   `gorm:"default:true" json:"enabled"`
+	Source   string `gorm:"size:20;default:'local'" json:"source"` // 用户来源: local, ldap, oidc
+
+	MustChangePassword bool       `json:"must_change_password"` // 下次登录后需先修改密码 (默认账号、管理员重置密码后)
+	FailedLogins       int        `json:"failed_logins"`        // 连续登录失败次数, 登录成功后清零
+	LockedUntil        *time.Time `json:"locked_until"`         // 连续登录失败过多时锁定到该时间
+	LastLoginAt        *time.Time `json:"last_login_at"`
 }
 
 // TableName 指定表名
@@ -38,8 +60,29 @@ func (u *User) SetPassword(password string) error {
 	return nil
 }
 
+// RoleList 返回角色列表
+func (u *User) RoleList() []string {
+	var roles []string
+	for _, role := range strings.Split(u.Roles, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// Locked 判断用户是否处于登录锁定中
+func (u *User) Locked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
 // CheckPassword 验证密码
 func (u *User) CheckPassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 	return err == nil
 }
+
+// External 判断是否为外部认证源 (LDAP、OIDC) 的用户, 外部用户不能使用本地密码登录
+func (u *User) External() bool {
+	return u.Source != "" && u.Source != UserSourceLocal
+}
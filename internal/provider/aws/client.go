@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Client AWS 客户端
+type Client struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	Region          string
+	// Endpoint 自定义服务地址(可选),用于 LocalStack 等兼容 AWS API 的本地服务
+	Endpoint  string
+	ec2Client *ec2.Client
+	rdsClient *rds.Client
+	s3Client  *s3.Client
+}
+
+// NewClient 创建 AWS 客户端
+func NewClient(accessKeyID, secretAccessKey, region, endpoint string) *Client {
+	return &Client{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		Region:          region,
+		Endpoint:        endpoint,
+	}
+}
+
+// awsConfig 构造 AWS SDK 配置
+func (c *Client) awsConfig() aws.Config {
+	return aws.Config{
+		Region:      c.Region,
+		Credentials: credentials.NewStaticCredentialsProvider(c.AccessKeyID, c.SecretAccessKey, ""),
+	}
+}
+
+// GetEC2Client 获取 EC2 客户端
+func (c *Client) GetEC2Client() *ec2.Client {
+	if c.ec2Client != nil {
+		return c.ec2Client
+	}
+
+	c.ec2Client = ec2.NewFromConfig(c.awsConfig(), func(o *ec2.Options) {
+		if c.Endpoint != "" {
+			o.BaseEndpoint = aws.String(c.Endpoint)
+		}
+	})
+	return c.ec2Client
+}
+
+// GetRDSClient 获取 RDS 客户端
+func (c *Client) GetRDSClient() *rds.Client {
+	if c.rdsClient != nil {
+		return c.rdsClient
+	}
+
+	c.rdsClient = rds.NewFromConfig(c.awsConfig(), func(o *rds.Options) {
+		if c.Endpoint != "" {
+			o.BaseEndpoint = aws.String(c.Endpoint)
+		}
+	})
+	return c.rdsClient
+}
+
+// GetS3Client 获取 S3 客户端
+func (c *Client) GetS3Client() *s3.Client {
+	if c.s3Client != nil {
+		return c.s3Client
+	}
+
+	c.s3Client = s3.NewFromConfig(c.awsConfig(), func(o *s3.Options) {
+		if c.Endpoint != "" {
+			o.BaseEndpoint = aws.String(c.Endpoint)
+			// 自定义地址通常不支持虚拟主机风格的 Bucket 域名
+			o.UsePathStyle = true
+		}
+	})
+	return c.s3Client
+}
@@ -0,0 +1,259 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+)
+
+// ListEC2Instances 列出 EC2 实例
+func (p *AWSProvider) ListEC2Instances(ctx context.Context, opts *provider.QueryOptions) ([]*model.Instance, error) {
+	if opts == nil {
+		opts = &provider.QueryOptions{}
+	}
+
+	regions, err := p.targetRegions(opts.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	var allInstances []*model.Instance
+	for _, region := range regions {
+		logx.Debug("Querying EC2 instances in region %s", region)
+
+		instances, err := p.listEC2InstancesInRegion(ctx, p.clients[region], opts)
+		if err != nil {
+			// 指定区域时直接返回错误,否则跳过失败的区域
+			if opts.Region != "" {
+				return nil, err
+			}
+			logx.Warn("Failed to query region %s, error %v", region, err)
+			continue
+		}
+
+		allInstances = append(allInstances, instances...)
+	}
+
+	return paginate(allInstances, opts.PageSize, opts.PageNum), nil
+}
+
+// listEC2InstancesInRegion 查询单个区域的全部 EC2 实例
+func (p *AWSProvider) listEC2InstancesInRegion(ctx context.Context, client *Client, opts *provider.QueryOptions) ([]*model.Instance, error) {
+	ec2Client := client.GetEC2Client()
+
+	input := &ec2.DescribeInstancesInput{}
+
+	// 应用过滤条件
+	if status, ok := opts.Filters["status"]; ok && status != "" {
+		input.Filters = append(input.Filters, types.Filter{
+			Name:   aws.String("instance-state-name"),
+			Values: []string{status},
+		})
+	}
+	for key, value := range opts.Tags {
+		input.Filters = append(input.Filters, types.Filter{
+			Name:   aws.String("tag:" + key),
+			Values: []string{value},
+		})
+	}
+
+	var instances []*model.Instance
+	paginator := ec2.NewDescribeInstancesPaginator(ec2Client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe instances: %w", err)
+		}
+
+		for _, reservation := range page.Reservations {
+			for _, inst := range reservation.Instances {
+				instances = append(instances, convertEC2ToInstance(inst, client.Region))
+			}
+		}
+	}
+
+	fillInstanceTypeSpecs(ctx, ec2Client, instances)
+
+	return instances, nil
+}
+
+// GetEC2Instance 获取 EC2 实例详情
+func (p *AWSProvider) GetEC2Instance(ctx context.Context, instanceID string) (*model.Instance, error) {
+	// 遍历所有区域查找实例
+	for _, region := range p.regions {
+		logx.Debug("Searching instance in region %s, instance_id %s", region, instanceID)
+
+		ec2Client := p.clients[region].GetEC2Client()
+		response, err := ec2Client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+			InstanceIds: []string{instanceID},
+		})
+		if err != nil {
+			logx.Warn("Failed to describe instance, region %s, error %v", region, err)
+			continue
+		}
+
+		for _, reservation := range response.Reservations {
+			if len(reservation.Instances) > 0 {
+				instance := convertEC2ToInstance(reservation.Instances[0], region)
+				fillInstanceTypeSpecs(ctx, ec2Client, []*model.Instance{instance})
+				return instance, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("instance %s not found in any region", instanceID)
+}
+
+// fillInstanceTypeSpecs 补充实例规格对应的 CPU 和内存
+// DescribeInstances 不返回内存信息,需要通过 DescribeInstanceTypes 查询,失败时仅记录日志
+func fillInstanceTypeSpecs(ctx context.Context, ec2Client *ec2.Client, instances []*model.Instance) {
+	if len(instances) == 0 {
+		return
+	}
+
+	seen := make(map[string]bool)
+	var instanceTypes []types.InstanceType
+	for _, inst := range instances {
+		if inst.InstanceType != "" && !seen[inst.InstanceType] {
+			seen[inst.InstanceType] = true
+			instanceTypes = append(instanceTypes, types.InstanceType(inst.InstanceType))
+		}
+	}
+
+	specs := make(map[string]types.InstanceTypeInfo)
+	// DescribeInstanceTypes 单次最多查询 100 个规格
+	for start := 0; start < len(instanceTypes); start += 100 {
+		end := start + 100
+		if end > len(instanceTypes) {
+			end = len(instanceTypes)
+		}
+
+		response, err := ec2Client.DescribeInstanceTypes(ctx, &ec2.DescribeInstanceTypesInput{
+			InstanceTypes: instanceTypes[start:end],
+		})
+		if err != nil {
+			logx.Warn("Failed to describe instance types, error %v", err)
+			return
+		}
+
+		for _, info := range response.InstanceTypes {
+			specs[string(info.InstanceType)] = info
+		}
+	}
+
+	for _, inst := range instances {
+		info, ok := specs[inst.InstanceType]
+		if !ok {
+			continue
+		}
+		if info.VCpuInfo != nil && info.VCpuInfo.DefaultVCpus != nil && inst.CPU == 0 {
+			inst.CPU = int(*info.VCpuInfo.DefaultVCpus)
+		}
+		if info.MemoryInfo != nil && info.MemoryInfo.SizeInMiB != nil {
+			inst.Memory = int(*info.MemoryInfo.SizeInMiB)
+		}
+	}
+}
+
+// convertEC2ToInstance 将 AWS EC2 实例转换为统一的 Instance 模型
+func convertEC2ToInstance(inst types.Instance, region string) *model.Instance {
+	instance := &model.Instance{
+		ID:           aws.ToString(inst.InstanceId),
+		Provider:     "aws",
+		Region:       region,
+		InstanceType: string(inst.InstanceType),
+		Tags:         make(map[string]string),
+		Metadata:     make(map[string]any),
+	}
+
+	// 状态
+	if inst.State != nil {
+		instance.Status = string(inst.State.Name)
+	}
+
+	// 可用区
+	if inst.Placement != nil {
+		instance.Zone = aws.ToString(inst.Placement.AvailabilityZone)
+	}
+
+	// 内网 IP (包含所有网卡上的辅助 IP)
+	seenIPs := make(map[string]bool)
+	if ip := aws.ToString(inst.PrivateIpAddress); ip != "" {
+		instance.PrivateIP = append(instance.PrivateIP, ip)
+		seenIPs[ip] = true
+	}
+	for _, eni := range inst.NetworkInterfaces {
+		for _, addr := range eni.PrivateIpAddresses {
+			ip := aws.ToString(addr.PrivateIpAddress)
+			if ip != "" && !seenIPs[ip] {
+				instance.PrivateIP = append(instance.PrivateIP, ip)
+				seenIPs[ip] = true
+			}
+		}
+	}
+
+	// 公网 IP
+	if ip := aws.ToString(inst.PublicIpAddress); ip != "" {
+		instance.PublicIP = append(instance.PublicIP, ip)
+	}
+
+	// CPU
+	if inst.CpuOptions != nil && inst.CpuOptions.CoreCount != nil && inst.CpuOptions.ThreadsPerCore != nil {
+		instance.CPU = int(*inst.CpuOptions.CoreCount * *inst.CpuOptions.ThreadsPerCore)
+	}
+
+	// 操作系统
+	instance.OSType = string(inst.Platform)
+	if instance.OSType == "" {
+		instance.OSType = "linux"
+	}
+	instance.OSName = aws.ToString(inst.PlatformDetails)
+
+	// 创建时间
+	if inst.LaunchTime != nil {
+		instance.CreatedAt = *inst.LaunchTime
+	}
+
+	// VPC 信息
+	if inst.VpcId != nil {
+		instance.Metadata["vpc_id"] = *inst.VpcId
+	}
+	if inst.SubnetId != nil {
+		instance.Metadata["subnet_id"] = *inst.SubnetId
+	}
+
+	// 计费模式
+	if inst.InstanceLifecycle != "" {
+		instance.Metadata["lifecycle"] = string(inst.InstanceLifecycle)
+	}
+
+	// 镜像和密钥对
+	if inst.ImageId != nil {
+		instance.Metadata["image_id"] = *inst.ImageId
+	}
+	if inst.KeyName != nil {
+		instance.Metadata["key_name"] = *inst.KeyName
+	}
+
+	// 标签, Name 标签作为实例名称
+	for _, tag := range inst.Tags {
+		key := aws.ToString(tag.Key)
+		value := aws.ToString(tag.Value)
+		instance.Tags[key] = value
+		if key == "Name" {
+			instance.Name = value
+		}
+	}
+
+	// 生成控制台跳转URL
+	instance.ConsoleURL = fmt.Sprintf("https://%s.console.aws.amazon.com/ec2/home?region=%s#InstanceDetails:instanceId=%s",
+		region, region, instance.ID)
+
+	return instance
+}
@@ -0,0 +1,222 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+)
+
+const ec2FirstPage = `<DescribeInstancesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>req-1</requestId>
+  <reservationSet>
+    <item>
+      <reservationId>r-1</reservationId>
+      <instancesSet>
+        <item>
+          <instanceId>i-web</instanceId>
+          <imageId>ami-123</imageId>
+          <instanceState><code>16</code><name>running</name></instanceState>
+          <keyName>ops</keyName>
+          <instanceType>t3.large</instanceType>
+          <launchTime>2024-03-01T08:30:00.000Z</launchTime>
+          <placement><availabilityZone>us-east-1a</availabilityZone></placement>
+          <subnetId>subnet-1</subnetId>
+          <vpcId>vpc-1</vpcId>
+          <privateIpAddress>10.0.0.10</privateIpAddress>
+          <ipAddress>54.1.2.3</ipAddress>
+          <instanceLifecycle>spot</instanceLifecycle>
+          <platformDetails>Linux/UNIX</platformDetails>
+          <cpuOptions><coreCount>2</coreCount><threadsPerCore>2</threadsPerCore></cpuOptions>
+          <networkInterfaceSet>
+            <item>
+              <privateIpAddressesSet>
+                <item><privateIpAddress>10.0.0.10</privateIpAddress><primary>true</primary></item>
+                <item><privateIpAddress>10.0.0.11</privateIpAddress><primary>false</primary></item>
+              </privateIpAddressesSet>
+            </item>
+          </networkInterfaceSet>
+          <tagSet>
+            <item><key>Name</key><value>web-1</value></item>
+            <item><key>env</key><value>prod</value></item>
+          </tagSet>
+        </item>
+      </instancesSet>
+    </item>
+  </reservationSet>
+  <nextToken>page-2</nextToken>
+</DescribeInstancesResponse>`
+
+const ec2SecondPage = `<DescribeInstancesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>req-2</requestId>
+  <reservationSet>
+    <item>
+      <reservationId>r-2</reservationId>
+      <instancesSet>
+        <item>
+          <instanceId>i-win</instanceId>
+          <instanceState><code>80</code><name>stopped</name></instanceState>
+          <instanceType>t3.micro</instanceType>
+          <placement><availabilityZone>us-east-1b</availabilityZone></placement>
+          <platform>windows</platform>
+          <platformDetails>Windows</platformDetails>
+        </item>
+      </instancesSet>
+    </item>
+  </reservationSet>
+</DescribeInstancesResponse>`
+
+const ec2InstanceTypes = `<DescribeInstanceTypesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>req-3</requestId>
+  <instanceTypeSet>
+    <item>
+      <instanceType>t3.large</instanceType>
+      <vCpuInfo><defaultVCpus>2</defaultVCpus></vCpuInfo>
+      <memoryInfo><sizeInMiB>8192</sizeInMiB></memoryInfo>
+    </item>
+    <item>
+      <instanceType>t3.micro</instanceType>
+      <vCpuInfo><defaultVCpus>2</defaultVCpus></vCpuInfo>
+      <memoryInfo><sizeInMiB>1024</sizeInMiB></memoryInfo>
+    </item>
+  </instanceTypeSet>
+</DescribeInstanceTypesResponse>`
+
+// ec2Handler 按 NextToken 返回两页实例, 并返回实例规格
+func ec2Handler(req fakeRequest) (int, string) {
+	switch req.Action {
+	case "DescribeInstances":
+		if req.Params["NextToken"] == "page-2" {
+			return http.StatusOK, ec2SecondPage
+		}
+		return http.StatusOK, ec2FirstPage
+	case "DescribeInstanceTypes":
+		return http.StatusOK, ec2InstanceTypes
+	}
+	return http.StatusBadRequest, ec2Error("InvalidAction", req.Action)
+}
+
+func TestListEC2InstancesPaginatesAndMapsFields(t *testing.T) {
+	server := newFakeAWS(t, ec2Handler)
+	p := newTestProvider(t, server.URL, "us-east-1")
+
+	instances, err := p.ListInstances(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListInstances() error = %v", err)
+	}
+	if len(instances) != 2 {
+		t.Fatalf("ListInstances() returned %d instances, want 2 across pages", len(instances))
+	}
+
+	// 第二页请求携带第一页返回的 nextToken
+	requests := server.requestsFor("DescribeInstances")
+	if len(requests) != 2 || requests[1].Params["NextToken"] != "page-2" {
+		t.Errorf("DescribeInstances requests = %+v, want second page with NextToken page-2", requests)
+	}
+
+	web := instances[0]
+	want := &model.Instance{
+		ID:           "i-web",
+		Name:         "web-1",
+		Provider:     "aws",
+		Region:       "us-east-1",
+		Zone:         "us-east-1a",
+		InstanceType: "t3.large",
+		Status:       "running",
+		PrivateIP:    []string{"10.0.0.10", "10.0.0.11"},
+		PublicIP:     []string{"54.1.2.3"},
+		CPU:          4, // coreCount * threadsPerCore, 不被规格的默认 vCPU 覆盖
+		Memory:       8192,
+		OSType:       "linux",
+		OSName:       "Linux/UNIX",
+		CreatedAt:    time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC),
+		Tags:         map[string]string{"Name": "web-1", "env": "prod"},
+		Metadata: map[string]any{
+			"vpc_id":    "vpc-1",
+			"subnet_id": "subnet-1",
+			"lifecycle": "spot",
+			"image_id":  "ami-123",
+			"key_name":  "ops",
+		},
+		ConsoleURL: "https://us-east-1.console.aws.amazon.com/ec2/home?region=us-east-1#InstanceDetails:instanceId=i-web",
+	}
+	if !reflect.DeepEqual(web, want) {
+		t.Errorf("first instance = %+v\nwant %+v", web, want)
+	}
+
+	// 未返回 cpuOptions 时使用规格的默认 vCPU
+	win := instances[1]
+	if win.ID != "i-win" || win.Status != "stopped" || win.OSType != "windows" || win.CPU != 2 || win.Memory != 1024 {
+		t.Errorf("second instance = %+v, want windows t3.micro with 2 vCPU / 1024 MiB", win)
+	}
+	if len(win.PrivateIP) != 0 || len(win.PublicIP) != 0 || win.Name != "" {
+		t.Errorf("second instance addresses = %v / %v, name %q, want empty", win.PrivateIP, win.PublicIP, win.Name)
+	}
+
+	// 两页中的规格合并为一次 DescribeInstanceTypes 查询
+	if typeRequests := server.requestsFor("DescribeInstanceTypes"); len(typeRequests) != 1 {
+		t.Errorf("DescribeInstanceTypes called %d times, want 1", len(typeRequests))
+	}
+}
+
+func TestListEC2InstancesFiltersAndPageSlice(t *testing.T) {
+	server := newFakeAWS(t, ec2Handler)
+	p := newTestProvider(t, server.URL, "us-east-1")
+
+	instances, err := p.ListInstances(context.Background(), &provider.QueryOptions{
+		PageSize: 1,
+		PageNum:  2,
+		Filters:  map[string]string{"status": "running"},
+		Tags:     map[string]string{"env": "prod"},
+	})
+	if err != nil {
+		t.Fatalf("ListInstances() error = %v", err)
+	}
+	if len(instances) != 1 || instances[0].ID != "i-win" {
+		t.Fatalf("ListInstances() page 2 = %+v, want i-win", instances)
+	}
+
+	params := server.requestsFor("DescribeInstances")[0].Params
+	wantParams := map[string]string{
+		"Filter.1.Name":    "instance-state-name",
+		"Filter.1.Value.1": "running",
+		"Filter.2.Name":    "tag:env",
+		"Filter.2.Value.1": "prod",
+	}
+	for key, value := range wantParams {
+		if params[key] != value {
+			t.Errorf("DescribeInstances param %s = %q, want %q", key, params[key], value)
+		}
+	}
+}
+
+func TestListEC2InstancesRegionErrors(t *testing.T) {
+	server := newFakeAWS(t, func(req fakeRequest) (int, string) {
+		if req.Region == "us-west-2" {
+			return http.StatusForbidden, ec2Error("AuthFailure", "region disabled")
+		}
+		return ec2Handler(req)
+	})
+	p := newTestProvider(t, server.URL, "us-east-1", "us-west-2")
+
+	// 遍历全部区域时跳过失败的区域
+	instances, err := p.ListInstances(context.Background(), &provider.QueryOptions{})
+	if err != nil {
+		t.Fatalf("ListInstances() error = %v, want failed region skipped", err)
+	}
+	if len(instances) != 2 {
+		t.Errorf("ListInstances() returned %d instances, want 2 from us-east-1", len(instances))
+	}
+
+	// 指定区域时直接返回错误
+	if _, err := p.ListInstances(context.Background(), &provider.QueryOptions{Region: "us-west-2"}); err == nil {
+		t.Errorf("ListInstances(us-west-2) error = nil, want AuthFailure")
+	}
+	if _, err := p.ListInstances(context.Background(), &provider.QueryOptions{Region: "eu-west-1"}); err == nil {
+		t.Errorf("ListInstances(eu-west-1) error = nil, want region not configured")
+	}
+}
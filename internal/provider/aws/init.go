@@ -0,0 +1,7 @@
+package aws
+
+import "github.com/eryajf/zenops/internal/provider"
+
+func init() {
+	provider.Register("aws", NewAWSProvider())
+}
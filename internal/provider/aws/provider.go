@@ -0,0 +1,186 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+)
+
+// AWSProvider AWS Provider
+type AWSProvider struct {
+	name            string
+	accessKeyID     string
+	secretAccessKey string
+	endpoint        string
+	regions         []string
+	clients         map[string]*Client // region -> client
+}
+
+// NewAWSProvider 创建 AWS Provider
+func NewAWSProvider() provider.Provider {
+	return &AWSProvider{
+		name:    "aws",
+		clients: make(map[string]*Client),
+	}
+}
+
+// GetName 获取 Provider 名称
+func (p *AWSProvider) GetName() string {
+	return p.name
+}
+
+// Initialize 初始化 Provider
+func (p *AWSProvider) Initialize(config map[string]any) error {
+	// 解析配置
+	accessKeyID, ok := config["access_key_id"].(string)
+	if !ok {
+		return fmt.Errorf("access_key_id is required")
+	}
+
+	secretAccessKey, ok := config["secret_access_key"].(string)
+	if !ok {
+		return fmt.Errorf("secret_access_key is required")
+	}
+
+	regions, ok := config["regions"].([]any)
+	if !ok || len(regions) == 0 {
+		return fmt.Errorf("regions are required")
+	}
+
+	// endpoint 为可选配置,用于指向本地的 AWS 兼容服务
+	endpoint, _ := config["endpoint"].(string)
+
+	p.accessKeyID = accessKeyID
+	p.secretAccessKey = secretAccessKey
+	p.endpoint = endpoint
+
+	// Provider 在注册表中是单例,重新初始化时需要清空之前账号的客户端
+	p.regions = nil
+	p.clients = make(map[string]*Client)
+
+	// 初始化每个区域的客户端
+	for _, r := range regions {
+		region, ok := r.(string)
+		if !ok {
+			continue
+		}
+
+		p.regions = append(p.regions, region)
+		p.clients[region] = NewClient(accessKeyID, secretAccessKey, region, endpoint)
+
+		logx.Debug("%s", "Initialized AWS client for region "+region)
+	}
+
+	logx.Info("AWS Provider initialized, regions count %d", len(p.regions))
+
+	return nil
+}
+
+// ListInstances 列出实例
+func (p *AWSProvider) ListInstances(ctx context.Context, opts *provider.QueryOptions) ([]*model.Instance, error) {
+	return p.ListEC2Instances(ctx, opts)
+}
+
+// GetInstance 获取实例详情
+func (p *AWSProvider) GetInstance(ctx context.Context, instanceID string) (*model.Instance, error) {
+	return p.GetEC2Instance(ctx, instanceID)
+}
+
+// ListDatabases 列出数据库
+func (p *AWSProvider) ListDatabases(ctx context.Context, opts *provider.QueryOptions) ([]*model.Database, error) {
+	return p.ListRDSInstances(ctx, opts)
+}
+
+// GetDatabase 获取数据库详情
+func (p *AWSProvider) GetDatabase(ctx context.Context, dbID string) (*model.Database, error) {
+	return p.GetRDSInstance(ctx, dbID)
+}
+
+// ListOSSBuckets 列出对象存储桶
+func (p *AWSProvider) ListOSSBuckets(ctx context.Context, opts *provider.QueryOptions) ([]*model.OSSBucket, error) {
+	if opts == nil {
+		opts = &provider.QueryOptions{}
+	}
+
+	// S3 的 ListBuckets 是全局接口,使用第一个区域的客户端即可
+	client, err := p.firstClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.ListS3Buckets(ctx, opts.PageSize, opts.PageNum, opts.Filters)
+}
+
+// GetOSSBucket 获取对象存储桶详情
+func (p *AWSProvider) GetOSSBucket(ctx context.Context, bucketName string) (*model.OSSBucket, error) {
+	client, err := p.firstClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.GetS3Bucket(ctx, bucketName)
+}
+
+// HealthCheck 健康检查
+func (p *AWSProvider) HealthCheck(ctx context.Context) error {
+	if len(p.clients) == 0 {
+		return fmt.Errorf("no clients initialized")
+	}
+
+	// 检查至少一个区域可用
+	for _, region := range p.regions {
+		_, err := p.clients[region].GetEC2Client().DescribeRegions(ctx, &ec2.DescribeRegionsInput{})
+		if err == nil {
+			logx.Debug("Health check passed, region %s", region)
+			return nil
+		}
+		logx.Warn("Health check failed, region %s, error %v", region, err)
+	}
+
+	return fmt.Errorf("all regions failed health check")
+}
+
+// firstClient 按配置顺序返回第一个区域的客户端
+func (p *AWSProvider) firstClient() (*Client, error) {
+	if len(p.regions) == 0 {
+		return nil, fmt.Errorf("no clients available")
+	}
+	return p.clients[p.regions[0]], nil
+}
+
+// targetRegions 返回本次查询需要遍历的区域
+func (p *AWSProvider) targetRegions(region string) ([]string, error) {
+	if region == "" {
+		return p.regions, nil
+	}
+	if _, exists := p.clients[region]; !exists {
+		return nil, fmt.Errorf("region %s not configured", region)
+	}
+	return []string{region}, nil
+}
+
+// paginate 对结果进行内存分页
+// AWS API 使用 NextToken 分页,无法直接跳到指定页,因此先拉取全量数据再按页码切片
+func paginate[T any](items []T, pageSize, pageNum int) []T {
+	if pageSize <= 0 {
+		return items
+	}
+	if pageNum < 1 {
+		pageNum = 1
+	}
+
+	start := (pageNum - 1) * pageSize
+	if start >= len(items) {
+		return []T{}
+	}
+
+	end := start + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
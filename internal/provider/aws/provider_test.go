@@ -0,0 +1,154 @@
+package aws
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeAWS 模拟 EC2、RDS 和 S3 的 HTTP 接口
+// EC2 和 RDS 使用 Query 协议 (表单 POST, Action 区分接口), S3 ListBuckets 为 GET /
+type fakeAWS struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []fakeRequest
+}
+
+// fakeRequest 记录的一次请求, Service 和 Region 取自签名的凭证范围
+type fakeRequest struct {
+	Service string
+	Region  string
+	Action  string
+	Params  map[string]string
+}
+
+// fakeHandler 根据请求返回 HTTP 状态码和 XML 响应体
+type fakeHandler func(req fakeRequest) (int, string)
+
+func newFakeAWS(t *testing.T, handler fakeHandler) *fakeAWS {
+	t.Helper()
+	f := &fakeAWS{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := fakeRequest{Params: make(map[string]string)}
+
+		// Authorization: AWS4-HMAC-SHA256 Credential=AK/20240101/us-east-1/ec2/aws4_request, ...
+		auth := r.Header.Get("Authorization")
+		if i := strings.Index(auth, "Credential="); i >= 0 {
+			scope := strings.Split(strings.SplitN(auth[i+len("Credential="):], ",", 2)[0], "/")
+			if len(scope) == 5 {
+				req.Region, req.Service = scope[2], scope[3]
+			}
+		}
+
+		if r.Method == http.MethodPost {
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("parse form error = %v", err)
+			}
+			for key := range r.PostForm {
+				req.Params[key] = r.PostForm.Get(key)
+			}
+			req.Action = req.Params["Action"]
+		} else {
+			io.Copy(io.Discard, r.Body)
+			for key := range r.URL.Query() {
+				req.Params[key] = r.URL.Query().Get(key)
+			}
+			req.Action = r.Method + " " + r.URL.Path
+		}
+
+		f.mu.Lock()
+		f.requests = append(f.requests, req)
+		f.mu.Unlock()
+
+		status, body := handler(req)
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// requestsFor 返回指定接口收到的请求
+func (f *fakeAWS) requestsFor(action string) []fakeRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result []fakeRequest
+	for _, req := range f.requests {
+		if req.Action == action {
+			result = append(result, req)
+		}
+	}
+	return result
+}
+
+// newTestProvider 创建指向模拟服务的 AWS Provider
+func newTestProvider(t *testing.T, endpoint string, regions ...string) *AWSProvider {
+	t.Helper()
+	var regionList []any
+	for _, region := range regions {
+		regionList = append(regionList, region)
+	}
+
+	p := NewAWSProvider().(*AWSProvider)
+	err := p.Initialize(map[string]any{
+		"access_key_id":     "AKIDEXAMPLE",
+		"secret_access_key": "secret",
+		"regions":           regionList,
+		"endpoint":          endpoint,
+	})
+	if err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	return p
+}
+
+// ec2Error 返回 EC2 Query 协议的错误响应
+func ec2Error(code, message string) string {
+	return fmt.Sprintf(`<Response><Errors><Error><Code>%s</Code><Message>%s</Message></Error></Errors><RequestID>req-1</RequestID></Response>`, code, message)
+}
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	tests := []struct {
+		name     string
+		pageSize int
+		pageNum  int
+		want     []int
+	}{
+		{name: "no page size returns all", pageSize: 0, pageNum: 3, want: []int{1, 2, 3, 4, 5}},
+		{name: "first page", pageSize: 2, pageNum: 1, want: []int{1, 2}},
+		{name: "page num defaults to 1", pageSize: 2, pageNum: 0, want: []int{1, 2}},
+		{name: "last partial page", pageSize: 2, pageNum: 3, want: []int{5}},
+		{name: "beyond last page", pageSize: 2, pageNum: 4, want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paginate(items, tt.pageSize, tt.pageNum); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("paginate(%d, %d) = %v, want %v", tt.pageSize, tt.pageNum, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetRegions(t *testing.T) {
+	p := newTestProvider(t, "http://127.0.0.1:1", "us-east-1", "us-west-2")
+
+	regions, err := p.targetRegions("")
+	if err != nil || !reflect.DeepEqual(regions, []string{"us-east-1", "us-west-2"}) {
+		t.Errorf("targetRegions(\"\") = %v, %v, want all configured regions", regions, err)
+	}
+	regions, err = p.targetRegions("us-west-2")
+	if err != nil || !reflect.DeepEqual(regions, []string{"us-west-2"}) {
+		t.Errorf("targetRegions(us-west-2) = %v, %v", regions, err)
+	}
+	if _, err := p.targetRegions("eu-west-1"); err == nil {
+		t.Errorf("targetRegions(eu-west-1) error = nil, want region not configured")
+	}
+}
@@ -0,0 +1,130 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+)
+
+// ListRDSInstances 列出 RDS 实例
+func (p *AWSProvider) ListRDSInstances(ctx context.Context, opts *provider.QueryOptions) ([]*model.Database, error) {
+	if opts == nil {
+		opts = &provider.QueryOptions{}
+	}
+
+	regions, err := p.targetRegions(opts.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	var allDatabases []*model.Database
+	for _, region := range regions {
+		logx.Debug("Querying RDS instances in region %s", region)
+
+		databases, err := p.listRDSInstancesInRegion(ctx, p.clients[region], opts)
+		if err != nil {
+			if opts.Region != "" {
+				return nil, err
+			}
+			logx.Warn("Failed to query region %s, error %v", region, err)
+			continue
+		}
+
+		allDatabases = append(allDatabases, databases...)
+	}
+
+	return paginate(allDatabases, opts.PageSize, opts.PageNum), nil
+}
+
+// listRDSInstancesInRegion 查询单个区域的全部 RDS 实例
+func (p *AWSProvider) listRDSInstancesInRegion(ctx context.Context, client *Client, opts *provider.QueryOptions) ([]*model.Database, error) {
+	input := &rds.DescribeDBInstancesInput{}
+
+	// 应用过滤条件
+	if engine, ok := opts.Filters["engine"]; ok && engine != "" {
+		input.Filters = append(input.Filters, types.Filter{
+			Name:   aws.String("engine"),
+			Values: []string{engine},
+		})
+	}
+
+	var databases []*model.Database
+	paginator := rds.NewDescribeDBInstancesPaginator(client.GetRDSClient(), input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe db instances: %w", err)
+		}
+
+		for _, inst := range page.DBInstances {
+			databases = append(databases, convertRDSToDatabase(inst, client.Region))
+		}
+	}
+
+	return databases, nil
+}
+
+// GetRDSInstance 获取 RDS 实例详情
+func (p *AWSProvider) GetRDSInstance(ctx context.Context, dbID string) (*model.Database, error) {
+	for _, region := range p.regions {
+		logx.Debug("Searching RDS instance in region %s, db_instance_identifier %s", region, dbID)
+
+		response, err := p.clients[region].GetRDSClient().DescribeDBInstances(ctx, &rds.DescribeDBInstancesInput{
+			DBInstanceIdentifier: aws.String(dbID),
+		})
+		if err != nil {
+			logx.Warn("Failed to describe db instance, region %s, error %v", region, err)
+			continue
+		}
+
+		if len(response.DBInstances) > 0 {
+			return convertRDSToDatabase(response.DBInstances[0], region), nil
+		}
+	}
+
+	return nil, fmt.Errorf("db instance %s not found in any region", dbID)
+}
+
+// convertRDSToDatabase 将 AWS RDS 实例转换为统一的 Database 模型
+func convertRDSToDatabase(inst types.DBInstance, region string) *model.Database {
+	database := &model.Database{
+		ID:            aws.ToString(inst.DBInstanceIdentifier),
+		Name:          aws.ToString(inst.DBInstanceIdentifier),
+		Provider:      "aws",
+		Region:        region,
+		Engine:        aws.ToString(inst.Engine),
+		EngineVersion: aws.ToString(inst.EngineVersion),
+		Status:        aws.ToString(inst.DBInstanceStatus),
+		Tags:          make(map[string]string),
+	}
+
+	// 连接地址
+	if inst.Endpoint != nil {
+		database.Endpoint = aws.ToString(inst.Endpoint.Address)
+		if inst.Endpoint.Port != nil {
+			database.Port = int(*inst.Endpoint.Port)
+		}
+	}
+
+	// 创建时间
+	if inst.InstanceCreateTime != nil {
+		database.CreatedAt = *inst.InstanceCreateTime
+	}
+
+	// 标签
+	for _, tag := range inst.TagList {
+		database.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+
+	// 生成控制台跳转URL
+	database.ConsoleURL = fmt.Sprintf("https://%s.console.aws.amazon.com/rds/home?region=%s#database:id=%s",
+		region, region, database.ID)
+
+	return database
+}
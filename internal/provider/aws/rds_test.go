@@ -0,0 +1,120 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+)
+
+const rdsFirstPage = `<DescribeDBInstancesResponse xmlns="http://rds.amazonaws.com/doc/2014-10-31/">
+  <DescribeDBInstancesResult>
+    <DBInstances>
+      <DBInstance>
+        <DBInstanceIdentifier>orders-db</DBInstanceIdentifier>
+        <Engine>mysql</Engine>
+        <EngineVersion>8.0.35</EngineVersion>
+        <DBInstanceStatus>available</DBInstanceStatus>
+        <Endpoint>
+          <Address>orders-db.abc.us-east-1.rds.amazonaws.com</Address>
+          <Port>3306</Port>
+        </Endpoint>
+        <InstanceCreateTime>2024-02-01T10:00:00Z</InstanceCreateTime>
+        <TagList>
+          <Tag><Key>env</Key><Value>prod</Value></Tag>
+        </TagList>
+      </DBInstance>
+    </DBInstances>
+    <Marker>marker-2</Marker>
+  </DescribeDBInstancesResult>
+  <ResponseMetadata><RequestId>req-1</RequestId></ResponseMetadata>
+</DescribeDBInstancesResponse>`
+
+const rdsSecondPage = `<DescribeDBInstancesResponse xmlns="http://rds.amazonaws.com/doc/2014-10-31/">
+  <DescribeDBInstancesResult>
+    <DBInstances>
+      <DBInstance>
+        <DBInstanceIdentifier>creating-db</DBInstanceIdentifier>
+        <Engine>postgres</Engine>
+        <EngineVersion>16.1</EngineVersion>
+        <DBInstanceStatus>creating</DBInstanceStatus>
+      </DBInstance>
+    </DBInstances>
+  </DescribeDBInstancesResult>
+  <ResponseMetadata><RequestId>req-2</RequestId></ResponseMetadata>
+</DescribeDBInstancesResponse>`
+
+// rdsHandler 按 Marker 返回两页 RDS 实例
+func rdsHandler(req fakeRequest) (int, string) {
+	if req.Action != "DescribeDBInstances" {
+		return http.StatusBadRequest, `<ErrorResponse><Error><Code>InvalidAction</Code></Error></ErrorResponse>`
+	}
+	if req.Params["Marker"] == "marker-2" {
+		return http.StatusOK, rdsSecondPage
+	}
+	return http.StatusOK, rdsFirstPage
+}
+
+func TestListRDSInstancesPaginatesAndMapsFields(t *testing.T) {
+	server := newFakeAWS(t, rdsHandler)
+	p := newTestProvider(t, server.URL, "us-east-1")
+
+	databases, err := p.ListDatabases(context.Background(), &provider.QueryOptions{Filters: map[string]string{"engine": "mysql"}})
+	if err != nil {
+		t.Fatalf("ListDatabases() error = %v", err)
+	}
+	if len(databases) != 2 {
+		t.Fatalf("ListDatabases() returned %d databases, want 2 across pages", len(databases))
+	}
+
+	requests := server.requestsFor("DescribeDBInstances")
+	if len(requests) != 2 || requests[1].Params["Marker"] != "marker-2" {
+		t.Errorf("DescribeDBInstances requests = %+v, want second page with Marker marker-2", requests)
+	}
+	if requests[0].Service != "rds" || requests[0].Params["Filters.Filter.1.Name"] != "engine" ||
+		requests[0].Params["Filters.Filter.1.Values.Value.1"] != "mysql" {
+		t.Errorf("DescribeDBInstances request = %+v, want engine filter", requests[0])
+	}
+
+	want := &model.Database{
+		ID:            "orders-db",
+		Name:          "orders-db",
+		Provider:      "aws",
+		Region:        "us-east-1",
+		Engine:        "mysql",
+		EngineVersion: "8.0.35",
+		Status:        "available",
+		Endpoint:      "orders-db.abc.us-east-1.rds.amazonaws.com",
+		Port:          3306,
+		CreatedAt:     time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC),
+		Tags:          map[string]string{"env": "prod"},
+		ConsoleURL:    "https://us-east-1.console.aws.amazon.com/rds/home?region=us-east-1#database:id=orders-db",
+	}
+	if !reflect.DeepEqual(databases[0], want) {
+		t.Errorf("first database = %+v\nwant %+v", databases[0], want)
+	}
+
+	// 创建中的实例还没有连接地址
+	creating := databases[1]
+	if creating.ID != "creating-db" || creating.Engine != "postgres" || creating.Endpoint != "" || creating.Port != 0 {
+		t.Errorf("second database = %+v, want creating-db without endpoint", creating)
+	}
+}
+
+func TestListRDSInstancesAcrossRegions(t *testing.T) {
+	server := newFakeAWS(t, rdsHandler)
+	p := newTestProvider(t, server.URL, "us-east-1", "us-west-2")
+
+	// 两个区域各两条, 内存分页取第二页
+	databases, err := p.ListDatabases(context.Background(), &provider.QueryOptions{PageSize: 3, PageNum: 2})
+	if err != nil {
+		t.Fatalf("ListDatabases() error = %v", err)
+	}
+	if len(databases) != 1 || databases[0].ID != "creating-db" || databases[0].Region != "us-west-2" {
+		t.Fatalf("ListDatabases() page 2 = %+v, want creating-db in us-west-2", databases)
+	}
+}
@@ -0,0 +1,125 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/eryajf/zenops/internal/model"
+)
+
+// ListS3Buckets 查询 S3 Bucket 列表
+func (c *Client) ListS3Buckets(ctx context.Context, pageSize, pageNum int, filters map[string]string) ([]*model.OSSBucket, error) {
+	s3Client := c.GetS3Client()
+
+	input := &s3.ListBucketsInput{}
+	if prefix, ok := filters["prefix"]; ok && prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+
+	logx.Debug("Querying AWS S3 buckets, page_size %d, page_num %d", pageSize, pageNum)
+
+	var buckets []*model.OSSBucket
+	paginator := s3.NewListBucketsPaginator(s3Client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list buckets: %w", err)
+		}
+
+		for _, bucket := range page.Buckets {
+			buckets = append(buckets, convertS3Bucket(bucket, aws.ToString(bucket.BucketRegion)))
+		}
+	}
+
+	logx.Debug("S3 API response - Buckets count: %d", len(buckets))
+
+	return paginate(buckets, pageSize, pageNum), nil
+}
+
+// GetS3Bucket 获取 S3 Bucket 详情
+func (c *Client) GetS3Bucket(ctx context.Context, bucketName string) (*model.OSSBucket, error) {
+	s3Client := c.GetS3Client()
+
+	// 通过 ListBuckets 的前缀过滤获取创建时间等基础信息
+	var found *types.Bucket
+	paginator := s3.NewListBucketsPaginator(s3Client, &s3.ListBucketsInput{Prefix: aws.String(bucketName)})
+	for paginator.HasMorePages() && found == nil {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list buckets: %w", err)
+		}
+		for i := range page.Buckets {
+			if aws.ToString(page.Buckets[i].Name) == bucketName {
+				found = &page.Buckets[i]
+				break
+			}
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("bucket %s not found", bucketName)
+	}
+
+	region := aws.ToString(found.BucketRegion)
+	if region == "" {
+		location, err := s3Client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{Bucket: aws.String(bucketName)})
+		if err != nil {
+			logx.Warn("Failed to get bucket location, bucket %s, error %v", bucketName, err)
+		} else {
+			region = string(location.LocationConstraint)
+			// us-east-1 的 LocationConstraint 为空
+			if region == "" {
+				region = "us-east-1"
+			}
+		}
+	}
+
+	bucket := convertS3Bucket(*found, region)
+
+	// 访问控制: 检查公共访问阻止配置
+	publicAccess, err := s3Client.GetPublicAccessBlock(ctx, &s3.GetPublicAccessBlockInput{Bucket: aws.String(bucketName)})
+	if err == nil && publicAccess.PublicAccessBlockConfiguration != nil {
+		cfg := publicAccess.PublicAccessBlockConfiguration
+		if aws.ToBool(cfg.BlockPublicAcls) && aws.ToBool(cfg.BlockPublicPolicy) &&
+			aws.ToBool(cfg.IgnorePublicAcls) && aws.ToBool(cfg.RestrictPublicBuckets) {
+			bucket.ACL = "private"
+		} else {
+			bucket.ACL = "public-access-allowed"
+		}
+	}
+
+	// 版本控制
+	versioning, err := s3Client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{Bucket: aws.String(bucketName)})
+	if err == nil && versioning.Status != "" {
+		bucket.Metadata["versioning"] = string(versioning.Status)
+	}
+
+	return bucket, nil
+}
+
+// convertS3Bucket 将 S3 Bucket 转换为统一的 OSSBucket 模型
+func convertS3Bucket(bucket types.Bucket, region string) *model.OSSBucket {
+	name := aws.ToString(bucket.Name)
+
+	result := &model.OSSBucket{
+		Name:       name,
+		Provider:   "aws",
+		Region:     region,
+		Metadata:   make(map[string]any),
+		ConsoleURL: fmt.Sprintf("https://s3.console.aws.amazon.com/s3/buckets/%s", name),
+	}
+
+	if bucket.CreationDate != nil {
+		result.CreatedAt = bucket.CreationDate.Format("2006-01-02 15:04:05")
+	}
+
+	if region != "" {
+		result.ConsoleURL += "?region=" + region
+	}
+
+	return result
+}
@@ -0,0 +1,100 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/eryajf/zenops/internal/provider"
+)
+
+const s3FirstPage = `<ListAllMyBucketsResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Owner><ID>owner</ID></Owner>
+  <Buckets>
+    <Bucket><Name>logs-a</Name><CreationDate>2024-01-02T03:04:05.000Z</CreationDate><BucketRegion>us-east-1</BucketRegion></Bucket>
+    <Bucket><Name>logs-b</Name><CreationDate>2024-01-03T03:04:05.000Z</CreationDate><BucketRegion>eu-west-1</BucketRegion></Bucket>
+  </Buckets>
+  <ContinuationToken>token-2</ContinuationToken>
+</ListAllMyBucketsResult>`
+
+const s3SecondPage = `<ListAllMyBucketsResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Owner><ID>owner</ID></Owner>
+  <Buckets>
+    <Bucket><Name>logs-c</Name><CreationDate>2024-01-04T03:04:05.000Z</CreationDate></Bucket>
+  </Buckets>
+</ListAllMyBucketsResult>`
+
+// s3Handler 按 continuation-token 返回两页 Bucket
+func s3Handler(req fakeRequest) (int, string) {
+	if req.Action != "GET /" {
+		return http.StatusNotFound, `<Error><Code>NoSuchKey</Code></Error>`
+	}
+	if req.Params["continuation-token"] == "token-2" {
+		return http.StatusOK, s3SecondPage
+	}
+	return http.StatusOK, s3FirstPage
+}
+
+func TestListS3BucketsPaginatesAndMapsFields(t *testing.T) {
+	server := newFakeAWS(t, s3Handler)
+	p := newTestProvider(t, server.URL, "us-west-2", "us-east-1")
+
+	buckets, err := p.ListOSSBuckets(context.Background(), &provider.QueryOptions{Filters: map[string]string{"prefix": "logs-"}})
+	if err != nil {
+		t.Fatalf("ListOSSBuckets() error = %v", err)
+	}
+	if len(buckets) != 3 {
+		t.Fatalf("ListOSSBuckets() returned %d buckets, want 3 across pages", len(buckets))
+	}
+
+	requests := server.requestsFor("GET /")
+	if len(requests) != 2 || requests[1].Params["continuation-token"] != "token-2" {
+		t.Errorf("ListBuckets requests = %+v, want second page with continuation-token", requests)
+	}
+	// ListBuckets 是全局接口, 只使用第一个配置的区域
+	if requests[0].Service != "s3" || requests[0].Region != "us-west-2" || requests[0].Params["prefix"] != "logs-" {
+		t.Errorf("ListBuckets request = %+v, want s3 in us-west-2 with prefix", requests[0])
+	}
+
+	tests := []struct {
+		name       string
+		region     string
+		createdAt  string
+		consoleURL string
+	}{
+		{name: "logs-a", region: "us-east-1", createdAt: "2024-01-02 03:04:05", consoleURL: "https://s3.console.aws.amazon.com/s3/buckets/logs-a?region=us-east-1"},
+		{name: "logs-b", region: "eu-west-1", createdAt: "2024-01-03 03:04:05", consoleURL: "https://s3.console.aws.amazon.com/s3/buckets/logs-b?region=eu-west-1"},
+		{name: "logs-c", region: "", createdAt: "2024-01-04 03:04:05", consoleURL: "https://s3.console.aws.amazon.com/s3/buckets/logs-c"},
+	}
+	for i, tt := range tests {
+		bucket := buckets[i]
+		if bucket.Name != tt.name || bucket.Provider != "aws" || bucket.Region != tt.region ||
+			bucket.CreatedAt != tt.createdAt || bucket.ConsoleURL != tt.consoleURL {
+			t.Errorf("bucket %d = %+v, want %s in %q created %s", i, bucket, tt.name, tt.region, tt.createdAt)
+		}
+	}
+}
+
+func TestListS3BucketsPageSlice(t *testing.T) {
+	server := newFakeAWS(t, s3Handler)
+	p := newTestProvider(t, server.URL, "us-east-1")
+
+	buckets, err := p.ListOSSBuckets(context.Background(), &provider.QueryOptions{PageSize: 2, PageNum: 2})
+	if err != nil {
+		t.Fatalf("ListOSSBuckets() error = %v", err)
+	}
+	if len(buckets) != 1 || buckets[0].Name != "logs-c" {
+		t.Fatalf("ListOSSBuckets() page 2 = %+v, want logs-c", buckets)
+	}
+}
+
+func TestListS3BucketsError(t *testing.T) {
+	server := newFakeAWS(t, func(req fakeRequest) (int, string) {
+		return http.StatusForbidden, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`
+	})
+	p := newTestProvider(t, server.URL, "us-east-1")
+
+	if _, err := p.ListOSSBuckets(context.Background(), nil); err == nil {
+		t.Fatalf("ListOSSBuckets() error = nil, want AccessDenied")
+	}
+}
@@ -0,0 +1,93 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL github.com 的 API 地址
+	DefaultBaseURL = "https://api.github.com"
+	// defaultTimeout 单次 API 请求超时时间
+	defaultTimeout = 30 * time.Second
+)
+
+// Client GitHub REST API 客户端
+type Client struct {
+	BaseURL    string
+	Token      string
+	httpClient *http.Client
+}
+
+// NewClient 创建 GitHub 客户端
+// baseURL 为空时使用 github.com, GitHub Enterprise 可传入 https://github.example.com 或 https://github.example.com/api/v3
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		BaseURL: normalizeBaseURL(baseURL),
+		Token:   token,
+		httpClient: &http.Client{
+			Timeout: defaultTimeout,
+		},
+	}
+}
+
+// get 发送 GET 请求并将响应解析到 out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	endpoint := c.BaseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request github: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("github api %s returned status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode github response: %w", err)
+	}
+
+	return nil
+}
+
+// normalizeBaseURL 规范化 API 地址
+// GitHub Enterprise Server 的 REST API 位于 /api/v3, 只填写实例地址时自动补全
+func normalizeBaseURL(baseURL string) string {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return DefaultBaseURL
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return baseURL
+	}
+
+	if u.Host != "api.github.com" && (u.Path == "" || u.Path == "/") {
+		return baseURL + "/api/v3"
+	}
+
+	return baseURL
+}
@@ -0,0 +1,171 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitHub 模拟 GitHub Enterprise 的 REST API (/api/v3), 记录收到的请求
+type fakeGitHub struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []*url.URL
+}
+
+const testRunsJSON = `{
+  "total_count": 2,
+  "workflow_runs": [
+    {
+      "id": 1002, "run_number": 58, "status": "completed", "conclusion": "failure", "event": "push",
+      "head_branch": "main", "head_sha": "abc123", "html_url": "https://github.example.com/acme/app/actions/runs/1002",
+      "created_at": "2024-06-01T10:00:00Z", "updated_at": "2024-06-01T10:05:30Z", "run_started_at": "2024-06-01T10:00:30Z",
+      "actor": {"login": "alice"}, "triggering_actor": {"login": "bob"}
+    },
+    {
+      "id": 1001, "run_number": 57, "status": "in_progress", "conclusion": null, "event": "workflow_dispatch",
+      "head_branch": "release", "head_sha": "def456", "html_url": "https://github.example.com/acme/app/actions/runs/1001",
+      "created_at": "2024-06-01T09:00:00Z", "updated_at": "2024-06-01T09:03:00Z",
+      "actor": {"login": "carol"}
+    }
+  ]
+}`
+
+func newFakeGitHub(t *testing.T) *fakeGitHub {
+	t.Helper()
+	f := &fakeGitHub{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.requests = append(f.requests, r.URL)
+		f.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"Bad credentials"}`))
+			return
+		}
+		if r.Header.Get("Accept") != "application/vnd.github+json" || r.Header.Get("X-GitHub-Api-Version") == "" {
+			t.Errorf("missing github headers: %v", r.Header)
+		}
+
+		switch r.URL.Path {
+		case "/api/v3/user":
+			w.Write([]byte(`{"login":"zenops-bot"}`))
+		case "/api/v3/repos/acme/app/actions/workflows":
+			w.Write([]byte(`{"total_count": 2, "workflows": [
+				{"id": 11, "name": "CI", "path": ".github/workflows/ci.yml", "state": "active", "html_url": "https://github.example.com/acme/app/actions/workflows/ci.yml"},
+				{"id": 12, "name": "Nightly", "path": "", "state": "disabled_manually", "html_url": "https://github.example.com/acme/app/actions/workflows/12"}
+			]}`))
+		case "/api/v3/repos/acme/app/actions/workflows/ci.yml":
+			w.Write([]byte(`{"id": 11, "name": "CI", "path": ".github/workflows/ci.yml", "state": "active", "html_url": "https://github.example.com/acme/app/actions/workflows/ci.yml"}`))
+		case "/api/v3/repos/acme/app/actions/workflows/ci.yml/runs", "/api/v3/repos/acme/app/actions/runs":
+			w.Write([]byte(testRunsJSON))
+		case "/api/v3/repos/acme/broken/actions/workflows":
+			w.Write([]byte(`{"workflows": "not-a-list"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not Found"}`))
+		}
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// lastRequest 返回最后一次请求的地址
+func (f *fakeGitHub) lastRequest() *url.URL {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.requests) == 0 {
+		return nil
+	}
+	return f.requests[len(f.requests)-1]
+}
+
+// newTestProvider 创建指向模拟服务的 GitHub Provider, 地址按 Enterprise 实例地址填写
+func newTestProvider(t *testing.T, baseURL string) *GitHubProvider {
+	t.Helper()
+	p := NewGitHubProvider().(*GitHubProvider)
+	if err := p.Initialize(map[string]any{"url": baseURL, "token": "test-token"}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	return p
+}
+
+func TestNormalizeBaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{baseURL: "", want: DefaultBaseURL},
+		{baseURL: "https://api.github.com/", want: "https://api.github.com"},
+		{baseURL: "https://github.example.com", want: "https://github.example.com/api/v3"},
+		{baseURL: " https://github.example.com/ ", want: "https://github.example.com/api/v3"},
+		{baseURL: "https://github.example.com/api/v3", want: "https://github.example.com/api/v3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			if got := normalizeBaseURL(tt.baseURL); got != tt.want {
+				t.Errorf("normalizeBaseURL(%q) = %q, want %q", tt.baseURL, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitialize(t *testing.T) {
+	p := NewGitHubProvider().(*GitHubProvider)
+	if err := p.Initialize(map[string]any{"url": "https://github.example.com"}); err == nil {
+		t.Errorf("Initialize() without token error = nil, want token is required")
+	}
+
+	if err := p.Initialize(map[string]any{"token": "test-token"}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if p.client.BaseURL != DefaultBaseURL {
+		t.Errorf("base url = %q, want %q", p.client.BaseURL, DefaultBaseURL)
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	server := newFakeGitHub(t)
+
+	if err := newTestProvider(t, server.URL).HealthCheck(context.Background()); err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+
+	// 错误的 Token 返回状态码和响应内容
+	p := NewGitHubProvider().(*GitHubProvider)
+	p.Initialize(map[string]any{"url": server.URL, "token": "wrong"})
+	err := p.HealthCheck(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("HealthCheck() with wrong token error = %v, want 401 Bad credentials", err)
+	}
+
+	if err := NewGitHubProvider().HealthCheck(context.Background()); err == nil {
+		t.Errorf("HealthCheck() before Initialize error = nil")
+	}
+}
+
+func TestClientGetDecodeError(t *testing.T) {
+	server := newFakeGitHub(t)
+	client := NewClient(server.URL, "test-token")
+
+	var out struct {
+		Workflows []githubWorkflow `json:"workflows"`
+	}
+	err := client.get(context.Background(), "/repos/acme/broken/actions/workflows", nil, &out)
+	var typeErr *json.UnmarshalTypeError
+	if err == nil || !strings.Contains(err.Error(), "decode") {
+		t.Fatalf("get() error = %v, want decode error", err)
+	}
+	if !errors.As(err, &typeErr) {
+		t.Errorf("get() error = %v, want wrapped json.UnmarshalTypeError", err)
+	}
+}
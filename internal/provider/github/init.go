@@ -0,0 +1,7 @@
+package github
+
+import "github.com/eryajf/zenops/internal/provider"
+
+func init() {
+	provider.RegisterCICD("github", NewGitHubProvider())
+}
@@ -0,0 +1,93 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+)
+
+// GitHubProvider GitHub Actions Provider
+// 工作流映射为 model.Job, 工作流运行映射为 model.Build
+// Job 名称格式为 "owner/repo/workflow", workflow 为工作流文件名 (如 ci.yml) 或 ID
+type GitHubProvider struct {
+	name   string
+	client *Client
+}
+
+// NewGitHubProvider 创建 GitHub Provider
+func NewGitHubProvider() provider.CICDProvider {
+	return &GitHubProvider{
+		name: "github",
+	}
+}
+
+// GetName 获取 Provider 名称
+func (p *GitHubProvider) GetName() string {
+	return p.name
+}
+
+// Initialize 初始化 Provider
+func (p *GitHubProvider) Initialize(config map[string]any) error {
+	// url 为可选配置, 用于 GitHub Enterprise
+	url, _ := config["url"].(string)
+
+	token, ok := config["token"].(string)
+	if !ok || token == "" {
+		return fmt.Errorf("token is required")
+	}
+
+	// 创建客户端
+	p.client = NewClient(url, token)
+
+	logx.Info("GitHub Provider initialized, url %s", p.client.BaseURL)
+
+	return nil
+}
+
+// GetJobBuilds 实现 CICDProvider 接口, 返回工作流最近的运行记录
+func (p *GitHubProvider) GetJobBuilds(ctx context.Context, jobName string, limit int) ([]*model.Build, error) {
+	opts := &provider.QueryOptions{
+		PageSize: limit,
+		PageNum:  1,
+	}
+	return p.ListRuns(ctx, jobName, opts)
+}
+
+// HealthCheck 健康检查
+func (p *GitHubProvider) HealthCheck(ctx context.Context) error {
+	if p.client == nil {
+		return fmt.Errorf("client not initialized")
+	}
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := p.client.get(ctx, "/user", nil, &user); err != nil {
+		return err
+	}
+
+	logx.Debug("Health check passed, user %s", user.Login)
+	return nil
+}
+
+// splitRepo 校验并拆分 "owner/repo"
+func splitRepo(repo string) (string, string, error) {
+	parts := strings.Split(strings.Trim(repo, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid repository '%s', expected owner/repo", repo)
+	}
+	return parts[0], parts[1], nil
+}
+
+// splitWorkflow 拆分 "owner/repo/workflow", 返回仓库和工作流标识
+func splitWorkflow(jobName string) (string, string, error) {
+	parts := strings.Split(strings.Trim(jobName, "/"), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", fmt.Errorf("invalid workflow '%s', expected owner/repo/workflow", jobName)
+	}
+	return parts[0] + "/" + parts[1], parts[2], nil
+}
@@ -0,0 +1,134 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+)
+
+// githubActor GitHub API 返回的用户结构
+type githubActor struct {
+	Login string `json:"login"`
+}
+
+// githubRun GitHub API 返回的工作流运行结构
+type githubRun struct {
+	ID              int64        `json:"id"`
+	RunNumber       int          `json:"run_number"`
+	Status          string       `json:"status"`     // queued, in_progress, completed 等
+	Conclusion      string       `json:"conclusion"` // success, failure, cancelled, timed_out 等, 未结束时为空
+	Event           string       `json:"event"`
+	HeadBranch      string       `json:"head_branch"`
+	HeadSHA         string       `json:"head_sha"`
+	HTMLURL         string       `json:"html_url"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+	RunStartedAt    *time.Time   `json:"run_started_at"`
+	Actor           *githubActor `json:"actor"`
+	TriggeringActor *githubActor `json:"triggering_actor"`
+}
+
+// ListRuns 列出工作流运行记录 (按时间倒序)
+// target 为 owner/repo (仓库下所有工作流) 或 owner/repo/workflow (指定工作流)
+// 支持的过滤条件: branch, status (如 success, failure, in_progress), event (如 push, schedule)
+func (p *GitHubProvider) ListRuns(ctx context.Context, target string, opts *provider.QueryOptions) ([]*model.Build, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	var path string
+	if strings.Count(strings.Trim(target, "/"), "/") == 1 {
+		owner, repo, err := splitRepo(target)
+		if err != nil {
+			return nil, err
+		}
+		path = fmt.Sprintf("/repos/%s/%s/actions/runs", owner, repo)
+	} else {
+		repo, workflowID, err := splitWorkflow(target)
+		if err != nil {
+			return nil, err
+		}
+		path = fmt.Sprintf("/repos/%s/actions/workflows/%s/runs", repo, url.PathEscape(workflowID))
+	}
+
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	pageNum := opts.PageNum
+	if pageNum <= 0 {
+		pageNum = 1
+	}
+
+	query := url.Values{}
+	query.Set("per_page", strconv.Itoa(pageSize))
+	query.Set("page", strconv.Itoa(pageNum))
+	for _, key := range []string{"branch", "status", "event"} {
+		if v := opts.Filters[key]; v != "" {
+			query.Set(key, strings.ToLower(v))
+		}
+	}
+
+	var resp struct {
+		TotalCount   int         `json:"total_count"`
+		WorkflowRuns []githubRun `json:"workflow_runs"`
+	}
+	if err := p.client.get(ctx, path, query, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list workflow runs of '%s': %w", target, err)
+	}
+
+	logx.Debug("Fetched GitHub workflow runs, target %s, count %d", target, len(resp.WorkflowRuns))
+
+	result := make([]*model.Build, 0, len(resp.WorkflowRuns))
+	for i := range resp.WorkflowRuns {
+		result = append(result, convertRunToModel(&resp.WorkflowRuns[i]))
+	}
+
+	return result, nil
+}
+
+// convertRunToModel 将 GitHub 工作流运行转换为统一的 Build 模型
+func convertRunToModel(run *githubRun) *model.Build {
+	build := &model.Build{
+		Number:  run.RunNumber,
+		Result:  strings.ToUpper(run.Conclusion),
+		URL:     run.HTMLURL,
+		Ref:     run.HeadBranch,
+		Commit:  run.HeadSHA,
+		Trigger: run.Event,
+	}
+
+	// 已结束的运行以结论作为状态, 与 Jenkins 保持一致
+	if run.Status == "completed" && run.Conclusion != "" {
+		build.Status = build.Result
+	} else {
+		build.Status = strings.ToUpper(run.Status)
+	}
+
+	// 重新运行时 triggering_actor 为实际触发人, actor 为最初触发人
+	if run.TriggeringActor != nil && run.TriggeringActor.Login != "" {
+		build.Actor = run.TriggeringActor.Login
+	} else if run.Actor != nil {
+		build.Actor = run.Actor.Login
+	}
+
+	started := run.CreatedAt
+	if run.RunStartedAt != nil && !run.RunStartedAt.IsZero() {
+		started = *run.RunStartedAt
+	}
+	build.Timestamp = started
+
+	// 运行结束后 updated_at 即为结束时间
+	if run.Status == "completed" && run.UpdatedAt.After(started) {
+		build.Duration = run.UpdatedAt.Sub(started).Milliseconds()
+	}
+
+	return build
+}
@@ -0,0 +1,112 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eryajf/zenops/internal/provider"
+)
+
+func TestListRuns(t *testing.T) {
+	server := newFakeGitHub(t)
+	p := newTestProvider(t, server.URL)
+
+	tests := []struct {
+		name   string
+		target string
+		path   string
+	}{
+		{name: "repository", target: "acme/app", path: "/api/v3/repos/acme/app/actions/runs"},
+		{name: "workflow", target: "acme/app/ci.yml", path: "/api/v3/repos/acme/app/actions/workflows/ci.yml/runs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, err := p.ListRuns(context.Background(), tt.target, &provider.QueryOptions{
+				PageSize: 5,
+				PageNum:  2,
+				Filters:  map[string]string{"branch": "main", "status": "FAILURE"},
+			})
+			if err != nil {
+				t.Fatalf("ListRuns() error = %v", err)
+			}
+			if len(runs) != 2 {
+				t.Fatalf("ListRuns() returned %d runs, want 2", len(runs))
+			}
+
+			request := server.lastRequest()
+			query := request.Query()
+			if request.Path != tt.path {
+				t.Errorf("request path = %s, want %s", request.Path, tt.path)
+			}
+			// 状态过滤条件统一转为小写, 空的过滤条件不发送
+			if query.Get("per_page") != "5" || query.Get("page") != "2" || query.Get("branch") != "main" ||
+				query.Get("status") != "failure" || query.Has("event") {
+				t.Errorf("request query = %v", query)
+			}
+		})
+	}
+}
+
+func TestListRunsMapsBuilds(t *testing.T) {
+	server := newFakeGitHub(t)
+	p := newTestProvider(t, server.URL)
+
+	runs, err := p.GetJobBuilds(context.Background(), "acme/app/ci.yml", 2)
+	if err != nil {
+		t.Fatalf("GetJobBuilds() error = %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("GetJobBuilds() returned %d runs, want 2", len(runs))
+	}
+
+	// 已结束的运行: 状态取结论, 触发人取 triggering_actor, 耗时从 run_started_at 计算
+	completed := runs[0]
+	if completed.Number != 58 || completed.Status != "FAILURE" || completed.Result != "FAILURE" {
+		t.Errorf("completed run = %+v, want run 58 FAILURE", completed)
+	}
+	if completed.Actor != "bob" || completed.Trigger != "push" || completed.Ref != "main" || completed.Commit != "abc123" {
+		t.Errorf("completed run actor = %s, trigger = %s, ref = %s, commit = %s", completed.Actor, completed.Trigger, completed.Ref, completed.Commit)
+	}
+	if !completed.Timestamp.Equal(time.Date(2024, 6, 1, 10, 0, 30, 0, time.UTC)) || completed.Duration != 300000 {
+		t.Errorf("completed run timestamp = %v, duration = %d, want started 10:00:30 and 300000ms", completed.Timestamp, completed.Duration)
+	}
+	if completed.URL != "https://github.example.com/acme/app/actions/runs/1002" {
+		t.Errorf("completed run url = %s", completed.URL)
+	}
+
+	// 运行中: 状态取 status, 没有结论和耗时, 开始时间回退到 created_at
+	running := runs[1]
+	if running.Status != "IN_PROGRESS" || running.Result != "" || running.Duration != 0 || running.Actor != "carol" {
+		t.Errorf("running run = %+v, want IN_PROGRESS by carol without duration", running)
+	}
+	if !running.Timestamp.Equal(time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("running run timestamp = %v, want created_at", running.Timestamp)
+	}
+}
+
+func TestListRunsErrors(t *testing.T) {
+	server := newFakeGitHub(t)
+	p := newTestProvider(t, server.URL)
+
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{name: "invalid target", target: "acme", want: "expected owner/repo/workflow"},
+		{name: "too many segments", target: "acme/app/ci.yml/extra", want: "expected owner/repo/workflow"},
+		{name: "not found", target: "acme/app/missing.yml", want: "status 404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.ListRuns(context.Background(), tt.target, &provider.QueryOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ListRuns(%q) error = %v, want %q", tt.target, err, tt.want)
+			}
+		})
+	}
+}
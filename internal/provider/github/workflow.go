@@ -0,0 +1,121 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+)
+
+// githubWorkflow GitHub API 返回的工作流结构
+type githubWorkflow struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+}
+
+// ListJobs 列出仓库中的工作流
+// 必须通过 opts.Filters["repo"] 指定仓库, 格式为 owner/repo
+func (p *GitHubProvider) ListJobs(ctx context.Context, opts *provider.QueryOptions) ([]*model.Job, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	owner, repo, err := splitRepo(opts.Filters["repo"])
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = 30
+	}
+	pageNum := opts.PageNum
+	if pageNum <= 0 {
+		pageNum = 1
+	}
+
+	query := url.Values{}
+	query.Set("per_page", strconv.Itoa(pageSize))
+	query.Set("page", strconv.Itoa(pageNum))
+
+	var resp struct {
+		TotalCount int              `json:"total_count"`
+		Workflows  []githubWorkflow `json:"workflows"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/actions/workflows", owner, repo)
+	if err := p.client.get(ctx, path, query, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+
+	logx.Debug("Fetched GitHub workflows, repo %s/%s, count %d", owner, repo, len(resp.Workflows))
+
+	result := make([]*model.Job, 0, len(resp.Workflows))
+	for i := range resp.Workflows {
+		result = append(result, convertWorkflowToModel(owner+"/"+repo, &resp.Workflows[i]))
+	}
+
+	return result, nil
+}
+
+// GetJob 获取工作流详情, 并附带最近一次运行
+// jobName 格式为 owner/repo/workflow
+func (p *GitHubProvider) GetJob(ctx context.Context, jobName string) (*model.Job, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	repo, workflowID, err := splitWorkflow(jobName)
+	if err != nil {
+		return nil, err
+	}
+
+	var workflow githubWorkflow
+	path := fmt.Sprintf("/repos/%s/actions/workflows/%s", repo, url.PathEscape(workflowID))
+	if err := p.client.get(ctx, path, nil, &workflow); err != nil {
+		return nil, fmt.Errorf("failed to get workflow '%s': %w", jobName, err)
+	}
+
+	job := convertWorkflowToModel(repo, &workflow)
+
+	runs, err := p.ListRuns(ctx, jobName, &provider.QueryOptions{PageSize: 1, PageNum: 1})
+	if err != nil {
+		logx.Warn("Failed to get latest run, workflow %s, error %v", jobName, err)
+	} else if len(runs) > 0 {
+		job.LastBuild = runs[0]
+	}
+
+	logx.Info("Fetched GitHub workflow, name %s", jobName)
+
+	return job, nil
+}
+
+// convertWorkflowToModel 将 GitHub 工作流转换为统一的 Job 模型
+func convertWorkflowToModel(repo string, workflow *githubWorkflow) *model.Job {
+	return &model.Job{
+		Name:        repo + "/" + workflowFileName(workflow),
+		DisplayName: workflow.Name,
+		Description: workflow.Path,
+		URL:         workflow.HTMLURL,
+		Buildable:   workflow.State == "active",
+	}
+}
+
+// workflowFileName 返回工作流文件名 (如 ci.yml), 可直接作为 API 中的 workflow_id 使用
+func workflowFileName(workflow *githubWorkflow) string {
+	for i := len(workflow.Path) - 1; i >= 0; i-- {
+		if workflow.Path[i] == '/' {
+			return workflow.Path[i+1:]
+		}
+	}
+	if workflow.Path != "" {
+		return workflow.Path
+	}
+	return strconv.FormatInt(workflow.ID, 10)
+}
@@ -0,0 +1,89 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/eryajf/zenops/internal/provider"
+)
+
+func TestListJobs(t *testing.T) {
+	server := newFakeGitHub(t)
+	p := newTestProvider(t, server.URL)
+
+	jobs, err := p.ListJobs(context.Background(), &provider.QueryOptions{Filters: map[string]string{"repo": "acme/app"}})
+	if err != nil {
+		t.Fatalf("ListJobs() error = %v", err)
+	}
+	if query := server.lastRequest().Query(); query.Get("per_page") != "30" || query.Get("page") != "1" {
+		t.Errorf("ListJobs() query = %v, want default per_page 30 and page 1", query)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("ListJobs() returned %d jobs, want 2", len(jobs))
+	}
+
+	ci := jobs[0]
+	if ci.Name != "acme/app/ci.yml" || ci.DisplayName != "CI" || ci.Description != ".github/workflows/ci.yml" || !ci.Buildable {
+		t.Errorf("first job = %+v, want acme/app/ci.yml", ci)
+	}
+	// 没有文件路径的工作流使用 ID 作为名称, 禁用的工作流不可构建
+	if nightly := jobs[1]; nightly.Name != "acme/app/12" || nightly.Buildable {
+		t.Errorf("second job = %+v, want acme/app/12 not buildable", nightly)
+	}
+}
+
+func TestListJobsErrors(t *testing.T) {
+	server := newFakeGitHub(t)
+	p := newTestProvider(t, server.URL)
+
+	tests := []struct {
+		name string
+		repo string
+		want string
+	}{
+		{name: "missing repo", repo: "", want: "expected owner/repo"},
+		{name: "invalid repo", repo: "acme/app/ci.yml", want: "expected owner/repo"},
+		{name: "not found", repo: "acme/missing", want: "status 404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.ListJobs(context.Background(), &provider.QueryOptions{Filters: map[string]string{"repo": tt.repo}})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ListJobs(%q) error = %v, want %q", tt.repo, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewGitHubProvider().(*GitHubProvider).ListJobs(context.Background(), &provider.QueryOptions{}); err == nil {
+		t.Errorf("ListJobs() before Initialize error = nil")
+	}
+}
+
+func TestGetJob(t *testing.T) {
+	server := newFakeGitHub(t)
+	p := newTestProvider(t, server.URL)
+
+	job, err := p.GetJob(context.Background(), "acme/app/ci.yml")
+	if err != nil {
+		t.Fatalf("GetJob() error = %v", err)
+	}
+	if job.Name != "acme/app/ci.yml" || job.DisplayName != "CI" {
+		t.Errorf("GetJob() = %+v", job)
+	}
+	// 附带最近一次运行
+	if job.LastBuild == nil || job.LastBuild.Number != 58 {
+		t.Errorf("last build = %+v, want run 58", job.LastBuild)
+	}
+	if query := server.lastRequest().Query(); query.Get("per_page") != "1" {
+		t.Errorf("latest run query = %v, want per_page 1", query)
+	}
+
+	if _, err := p.GetJob(context.Background(), "acme/app"); err == nil || !strings.Contains(err.Error(), "owner/repo/workflow") {
+		t.Errorf("GetJob(acme/app) error = %v, want invalid workflow", err)
+	}
+	if _, err := p.GetJob(context.Background(), "acme/app/missing.yml"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("GetJob(missing) error = %v, want status 404", err)
+	}
+}
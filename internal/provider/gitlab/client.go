@@ -0,0 +1,72 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultTimeout 单次 API 请求超时时间
+const defaultTimeout = 30 * time.Second
+
+// Client GitLab REST API (v4) 客户端
+type Client struct {
+	URL        string
+	Token      string
+	httpClient *http.Client
+}
+
+// NewClient 创建 GitLab 客户端
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		URL:   strings.TrimRight(baseURL, "/"),
+		Token: token,
+		httpClient: &http.Client{
+			Timeout: defaultTimeout,
+		},
+	}
+}
+
+// get 发送 GET 请求并将响应解析到 out, 返回下一页页码 (没有下一页时为 0)
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) (int, error) {
+	endpoint := c.URL + "/api/v4" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.Token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to request gitlab: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return 0, fmt.Errorf("gitlab api %s returned status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return 0, fmt.Errorf("failed to decode gitlab response: %w", err)
+	}
+
+	nextPage, _ := strconv.Atoi(resp.Header.Get("X-Next-Page"))
+	return nextPage, nil
+}
+
+// projectPath 返回项目的 API 路径, 支持项目 ID 或 "group/project" 形式的完整路径
+func projectPath(project string) string {
+	return "/projects/" + url.PathEscape(project)
+}
@@ -0,0 +1,7 @@
+package gitlab
+
+import "github.com/eryajf/zenops/internal/provider"
+
+func init() {
+	provider.RegisterCICD("gitlab", NewGitLabProvider())
+}
@@ -0,0 +1,210 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+)
+
+// maxJobPages 查询流水线作业时最多翻页数, 防止异常数据导致无限请求
+const maxJobPages = 20
+
+// gitlabPipeline GitLab API 返回的流水线结构
+type gitlabPipeline struct {
+	ID         int64      `json:"id"`
+	Status     string     `json:"status"`
+	Ref        string     `json:"ref"`
+	SHA        string     `json:"sha"`
+	WebURL     string     `json:"web_url"`
+	CreatedAt  *time.Time `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	Duration   *float64   `json:"duration"` // 秒, 仅详情接口返回
+}
+
+// gitlabJob GitLab API 返回的作业结构
+type gitlabJob struct {
+	ID            int64      `json:"id"`
+	Name          string     `json:"name"`
+	Stage         string     `json:"stage"`
+	Status        string     `json:"status"`
+	AllowFailure  bool       `json:"allow_failure"`
+	FailureReason string     `json:"failure_reason"`
+	WebURL        string     `json:"web_url"`
+	StartedAt     *time.Time `json:"started_at"`
+	Duration      *float64   `json:"duration"` // 秒
+	Runner        *struct {
+		Description string `json:"description"`
+	} `json:"runner"`
+}
+
+// ListPipelines 列出项目的流水线 (按 ID 倒序)
+// 支持的过滤条件: ref (分支或标签), status (如 success, failed, running)
+func (p *GitLabProvider) ListPipelines(ctx context.Context, project string, opts *provider.QueryOptions) ([]*model.Build, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	pageNum := opts.PageNum
+	if pageNum <= 0 {
+		pageNum = 1
+	}
+
+	query := url.Values{}
+	query.Set("order_by", "id")
+	query.Set("sort", "desc")
+	query.Set("per_page", strconv.Itoa(pageSize))
+	query.Set("page", strconv.Itoa(pageNum))
+	if ref := opts.Filters["ref"]; ref != "" {
+		query.Set("ref", ref)
+	}
+	if status := opts.Filters["status"]; status != "" {
+		query.Set("status", strings.ToLower(status))
+	}
+
+	var pipelines []gitlabPipeline
+	if _, err := p.client.get(ctx, projectPath(project)+"/pipelines", query, &pipelines); err != nil {
+		return nil, fmt.Errorf("failed to list pipelines of '%s': %w", project, err)
+	}
+
+	logx.Debug("Fetched GitLab pipelines, project %s, count %d", project, len(pipelines))
+
+	result := make([]*model.Build, 0, len(pipelines))
+	for i := range pipelines {
+		result = append(result, convertPipelineToModel(&pipelines[i]))
+	}
+
+	return result, nil
+}
+
+// GetPipeline 获取流水线详情
+func (p *GitLabProvider) GetPipeline(ctx context.Context, project string, pipelineID int) (*model.Build, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	var pipeline gitlabPipeline
+	path := fmt.Sprintf("%s/pipelines/%d", projectPath(project), pipelineID)
+	if _, err := p.client.get(ctx, path, nil, &pipeline); err != nil {
+		return nil, fmt.Errorf("failed to get pipeline #%d: %w", pipelineID, err)
+	}
+
+	logx.Info("Fetched GitLab pipeline, project %s, pipeline %d", project, pipelineID)
+
+	return convertPipelineToModel(&pipeline), nil
+}
+
+// ListPipelineJobs 列出流水线中的所有作业, 按执行顺序 (作业 ID 升序) 返回
+func (p *GitLabProvider) ListPipelineJobs(ctx context.Context, project string, pipelineID int) ([]*model.PipelineJob, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	path := fmt.Sprintf("%s/pipelines/%d/jobs", projectPath(project), pipelineID)
+
+	var all []gitlabJob
+	page := 1
+	for i := 0; i < maxJobPages && page > 0; i++ {
+		query := url.Values{}
+		query.Set("per_page", "100")
+		query.Set("page", strconv.Itoa(page))
+
+		var jobs []gitlabJob
+		nextPage, err := p.client.get(ctx, path, query, &jobs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list jobs of pipeline #%d: %w", pipelineID, err)
+		}
+
+		all = append(all, jobs...)
+		page = nextPage
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].ID < all[j].ID
+	})
+
+	result := make([]*model.PipelineJob, 0, len(all))
+	for i := range all {
+		result = append(result, convertJobToModel(&all[i]))
+	}
+
+	return result, nil
+}
+
+// convertPipelineToModel 将 GitLab 流水线转换为统一的 Build 模型
+func convertPipelineToModel(pipeline *gitlabPipeline) *model.Build {
+	build := &model.Build{
+		Number: int(pipeline.ID),
+		Status: strings.ToUpper(pipeline.Status),
+		Result: pipelineResult(pipeline.Status),
+		URL:    pipeline.WebURL,
+		Ref:    pipeline.Ref,
+		Commit: pipeline.SHA,
+	}
+
+	// 时间戳优先使用开始时间, 未开始的流水线使用创建时间
+	if pipeline.StartedAt != nil {
+		build.Timestamp = *pipeline.StartedAt
+	} else if pipeline.CreatedAt != nil {
+		build.Timestamp = *pipeline.CreatedAt
+	}
+
+	if pipeline.Duration != nil {
+		build.Duration = int64(*pipeline.Duration * 1000)
+	}
+
+	return build
+}
+
+// convertJobToModel 将 GitLab 作业转换为统一的 PipelineJob 模型
+func convertJobToModel(job *gitlabJob) *model.PipelineJob {
+	result := &model.PipelineJob{
+		ID:            job.ID,
+		Name:          job.Name,
+		Stage:         job.Stage,
+		Status:        strings.ToUpper(job.Status),
+		AllowFailure:  job.AllowFailure,
+		FailureReason: job.FailureReason,
+		URL:           job.WebURL,
+	}
+
+	if job.StartedAt != nil {
+		result.StartedAt = *job.StartedAt
+	}
+	if job.Duration != nil {
+		result.Duration = int64(*job.Duration * 1000)
+	}
+	if job.Runner != nil {
+		result.Runner = job.Runner.Description
+	}
+
+	return result
+}
+
+// pipelineResult 将已结束流水线的状态映射为与 Jenkins 一致的构建结果, 未结束时返回空
+func pipelineResult(status string) string {
+	switch status {
+	case "success":
+		return "SUCCESS"
+	case "failed":
+		return "FAILURE"
+	case "canceled":
+		return "ABORTED"
+	case "skipped":
+		return "NOT_BUILT"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,106 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+)
+
+// defaultPageSize 未指定分页大小时每页返回的数量
+const defaultPageSize = 20
+
+// gitlabProject GitLab API 返回的项目结构
+type gitlabProject struct {
+	ID                int64  `json:"id"`
+	Name              string `json:"name"`
+	NameWithNamespace string `json:"name_with_namespace"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	Description       string `json:"description"`
+	WebURL            string `json:"web_url"`
+	BuildsAccessLevel string `json:"builds_access_level"`
+	Archived          bool   `json:"archived"`
+}
+
+// ListJobs 列出当前用户有权限的项目
+// 支持的过滤条件: search (按名称搜索)
+func (p *GitLabProvider) ListJobs(ctx context.Context, opts *provider.QueryOptions) ([]*model.Job, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	pageNum := opts.PageNum
+	if pageNum <= 0 {
+		pageNum = 1
+	}
+
+	query := url.Values{}
+	query.Set("membership", "true")
+	query.Set("archived", "false")
+	query.Set("order_by", "last_activity_at")
+	query.Set("per_page", strconv.Itoa(pageSize))
+	query.Set("page", strconv.Itoa(pageNum))
+	if search := opts.Filters["search"]; search != "" {
+		query.Set("search", search)
+	}
+
+	var projects []gitlabProject
+	if _, err := p.client.get(ctx, "/projects", query, &projects); err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	logx.Debug("Fetched GitLab projects, page %d, count %d", pageNum, len(projects))
+
+	result := make([]*model.Job, 0, len(projects))
+	for i := range projects {
+		result = append(result, convertProjectToModel(&projects[i]))
+	}
+
+	return result, nil
+}
+
+// GetJob 获取项目详情, 并附带最近一次流水线
+// jobName 为项目 ID 或完整路径, 如 "group/subgroup/project"
+func (p *GitLabProvider) GetJob(ctx context.Context, jobName string) (*model.Job, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	var project gitlabProject
+	if _, err := p.client.get(ctx, projectPath(jobName), nil, &project); err != nil {
+		return nil, fmt.Errorf("failed to get project '%s': %w", jobName, err)
+	}
+
+	job := convertProjectToModel(&project)
+
+	pipelines, err := p.ListPipelines(ctx, jobName, &provider.QueryOptions{PageSize: 1, PageNum: 1})
+	if err != nil {
+		logx.Warn("Failed to get latest pipeline, project %s, error %v", jobName, err)
+	} else if len(pipelines) > 0 {
+		job.LastBuild = pipelines[0]
+	}
+
+	logx.Info("Fetched GitLab project, name %s", jobName)
+
+	return job, nil
+}
+
+// convertProjectToModel 将 GitLab 项目转换为统一的 Job 模型
+func convertProjectToModel(project *gitlabProject) *model.Job {
+	return &model.Job{
+		Name:        project.PathWithNamespace,
+		DisplayName: project.NameWithNamespace,
+		Description: project.Description,
+		URL:         project.WebURL,
+		// 未开启 CI/CD 功能或已归档的项目无法运行流水线
+		Buildable: project.BuildsAccessLevel != "disabled" && !project.Archived,
+	}
+}
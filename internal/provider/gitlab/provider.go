@@ -0,0 +1,76 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+)
+
+// GitLabProvider GitLab CI Provider
+// 项目映射为 model.Job, 流水线映射为 model.Build
+type GitLabProvider struct {
+	name   string
+	client *Client
+}
+
+// NewGitLabProvider 创建 GitLab Provider
+func NewGitLabProvider() provider.CICDProvider {
+	return &GitLabProvider{
+		name: "gitlab",
+	}
+}
+
+// GetName 获取 Provider 名称
+func (p *GitLabProvider) GetName() string {
+	return p.name
+}
+
+// Initialize 初始化 Provider
+func (p *GitLabProvider) Initialize(config map[string]any) error {
+	// 解析配置
+	url, ok := config["url"].(string)
+	if !ok || url == "" {
+		return fmt.Errorf("url is required")
+	}
+
+	token, ok := config["token"].(string)
+	if !ok || token == "" {
+		return fmt.Errorf("token is required")
+	}
+
+	// 创建客户端
+	p.client = NewClient(url, token)
+
+	logx.Info("GitLab Provider initialized, url %s", url)
+
+	return nil
+}
+
+// GetJobBuilds 实现 CICDProvider 接口, 返回项目最近的流水线
+func (p *GitLabProvider) GetJobBuilds(ctx context.Context, jobName string, limit int) ([]*model.Build, error) {
+	opts := &provider.QueryOptions{
+		PageSize: limit,
+		PageNum:  1,
+	}
+	return p.ListPipelines(ctx, jobName, opts)
+}
+
+// HealthCheck 健康检查
+func (p *GitLabProvider) HealthCheck(ctx context.Context) error {
+	if p.client == nil {
+		return fmt.Errorf("client not initialized")
+	}
+
+	var user struct {
+		Username string `json:"username"`
+	}
+	if _, err := p.client.get(ctx, "/user", nil, &user); err != nil {
+		return err
+	}
+
+	logx.Debug("Health check passed, user %s", user.Username)
+	return nil
+}
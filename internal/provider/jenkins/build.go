@@ -17,12 +17,10 @@ func (p *JenkinsProvider) ListBuilds(ctx context.Context, jobName string, opts *
 		return nil, err
 	}
 
-	jenkins := p.client.GetJenkins()
-
-	// 获取 Job
-	job, err := jenkins.GetJob(ctx, jobName)
+	// 获取 Job, 支持文件夹路径
+	job, err := p.getJob(ctx, jobName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get job '%s': %w", jobName, err)
+		return nil, err
 	}
 
 	// 获取所有构建 ID
@@ -71,12 +69,10 @@ func (p *JenkinsProvider) GetBuild(ctx context.Context, jobName string, buildNum
 		return nil, err
 	}
 
-	jenkins := p.client.GetJenkins()
-
-	// 获取 Job
-	job, err := jenkins.GetJob(ctx, jobName)
+	// 获取 Job, 支持文件夹路径
+	job, err := p.getJob(ctx, jobName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get job '%s': %w", jobName, err)
+		return nil, err
 	}
 
 	// 获取构建
@@ -96,12 +92,10 @@ func (p *JenkinsProvider) GetLastBuild(ctx context.Context, jobName string) (*mo
 		return nil, err
 	}
 
-	jenkins := p.client.GetJenkins()
-
-	// 获取 Job
-	job, err := jenkins.GetJob(ctx, jobName)
+	// 获取 Job, 支持文件夹路径
+	job, err := p.getJob(ctx, jobName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get job '%s': %w", jobName, err)
+		return nil, err
 	}
 
 	// 获取最后一次构建
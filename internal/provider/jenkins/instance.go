@@ -0,0 +1,70 @@
+package jenkins
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/model"
+)
+
+// DefaultSearchTimeout 跨实例搜索时单个实例的超时时间
+const DefaultSearchTimeout = 30 * time.Second
+
+// Instance 带名称的 Jenkins 实例
+type Instance struct {
+	Name     string
+	Provider *JenkinsProvider
+}
+
+// InstanceJobs 单个 Jenkins 实例的 Job 搜索结果
+type InstanceJobs struct {
+	Instance string       `json:"instance"`
+	Jobs     []*model.Job `json:"jobs"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// NewInstanceProvider 创建并初始化指定 Jenkins 实例的 Provider
+// 每个实例使用独立的 Provider, 避免同时访问多个实例时共用同一个客户端
+func NewInstanceProvider(url, username, token string) (*JenkinsProvider, error) {
+	p := &JenkinsProvider{name: "jenkins"}
+	err := p.Initialize(map[string]any{
+		"url":      url,
+		"username": username,
+		"token":    token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SearchJobsInInstances 并发在多个 Jenkins 实例中搜索 Job
+// 结果按传入实例的顺序返回, 单个实例失败时记录错误, 不影响其他实例
+func SearchJobsInInstances(ctx context.Context, instances []Instance, keyword string) []InstanceJobs {
+	results := make([]InstanceJobs, len(instances))
+
+	var wg sync.WaitGroup
+	for i, instance := range instances {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, DefaultSearchTimeout)
+			defer cancel()
+
+			results[i].Instance = instance.Name
+			jobs, err := instance.Provider.SearchJobs(ctx, keyword)
+			if err != nil {
+				logx.Warn("Failed to search jenkins jobs, instance %s, error %v", instance.Name, err)
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].Jobs = jobs
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
@@ -3,6 +3,7 @@ package jenkins
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"cnb.cool/zhiqiangwang/pkg/logx"
@@ -11,32 +12,53 @@ import (
 	"github.com/eryajf/zenops/internal/provider"
 )
 
+const (
+	// maxFolderDepth 遍历文件夹的最大层级, 防止异常配置导致无限递归
+	maxFolderDepth = 10
+
+	// jobTreeQuery 遍历子 Job 时查询的字段
+	jobTreeQuery = "jobs[_class,name,displayName,url,description,buildable,color,lastBuild[number,url]]"
+)
+
+// Jenkins 中需要特殊处理的 Job 类型
+const (
+	classFolder             = "com.cloudbees.hudson.plugins.folder.Folder"
+	classOrganizationFolder = "jenkins.branch.OrganizationFolder"
+	classMultiBranchProject = "org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject"
+)
+
+// jenkinsJobItem Jenkins API 返回的子 Job 结构
+type jenkinsJobItem struct {
+	Class       string `json:"_class"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	Buildable   bool   `json:"buildable"`
+	Color       string `json:"color"`
+	LastBuild   *struct {
+		Number int    `json:"number"`
+		URL    string `json:"url"`
+	} `json:"lastBuild"`
+}
+
 // ListJobs 列出所有 Job
+// 递归遍历文件夹, Job 名称为完整路径 (如 folder/sub/job); 多分支项目作为一个 Job 返回
+// 支持的过滤条件: folder 只列出指定文件夹下的 Job, branches 为 true 时同时列出多分支项目的分支
 func (p *JenkinsProvider) ListJobs(ctx context.Context, opts *provider.QueryOptions) ([]*model.Job, error) {
 	if err := p.client.Connect(ctx); err != nil {
 		return nil, err
 	}
 
-	jenkins := p.client.GetJenkins()
+	folder := strings.Trim(opts.Filters["folder"], "/")
+	includeBranches := opts.Filters["branches"] == "true"
 
-	// 获取所有 Job
-	jobs, err := jenkins.GetAllJobs(ctx)
+	result, err := p.walkJobs(ctx, folder, includeBranches, 0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all jobs: %w", err)
+		return nil, err
 	}
 
-	logx.Debug("Fetched Jenkins jobs, count %d", len(jobs))
-
-	var result []*model.Job
-	for _, job := range jobs {
-		// 跳过文件夹类型
-		if job.Raw.Class == "com.cloudbees.hudson.plugins.folder.Folder" {
-			continue
-		}
-
-		modelJob := convertJobToModel(job)
-		result = append(result, modelJob)
-	}
+	logx.Debug("Fetched Jenkins jobs, folder %s, count %d", folder, len(result))
 
 	// 应用分页
 	if opts.PageSize > 0 && opts.PageNum > 0 {
@@ -62,47 +84,70 @@ func (p *JenkinsProvider) GetJob(ctx context.Context, jobName string) (*model.Jo
 		return nil, err
 	}
 
-	jenkins := p.client.GetJenkins()
-
 	// 支持文件夹路径,如 "folder/subfolder/job"
-	job, err := jenkins.GetJob(ctx, jobName)
+	job, err := p.getJob(ctx, jobName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get job '%s': %w", jobName, err)
+		return nil, err
 	}
 
 	logx.Info("Fetched Jenkins job, name %s", jobName)
 
-	return convertJobToModel(job), nil
+	return convertJobToModel(job, strings.Trim(jobName, "/")), nil
+}
+
+// ListBranches 列出多分支项目的所有分支
+// 返回的分支名称为完整路径, 可直接用于查询构建历史、日志或触发构建
+func (p *JenkinsProvider) ListBranches(ctx context.Context, jobName string) ([]*model.Job, error) {
+	if err := p.client.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	job, err := p.getJob(ctx, jobName)
+	if err != nil {
+		return nil, err
+	}
+	if job.Raw.Class != classMultiBranchProject {
+		return nil, fmt.Errorf("job '%s' is not a multibranch project", jobName)
+	}
+
+	items, err := p.listChildren(ctx, strings.Trim(jobName, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Job, 0, len(items))
+	for i := range items {
+		branch := convertJobItemToModel(&items[i], strings.Trim(jobName, "/"))
+		branch.Type = "Branch"
+		result = append(result, branch)
+	}
+
+	logx.Info("Fetched Jenkins branches, job %s, count %d", jobName, len(result))
+
+	return result, nil
 }
 
 // SearchJobs 搜索 Job
+// 按完整路径、显示名称或描述匹配, 多分支项目的分支也会参与搜索
 func (p *JenkinsProvider) SearchJobs(ctx context.Context, keyword string) ([]*model.Job, error) {
 	if err := p.client.Connect(ctx); err != nil {
 		return nil, err
 	}
 
-	jenkins := p.client.GetJenkins()
-
-	// 获取所有 Job
-	jobs, err := jenkins.GetAllJobs(ctx)
+	jobs, err := p.walkJobs(ctx, "", true, 0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all jobs: %w", err)
+		return nil, err
 	}
 
 	var result []*model.Job
 	keyword = strings.ToLower(keyword)
 
 	for _, job := range jobs {
-		// 跳过文件夹类型
-		if job.Raw.Class == "com.cloudbees.hudson.plugins.folder.Folder" {
-			continue
-		}
-
 		// 按名称或描述搜索
-		if strings.Contains(strings.ToLower(job.GetName()), keyword) ||
-			strings.Contains(strings.ToLower(job.GetDescription()), keyword) {
-			modelJob := convertJobToModel(job)
-			result = append(result, modelJob)
+		if strings.Contains(strings.ToLower(job.Name), keyword) ||
+			strings.Contains(strings.ToLower(job.DisplayName), keyword) ||
+			strings.Contains(strings.ToLower(job.Description), keyword) {
+			result = append(result, job)
 		}
 	}
 
@@ -111,14 +156,144 @@ func (p *JenkinsProvider) SearchJobs(ctx context.Context, keyword string) ([]*mo
 	return result, nil
 }
 
+// walkJobs 递归遍历文件夹下的 Job, folder 为空时从根目录开始
+func (p *JenkinsProvider) walkJobs(ctx context.Context, folder string, includeBranches bool, depth int) ([]*model.Job, error) {
+	if depth > maxFolderDepth {
+		logx.Warn("Jenkins folder too deep, skipping, folder %s", folder)
+		return nil, nil
+	}
+
+	items, err := p.listChildren(ctx, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*model.Job
+	for i := range items {
+		item := &items[i]
+		fullName := joinJobPath(folder, item.Name)
+
+		switch item.Class {
+		case classFolder, classOrganizationFolder:
+			children, err := p.walkJobs(ctx, fullName, includeBranches, depth+1)
+			if err != nil {
+				// 无权限访问的文件夹不影响其他 Job
+				logx.Warn("Failed to list jenkins folder, folder %s, error %v", fullName, err)
+				continue
+			}
+			result = append(result, children...)
+		case classMultiBranchProject:
+			result = append(result, convertJobItemToModel(item, folder))
+			if !includeBranches {
+				continue
+			}
+			branches, err := p.listChildren(ctx, fullName)
+			if err != nil {
+				logx.Warn("Failed to list jenkins branches, job %s, error %v", fullName, err)
+				continue
+			}
+			for j := range branches {
+				branch := convertJobItemToModel(&branches[j], fullName)
+				branch.Type = "Branch"
+				result = append(result, branch)
+			}
+		default:
+			result = append(result, convertJobItemToModel(item, folder))
+		}
+	}
+
+	return result, nil
+}
+
+// listChildren 查询文件夹 (或多分支项目) 的直接子 Job, folder 为空时查询根目录
+func (p *JenkinsProvider) listChildren(ctx context.Context, folder string) ([]jenkinsJobItem, error) {
+	jenkins := p.client.GetJenkins()
+
+	var resp struct {
+		Jobs []jenkinsJobItem `json:"jobs"`
+	}
+	query := map[string]string{"tree": jobTreeQuery}
+	if _, err := jenkins.Requester.GetJSON(ctx, jobBase(folder), &resp, query); err != nil {
+		return nil, fmt.Errorf("failed to list jobs in '%s': %w", folder, err)
+	}
+
+	return resp.Jobs, nil
+}
+
+// getJob 获取 Job, 支持 "folder/subfolder/job" 形式的文件夹路径
+func (p *JenkinsProvider) getJob(ctx context.Context, jobName string) (*gojenkins.Job, error) {
+	parts := strings.Split(strings.Trim(jobName, "/"), "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+
+	job, err := p.client.GetJenkins().GetJob(ctx, parts[len(parts)-1], parts[:len(parts)-1]...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job '%s': %w", jobName, err)
+	}
+	return job, nil
+}
+
+// jobBase 将完整路径转换为 Jenkins API 路径, 如 a/b -> /job/a/job/b
+func jobBase(fullName string) string {
+	if fullName == "" {
+		return "/"
+	}
+
+	var sb strings.Builder
+	for _, part := range strings.Split(fullName, "/") {
+		sb.WriteString("/job/")
+		sb.WriteString(url.PathEscape(part))
+	}
+	return sb.String()
+}
+
+// joinJobPath 拼接 Job 完整路径
+func joinJobPath(folder, name string) string {
+	if folder == "" {
+		return name
+	}
+	return folder + "/" + name
+}
+
+// convertJobItemToModel 将子 Job 转换为统一的 Job 模型
+func convertJobItemToModel(item *jenkinsJobItem, folder string) *model.Job {
+	modelJob := &model.Job{
+		Name:        joinJobPath(folder, item.Name),
+		DisplayName: item.DisplayName,
+		Description: item.Description,
+		URL:         item.URL,
+		Buildable:   item.Buildable,
+		Type:        extractJobType(item.Class),
+		Status:      convertJobColor(item.Color),
+	}
+	if modelJob.DisplayName == "" {
+		modelJob.DisplayName = item.Name
+	}
+
+	if item.LastBuild != nil && item.LastBuild.Number > 0 {
+		modelJob.LastBuild = &model.Build{
+			Number: item.LastBuild.Number,
+			URL:    item.LastBuild.URL,
+		}
+	}
+
+	return modelJob
+}
+
 // convertJobToModel 将 Jenkins Job 转换为统一的 Job 模型
-func convertJobToModel(job *gojenkins.Job) *model.Job {
+func convertJobToModel(job *gojenkins.Job, fullName string) *model.Job {
 	modelJob := &model.Job{
-		Name:        job.GetName(),
-		DisplayName: job.GetName(),
+		Name:        fullName,
+		DisplayName: job.Raw.DisplayName,
 		Description: job.GetDescription(),
 		URL:         job.Raw.URL,
-		Buildable:   true, // 默认为 true
+		Buildable:   job.Raw.Buildable,
+		Type:        extractJobType(job.Raw.Class),
+		Status:      convertJobColor(job.Raw.Color),
+	}
+	if modelJob.DisplayName == "" {
+		modelJob.DisplayName = job.GetName()
 	}
 
 	// 最后构建信息
@@ -132,52 +307,51 @@ func convertJobToModel(job *gojenkins.Job) *model.Job {
 	return modelJob
 }
 
-// These functions are intentionally kept for future use
 // extractJobType 从 Java 类名提取任务类型
-// func extractJobType(class string) string {
-// 	// 例如: "hudson.model.FreeStyleProject" -> "FreeStyle"
-// 	//      "org.jenkinsci.plugins.workflow.job.WorkflowJob" -> "Pipeline"
-// 	if strings.Contains(class, "FreeStyleProject") {
-// 		return "FreeStyle"
-// 	}
-// 	if strings.Contains(class, "WorkflowJob") {
-// 		return "Pipeline"
-// 	}
-// 	if strings.Contains(class, "MatrixProject") {
-// 		return "Matrix"
-// 	}
-// 	if strings.Contains(class, "MavenModuleSet") {
-// 		return "Maven"
-// 	}
-//
-// 	// 提取类名最后一部分
-// 	parts := strings.Split(class, ".")
-// 	if len(parts) > 0 {
-// 		return parts[len(parts)-1]
-// 	}
-//
-// 	return "Unknown"
-// }
+func extractJobType(class string) string {
+	// 例如: "hudson.model.FreeStyleProject" -> "FreeStyle"
+	//      "org.jenkinsci.plugins.workflow.job.WorkflowJob" -> "Pipeline"
+	switch {
+	case class == "":
+		return ""
+	case strings.Contains(class, "FreeStyleProject"):
+		return "FreeStyle"
+	case strings.Contains(class, "WorkflowMultiBranchProject"):
+		return "MultiBranch"
+	case strings.Contains(class, "WorkflowJob"):
+		return "Pipeline"
+	case strings.Contains(class, "MatrixProject"):
+		return "Matrix"
+	case strings.Contains(class, "MavenModuleSet"):
+		return "Maven"
+	}
+
+	// 提取类名最后一部分
+	parts := strings.Split(class, ".")
+	return parts[len(parts)-1]
+}
 
 // convertJobColor 转换 Jenkins 颜色状态为友好的状态名
-// func convertJobColor(color string) string {
-// 	// 移除 _anime 后缀 (表示正在构建)
-// 	color = strings.TrimSuffix(color, "_anime")
-//
-// 	switch color {
-// 	case "blue":
-// 		return "Success"
-// 	case "red":
-// 		return "Failed"
-// 	case "yellow":
-// 		return "Unstable"
-// 	case "grey":
-// 		return "NotBuilt"
-// 	case "disabled":
-// 		return "Disabled"
-// 	case "aborted":
-// 		return "Aborted"
-// 	default:
-// 		return color
-// 	}
-// }
+func convertJobColor(color string) string {
+	// _anime 后缀表示正在构建
+	if strings.HasSuffix(color, "_anime") {
+		return "Building"
+	}
+
+	switch color {
+	case "blue":
+		return "Success"
+	case "red":
+		return "Failed"
+	case "yellow":
+		return "Unstable"
+	case "notbuilt", "grey":
+		return "NotBuilt"
+	case "disabled":
+		return "Disabled"
+	case "aborted":
+		return "Aborted"
+	default:
+		return color
+	}
+}
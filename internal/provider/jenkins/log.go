@@ -0,0 +1,264 @@
+package jenkins
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/bndr/gojenkins"
+	"github.com/eryajf/zenops/internal/model"
+)
+
+const (
+	// DefaultLogTailLines 默认返回的日志行数
+	DefaultLogTailLines = 200
+	// MaxLogTailLines 允许返回的最大日志行数
+	MaxLogTailLines = 5000
+	// DefaultLogMaxBytes 默认返回的日志大小上限
+	DefaultLogMaxBytes = 64 * 1024
+	// MaxLogMaxBytes 允许返回的最大日志大小
+	MaxLogMaxBytes = 1024 * 1024
+	// maxLogLineBytes 单行日志保留的最大长度, 避免压缩后的超长行占满输出
+	maxLogLineBytes = 4096
+)
+
+var (
+	// ansiPattern 匹配终端颜色控制符
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+	// errorLinePattern 匹配常见的错误日志行
+	errorLinePattern = regexp.MustCompile(`(?i)(\berror\b|\berr!|\bfailed\b|\bfailure\b|exception|\bfatal\b|panic:|build failed|exit code [1-9]|non-zero exit|returned [1-9]|cannot |could not|no such file|not found|permission denied|timed out|timeout)`)
+)
+
+// BuildLogOptions 日志查询选项
+type BuildLogOptions struct {
+	TailLines int    // 返回最后 N 行 (grep 时为最后 N 个匹配行), 默认 200
+	Grep      string // 只返回匹配的行 (不区分大小写的正则表达式, 非法时按普通文本匹配)
+	MaxBytes  int    // 返回内容的大小上限, 超出时保留末尾部分, 默认 64KB
+}
+
+// GetBuildLog 获取构建控制台日志
+// buildNumber 小于等于 0 时获取最后一次构建; 日志以流式读取, 内存中只保留需要返回的部分
+func (p *JenkinsProvider) GetBuildLog(ctx context.Context, jobName string, buildNumber int, opts BuildLogOptions) (*model.BuildLog, error) {
+	if err := p.client.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	job, err := p.getJob(ctx, jobName)
+	if err != nil {
+		return nil, err
+	}
+
+	var build *gojenkins.Build
+	if buildNumber > 0 {
+		build, err = job.GetBuild(ctx, int64(buildNumber))
+	} else {
+		build, err = job.GetLastBuild(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get build of job '%s': %w", jobName, err)
+	}
+
+	tailLines := opts.TailLines
+	if tailLines <= 0 {
+		tailLines = DefaultLogTailLines
+	}
+	tailLines = min(tailLines, MaxLogTailLines)
+
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultLogMaxBytes
+	}
+	maxBytes = min(maxBytes, MaxLogMaxBytes)
+
+	var grep *regexp.Regexp
+	if opts.Grep != "" {
+		grep, err = regexp.Compile("(?i)" + opts.Grep)
+		if err != nil {
+			grep = regexp.MustCompile("(?i)" + regexp.QuoteMeta(opts.Grep))
+		}
+	}
+
+	body, err := p.openConsoleText(ctx, build)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	modelBuild := convertBuildToModel(build, jobName)
+	result := &model.BuildLog{
+		JobName: jobName,
+		Number:  modelBuild.Number,
+		Status:  modelBuild.Status,
+		URL:     modelBuild.URL,
+	}
+
+	// 环形缓冲区保存最后 tailLines 行
+	ring := make([]string, tailLines)
+	kept := 0
+	matched := 0
+
+	reader := bufio.NewReader(body)
+	for {
+		line, readErr := reader.ReadString('\n')
+		if line != "" {
+			result.TotalBytes += int64(len(line))
+			result.TotalLines++
+
+			line = ansiPattern.ReplaceAllString(strings.TrimRight(line, "\r\n"), "")
+			if len(line) > maxLogLineBytes {
+				line = line[:maxLogLineBytes] + " ...(truncated)"
+			}
+
+			if grep == nil || grep.MatchString(line) {
+				if grep != nil {
+					line = fmt.Sprintf("%d: %s", result.TotalLines, line)
+				}
+				ring[matched%tailLines] = line
+				matched++
+				kept = min(matched, tailLines)
+			}
+		}
+		if readErr != nil {
+			if errors.Is(readErr, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to read console log: %w", readErr)
+		}
+	}
+
+	lines := make([]string, 0, kept)
+	for i := matched - kept; i < matched; i++ {
+		lines = append(lines, ring[i%tailLines])
+	}
+	result.Truncated = matched > kept
+
+	// 超出大小限制时从头部丢弃, 保留最接近失败点的末尾日志
+	size := 0
+	start := len(lines)
+	for start > 0 && size+len(lines[start-1])+1 <= maxBytes {
+		start--
+		size += len(lines[start]) + 1
+	}
+	if start > 0 {
+		lines = lines[start:]
+		result.Truncated = true
+	}
+
+	result.Content = strings.Join(lines, "\n")
+	result.Lines = len(lines)
+
+	logx.Info("Fetched Jenkins build log, job %s, build %d, total lines %d, returned %d",
+		jobName, result.Number, result.TotalLines, result.Lines)
+
+	return result, nil
+}
+
+// openConsoleText 打开构建的纯文本控制台输出
+// gojenkins 的 GetConsoleOutput 会一次性读入完整日志, 超大日志时改为流式读取
+func (p *JenkinsProvider) openConsoleText(ctx context.Context, build *gojenkins.Build) (io.ReadCloser, error) {
+	requester := build.Jenkins.Requester
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requester.Base+build.Base+"/consoleText", nil)
+	if err != nil {
+		return nil, err
+	}
+	if requester.BasicAuth != nil {
+		req.SetBasicAuth(requester.BasicAuth.Username, requester.BasicAuth.Password)
+	}
+
+	client := requester.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get console log: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to get console log: %s", resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+// ExtractErrorRegion 从日志中提取错误相关的片段, 最多返回 maxLines 行
+// 优先保留第一处错误 (通常是根因) 及日志末尾, 其余错误片段按出现顺序补充, 片段之间以 "..." 分隔
+func ExtractErrorRegion(content string, maxLines int) string {
+	lines := strings.Split(content, "\n")
+	if maxLines <= 0 || len(lines) <= maxLines {
+		return content
+	}
+
+	const (
+		before   = 5
+		after    = 10
+		tailSize = 20
+	)
+
+	type window struct{ start, end int }
+
+	// 收集每处错误的上下文窗口
+	var windows []window
+	for i, line := range lines {
+		if !errorLinePattern.MatchString(line) {
+			continue
+		}
+		start := max(i-before, 0)
+		end := min(i+after+1, len(lines))
+		if n := len(windows); n > 0 && start <= windows[n-1].end {
+			windows[n-1].end = max(windows[n-1].end, end)
+			continue
+		}
+		windows = append(windows, window{start, end})
+	}
+
+	selected := make([]bool, len(lines))
+	budget := maxLines
+	mark := func(w window) {
+		for i := w.start; i < w.end && budget > 0; i++ {
+			if !selected[i] {
+				selected[i] = true
+				budget--
+			}
+		}
+	}
+
+	// 日志末尾通常包含最终的失败信息
+	mark(window{max(len(lines)-min(tailSize, maxLines/2), 0), len(lines)})
+	for _, w := range windows {
+		if budget == 0 {
+			break
+		}
+		mark(w)
+	}
+	// 未匹配到错误时直接返回末尾日志
+	if len(windows) == 0 {
+		mark(window{max(len(lines)-maxLines, 0), len(lines)})
+	}
+
+	var sb strings.Builder
+	gap := false
+	for i, line := range lines {
+		if !selected[i] {
+			gap = true
+			continue
+		}
+		if gap && sb.Len() > 0 {
+			sb.WriteString("...\n")
+		}
+		gap = false
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
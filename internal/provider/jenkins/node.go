@@ -0,0 +1,97 @@
+package jenkins
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/model"
+)
+
+// agentTreeQuery 查询构建节点时的字段
+const agentTreeQuery = "computer[displayName,description,offline,temporarilyOffline,offlineCauseReason,numExecutors," +
+	"assignedLabels[name],executors[idle,currentExecutable[fullDisplayName,url]],oneOffExecutors[idle,currentExecutable[fullDisplayName,url]]]"
+
+// jenkinsExecutor Jenkins API 返回的执行器结构
+type jenkinsExecutor struct {
+	Idle              bool `json:"idle"`
+	CurrentExecutable *struct {
+		FullDisplayName string `json:"fullDisplayName"`
+		URL             string `json:"url"`
+	} `json:"currentExecutable"`
+}
+
+// jenkinsComputer Jenkins API 返回的构建节点结构
+type jenkinsComputer struct {
+	DisplayName        string `json:"displayName"`
+	Description        string `json:"description"`
+	Offline            bool   `json:"offline"`
+	TemporarilyOffline bool   `json:"temporarilyOffline"`
+	OfflineCauseReason string `json:"offlineCauseReason"`
+	NumExecutors       int    `json:"numExecutors"`
+	AssignedLabels     []struct {
+		Name string `json:"name"`
+	} `json:"assignedLabels"`
+	Executors       []jenkinsExecutor `json:"executors"`
+	OneOffExecutors []jenkinsExecutor `json:"oneOffExecutors"`
+}
+
+// ListAgents 列出所有构建节点, 包含在线状态、执行器使用情况和标签
+func (p *JenkinsProvider) ListAgents(ctx context.Context) ([]*model.Agent, error) {
+	if err := p.client.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	jenkins := p.client.GetJenkins()
+
+	var resp struct {
+		Computer []jenkinsComputer `json:"computer"`
+	}
+	query := map[string]string{"tree": agentTreeQuery}
+	if _, err := jenkins.Requester.GetJSON(ctx, "/computer", &resp, query); err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+
+	logx.Debug("Fetched Jenkins agents, count %d", len(resp.Computer))
+
+	result := make([]*model.Agent, 0, len(resp.Computer))
+	for i := range resp.Computer {
+		result = append(result, convertComputerToModel(&resp.Computer[i]))
+	}
+
+	return result, nil
+}
+
+// convertComputerToModel 将 Jenkins 构建节点转换为统一的 Agent 模型
+func convertComputerToModel(computer *jenkinsComputer) *model.Agent {
+	agent := &model.Agent{
+		Name:               computer.DisplayName,
+		Description:        computer.Description,
+		Online:             !computer.Offline,
+		TemporarilyOffline: computer.TemporarilyOffline,
+		OfflineReason:      computer.OfflineCauseReason,
+		Executors:          computer.NumExecutors,
+		Labels:             []string{},
+	}
+
+	// 每个节点都带有与节点同名的标签, 不作展示
+	for _, label := range computer.AssignedLabels {
+		if label.Name != "" && label.Name != computer.DisplayName {
+			agent.Labels = append(agent.Labels, label.Name)
+		}
+	}
+
+	// 轻量执行器 (如 Pipeline 的 flyweight 任务) 不占用执行器数量, 只记录运行中的构建
+	for i, executor := range slices.Concat(computer.Executors, computer.OneOffExecutors) {
+		if executor.Idle || executor.CurrentExecutable == nil {
+			continue
+		}
+		if i < len(computer.Executors) {
+			agent.BusyExecutors++
+		}
+		agent.RunningBuilds = append(agent.RunningBuilds, executor.CurrentExecutable.FullDisplayName)
+	}
+
+	return agent
+}
@@ -0,0 +1,176 @@
+package jenkins
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/model"
+)
+
+// jenkinsQueueItem Jenkins API 返回的队列项结构
+type jenkinsQueueItem struct {
+	ID           int64  `json:"id"`
+	URL          string `json:"url"`
+	Why          string `json:"why"`
+	Blocked      bool   `json:"blocked"`
+	Buildable    bool   `json:"buildable"`
+	Stuck        bool   `json:"stuck"`
+	Cancelled    bool   `json:"cancelled"`
+	InQueueSince int64  `json:"inQueueSince"`
+	Task         struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"task"`
+	Actions []struct {
+		Parameters []struct {
+			Name  string `json:"name"`
+			Value any    `json:"value"`
+		} `json:"parameters"`
+	} `json:"actions"`
+	Executable *struct {
+		Number int    `json:"number"`
+		URL    string `json:"url"`
+	} `json:"executable"`
+}
+
+// GetQueue 获取构建队列中的所有等待项
+func (p *JenkinsProvider) GetQueue(ctx context.Context) ([]*model.QueueItem, error) {
+	if err := p.client.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	jenkins := p.client.GetJenkins()
+
+	var resp struct {
+		Items []jenkinsQueueItem `json:"items"`
+	}
+	if _, err := jenkins.Requester.GetJSON(ctx, jenkins.GetQueueUrl(), &resp, nil); err != nil {
+		return nil, fmt.Errorf("failed to get queue: %w", err)
+	}
+
+	logx.Debug("Fetched Jenkins queue, count %d", len(resp.Items))
+
+	result := make([]*model.QueueItem, 0, len(resp.Items))
+	for i := range resp.Items {
+		result = append(result, convertQueueItemToModel(&resp.Items[i]))
+	}
+
+	return result, nil
+}
+
+// GetQueueItem 获取指定队列项
+// 队列项开始执行后仍可查询一段时间, 此时 BuildNumber 为对应的构建号
+func (p *JenkinsProvider) GetQueueItem(ctx context.Context, id int64) (*model.QueueItem, error) {
+	if err := p.client.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	jenkins := p.client.GetJenkins()
+
+	var item jenkinsQueueItem
+	resp, err := jenkins.Requester.GetJSON(ctx, fmt.Sprintf("%s/item/%d", jenkins.GetQueueUrl(), id), &item, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue item %d: %w", id, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get queue item %d: %s", id, resp.Status)
+	}
+
+	return convertQueueItemToModel(&item), nil
+}
+
+// CancelQueueItem 取消队列中尚未开始执行的构建
+func (p *JenkinsProvider) CancelQueueItem(ctx context.Context, id int64) error {
+	item, err := p.GetQueueItem(ctx, id)
+	if err != nil {
+		return err
+	}
+	if item.BuildNumber > 0 {
+		return fmt.Errorf("queue item %d has already started as build #%d", id, item.BuildNumber)
+	}
+	if item.Cancelled {
+		return nil
+	}
+
+	jenkins := p.client.GetJenkins()
+
+	query := map[string]string{"id": strconv.FormatInt(id, 10)}
+	resp, err := jenkins.Requester.Post(ctx, jenkins.GetQueueUrl()+"/cancelItem", nil, nil, query)
+	if err != nil {
+		return fmt.Errorf("failed to cancel queue item %d: %w", id, err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("failed to cancel queue item %d: %s", id, resp.Status)
+	}
+
+	logx.Info("Cancelled Jenkins queue item, id %d, job %s", id, item.JobName)
+
+	return nil
+}
+
+// convertQueueItemToModel 将 Jenkins 队列项转换为统一的 QueueItem 模型
+func convertQueueItemToModel(item *jenkinsQueueItem) *model.QueueItem {
+	queueItem := &model.QueueItem{
+		ID:        item.ID,
+		JobName:   jobNameFromURL(item.Task.URL, item.Task.Name),
+		URL:       item.Task.URL,
+		Why:       item.Why,
+		Blocked:   item.Blocked,
+		Buildable: item.Buildable,
+		Stuck:     item.Stuck,
+		Cancelled: item.Cancelled,
+	}
+
+	if item.InQueueSince > 0 {
+		queueItem.InQueueSince = time.UnixMilli(item.InQueueSince)
+	}
+
+	for _, action := range item.Actions {
+		for _, param := range action.Parameters {
+			if queueItem.Params == nil {
+				queueItem.Params = make(map[string]string)
+			}
+			queueItem.Params[param.Name] = fmt.Sprint(param.Value)
+		}
+	}
+
+	if item.Executable != nil {
+		queueItem.BuildNumber = item.Executable.Number
+		queueItem.BuildURL = item.Executable.URL
+	}
+
+	return queueItem
+}
+
+// jobNameFromURL 从 Job URL 解析完整路径 (如 folder/job), 解析失败时返回 fallback
+// 队列项中的 task.name 不含文件夹, 需通过 URL 还原
+func jobNameFromURL(jobURL, fallback string) string {
+	u, err := url.Parse(jobURL)
+	if err != nil {
+		return fallback
+	}
+
+	var parts []string
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "job" {
+			name, err := url.PathUnescape(segments[i+1])
+			if err != nil {
+				return fallback
+			}
+			parts = append(parts, name)
+			i++
+		}
+	}
+
+	if len(parts) == 0 {
+		return fallback
+	}
+	return strings.Join(parts, "/")
+}
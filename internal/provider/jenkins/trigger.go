@@ -0,0 +1,230 @@
+package jenkins
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/bndr/gojenkins"
+	"github.com/eryajf/zenops/internal/model"
+)
+
+const (
+	// DefaultTriggerWait 触发构建后等待分配构建号的默认时长
+	DefaultTriggerWait = 10 * time.Second
+	// queuePollInterval 轮询队列项的间隔
+	queuePollInterval = time.Second
+)
+
+// GetJobParameters 获取 Job 的参数定义, 包含选项参数的可选值
+func (p *JenkinsProvider) GetJobParameters(ctx context.Context, jobName string) ([]*model.JobParameter, error) {
+	if err := p.client.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	job, err := p.getJob(ctx, jobName)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.fetchJobParameters(ctx, job)
+}
+
+// fetchJobParameters 查询参数定义
+// gojenkins 的 ParameterDefinition 不含 choices, 这里通过 tree 参数直接获取
+func (p *JenkinsProvider) fetchJobParameters(ctx context.Context, job *gojenkins.Job) ([]*model.JobParameter, error) {
+	var resp struct {
+		Property []struct {
+			ParameterDefinitions []struct {
+				Name                  string   `json:"name"`
+				Type                  string   `json:"type"`
+				Description           string   `json:"description"`
+				Choices               []string `json:"choices"`
+				DefaultParameterValue *struct {
+					Value any `json:"value"`
+				} `json:"defaultParameterValue"`
+			} `json:"parameterDefinitions"`
+		} `json:"property"`
+	}
+
+	query := map[string]string{
+		"tree": "property[parameterDefinitions[name,type,description,choices,defaultParameterValue[value]]]",
+	}
+	if _, err := job.Jenkins.Requester.GetJSON(ctx, job.Base, &resp, query); err != nil {
+		return nil, fmt.Errorf("failed to get parameter definitions: %w", err)
+	}
+
+	var result []*model.JobParameter
+	for _, property := range resp.Property {
+		for _, def := range property.ParameterDefinitions {
+			param := &model.JobParameter{
+				Name:        def.Name,
+				Type:        def.Type,
+				Description: def.Description,
+				Choices:     def.Choices,
+			}
+			if def.DefaultParameterValue != nil && def.DefaultParameterValue.Value != nil {
+				param.Default = fmt.Sprint(def.DefaultParameterValue.Value)
+			}
+			result = append(result, param)
+		}
+	}
+
+	return result, nil
+}
+
+// TriggerBuild 触发构建
+// 参数会先按 Job 的参数定义校验, 未传入的参数使用 Jenkins 中配置的默认值
+// 触发后最多等待 wait 时长以获取构建号, 超时仍在排队时返回的 BuildNumber 为 0
+func (p *JenkinsProvider) TriggerBuild(ctx context.Context, jobName string, params map[string]string, wait time.Duration) (*model.QueueItem, error) {
+	if err := p.client.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	job, err := p.getJob(ctx, jobName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !job.Raw.Buildable {
+		return nil, fmt.Errorf("job '%s' is not buildable", jobName)
+	}
+
+	definitions, err := p.fetchJobParameters(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized, err := validateBuildParams(definitions, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters for job '%s': %w", jobName, err)
+	}
+
+	queueID, err := job.InvokeSimple(ctx, normalized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to trigger job '%s': %w", jobName, err)
+	}
+	// gojenkins 在 Job 已有排队项时不会重复触发, 此时返回 0
+	if queueID == 0 {
+		return nil, fmt.Errorf("job '%s' already has a build waiting in the queue", jobName)
+	}
+
+	logx.Info("Triggered Jenkins build, job %s, queue id %d, params %v", jobName, queueID, normalized)
+
+	item, err := p.waitForBuildNumber(ctx, queueID, wait)
+	if err != nil {
+		// 构建已成功触发, 只是无法查询队列状态
+		logx.Warn("Failed to get queue item, job %s, queue id %d, error %v", jobName, queueID, err)
+		item = &model.QueueItem{ID: queueID}
+	}
+	if item.JobName == "" {
+		item.JobName = jobName
+	}
+	if item.Params == nil && len(normalized) > 0 {
+		item.Params = normalized
+	}
+
+	return item, nil
+}
+
+// waitForBuildNumber 轮询队列项, 直到分配构建号、被取消或超时
+func (p *JenkinsProvider) waitForBuildNumber(ctx context.Context, queueID int64, wait time.Duration) (*model.QueueItem, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		item, err := p.GetQueueItem(ctx, queueID)
+		if err != nil {
+			return nil, err
+		}
+		if item.BuildNumber > 0 || item.Cancelled || !time.Now().Before(deadline) {
+			return item, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return item, nil
+		case <-time.After(queuePollInterval):
+		}
+	}
+}
+
+// AbortBuild 中止正在运行的构建
+func (p *JenkinsProvider) AbortBuild(ctx context.Context, jobName string, buildNumber int) (*model.Build, error) {
+	if err := p.client.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	job, err := p.getJob(ctx, jobName)
+	if err != nil {
+		return nil, err
+	}
+
+	build, err := job.GetBuild(ctx, int64(buildNumber))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get build #%d: %w", buildNumber, err)
+	}
+
+	if !build.Raw.Building {
+		return nil, fmt.Errorf("build #%d of job '%s' is not running, result %s", buildNumber, jobName, build.Raw.Result)
+	}
+
+	ok, err := build.Stop(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to abort build #%d: %w", buildNumber, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("jenkins refused to abort build #%d", buildNumber)
+	}
+
+	logx.Info("Aborted Jenkins build, job %s, build %d", jobName, buildNumber)
+
+	return convertBuildToModel(build, jobName), nil
+}
+
+// validateBuildParams 按参数定义校验构建参数, 返回规范化后的参数
+func validateBuildParams(definitions []*model.JobParameter, params map[string]string) (map[string]string, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+
+	if len(definitions) == 0 {
+		return nil, fmt.Errorf("job does not accept parameters")
+	}
+
+	defs := make(map[string]*model.JobParameter, len(definitions))
+	names := make([]string, 0, len(definitions))
+	for _, def := range definitions {
+		defs[def.Name] = def
+		names = append(names, def.Name)
+	}
+	sort.Strings(names)
+
+	result := make(map[string]string, len(params))
+	for name, value := range params {
+		def, ok := defs[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown parameter '%s', available: %s", name, strings.Join(names, ", "))
+		}
+
+		switch def.Type {
+		case "ChoiceParameterDefinition":
+			if len(def.Choices) > 0 && !slices.Contains(def.Choices, value) {
+				return nil, fmt.Errorf("invalid value '%s' for parameter '%s', choices: %s", value, name, strings.Join(def.Choices, ", "))
+			}
+		case "BooleanParameterDefinition":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value '%s' for boolean parameter '%s'", value, name)
+			}
+			value = strconv.FormatBool(b)
+		}
+
+		result[name] = value
+	}
+
+	return result, nil
+}
@@ -0,0 +1,95 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// defaultTimeout 单次 API 请求超时时间
+const defaultTimeout = 30 * time.Second
+
+// Client Kubernetes 客户端
+type Client struct {
+	Cluster          string // 集群名称, 用于在结果中标识来源
+	DefaultNamespace string // 未指定命名空间时使用, 为空表示所有命名空间
+	clientset        k8s.Interface
+}
+
+// NewClient 根据 kubeconfig 内容创建 Kubernetes 客户端
+// contextName 为空时使用 kubeconfig 中的 current-context
+func NewClient(cluster, kubeconfig, contextName, defaultNamespace string) (*Client, error) {
+	if kubeconfig == "" {
+		return nil, fmt.Errorf("kubeconfig is required")
+	}
+
+	rawConfig, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	if contextName != "" {
+		if _, ok := rawConfig.Contexts[contextName]; !ok {
+			return nil, fmt.Errorf("context %s not found in kubeconfig", contextName)
+		}
+	}
+
+	restConfig, err := clientcmd.NewNonInteractiveClientConfig(
+		*rawConfig,
+		contextName,
+		&clientcmd.ConfigOverrides{},
+		nil,
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build rest config: %w", err)
+	}
+	restConfig.Timeout = defaultTimeout
+
+	clientset, err := k8s.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	return NewClientWithClientset(cluster, clientset, defaultNamespace), nil
+}
+
+// NewClientWithClientset 使用已有的 clientset 创建客户端 (可传入 fake.NewSimpleClientset())
+func NewClientWithClientset(cluster string, clientset k8s.Interface, defaultNamespace string) *Client {
+	return &Client{
+		Cluster:          cluster,
+		DefaultNamespace: defaultNamespace,
+		clientset:        clientset,
+	}
+}
+
+// GetClientset 获取底层 clientset
+func (c *Client) GetClientset() k8s.Interface {
+	return c.clientset
+}
+
+// ListContexts 列出 kubeconfig 中的所有 context
+func ListContexts(kubeconfig string) ([]string, string, error) {
+	rawConfig, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	contexts := make([]string, 0, len(rawConfig.Contexts))
+	for name := range rawConfig.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+
+	return contexts, rawConfig.CurrentContext, nil
+}
+
+// namespace 返回实际查询的命名空间
+func (c *Client) namespace(namespace string) string {
+	if namespace != "" {
+		return namespace
+	}
+	return c.DefaultNamespace
+}
@@ -0,0 +1,134 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+- name: staging
+  cluster:
+    server: https://staging.example.com:6443
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+- name: staging
+  context:
+    cluster: staging
+    user: admin
+users:
+- name: admin
+  user:
+    token: test-token
+`
+
+func TestListContexts(t *testing.T) {
+	contexts, current, err := ListContexts(testKubeconfig)
+	if err != nil {
+		t.Fatalf("ListContexts() error = %v", err)
+	}
+	if !reflect.DeepEqual(contexts, []string{"prod", "staging"}) || current != "prod" {
+		t.Errorf("ListContexts() = %v, %q, want [prod staging], prod", contexts, current)
+	}
+
+	if _, _, err := ListContexts("not: [yaml"); err == nil {
+		t.Errorf("ListContexts() with invalid kubeconfig error = nil")
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name        string
+		kubeconfig  string
+		contextName string
+		wantErr     bool
+	}{
+		{name: "current context", kubeconfig: testKubeconfig},
+		{name: "explicit context", kubeconfig: testKubeconfig, contextName: "staging"},
+		{name: "unknown context", kubeconfig: testKubeconfig, contextName: "dev", wantErr: true},
+		{name: "empty kubeconfig", kubeconfig: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient("prod", tt.kubeconfig, tt.contextName, "default")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (client.Cluster != "prod" || client.DefaultNamespace != "default" || client.GetClientset() == nil) {
+				t.Errorf("NewClient() = %+v", client)
+			}
+		})
+	}
+}
+
+func TestListNamespaces(t *testing.T) {
+	client := NewClientWithClientset("prod", fake.NewClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"team": "ops"}}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+	), "")
+
+	namespaces, err := client.ListNamespaces(context.Background())
+	if err != nil {
+		t.Fatalf("ListNamespaces() error = %v", err)
+	}
+	if len(namespaces) != 2 || namespaces[0].Name != "default" || namespaces[1].Name != "kube-system" {
+		t.Fatalf("ListNamespaces() = %+v, want sorted by name", namespaces)
+	}
+	if namespaces[0].Cluster != "prod" || namespaces[0].Status != "Active" || namespaces[0].Labels["team"] != "ops" {
+		t.Errorf("namespace = %+v", namespaces[0])
+	}
+}
+
+func TestListServices(t *testing.T) {
+	client := NewClientWithClientset("prod", fake.NewClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Type:        corev1.ServiceTypeLoadBalancer,
+				ClusterIP:   "10.96.0.10",
+				ExternalIPs: []string{"203.0.113.5"},
+				Selector:    map[string]string{"app": "web"},
+				Ports: []corev1.ServicePort{
+					{Port: 80, NodePort: 30080, Protocol: corev1.ProtocolTCP},
+					{Port: 53, Protocol: corev1.ProtocolUDP},
+				},
+			},
+			Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{
+				{IP: "198.51.100.1"},
+				{Hostname: "lb.example.com"},
+			}}},
+		},
+	), "default")
+
+	services, err := client.ListServices(context.Background(), "", "")
+	if err != nil {
+		t.Fatalf("ListServices() error = %v", err)
+	}
+	if len(services) != 1 {
+		t.Fatalf("ListServices() returned %d services, want 1", len(services))
+	}
+	got := services[0]
+	if got.Type != "LoadBalancer" || got.ClusterIP != "10.96.0.10" || got.Selector["app"] != "web" {
+		t.Errorf("service = %+v", got)
+	}
+	if !reflect.DeepEqual(got.ExternalIPs, []string{"203.0.113.5", "198.51.100.1", "lb.example.com"}) {
+		t.Errorf("service external ips = %v", got.ExternalIPs)
+	}
+	if !reflect.DeepEqual(got.Ports, []string{"80:30080/TCP", "53/UDP"}) {
+		t.Errorf("service ports = %v", got.Ports)
+	}
+}
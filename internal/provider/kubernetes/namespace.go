@@ -0,0 +1,35 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/eryajf/zenops/internal/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListNamespaces 列出命名空间
+func (c *Client) ListNamespaces(ctx context.Context) ([]*model.K8sNamespace, error) {
+	list, err := c.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	namespaces := make([]*model.K8sNamespace, 0, len(list.Items))
+	for _, ns := range list.Items {
+		namespaces = append(namespaces, &model.K8sNamespace{
+			Name:      ns.Name,
+			Cluster:   c.Cluster,
+			Status:    string(ns.Status.Phase),
+			Labels:    ns.Labels,
+			CreatedAt: ns.CreationTimestamp.Time,
+		})
+	}
+
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Name < namespaces[j].Name
+	})
+
+	return namespaces, nil
+}
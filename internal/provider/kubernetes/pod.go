@@ -0,0 +1,191 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/eryajf/zenops/internal/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+const (
+	// DefaultTailLines 默认返回的日志行数
+	DefaultTailLines int64 = 100
+	// MaxTailLines 单次允许返回的最大日志行数
+	MaxTailLines int64 = 2000
+	// maxLogBytes 单次读取日志的最大字节数, 避免超大日志撑爆 LLM 上下文
+	maxLogBytes int64 = 512 * 1024
+)
+
+// ListPods 列出 Pod
+func (c *Client) ListPods(ctx context.Context, namespace, labelSelector string) ([]*model.K8sPod, error) {
+	list, err := c.clientset.CoreV1().Pods(c.namespace(namespace)).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	pods := make([]*model.K8sPod, 0, len(list.Items))
+	for i := range list.Items {
+		pods = append(pods, c.convertPod(&list.Items[i]))
+	}
+
+	return pods, nil
+}
+
+// GetPod 获取 Pod 详情
+func (c *Client) GetPod(ctx context.Context, namespace, name string) (*model.K8sPod, error) {
+	pod, err := c.clientset.CoreV1().Pods(c.namespace(namespace)).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod: %w", err)
+	}
+
+	return c.convertPod(pod), nil
+}
+
+// FindPodsByIP 在所有命名空间中查找 Pod IP 匹配的 Pod
+func (c *Client) FindPodsByIP(ctx context.Context, ip string) ([]*model.K8sPod, error) {
+	// 使用 fieldSelector 让 API Server 过滤, 结果仍在本地校验一次 (fake clientset 不支持 fieldSelector)
+	list, err := c.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("status.podIP", ip).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var pods []*model.K8sPod
+	for i := range list.Items {
+		pod := &list.Items[i]
+		if podHasIP(pod, ip) {
+			pods = append(pods, c.convertPod(pod))
+		}
+	}
+
+	return pods, nil
+}
+
+// GetPodLogs 获取 Pod 最后 tailLines 行日志
+// container 为空时使用 Pod 的第一个容器
+func (c *Client) GetPodLogs(ctx context.Context, namespace, name, container string, tailLines int64, previous bool) (string, error) {
+	if tailLines <= 0 {
+		tailLines = DefaultTailLines
+	}
+	if tailLines > MaxTailLines {
+		tailLines = MaxTailLines
+	}
+
+	limitBytes := maxLogBytes
+	opts := &corev1.PodLogOptions{
+		Container:  container,
+		TailLines:  &tailLines,
+		LimitBytes: &limitBytes,
+		Previous:   previous,
+	}
+
+	data, err := c.clientset.CoreV1().Pods(c.namespace(namespace)).GetLogs(name, opts).DoRaw(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pod logs: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// podHasIP 判断 Pod 是否拥有指定 IP
+func podHasIP(pod *corev1.Pod, ip string) bool {
+	if pod.Status.PodIP == ip {
+		return true
+	}
+	for _, podIP := range pod.Status.PodIPs {
+		if podIP.IP == ip {
+			return true
+		}
+	}
+	return false
+}
+
+// convertPod 将 Pod 转换为统一的 K8sPod 模型
+func (c *Client) convertPod(pod *corev1.Pod) *model.K8sPod {
+	result := &model.K8sPod{
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+		Cluster:   c.Cluster,
+		Status:    podStatus(pod),
+		PodIP:     pod.Status.PodIP,
+		HostIP:    pod.Status.HostIP,
+		NodeName:  pod.Spec.NodeName,
+		Labels:    pod.Labels,
+		CreatedAt: pod.CreationTimestamp.Time,
+	}
+
+	for _, podIP := range pod.Status.PodIPs {
+		result.PodIPs = append(result.PodIPs, podIP.IP)
+	}
+
+	for _, container := range pod.Spec.Containers {
+		result.Containers = append(result.Containers, container.Name)
+	}
+
+	ready := 0
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Ready {
+			ready++
+		}
+		result.Restarts += status.RestartCount
+	}
+	result.Ready = fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers))
+
+	if len(pod.OwnerReferences) > 0 {
+		owner := pod.OwnerReferences[0]
+		result.Owner = owner.Kind + "/" + owner.Name
+	}
+
+	return result
+}
+
+// podStatus 计算 Pod 状态, 规则与 kubectl get pods 的 STATUS 列保持一致
+func podStatus(pod *corev1.Pod) string {
+	if pod.DeletionTimestamp != nil {
+		return "Terminating"
+	}
+
+	status := string(pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		status = pod.Status.Reason
+	}
+
+	// Init 容器未完成
+	for i, cs := range pod.Status.InitContainerStatuses {
+		if cs.State.Terminated != nil && cs.State.Terminated.ExitCode == 0 {
+			continue
+		}
+		if cs.State.Terminated != nil {
+			if cs.State.Terminated.Reason != "" {
+				return "Init:" + cs.State.Terminated.Reason
+			}
+			return fmt.Sprintf("Init:ExitCode:%d", cs.State.Terminated.ExitCode)
+		}
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" && cs.State.Waiting.Reason != "PodInitializing" {
+			return "Init:" + cs.State.Waiting.Reason
+		}
+		return fmt.Sprintf("Init:%d/%d", i, len(pod.Spec.InitContainers))
+	}
+
+	// 业务容器的等待/终止原因优先级高于 Phase
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+			return cs.State.Waiting.Reason
+		}
+		if cs.State.Terminated != nil && cs.State.Terminated.Reason != "" {
+			status = cs.State.Terminated.Reason
+		}
+	}
+
+	if strings.TrimSpace(status) == "" {
+		return "Unknown"
+	}
+	return status
+}
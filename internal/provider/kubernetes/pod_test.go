@@ -0,0 +1,242 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newPod 创建测试 Pod, 容器数量与 ready 状态由 statuses 决定
+func newPod(namespace, name, ip string, labels map[string]string, statuses ...corev1.ContainerStatus) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			PodIP:             ip,
+			HostIP:            "192.168.1.10",
+			ContainerStatuses: statuses,
+		},
+	}
+	for _, status := range statuses {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: status.Name})
+	}
+	return pod
+}
+
+func TestListPods(t *testing.T) {
+	created := metav1.NewTime(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	web := newPod("default", "web-5d8f7-abcde", "10.244.0.5", map[string]string{"app": "web"},
+		corev1.ContainerStatus{Name: "web", Ready: true, RestartCount: 2},
+		corev1.ContainerStatus{Name: "sidecar", Ready: false, RestartCount: 1},
+	)
+	web.CreationTimestamp = created
+	web.Status.PodIPs = []corev1.PodIP{{IP: "10.244.0.5"}, {IP: "fd00::5"}}
+	web.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-5d8f7"}}
+
+	db := newPod("default", "db-0", "10.244.0.6", map[string]string{"app": "db"}, corev1.ContainerStatus{Name: "db", Ready: true})
+	other := newPod("kube-system", "coredns", "10.244.0.7", map[string]string{"app": "web"}, corev1.ContainerStatus{Name: "coredns", Ready: true})
+
+	client := NewClientWithClientset("prod", fake.NewClientset(web, db, other), "default")
+
+	// 未指定命名空间时使用默认命名空间, 并按 labelSelector 过滤
+	pods, err := client.ListPods(context.Background(), "", "app=web")
+	if err != nil {
+		t.Fatalf("ListPods() error = %v", err)
+	}
+	if len(pods) != 1 {
+		t.Fatalf("ListPods() returned %d pods, want 1", len(pods))
+	}
+
+	got := pods[0]
+	if got.Name != "web-5d8f7-abcde" || got.Namespace != "default" || got.Cluster != "prod" || got.Status != "Running" {
+		t.Errorf("pod = %+v", got)
+	}
+	if got.Ready != "1/2" || got.Restarts != 3 {
+		t.Errorf("pod ready = %s, restarts = %d, want 1/2 and 3", got.Ready, got.Restarts)
+	}
+	if got.NodeName != "node-1" || got.HostIP != "192.168.1.10" || got.PodIP != "10.244.0.5" {
+		t.Errorf("pod node = %s/%s, pod ip = %s", got.NodeName, got.HostIP, got.PodIP)
+	}
+	if !reflect.DeepEqual(got.PodIPs, []string{"10.244.0.5", "fd00::5"}) || !reflect.DeepEqual(got.Containers, []string{"web", "sidecar"}) {
+		t.Errorf("pod ips = %v, containers = %v", got.PodIPs, got.Containers)
+	}
+	if got.Owner != "ReplicaSet/web-5d8f7" || !got.CreatedAt.Equal(created.Time) {
+		t.Errorf("pod owner = %s, created at = %v", got.Owner, got.CreatedAt)
+	}
+
+	// 显式指定命名空间
+	pods, err = client.ListPods(context.Background(), "kube-system", "")
+	if err != nil || len(pods) != 1 || pods[0].Name != "coredns" {
+		t.Errorf("ListPods(kube-system) = %+v, %v, want coredns", pods, err)
+	}
+}
+
+func TestGetPod(t *testing.T) {
+	client := NewClientWithClientset("prod", fake.NewClientset(newPod("default", "db-0", "10.244.0.6", nil)), "default")
+
+	pod, err := client.GetPod(context.Background(), "", "db-0")
+	if err != nil || pod.Name != "db-0" || pod.Ready != "0/0" {
+		t.Fatalf("GetPod() = %+v, %v", pod, err)
+	}
+	if _, err := client.GetPod(context.Background(), "", "missing"); err == nil {
+		t.Errorf("GetPod(missing) error = nil, want not found")
+	}
+}
+
+func TestFindPodsByIP(t *testing.T) {
+	dualStack := newPod("default", "dual", "10.244.0.8", nil)
+	dualStack.Status.PodIPs = []corev1.PodIP{{IP: "10.244.0.8"}, {IP: "fd00::8"}}
+
+	// 默认命名空间不限制按 IP 查找的范围
+	client := NewClientWithClientset("prod", fake.NewClientset(
+		newPod("default", "web", "10.244.0.5", nil),
+		newPod("kube-system", "coredns", "10.244.0.7", nil),
+		dualStack,
+	), "default")
+
+	tests := []struct {
+		ip   string
+		want []string
+	}{
+		{ip: "10.244.0.7", want: []string{"coredns"}},
+		{ip: "fd00::8", want: []string{"dual"}},
+		{ip: "10.0.0.1", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			pods, err := client.FindPodsByIP(context.Background(), tt.ip)
+			if err != nil {
+				t.Fatalf("FindPodsByIP() error = %v", err)
+			}
+			var names []string
+			for _, pod := range pods {
+				names = append(names, pod.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("FindPodsByIP(%s) = %v, want %v", tt.ip, names, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPodLogs(t *testing.T) {
+	tests := []struct {
+		name      string
+		tailLines int64
+		want      int64
+	}{
+		{name: "default", tailLines: 0, want: DefaultTailLines},
+		{name: "custom", tailLines: 20, want: 20},
+		{name: "capped", tailLines: MaxTailLines + 1, want: MaxTailLines},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewClientset(newPod("default", "web", "10.244.0.5", nil))
+			client := NewClientWithClientset("prod", clientset, "default")
+
+			logs, err := client.GetPodLogs(context.Background(), "", "web", "app", tt.tailLines, true)
+			if err != nil || logs != "fake logs" {
+				t.Fatalf("GetPodLogs() = %q, %v", logs, err)
+			}
+
+			var opts *corev1.PodLogOptions
+			for _, action := range clientset.Actions() {
+				if action.GetSubresource() == "log" {
+					opts, _ = action.(k8stesting.GenericActionImpl).Value.(*corev1.PodLogOptions)
+				}
+			}
+			if opts == nil {
+				t.Fatalf("no pod log request recorded")
+			}
+			if *opts.TailLines != tt.want || opts.Container != "app" || !opts.Previous || *opts.LimitBytes != maxLogBytes {
+				t.Errorf("log options = tail %d, container %q, previous %v, limit %d", *opts.TailLines, opts.Container, opts.Previous, *opts.LimitBytes)
+			}
+		})
+	}
+}
+
+func TestPodStatus(t *testing.T) {
+	now := metav1.Now()
+	tests := []struct {
+		name string
+		pod  corev1.Pod
+		want string
+	}{
+		{
+			name: "running",
+			pod:  corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+			want: "Running",
+		},
+		{
+			name: "terminating",
+			pod:  corev1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+			want: "Terminating",
+		},
+		{
+			name: "evicted reason",
+			pod:  corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"}},
+			want: "Evicted",
+		},
+		{
+			name: "crash loop",
+			pod: corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+				{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+			}}},
+			want: "CrashLoopBackOff",
+		},
+		{
+			name: "oom killed",
+			pod: corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+				{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}}},
+			}}},
+			want: "OOMKilled",
+		},
+		{
+			name: "init running",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: []corev1.Container{{Name: "migrate"}, {Name: "warmup"}}},
+				Status: corev1.PodStatus{Phase: corev1.PodPending, InitContainerStatuses: []corev1.ContainerStatus{
+					{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}},
+					{State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				}},
+			},
+			want: "Init:1/2",
+		},
+		{
+			name: "init failed",
+			pod: corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending, InitContainerStatuses: []corev1.ContainerStatus{
+				{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 2}}},
+			}}},
+			want: "Init:ExitCode:2",
+		},
+		{
+			name: "init image pull",
+			pod: corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending, InitContainerStatuses: []corev1.ContainerStatus{
+				{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+			}}},
+			want: "Init:ImagePullBackOff",
+		},
+		{
+			name: "unknown",
+			pod:  corev1.Pod{},
+			want: "Unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := podStatus(&tt.pod); got != tt.want {
+				t.Errorf("podStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
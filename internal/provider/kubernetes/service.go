@@ -0,0 +1,60 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eryajf/zenops/internal/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListServices 列出 Service
+func (c *Client) ListServices(ctx context.Context, namespace, labelSelector string) ([]*model.K8sService, error) {
+	list, err := c.clientset.CoreV1().Services(c.namespace(namespace)).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	services := make([]*model.K8sService, 0, len(list.Items))
+	for i := range list.Items {
+		services = append(services, c.convertService(&list.Items[i]))
+	}
+
+	return services, nil
+}
+
+// convertService 将 Service 转换为统一的 K8sService 模型
+func (c *Client) convertService(svc *corev1.Service) *model.K8sService {
+	result := &model.K8sService{
+		Name:        svc.Name,
+		Namespace:   svc.Namespace,
+		Cluster:     c.Cluster,
+		Type:        string(svc.Spec.Type),
+		ClusterIP:   svc.Spec.ClusterIP,
+		ExternalIPs: svc.Spec.ExternalIPs,
+		Selector:    svc.Spec.Selector,
+		CreatedAt:   svc.CreationTimestamp.Time,
+	}
+
+	// LoadBalancer 分配的地址
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			result.ExternalIPs = append(result.ExternalIPs, ingress.IP)
+		} else if ingress.Hostname != "" {
+			result.ExternalIPs = append(result.ExternalIPs, ingress.Hostname)
+		}
+	}
+
+	for _, port := range svc.Spec.Ports {
+		if port.NodePort != 0 {
+			result.Ports = append(result.Ports, fmt.Sprintf("%d:%d/%s", port.Port, port.NodePort, port.Protocol))
+		} else {
+			result.Ports = append(result.Ports, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,101 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eryajf/zenops/internal/model"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListDeployments 列出 Deployment
+func (c *Client) ListDeployments(ctx context.Context, namespace, labelSelector string) ([]*model.K8sWorkload, error) {
+	list, err := c.clientset.AppsV1().Deployments(c.namespace(namespace)).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	workloads := make([]*model.K8sWorkload, 0, len(list.Items))
+	for i := range list.Items {
+		workloads = append(workloads, c.convertDeployment(&list.Items[i]))
+	}
+
+	return workloads, nil
+}
+
+// ListStatefulSets 列出 StatefulSet
+func (c *Client) ListStatefulSets(ctx context.Context, namespace, labelSelector string) ([]*model.K8sWorkload, error) {
+	list, err := c.clientset.AppsV1().StatefulSets(c.namespace(namespace)).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+
+	workloads := make([]*model.K8sWorkload, 0, len(list.Items))
+	for i := range list.Items {
+		workloads = append(workloads, c.convertStatefulSet(&list.Items[i]))
+	}
+
+	return workloads, nil
+}
+
+// convertDeployment 将 Deployment 转换为统一的 K8sWorkload 模型
+func (c *Client) convertDeployment(d *appsv1.Deployment) *model.K8sWorkload {
+	workload := &model.K8sWorkload{
+		Kind:              "Deployment",
+		Name:              d.Name,
+		Namespace:         d.Namespace,
+		Cluster:           c.Cluster,
+		ReadyReplicas:     d.Status.ReadyReplicas,
+		UpdatedReplicas:   d.Status.UpdatedReplicas,
+		AvailableReplicas: d.Status.AvailableReplicas,
+		Images:            containerImages(d.Spec.Template.Spec.Containers),
+		Labels:            d.Labels,
+		CreatedAt:         d.CreationTimestamp.Time,
+	}
+
+	// 未设置 replicas 时默认为 1
+	workload.Replicas = 1
+	if d.Spec.Replicas != nil {
+		workload.Replicas = *d.Spec.Replicas
+	}
+
+	return workload
+}
+
+// convertStatefulSet 将 StatefulSet 转换为统一的 K8sWorkload 模型
+func (c *Client) convertStatefulSet(s *appsv1.StatefulSet) *model.K8sWorkload {
+	workload := &model.K8sWorkload{
+		Kind:              "StatefulSet",
+		Name:              s.Name,
+		Namespace:         s.Namespace,
+		Cluster:           c.Cluster,
+		ReadyReplicas:     s.Status.ReadyReplicas,
+		UpdatedReplicas:   s.Status.UpdatedReplicas,
+		AvailableReplicas: s.Status.AvailableReplicas,
+		Images:            containerImages(s.Spec.Template.Spec.Containers),
+		Labels:            s.Labels,
+		CreatedAt:         s.CreationTimestamp.Time,
+	}
+
+	workload.Replicas = 1
+	if s.Spec.Replicas != nil {
+		workload.Replicas = *s.Spec.Replicas
+	}
+
+	return workload
+}
+
+// containerImages 提取容器镜像列表
+func containerImages(containers []corev1.Container) []string {
+	images := make([]string, 0, len(containers))
+	for _, container := range containers {
+		images = append(images, container.Image)
+	}
+	return images
+}
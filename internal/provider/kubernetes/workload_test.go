@@ -0,0 +1,84 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+func TestListDeployments(t *testing.T) {
+	web := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{"app": "web"}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(3),
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "web", Image: "nginx:1.27"},
+				{Name: "sidecar", Image: "envoy:v1.30"},
+			}}},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: 2, UpdatedReplicas: 3, AvailableReplicas: 2},
+	}
+	// 未设置 replicas 的 Deployment 按 1 个副本处理
+	worker := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default", Labels: map[string]string{"app": "worker"}},
+	}
+	other := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system", Labels: map[string]string{"app": "web"}},
+	}
+
+	client := NewClientWithClientset("prod", fake.NewClientset(web, worker, other), "default")
+
+	workloads, err := client.ListDeployments(context.Background(), "", "app=web")
+	if err != nil {
+		t.Fatalf("ListDeployments() error = %v", err)
+	}
+	if len(workloads) != 1 {
+		t.Fatalf("ListDeployments() returned %d workloads, want 1", len(workloads))
+	}
+	got := workloads[0]
+	if got.Kind != "Deployment" || got.Name != "web" || got.Namespace != "default" || got.Cluster != "prod" {
+		t.Errorf("deployment = %+v", got)
+	}
+	if got.Replicas != 3 || got.ReadyReplicas != 2 || got.UpdatedReplicas != 3 || got.AvailableReplicas != 2 {
+		t.Errorf("deployment replicas = %d/%d/%d/%d, want 3/2/3/2", got.Replicas, got.ReadyReplicas, got.UpdatedReplicas, got.AvailableReplicas)
+	}
+	if !reflect.DeepEqual(got.Images, []string{"nginx:1.27", "envoy:v1.30"}) {
+		t.Errorf("deployment images = %v", got.Images)
+	}
+
+	workloads, err = client.ListDeployments(context.Background(), "default", "app=worker")
+	if err != nil || len(workloads) != 1 || workloads[0].Replicas != 1 || len(workloads[0].Images) != 0 {
+		t.Errorf("ListDeployments(worker) = %+v, %v, want 1 replica without images", workloads, err)
+	}
+}
+
+func TestListStatefulSets(t *testing.T) {
+	db := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysql", Namespace: "data"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: int32Ptr(2),
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "mysql", Image: "mysql:8.0"}}}},
+		},
+		Status: appsv1.StatefulSetStatus{ReadyReplicas: 1},
+	}
+
+	// 默认命名空间为空时查询所有命名空间
+	client := NewClientWithClientset("prod", fake.NewClientset(db), "")
+
+	workloads, err := client.ListStatefulSets(context.Background(), "", "")
+	if err != nil {
+		t.Fatalf("ListStatefulSets() error = %v", err)
+	}
+	if len(workloads) != 1 || workloads[0].Kind != "StatefulSet" || workloads[0].Replicas != 2 || workloads[0].ReadyReplicas != 1 {
+		t.Fatalf("ListStatefulSets() = %+v", workloads)
+	}
+}
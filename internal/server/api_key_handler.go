@@ -0,0 +1,220 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler API Key 处理器
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+// NewAPIKeyHandler 创建 API Key 处理器
+func NewAPIKeyHandler() *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: service.NewAPIKeyService(),
+	}
+}
+
+// CreateAPIKeyRequest 创建 API Key 请求
+type CreateAPIKeyRequest struct {
+	Name           string     `json:"name" binding:"required"`
+	Description    string     `json:"description"`
+	Scopes         []string   `json:"scopes" binding:"required"` // 如 ["cloud:read", "chat:write"], "*" 表示全部
+	ExpiresAt      *time.Time `json:"expires_at"`                // 为空表示永不过期
+	ServiceAccount string     `json:"service_account"`           // 服务账号名称, 仅管理员接口使用
+}
+
+// ListMyKeys 列出当前用户的个人访问令牌
+func (h *APIKeyHandler) ListMyKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.ListUserKeys(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    keys,
+	})
+}
+
+// CreateMyKey 为当前用户创建个人访问令牌, 实际权限不超过用户的角色
+func (h *APIKeyHandler) CreateMyKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	key := req.apiKey(c)
+	key.UserID = c.GetUint("user_id")
+	key.ServiceAccount = ""
+	h.createKey(c, key)
+}
+
+// RevokeMyKey 吊销当前用户的个人访问令牌
+func (h *APIKeyHandler) RevokeMyKey(c *gin.Context) {
+	key, ok := h.loadKey(c)
+	if !ok {
+		return
+	}
+	if key.UserID != c.GetUint("user_id") {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "api key not found",
+		})
+		return
+	}
+
+	h.revokeKey(c, key)
+}
+
+// ListKeys 列出所有用户和服务账号的 API Key
+func (h *APIKeyHandler) ListKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.ListKeys()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    keys,
+	})
+}
+
+// CreateServiceKey 为服务账号创建 API Key
+func (h *APIKeyHandler) CreateServiceKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+	if req.ServiceAccount == "" {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "service_account is required",
+		})
+		return
+	}
+
+	h.createKey(c, req.apiKey(c))
+}
+
+// RevokeKey 吊销任意 API Key
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+	key, ok := h.loadKey(c)
+	if !ok {
+		return
+	}
+
+	h.revokeKey(c, key)
+}
+
+// apiKey 根据请求生成 API Key 记录
+func (req *CreateAPIKeyRequest) apiKey(c *gin.Context) *model.APIKey {
+	return &model.APIKey{
+		Name:           req.Name,
+		Description:    req.Description,
+		Scopes:         req.Scopes,
+		ExpiresAt:      req.ExpiresAt,
+		ServiceAccount: req.ServiceAccount,
+		CreatedBy:      c.GetString("username"),
+	}
+}
+
+// createKey 校验并创建 API Key, Key 明文只在本次响应中返回
+func (h *APIKeyHandler) createKey(c *gin.Context, key *model.APIKey) {
+	if err := service.ValidateAPIKey(key); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	secret, err := h.apiKeyService.CreateKey(key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "API key created successfully, please save it now as it will not be shown again",
+		Data: gin.H{
+			"key":    key,
+			"secret": secret,
+		},
+	})
+}
+
+// revokeKey 吊销 API Key
+func (h *APIKeyHandler) revokeKey(c *gin.Context, key *model.APIKey) {
+	if err := h.apiKeyService.RevokeKey(key); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "API key revoked successfully",
+		Data:    key,
+	})
+}
+
+// loadKey 根据路径参数加载 API Key, 失败时已写入响应
+func (h *APIKeyHandler) loadKey(c *gin.Context) (*model.APIKey, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return nil, false
+	}
+
+	key, err := h.apiKeyService.GetKey(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	if key == nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "api key not found",
+		})
+		return nil, false
+	}
+	return key, true
+}
@@ -1,9 +1,13 @@
 package server
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
-	"strings"
+	"time"
 
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/config"
 	"github.com/eryajf/zenops/internal/middleware"
 	"github.com/eryajf/zenops/internal/model"
 	"github.com/eryajf/zenops/internal/service"
@@ -12,13 +16,17 @@ import (
 
 // AuthHandler 认证处理器
 type AuthHandler struct {
-	configService *service.ConfigService
+	config         *config.Config
+	userService    *service.UserService
+	sessionService *service.SessionService
 }
 
 // NewAuthHandler 创建认证处理器
-func NewAuthHandler() *AuthHandler {
+func NewAuthHandler(cfg *config.Config) *AuthHandler {
 	return &AuthHandler{
-		configService: service.NewConfigService(),
+		config:         cfg,
+		userService:    service.NewUserService(),
+		sessionService: service.NewSessionService(),
 	}
 }
 
@@ -30,18 +38,43 @@ type LoginRequest struct {
 
 // LoginResponse 登录响应
 type LoginResponse struct {
-	AccessToken string       `json:"accessToken"`
-	UserInfo    UserInfoData `json:"userInfo"`
+	AccessToken  string       `json:"accessToken"`
+	RefreshToken string       `json:"refreshToken"` // 访问令牌过期后通过 /auth/refresh 换取新令牌
+	ExpiresIn    int          `json:"expiresIn"`    // 访问令牌有效期, 单位秒
+	UserInfo     UserInfoData `json:"userInfo"`
+}
+
+// RefreshRequest 刷新令牌请求
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
 }
 
 // UserInfoData 用户信息
 type UserInfoData struct {
-	ID       uint     `json:"id"`
-	Username string   `json:"username"`
-	Nickname string   `json:"nickname"`
-	Email    string   `json:"email"`
-	Avatar   string   `json:"avatar,omitempty"`
-	Roles    []string `json:"roles"`
+	ID                 uint     `json:"id"`
+	Username           string   `json:"username"`
+	Nickname           string   `json:"nickname"`
+	Email              string   `json:"email"`
+	Avatar             string   `json:"avatar,omitempty"`
+	Roles              []string `json:"roles"`
+	MustChangePassword bool     `json:"mustChangePassword"` // 为 true 时需先修改密码才能访问其他接口
+}
+
+// newUserInfoData 转换为返回给前端的用户信息
+func newUserInfoData(user *model.User) UserInfoData {
+	roles := user.RoleList()
+	if len(roles) == 0 {
+		roles = []string{model.RoleUser}
+	}
+	return UserInfoData{
+		ID:                 user.ID,
+		Username:           user.Username,
+		Nickname:           user.Nickname,
+		Email:              user.Email,
+		Avatar:             user.Avatar,
+		Roles:              roles,
+		MustChangePassword: user.MustChangePassword,
+	}
 }
 
 // Login 用户登录
@@ -55,124 +88,315 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	db := h.configService.GetDB()
-
-	// 查询用户
-	var user model.User
-	if err := db.Where("username = ?", req.Username).First(&user).Error; err != nil {
+	user, err := h.userService.Authenticate(req.Username, req.Password, h.passwordBackends()...)
+	switch {
+	case errors.Is(err, service.ErrInvalidCredentials):
 		c.JSON(http.StatusOK, Response{
 			Code:    401,
 			Message: "用户名或密码错误",
 		})
 		return
+	case errors.Is(err, service.ErrUserLocked):
+		c.JSON(http.StatusOK, Response{
+			Code:    403,
+			Message: fmt.Sprintf("登录失败次数过多, 账号已锁定至 %s", user.LockedUntil.Format(time.DateTime)),
+		})
+		return
+	case err != nil:
+		h.respondLoginError(c, err)
+		return
 	}
 
-	// 检查用户是否启用
-	if !user.Enabled {
+	h.respondToken(c, user, "登录成功")
+}
+
+// passwordBackends 返回已启用的用户名密码外部认证源
+func (h *AuthHandler) passwordBackends() []service.PasswordBackend {
+	var backends []service.PasswordBackend
+	if ldapConfig := service.NewConfigService().LDAPConfig(h.config.Auth.LDAP); ldapConfig.Enabled {
+		backends = append(backends, service.NewLDAPBackend(ldapConfig))
+	}
+	return backends
+}
+
+// oidcConfig 返回 OIDC 单点登录配置
+func (h *AuthHandler) oidcConfig() config.OIDCConfig {
+	return service.NewConfigService().OIDCConfig(h.config.Auth.OIDC)
+}
+
+// respondLoginError 返回登录失败的原因
+func (h *AuthHandler) respondLoginError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserDisabled):
 		c.JSON(http.StatusOK, Response{
 			Code:    403,
 			Message: "用户已被禁用",
 		})
+	case errors.Is(err, service.ErrNoRoleMapped):
+		c.JSON(http.StatusOK, Response{
+			Code:    403,
+			Message: "用户所属的用户组未分配 ZenOps 角色, 请联系管理员",
+		})
+	case errors.Is(err, service.ErrUserSourceConflict):
+		c.JSON(http.StatusOK, Response{
+			Code:    403,
+			Message: "用户名已被其他来源的用户使用, 请联系管理员",
+		})
+	default:
+		logx.Error("Login failed: %v", err)
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+	}
+}
+
+// GetProviders 返回登录页可用的登录方式
+func (h *AuthHandler) GetProviders(c *gin.Context) {
+	oidcConfig := h.oidcConfig()
+	name := oidcConfig.Name
+	if name == "" {
+		name = "SSO"
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data: gin.H{
+			"local": true,
+			"ldap":  service.NewConfigService().LDAPConfig(h.config.Auth.LDAP).Enabled,
+			"oidc": gin.H{
+				"enabled": oidcConfig.Enabled,
+				"name":    name,
+			},
+		},
+	})
+}
+
+// OIDCAuthorize 生成 OIDC 授权地址, 前端跳转到该地址登录
+func (h *AuthHandler) OIDCAuthorize(c *gin.Context) {
+	oidcConfig := h.oidcConfig()
+	if !oidcConfig.Enabled {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "未启用 OIDC 单点登录",
+		})
+		return
+	}
+
+	url, err := service.NewOIDCBackend(oidcConfig).AuthCodeURL(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    gin.H{"url": url},
+	})
+}
+
+// OIDCCallbackRequest OIDC 回调请求, 前端回调页面将地址中的 code 和 state 提交到后端
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// OIDCCallback 使用授权码完成 OIDC 登录, 首次登录时自动创建用户
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	oidcConfig := h.oidcConfig()
+	if !oidcConfig.Enabled {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "未启用 OIDC 单点登录",
+		})
+		return
+	}
+
+	var req OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
 		return
 	}
 
-	// 验证密码
-	if !user.CheckPassword(req.Password) {
+	identity, err := service.NewOIDCBackend(oidcConfig).Exchange(c.Request.Context(), req.Code, req.State)
+	if errors.Is(err, service.ErrInvalidOIDCState) {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "登录请求已过期, 请重新登录",
+		})
+		return
+	}
+	if err != nil {
+		logx.Warn("OIDC login failed: %v", err)
 		c.JSON(http.StatusOK, Response{
 			Code:    401,
-			Message: "用户名或密码错误",
+			Message: "单点登录失败: " + err.Error(),
 		})
 		return
 	}
 
-	// 生成 JWT Token
-	token, err := middleware.GenerateToken(user.ID, user.Username, user.Roles)
+	user, err := h.userService.AuthenticateExternal(identity)
+	if err != nil {
+		h.respondLoginError(c, err)
+		return
+	}
+
+	h.respondToken(c, user, "登录成功")
+}
+
+// respondToken 创建登录会话, 返回访问令牌、刷新令牌和用户信息
+func (h *AuthHandler) respondToken(c *gin.Context, user *model.User, message string) {
+	session, refreshToken, err := h.sessionService.CreateSession(user, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, Response{
 			Code:    500,
-			Message: "生成令牌失败: " + err.Error(),
+			Message: "创建会话失败: " + err.Error(),
 		})
 		return
 	}
 
-	// 解析角色列表
-	var roles []string
-	if user.Roles != "" {
-		roles = strings.Split(user.Roles, ",")
-	} else {
-		roles = []string{"user"}
+	h.respondSessionToken(c, user, session, refreshToken, message)
+}
+
+// respondSessionToken 为已有会话生成访问令牌并返回
+func (h *AuthHandler) respondSessionToken(c *gin.Context, user *model.User, session *model.UserSession, refreshToken, message string) {
+	token, err := middleware.GenerateToken(user, session.SessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "生成令牌失败: " + err.Error(),
+		})
+		return
 	}
 
-	// 返回登录成功响应
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "登录成功",
+		Message: message,
 		Data: LoginResponse{
-			AccessToken: token,
-			UserInfo: UserInfoData{
-				ID:       user.ID,
-				Username: user.Username,
-				Nickname: user.Nickname,
-				Email:    user.Email,
-				Avatar:   user.Avatar,
-				Roles:    roles,
-			},
+			AccessToken:  token,
+			RefreshToken: refreshToken,
+			ExpiresIn:    int(service.AccessTokenTTL.Seconds()),
+			UserInfo:     newUserInfoData(user),
 		},
 	})
 }
 
-// Logout 用户登出
+// Refresh 使用刷新令牌换取新的访问令牌, 同时返回新的刷新令牌, 旧的刷新令牌立即失效
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	session, refreshToken, err := h.sessionService.Refresh(req.RefreshToken)
+	if errors.Is(err, service.ErrInvalidSession) {
+		c.JSON(http.StatusUnauthorized, Response{
+			Code:    401,
+			Message: "刷新令牌无效或已过期, 请重新登录",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, err := h.userService.GetUser(session.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+	if user == nil || !user.Enabled {
+		if err := h.sessionService.RevokeSession(session.UserID, session.SessionID); err != nil && !errors.Is(err, service.ErrInvalidSession) {
+			logx.Warn("Failed to revoke session %s: %v", session.SessionID, err)
+		}
+		c.JSON(http.StatusUnauthorized, Response{
+			Code:    401,
+			Message: "用户不存在或已被禁用",
+		})
+		return
+	}
+
+	h.respondSessionToken(c, user, session, refreshToken, "刷新成功")
+}
+
+// Logout 用户登出, 吊销当前会话
 func (h *AuthHandler) Logout(c *gin.Context) {
-	// JWT 是无状态的，登出只需要客户端删除 token 即可
+	if sessionID := c.GetString("session_id"); sessionID != "" {
+		err := h.sessionService.RevokeSession(c.GetUint("user_id"), sessionID)
+		if err != nil && !errors.Is(err, service.ErrInvalidSession) {
+			c.JSON(http.StatusInternalServerError, Response{
+				Code:    500,
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "登出成功",
 	})
 }
 
-// GetUserInfo 获取当前用户信息
-func (h *AuthHandler) GetUserInfo(c *gin.Context) {
-	// 从中间件中获取用户信息
-	userID, exists := c.Get("user_id")
-	if !exists {
+// currentUser 获取当前登录的用户, 失败时已写入响应
+func (h *AuthHandler) currentUser(c *gin.Context) (*model.User, bool) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
 		c.JSON(http.StatusUnauthorized, Response{
 			Code:    401,
 			Message: "未认证",
 		})
-		return
+		return nil, false
 	}
 
-	db := h.configService.GetDB()
-
-	// 查询用户详细信息
-	var user model.User
-	if err := db.First(&user, userID).Error; err != nil {
+	user, err := h.userService.GetUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	if user == nil {
 		c.JSON(http.StatusNotFound, Response{
 			Code:    404,
 			Message: "用户不存在",
 		})
-		return
+		return nil, false
 	}
+	return user, true
+}
 
-	// 解析角色列表
-	var roles []string
-	if user.Roles != "" {
-		roles = strings.Split(user.Roles, ",")
-	} else {
-		roles = []string{"user"}
+// GetUserInfo 获取当前用户信息
+func (h *AuthHandler) GetUserInfo(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
 	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
-		Data: UserInfoData{
-			ID:       user.ID,
-			Username: user.Username,
-			Nickname: user.Nickname,
-			Email:    user.Email,
-			Avatar:   user.Avatar,
-			Roles:    roles,
-		},
+		Data:    newUserInfoData(user),
 	})
 }
 
@@ -183,14 +407,10 @@ type ChangePasswordRequest struct {
 }
 
 // ChangePassword 修改密码
+// 修改成功后吊销原有会话并返回新的 Token, 需修改初始密码的用户用新 Token 才能访问其他接口
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
-	// 从中间件中获取用户信息
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, Response{
-			Code:    401,
-			Message: "未认证",
-		})
+	user, ok := h.currentUser(c)
+	if !ok {
 		return
 	}
 
@@ -203,47 +423,85 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	db := h.configService.GetDB()
-
-	// 查询用户
-	var user model.User
-	if err := db.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, Response{
-			Code:    404,
-			Message: "用户不存在",
+	err := h.userService.ChangePassword(user, req.OldPassword, req.NewPassword)
+	switch {
+	case errors.Is(err, service.ErrInvalidCredentials):
+		c.JSON(http.StatusOK, Response{
+			Code:    400,
+			Message: "原密码错误",
 		})
 		return
-	}
-
-	// 验证旧密码
-	if !user.CheckPassword(req.OldPassword) {
+	case errors.Is(err, service.ErrExternalUser):
 		c.JSON(http.StatusOK, Response{
 			Code:    400,
-			Message: "原密码错误",
+			Message: "LDAP 或 OIDC 用户请在认证源修改密码",
+		})
+		return
+	case errors.Is(err, service.ErrPasswordUnchanged):
+		c.JSON(http.StatusOK, Response{
+			Code:    400,
+			Message: "新密码不能与原密码相同",
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "保存密码失败: " + err.Error(),
 		})
 		return
 	}
 
-	// 设置新密码
-	if err := user.SetPassword(req.NewPassword); err != nil {
+	// 吊销包括当前会话在内的全部会话, 其他设备需使用新密码重新登录
+	if err := h.sessionService.RevokeUserSessions(user.ID); err != nil {
 		c.JSON(http.StatusInternalServerError, Response{
 			Code:    500,
-			Message: "设置新密码失败: " + err.Error(),
+			Message: err.Error(),
 		})
 		return
 	}
 
-	// 更新数据库
-	if err := db.Save(&user).Error; err != nil {
+	h.respondToken(c, user, "密码修改成功")
+}
+
+// ListSigningKeys 列出 JWT 签名密钥, 不返回密钥内容
+func (h *AuthHandler) ListSigningKeys(c *gin.Context) {
+	keys, err := service.NewJWTKeyService().ListKeys()
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, Response{
 			Code:    500,
-			Message: "保存密码失败: " + err.Error(),
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    keys,
+	})
+}
+
+// RotateSigningKey 轮换 JWT 签名密钥
+func (h *AuthHandler) RotateSigningKey(c *gin.Context) {
+	key, err := service.NewJWTKeyService().RotateKey()
+	if errors.Is(err, service.ErrEnvJWTKey) {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "密码修改成功",
+		Message: "JWT signing key rotated successfully",
+		Data:    key,
 	})
 }
@@ -0,0 +1,382 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider"
+	"github.com/eryajf/zenops/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ==================== AWS EC2 API ====================
+
+func (s *HTTPGinServer) handleAWSEC2List(c *gin.Context) {
+	accountName := c.Query("account")
+	region := c.Query("region")
+	status := c.Query("status")
+
+	p, awsConfig, err := s.getAWSProvider(accountName)
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filters := map[string]string{}
+	if status != "" {
+		filters["status"] = status
+	}
+
+	var allInstances []*model.Instance
+	pageNum := 1
+	pageSize := 100
+
+	for {
+		opts := &provider.QueryOptions{
+			Region:   region,
+			PageSize: pageSize,
+			PageNum:  pageNum,
+			Filters:  filters,
+		}
+
+		instances, err := p.ListInstances(c.Request.Context(), opts)
+		if err != nil {
+			s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to list instances: %v", err))
+			return
+		}
+
+		allInstances = append(allInstances, instances...)
+
+		if len(instances) < pageSize {
+			break
+		}
+		pageNum++
+	}
+
+	s.success(c, gin.H{
+		"total":     len(allInstances),
+		"instances": allInstances,
+		"account":   awsConfig.Name,
+	})
+}
+
+func (s *HTTPGinServer) handleAWSEC2Search(c *gin.Context) {
+	accountName := c.Query("account")
+	region := c.Query("region")
+	ip := c.Query("ip")
+	instanceName := c.Query("name")
+
+	if ip == "" && instanceName == "" {
+		s.error(c, http.StatusBadRequest, "Either 'ip' or 'name' parameter is required")
+		return
+	}
+
+	p, awsConfig, err := s.getAWSProvider(accountName)
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var matchedInstances []*model.Instance
+	pageNum := 1
+	pageSize := 100
+
+	for {
+		opts := &provider.QueryOptions{
+			Region:   region,
+			PageSize: pageSize,
+			PageNum:  pageNum,
+		}
+
+		instances, err := p.ListInstances(c.Request.Context(), opts)
+		if err != nil {
+			s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to list instances: %v", err))
+			return
+		}
+
+		for _, inst := range instances {
+			if ip != "" && (containsIP(inst.PrivateIP, ip) || containsIP(inst.PublicIP, ip)) {
+				matchedInstances = append(matchedInstances, inst)
+				continue
+			}
+			if instanceName != "" && inst.Name == instanceName {
+				matchedInstances = append(matchedInstances, inst)
+			}
+		}
+
+		if len(instances) < pageSize {
+			break
+		}
+		pageNum++
+	}
+
+	if len(matchedInstances) == 0 {
+		s.error(c, http.StatusNotFound, "No matching instances found")
+		return
+	}
+
+	s.success(c, gin.H{
+		"total":     len(matchedInstances),
+		"instances": matchedInstances,
+		"account":   awsConfig.Name,
+	})
+}
+
+func (s *HTTPGinServer) handleAWSEC2Get(c *gin.Context) {
+	accountName := c.Query("account")
+	instanceID := c.Query("instance_id")
+
+	if instanceID == "" {
+		s.error(c, http.StatusBadRequest, "instance_id is required")
+		return
+	}
+
+	p, awsConfig, err := s.getAWSProvider(accountName)
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	instance, err := p.GetInstance(c.Request.Context(), instanceID)
+	if err != nil {
+		s.error(c, http.StatusNotFound, fmt.Sprintf("Failed to get instance: %v", err))
+		return
+	}
+
+	s.success(c, gin.H{
+		"instance": instance,
+		"account":  awsConfig.Name,
+	})
+}
+
+// ==================== AWS RDS API ====================
+
+func (s *HTTPGinServer) handleAWSRDSList(c *gin.Context) {
+	accountName := c.Query("account")
+	region := c.Query("region")
+
+	p, awsConfig, err := s.getAWSProvider(accountName)
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var allDatabases []*model.Database
+	pageNum := 1
+	pageSize := 100
+
+	for {
+		opts := &provider.QueryOptions{
+			Region:   region,
+			PageSize: pageSize,
+			PageNum:  pageNum,
+		}
+
+		databases, err := p.ListDatabases(c.Request.Context(), opts)
+		if err != nil {
+			s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to list databases: %v", err))
+			return
+		}
+
+		allDatabases = append(allDatabases, databases...)
+
+		if len(databases) < pageSize {
+			break
+		}
+		pageNum++
+	}
+
+	s.success(c, gin.H{
+		"total":     len(allDatabases),
+		"databases": allDatabases,
+		"account":   awsConfig.Name,
+	})
+}
+
+func (s *HTTPGinServer) handleAWSRDSGet(c *gin.Context) {
+	accountName := c.Query("account")
+	dbID := c.Query("db_instance_identifier")
+
+	if dbID == "" {
+		s.error(c, http.StatusBadRequest, "db_instance_identifier is required")
+		return
+	}
+
+	p, awsConfig, err := s.getAWSProvider(accountName)
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	database, err := p.GetDatabase(c.Request.Context(), dbID)
+	if err != nil {
+		s.error(c, http.StatusNotFound, fmt.Sprintf("Failed to get database: %v", err))
+		return
+	}
+
+	s.success(c, gin.H{
+		"database": database,
+		"account":  awsConfig.Name,
+	})
+}
+
+// ==================== AWS S3 API ====================
+
+func (s *HTTPGinServer) handleAWSS3List(c *gin.Context) {
+	accountName := c.Query("account")
+
+	p, awsConfig, err := s.getAWSProvider(accountName)
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts := &provider.QueryOptions{
+		Filters: map[string]string{},
+	}
+	if prefix := c.Query("prefix"); prefix != "" {
+		opts.Filters["prefix"] = prefix
+	}
+
+	buckets, err := p.ListOSSBuckets(c.Request.Context(), opts)
+	if err != nil {
+		s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to list buckets: %v", err))
+		return
+	}
+
+	s.success(c, gin.H{
+		"total":   len(buckets),
+		"buckets": buckets,
+		"account": awsConfig.Name,
+	})
+}
+
+func (s *HTTPGinServer) handleAWSS3Get(c *gin.Context) {
+	accountName := c.Query("account")
+	bucketName := c.Query("bucket_name")
+
+	if bucketName == "" {
+		s.error(c, http.StatusBadRequest, "bucket_name is required")
+		return
+	}
+
+	p, awsConfig, err := s.getAWSProvider(accountName)
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bucket, err := p.GetOSSBucket(c.Request.Context(), bucketName)
+	if err != nil {
+		s.error(c, http.StatusNotFound, fmt.Sprintf("Failed to get bucket: %v", err))
+		return
+	}
+
+	s.success(c, gin.H{
+		"bucket":  bucket,
+		"account": awsConfig.Name,
+	})
+}
+
+// getAWSProvider 获取并初始化指定账号的 AWS Provider
+func (s *HTTPGinServer) getAWSProvider(accountName string) (provider.Provider, *config.ProviderConfig, error) {
+	awsConfig, err := getAWSConfigByName(s.config, accountName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, err := provider.GetProvider("aws")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get provider: %w", err)
+	}
+
+	providerConfig := map[string]any{
+		"access_key_id":     awsConfig.AK,
+		"secret_access_key": awsConfig.SK,
+		"regions":           interfaceSlice(awsConfig.Regions),
+		"endpoint":          awsConfig.Extra["endpoint"],
+	}
+
+	if err := p.Initialize(providerConfig); err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize provider: %w", err)
+	}
+
+	return p, awsConfig, nil
+}
+
+// getAWSConfigByName 根据名称获取 AWS 账号配置
+func getAWSConfigByName(cfg *config.Config, accountName string) (*config.ProviderConfig, error) {
+	// 先尝试从数据库加载
+	configService := service.NewConfigService()
+	accounts, err := configService.ListProviderAccounts("aws")
+	if err == nil && len(accounts) > 0 {
+		logx.Debug("Loading aws config from database for HTTP API, account count %d", len(accounts))
+
+		toProviderConfig := func(acc model.ProviderAccount) *config.ProviderConfig {
+			return &config.ProviderConfig{
+				Name:    acc.Name,
+				Enabled: acc.Enabled,
+				AK:      acc.AccessKey,
+				SK:      acc.SecretKey,
+				Regions: acc.Regions,
+				Extra:   map[string]string{"endpoint": acc.Endpoint},
+			}
+		}
+
+		// 如果没有指定账号名,返回第一个启用的账号
+		if accountName == "" {
+			for _, acc := range accounts {
+				if acc.Enabled {
+					return toProviderConfig(acc), nil
+				}
+			}
+			// 如果没有启用的,返回第一个
+			return toProviderConfig(accounts[0]), nil
+		}
+
+		// 根据名称查找
+		for _, acc := range accounts {
+			if acc.Name == accountName {
+				return toProviderConfig(acc), nil
+			}
+		}
+
+		return nil, fmt.Errorf("aws account '%s' not found in database", accountName)
+	}
+
+	// 如果数据库没有配置,回退到 YAML 配置
+	logx.Debug("No aws config in database for HTTP API, falling back to YAML config")
+	if len(cfg.Providers.AWS) == 0 {
+		return nil, fmt.Errorf("no aws account configured")
+	}
+
+	if accountName == "" {
+		for _, acc := range cfg.Providers.AWS {
+			if acc.Enabled {
+				return &acc, nil
+			}
+		}
+		return &cfg.Providers.AWS[0], nil
+	}
+
+	for _, acc := range cfg.Providers.AWS {
+		if acc.Name == accountName {
+			return &acc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("aws account '%s' not found", accountName)
+}
+
+// containsIP 判断 IP 列表中是否包含指定 IP
+func containsIP(ips []string, ip string) bool {
+	for _, item := range ips {
+		if item == ip {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/eryajf/zenops/internal/config"
 	"github.com/eryajf/zenops/internal/imcp"
 	"github.com/eryajf/zenops/internal/llm"
+	"github.com/eryajf/zenops/internal/middleware"
 	"github.com/eryajf/zenops/internal/model"
 	"github.com/eryajf/zenops/internal/service"
 	"github.com/gin-gonic/gin"
@@ -32,9 +34,10 @@ func NewChatHandler(cfg *config.Config, mcpServer *imcp.MCPServer) *ChatHandler
 	var llmClient *llm.Client
 	if cfg.LLM.Enabled {
 		llmConfig := &llm.Config{
-			Model:   cfg.LLM.Model,
-			APIKey:  cfg.LLM.APIKey,
-			BaseURL: cfg.LLM.BaseURL,
+			Provider: cfg.LLM.Provider,
+			Model:    cfg.LLM.Model,
+			APIKey:   cfg.LLM.APIKey,
+			BaseURL:  cfg.LLM.BaseURL,
 		}
 		llmClient = llm.NewClient(llmConfig, mcpServer)
 	}
@@ -48,90 +51,188 @@ func NewChatHandler(cfg *config.Config, mcpServer *imcp.MCPServer) *ChatHandler
 	}
 }
 
-// ChatMessage 对话消息
+// ChatMessage 对话消息, 兼容 OpenAI 消息格式
+// content 可以是字符串或内容块数组, 内容块中只使用文本部分
 type ChatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string         `json:"role"`
+	Content    any            `json:"content"`
+	Name       string         `json:"name,omitempty"`
+	ToolCalls  []llm.ToolCall `json:"tool_calls,omitempty"`   // assistant 发起的工具调用
+	ToolCallID string         `json:"tool_call_id,omitempty"` // tool 消息对应的工具调用
 }
 
-// ChatRequest 对话请求
+// StopSequences 停止序列, 兼容字符串和字符串数组两种写法
+type StopSequences []string
+
+// UnmarshalJSON 解析字符串或字符串数组
+func (s *StopSequences) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		if single != "" {
+			*s = StopSequences{single}
+		}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("stop must be a string or an array of strings")
+	}
+	*s = multiple
+	return nil
+}
+
+// StreamOptions 流式响应选项
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"` // 结束前额外发送一个包含用量的数据块
+}
+
+// ChatRequest 对话请求, 兼容 OpenAI Chat Completions 接口
 type ChatRequest struct {
-	Messages       []ChatMessage `json:"messages"`
-	Model          string        `json:"model,omitempty"`
-	Stream         bool          `json:"stream"`
-	Temperature    float64       `json:"temperature,omitempty"`
-	MaxTokens      int           `json:"max_tokens,omitempty"`
-	ConversationID uint          `json:"conversation_id,omitempty"` // 所属会话ID
+	Messages            []ChatMessage  `json:"messages"`
+	Model               string         `json:"model,omitempty"`
+	Stream              bool           `json:"stream"`
+	StreamOptions       *StreamOptions `json:"stream_options,omitempty"`
+	Temperature         *float32       `json:"temperature,omitempty"`
+	MaxTokens           int            `json:"max_tokens,omitempty"`
+	MaxCompletionTokens int            `json:"max_completion_tokens,omitempty"`
+	Stop                StopSequences  `json:"stop,omitempty"`
+	Tools               []llm.Tool     `json:"tools,omitempty"`           // 客户端工具, 由客户端自行执行
+	ToolChoice          any            `json:"tool_choice,omitempty"`     // "auto"、"none"、"required" 或指定函数
+	ConversationID      uint           `json:"conversation_id,omitempty"` // 所属会话ID, ZenOps 扩展字段
+}
+
+// ChatUsage token 用量
+type ChatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ChatResponseMessage 非流式响应中的回复消息, 只有工具调用时 content 为 null
+type ChatResponseMessage struct {
+	Role      string         `json:"role"`
+	Content   *string        `json:"content"`
+	ToolCalls []llm.ToolCall `json:"tool_calls,omitempty"`
+}
+
+// ChatChoice 非流式响应的候选回复
+type ChatChoice struct {
+	Index        int                 `json:"index"`
+	Message      ChatResponseMessage `json:"message"`
+	FinishReason string              `json:"finish_reason"`
 }
 
 // ChatResponse 非流式对话响应
 type ChatResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64  `json:"created"`
-	Model   string `json:"model"`
-	Choices []struct {
-		Index   int `json:"index"`
-		Message struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
-		} `json:"message"`
-		FinishReason string `json:"finish_reason"`
-	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []ChatChoice `json:"choices"`
+	Usage   ChatUsage    `json:"usage"`
+}
+
+// StreamToolCall 流式响应中的工具调用, 需要携带序号
+type StreamToolCall struct {
+	Index int `json:"index"`
+	llm.ToolCall
+}
+
+// StreamDelta 流式响应的增量内容
+type StreamDelta struct {
+	Role      string           `json:"role,omitempty"`
+	Content   string           `json:"content,omitempty"`
+	ToolCalls []StreamToolCall `json:"tool_calls,omitempty"`
+}
+
+// StreamChoice 流式响应的候选回复
+type StreamChoice struct {
+	Index        int         `json:"index"`
+	Delta        StreamDelta `json:"delta"`
+	FinishReason *string     `json:"finish_reason"`
 }
 
-// StreamChunk 流式响��块
+// StreamChunk 流式响应块
 type StreamChunk struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"`
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []StreamChoice `json:"choices"`
+	Usage   *ChatUsage     `json:"usage,omitempty"`
+}
+
+// OpenAIModel 模型列表中的模型
+type OpenAIModel struct {
 	ID      string `json:"id"`
 	Object  string `json:"object"`
 	Created int64  `json:"created"`
-	Model   string `json:"model"`
-	Choices []struct {
-		Index int `json:"index"`
-		Delta struct {
-			Role    string `json:"role,omitempty"`
-			Content string `json:"content,omitempty"`
-		} `json:"delta"`
-		FinishReason *string `json:"finish_reason"`
-	} `json:"choices"`
+	OwnedBy string `json:"owned_by"`
 }
 
 // Completions 处理对话请求 (支持流式和非流式，集成 MCP 工具)
+// 兼容 OpenAI Chat Completions 接口, 其他工具可将 /api/v1 作为 OpenAI base URL 接入,
+// ZenOps 的运维工具在服务端自动调用; 客户端自带的工具被调用时以 tool_calls 返回给客户端执行
 func (h *ChatHandler) Completions(c *gin.Context) {
 	var req ChatRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, Response{
-			Code:    400,
-			Message: "Invalid request: " + err.Error(),
-		})
+		openAIError(c, http.StatusBadRequest, "invalid_request_error", "Invalid request: "+err.Error())
+		return
+	}
+	if len(req.Messages) == 0 {
+		openAIError(c, http.StatusBadRequest, "invalid_request_error", "messages is required")
 		return
 	}
 
-	// 获取用户名（从请求头或使用默认值）
-	username := c.GetHeader("X-Username")
+	// 获取用户名: 使用 JWT Token 或 API Key 解析出的身份, 共享 API Key 调用时无法区分调用方
+	username := c.GetString("username")
 	if username == "" {
 		username = "api_user"
 	}
 
-	// 提取用户最后一条消息
+	// 会话只能由所属用户继续对话
+	if req.ConversationID > 0 {
+		conversation, err := h.conversationService.GetConversation(req.ConversationID)
+		if err != nil {
+			openAIError(c, http.StatusInternalServerError, "server_error", "Failed to get conversation: "+err.Error())
+			return
+		}
+		if conversation == nil || conversation.Username != username {
+			openAIError(c, http.StatusNotFound, "invalid_request_error", "conversation not found")
+			return
+		}
+	}
+
+	// 调用模型前检查用户配额
+	var quotaErr *service.QuotaExceededError
+	if err := service.NewUsageService().CheckQuota(username); errors.As(err, &quotaErr) {
+		openAIError(c, http.StatusTooManyRequests, "insufficient_quota", quotaErr.Message())
+		return
+	} else if err != nil {
+		logx.Warn("Failed to check LLM quota: %v", err)
+	}
+
+	// 将客户端消息转换为 LLM 消息格式, 并提取用户最后一条消息
 	var userMessage string
-	if len(req.Messages) > 0 {
-		for i := len(req.Messages) - 1; i >= 0; i-- {
-			if req.Messages[i].Role == "user" {
-				userMessage = req.Messages[i].Content
-				break
-			}
+	llmMessages := make([]llm.Message, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		content := chatMessageText(msg.Content)
+		if msg.Role == "user" {
+			userMessage = content
 		}
+		llmMessages = append(llmMessages, llm.Message{
+			Role:       msg.Role,
+			Content:    content,
+			ToolCalls:  msg.ToolCalls,
+			ToolCallID: msg.ToolCallID,
+			Name:       msg.Name,
+		})
 	}
 
-	// 保存用户消息到数据库
+	// 保存用户消息到数据库, 客户端回传工具结果时最后一条不是用户消息, 不重复保存
 	var userLog *model.ChatLog
-	if userMessage != "" {
+	if userMessage != "" && req.Messages[len(req.Messages)-1].Role == "user" {
 		var err error
 		userLog, err = h.chatLogService.CreateUserMessageWithConversation(username, "API", userMessage, req.ConversationID)
 		if err != nil {
@@ -146,104 +247,75 @@ func (h *ChatHandler) Completions(c *gin.Context) {
 		}
 	}
 
-	// 动态加载 LLM 配置（从数据库）
-	configService := service.NewConfigService()
-	var llmConfig *model.LLMConfig
-	var err error
-
-	// 如果请求中指定了模型ID，尝试从数据库中查找对应的配置
-	if req.Model != "" {
-		// 先尝试按名称查找（Model字段）
-		llmConfigs, err := configService.ListLLMConfigs()
-		if err == nil {
-			for _, cfg := range llmConfigs {
-				if cfg.Model == req.Model && cfg.Enabled {
-					llmConfig = &cfg
-					break
-				}
-			}
-		}
+	// 有会话时使用已保存的对话日志作为历史, 便于按对话日志ID关联会话摘要
+	if req.ConversationID > 0 {
+		llmMessages = h.conversationHistory(llmMessages, userLog, req.ConversationID)
 	}
 
-	// 如果没有找到指定的模型，使用默认的已启用模型
-	if llmConfig == nil {
-		llmConfig, err = configService.GetDefaultLLMConfig()
-		if err != nil {
-			logx.Error("Failed to get default LLM config: %v", err)
-			c.JSON(http.StatusInternalServerError, Response{
-				Code:    500,
-				Message: fmt.Sprintf("Failed to load LLM config: %v", err),
-			})
-			return
-		}
-		if llmConfig == nil {
-			c.JSON(http.StatusServiceUnavailable, Response{
-				Code:    503,
-				Message: "No enabled LLM configuration found. Please configure an LLM model first.",
-			})
+	// 动态加载 LLM 配置（从数据库）
+	// 请求中指定的模型 (按配置名称或模型名称匹配) 作为首选, 其余启用的模型作为备用
+	llmClient, err := llm.LoadClient(req.Model, h.config.LLM, h.mcpServer)
+	if err != nil {
+		if errors.Is(err, llm.ErrNotConfigured) {
+			openAIError(c, http.StatusServiceUnavailable, "server_error",
+				"No enabled LLM configuration found. Please configure an LLM model first.")
 			return
 		}
+		logx.Error("Failed to load LLM config: %v", err)
+		openAIError(c, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Failed to load LLM config: %v", err))
+		return
 	}
 
-	// 使用数据库中的配置创建 LLM 客户端
-	llmClientConfig := &llm.Config{
-		Model:   llmConfig.Model,
-		APIKey:  llmConfig.APIKey,
-		BaseURL: llmConfig.BaseURL,
-	}
-	llmClient := llm.NewClient(llmClientConfig, h.mcpServer)
-
-	logx.Info("Using LLM config: provider=%s, model=%s", llmConfig.Provider, llmConfig.Model)
-
-	// 使用 llm.Client 调用 LLM（支持 MCP 工具）
-	ctx := context.Background()
-
-	// 将前端传来的消息转换为 LLM 消息格式
-	llmMessages := make([]llm.Message, 0, len(req.Messages))
-	for _, msg := range req.Messages {
-		llmMessages = append(llmMessages, llm.Message{
-			Role:    msg.Role,
-			Content: msg.Content,
-		})
+	// 使用 llm.Client 调用 LLM（支持 MCP 工具）, 记录发起人用于工具调用审计
+	// 基于请求上下文, 客户端断开时取消正在执行的模型调用和工具调用
+	ctx := service.WithCaller(c.Request.Context(), username, "api")
+	// 记录调用方的角色和 API Key 权限范围, 模型发起的写操作工具调用同样按权限校验
+	ctx = service.WithPermissions(ctx, middleware.RequestPermissions(c))
+	ctx = service.WithConversation(ctx, req.ConversationID)
+	if userLog != nil {
+		ctx = service.WithChatLog(ctx, userLog.ID)
 	}
+	ctx = llm.WithOptions(ctx, req.chatOptions())
+	ctx, answer := llm.WithAnswer(ctx)
 
 	// 调用 LLM 流式对话（传递完整的消息历史，会自动使用已启用的 MCP 工具）
-	var responseCh <-chan string
-	if len(llmMessages) > 0 {
-		// 使用新方法传递完整的消息历史
-		responseCh, err = llmClient.ChatWithToolsAndStreamMessages(ctx, llmMessages)
-	} else {
-		// 降级：如果没有消息历史，使用旧方法
-		responseCh, err = llmClient.ChatWithToolsAndStream(ctx, userMessage)
-	}
-
+	responseCh, err := llmClient.ChatWithToolsAndStreamMessages(ctx, llmMessages)
 	if err != nil {
 		logx.Error("Failed to call LLM: %v", err)
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: fmt.Sprintf("LLM调用失败: %v", err),
-		})
+		openAIError(c, http.StatusInternalServerError, "server_error", fmt.Sprintf("LLM调用失败: %v", err))
 		return
 	}
 
 	logx.Info("Calling LLM with %d messages in history", len(llmMessages))
 
+	id := fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano())
+	created := time.Now().Unix()
+
 	// 处理流式响应
 	if req.Stream {
+		flusher, ok := c.Writer.(http.Flusher)
+		if !ok {
+			openAIError(c, http.StatusInternalServerError, "server_error", "Streaming not supported")
+			return
+		}
+
 		// 设置 SSE 响应头
 		c.Header("Content-Type", "text/event-stream")
 		c.Header("Cache-Control", "no-cache")
 		c.Header("Connection", "keep-alive")
 		c.Header("Transfer-Encoding", "chunked")
 
-		flusher, ok := c.Writer.(http.Flusher)
-		if !ok {
-			c.JSON(http.StatusInternalServerError, Response{
-				Code:    500,
-				Message: "Streaming not supported",
-			})
-			return
+		// 同一次响应的所有数据块使用相同的 ID, 第一个数据块携带角色
+		chunk := func(delta StreamDelta, finishReason *string) StreamChunk {
+			return StreamChunk{
+				ID:      id,
+				Object:  "chat.completion.chunk",
+				Created: created,
+				Model:   req.Model,
+				Choices: []StreamChoice{{Delta: delta, FinishReason: finishReason}},
+			}
 		}
+		writeStreamChunk(c, flusher, chunk(StreamDelta{Role: "assistant"}, nil))
 
 		// 用于收集 AI 的完整响应
 		var aiResponse strings.Builder
@@ -256,159 +328,247 @@ func (h *ChatHandler) Completions(c *gin.Context) {
 			}
 
 			aiResponse.WriteString(content)
+			writeStreamChunk(c, flusher, chunk(StreamDelta{Content: content}, nil))
+			responseCounter++
+		}
 
-			// 构建 SSE 格式的响应（OpenAI 格式）
-			chunk := StreamChunk{
-				ID:      fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano()),
-				Object:  "chat.completion.chunk",
-				Created: time.Now().Unix(),
-				Model:   req.Model,
-				Choices: []struct {
-					Index int `json:"index"`
-					Delta struct {
-						Role    string `json:"role,omitempty"`
-						Content string `json:"content,omitempty"`
-					} `json:"delta"`
-					FinishReason *string `json:"finish_reason"`
-				}{
-					{
-						Index: 0,
-						Delta: struct {
-							Role    string `json:"role,omitempty"`
-							Content string `json:"content,omitempty"`
-						}{
-							Content: content,
-						},
-						FinishReason: nil,
-					},
-				},
-			}
-
-			// 序列化为 JSON
-			chunkJSON, err := json.Marshal(chunk)
-			if err != nil {
-				logx.Error("Failed to marshal chunk: %v", err)
-				continue
+		// 响应通道关闭后才能读取实际回答的模型、结束原因和需要客户端执行的工具调用
+		final := chunk(StreamDelta{}, nil)
+		if answer.Model != "" {
+			final.Model = answer.Model
+		}
+		if len(answer.ToolCalls) > 0 {
+			toolCalls := make([]StreamToolCall, 0, len(answer.ToolCalls))
+			for i, toolCall := range answer.ToolCalls {
+				toolCalls = append(toolCalls, StreamToolCall{Index: i, ToolCall: toolCall})
 			}
-
-			// 发送 SSE 数据
-			fmt.Fprintf(c.Writer, "data: %s\n\n", string(chunkJSON))
-			flusher.Flush()
-			responseCounter++
+			toolChunk := final
+			toolChunk.Choices = []StreamChoice{{Delta: StreamDelta{ToolCalls: toolCalls}}}
+			writeStreamChunk(c, flusher, toolChunk)
+		}
+		finishReason := answerFinishReason(answer)
+		final.Choices[0].FinishReason = &finishReason
+		writeStreamChunk(c, flusher, final)
+
+		if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+			usageChunk := final
+			usageChunk.Choices = []StreamChoice{}
+			usageChunk.Usage = answerUsage(answer)
+			writeStreamChunk(c, flusher, usageChunk)
 		}
 
 		// 发送结束标记
 		fmt.Fprintf(c.Writer, "data: [DONE]\n\n")
 		flusher.Flush()
 
-		logx.Info("Stream completed: sent %d chunks", responseCounter)
+		logx.Info("Stream completed: sent %d chunks, finish reason %s", responseCounter, finishReason)
+
+		h.saveAIResponse(username, userMessage, aiResponse.String(), answer, userLog, req.ConversationID)
+		return
+	}
+
+	// 非流式响应：收集所有响应内容
+	var fullResponse strings.Builder
+	for content := range responseCh {
+		fullResponse.WriteString(content)
+	}
+
+	aiMessage := fullResponse.String()
+	h.saveAIResponse(username, userMessage, aiMessage, answer, userLog, req.ConversationID)
+
+	// 返回实际回答的模型, 发生模型切换时与请求的模型不同
+	responseModel := req.Model
+	if answer.Model != "" {
+		responseModel = answer.Model
+	}
+
+	message := ChatResponseMessage{
+		Role:      "assistant",
+		ToolCalls: answer.ToolCalls,
+	}
+	if aiMessage != "" || len(answer.ToolCalls) == 0 {
+		message.Content = &aiMessage
+	}
+
+	c.JSON(http.StatusOK, ChatResponse{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: created,
+		Model:   responseModel,
+		Choices: []ChatChoice{
+			{
+				Index:        0,
+				Message:      message,
+				FinishReason: answerFinishReason(answer),
+			},
+		},
+		Usage: *answerUsage(answer),
+	})
+}
+
+// chatOptions 返回需要透传给模型的生成参数
+func (req *ChatRequest) chatOptions() llm.ChatOptions {
+	options := llm.ChatOptions{
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		Stop:        req.Stop,
+		ToolChoice:  req.ToolChoice,
+	}
+	if req.MaxCompletionTokens > 0 {
+		options.MaxTokens = req.MaxCompletionTokens
+	}
+	for _, tool := range req.Tools {
+		if tool.Type == "function" && tool.Function.Name != "" {
+			options.Tools = append(options.Tools, tool)
+		}
+	}
+	return options
+}
 
-		// 保存 AI 响应到数据库
-		if userLog != nil && aiResponse.Len() > 0 {
-			_, err := h.chatLogService.CreateAIMessageWithConversation(username, "API", aiResponse.String(), userLog.ID, req.ConversationID)
-			if err != nil {
-				logx.Error("Failed to save AI response: %v", err)
+// chatMessageText 返回消息内容的文本, 内容块数组只拼接其中的文本部分
+func chatMessageText(content any) string {
+	switch v := content.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		var parts []string
+		for _, part := range v {
+			block, ok := part.(map[string]any)
+			if !ok || block["type"] != "text" {
+				continue
 			}
-			// 如果有会话ID，更新会话的最后消息时间
-			if req.ConversationID > 0 && err == nil {
-				if err := h.conversationService.UpdateLastMessageAt(req.ConversationID); err != nil {
-					logx.Error("Failed to update conversation last message time: %v", err)
-				}
-
-				// 检查是否需要生成标题
-				shouldGenerate, err := h.conversationService.ShouldGenerateTitle(req.ConversationID)
-				if err == nil && shouldGenerate && userMessage != "" {
-					// 异步生成标题，避免阻塞响应
-					go func() {
-						title := h.generateConversationTitle(context.Background(), userMessage)
-						if err := h.conversationService.UpdateConversation(req.ConversationID, title); err != nil {
-							logx.Error("Failed to update conversation title: %v", err)
-						} else {
-							logx.Info("Generated conversation title: %s", title)
-						}
-					}()
-				}
+			if text, ok := block["text"].(string); ok {
+				parts = append(parts, text)
 			}
 		}
-	} else {
-		// 非流式响应：收集所有响应内容
-		var fullResponse strings.Builder
-		for content := range responseCh {
-			fullResponse.WriteString(content)
-		}
+		return strings.Join(parts, "\n")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
 
-		aiMessage := fullResponse.String()
+// answerFinishReason 返回本次对话的结束原因, 未记录时视为正常结束
+func answerFinishReason(answer *llm.Answer) string {
+	if answer.FinishReason == "" {
+		return llm.FinishReasonStop
+	}
+	return answer.FinishReason
+}
 
-		// 保存 AI 响应到数据库
-		if userLog != nil && aiMessage != "" {
-			_, err := h.chatLogService.CreateAIMessageWithConversation(username, "API", aiMessage, userLog.ID, req.ConversationID)
-			if err != nil {
-				logx.Error("Failed to save AI response: %v", err)
-			}
-			// 如果有会话ID，更新会话的最后消息时间
-			if req.ConversationID > 0 && err == nil {
-				if err := h.conversationService.UpdateLastMessageAt(req.ConversationID); err != nil {
-					logx.Error("Failed to update conversation last message time: %v", err)
-				}
-
-				// 检查是否需要生成标题
-				shouldGenerate, err := h.conversationService.ShouldGenerateTitle(req.ConversationID)
-				if err == nil && shouldGenerate && userMessage != "" {
-					// 异步生成标题，避免阻塞响应
-					go func() {
-						title := h.generateConversationTitle(context.Background(), userMessage)
-						if err := h.conversationService.UpdateConversation(req.ConversationID, title); err != nil {
-							logx.Error("Failed to update conversation title: %v", err)
-						} else {
-							logx.Info("Generated conversation title: %s", title)
-						}
-					}()
-				}
+// answerUsage 返回本次对话所有模型调用的累计用量
+func answerUsage(answer *llm.Answer) *ChatUsage {
+	return &ChatUsage{
+		PromptTokens:     answer.Usage.PromptTokens,
+		CompletionTokens: answer.Usage.CompletionTokens,
+		TotalTokens:      answer.Usage.TotalTokens(),
+	}
+}
+
+// writeStreamChunk 以 SSE 格式发送一个数据块
+func writeStreamChunk(c *gin.Context, flusher http.Flusher, chunk StreamChunk) {
+	chunkJSON, err := json.Marshal(chunk)
+	if err != nil {
+		logx.Error("Failed to marshal chunk: %v", err)
+		return
+	}
+	fmt.Fprintf(c.Writer, "data: %s\n\n", string(chunkJSON))
+	flusher.Flush()
+}
+
+// openAIError 以 OpenAI 的错误格式返回
+func openAIError(c *gin.Context, status int, errType, message string) {
+	c.JSON(status, gin.H{
+		"error": gin.H{
+			"message": message,
+			"type":    errType,
+		},
+	})
+}
+
+// saveAIResponse 保存 AI 响应到数据库, 会话的第一轮对话完成后异步生成标题
+func (h *ChatHandler) saveAIResponse(username, userMessage, aiMessage string, answer *llm.Answer, userLog *model.ChatLog, conversationID uint) {
+	if userLog == nil || aiMessage == "" {
+		return
+	}
+
+	_, err := h.chatLogService.CreateAIMessageWithModel(username, "API", aiMessage, answer.Model, userLog.ID, conversationID)
+	if err != nil {
+		logx.Error("Failed to save AI response: %v", err)
+	}
+	// 如果有会话ID，更新会话的最后消息时间
+	if conversationID == 0 || err != nil {
+		return
+	}
+	if err := h.conversationService.UpdateLastMessageAt(conversationID); err != nil {
+		logx.Error("Failed to update conversation last message time: %v", err)
+	}
+
+	// 检查是否需要生成标题
+	shouldGenerate, err := h.conversationService.ShouldGenerateTitle(conversationID)
+	if err == nil && shouldGenerate && userMessage != "" {
+		// 异步生成标题，避免阻塞响应
+		go func() {
+			title := h.generateConversationTitle(context.Background(), userMessage)
+			if err := h.conversationService.UpdateConversation(conversationID, title); err != nil {
+				logx.Error("Failed to update conversation title: %v", err)
+			} else {
+				logx.Info("Generated conversation title: %s", title)
 			}
-		}
+		}()
+	}
+}
 
-		// 构建非流式响应
-		chatResp := ChatResponse{
-			ID:      fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano()),
-			Object:  "chat.completion",
-			Created: time.Now().Unix(),
-			Model:   req.Model,
-			Choices: []struct {
-				Index   int `json:"index"`
-				Message struct {
-					Role    string `json:"role"`
-					Content string `json:"content"`
-				} `json:"message"`
-				FinishReason string `json:"finish_reason"`
-			}{
-				{
-					Index: 0,
-					Message: struct {
-						Role    string `json:"role"`
-						Content string `json:"content"`
-					}{
-						Role:    "assistant",
-						Content: aiMessage,
-					},
-					FinishReason: "stop",
-				},
-			},
-		}
+// conversationHistory 使用会话已保存的对话日志替换客户端传入的历史
+// 保留客户端的系统消息, 以及最后一条用户消息之后的工具调用和工具结果 (客户端回传工具结果时未保存)
+func (h *ChatHandler) conversationHistory(clientMessages []llm.Message, userLog *model.ChatLog, conversationID uint) []llm.Message {
+	var logs []model.ChatLog
+	var err error
+	if userLog != nil {
+		logs, err = h.chatLogService.GetConversationContext(userLog.ID)
+	} else {
+		logs, err = h.conversationService.GetConversationMessages(conversationID)
+	}
+	if err != nil {
+		logx.Warn("Failed to load conversation history, conversation %d, error %v", conversationID, err)
+		return clientMessages
+	}
+	stored := llm.HistoryFromLogs(logs)
+	if len(stored) == 0 {
+		return clientMessages
+	}
 
-		c.JSON(http.StatusOK, Response{
-			Code:    200,
-			Message: "success",
-			Data:    chatResp,
-		})
+	lastUser := -1
+	var messages []llm.Message
+	for i, msg := range clientMessages {
+		if msg.Role == "system" {
+			messages = append(messages, msg)
+		}
+		if msg.Role == "user" {
+			lastUser = i
+		}
 	}
+	messages = append(messages, stored...)
+	for _, msg := range clientMessages[lastUser+1:] {
+		if msg.Role != "system" {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// APIKeys 返回 OpenAI 兼容接口可用的 API Key
+// 优先使用数据库系统配置 (auth.tokens), 未配置时使用配置文件
+func (h *ChatHandler) APIKeys() []string {
+	return service.NewConfigService().AuthTokens(h.config.Auth.Tokens)
 }
 
 // generateConversationTitle 生成会话标题
 func (h *ChatHandler) generateConversationTitle(ctx context.Context, userMessage string) string {
-	// 从数据库加载 LLM 配置
-	configService := service.NewConfigService()
-	llmConfig, err := configService.GetDefaultLLMConfig()
-	if err != nil || llmConfig == nil {
+	// 从数据库加载 LLM 配置, 默认模型不可用时使用备用模型
+	llmClient, err := llm.LoadClient("", h.config.LLM, h.mcpServer)
+	if err != nil {
 		logx.Error("Failed to get default LLM config for title generation: %v", err)
 		// 如果生成失败，使用用户消息的前10个字符作为标题
 		if len(userMessage) > 10 {
@@ -417,14 +577,6 @@ func (h *ChatHandler) generateConversationTitle(ctx context.Context, userMessage
 		return userMessage
 	}
 
-	// 创建 LLM 客户端
-	llmClientConfig := &llm.Config{
-		Model:   llmConfig.Model,
-		APIKey:  llmConfig.APIKey,
-		BaseURL: llmConfig.BaseURL,
-	}
-	llmClient := llm.NewClient(llmClientConfig, h.mcpServer)
-
 	// 构建生成标题的提示词
 	titlePrompt := fmt.Sprintf(`请根据下面的用户问题，生成一个简短的会话标题（5-15个字）。
 只返回标题文本，不要包含任何其他内容、标点符号或解释。
@@ -517,3 +669,36 @@ func (h *ChatHandler) GetModels(c *gin.Context) {
 		},
 	})
 }
+
+// ListModels 以 OpenAI 格式列出所有启用的模型, 模型 ID 为 LLM 配置名称
+func (h *ChatHandler) ListModels(c *gin.Context) {
+	llmConfigs, err := service.NewConfigService().GetEnabledLLMConfigs()
+	if err != nil {
+		openAIError(c, http.StatusInternalServerError, "server_error", fmt.Sprintf("Failed to load LLM configs: %v", err))
+		return
+	}
+
+	models := make([]OpenAIModel, 0, len(llmConfigs))
+	for _, cfg := range llmConfigs {
+		models = append(models, OpenAIModel{
+			ID:      cfg.Name,
+			Object:  "model",
+			Created: cfg.CreatedAt.Unix(),
+			OwnedBy: cfg.Provider,
+		})
+	}
+
+	// 数据库中没有启用的模型时回退到配置文件
+	if len(models) == 0 && h.config.LLM.Enabled {
+		models = append(models, OpenAIModel{
+			ID:      h.config.LLM.Model,
+			Object:  "model",
+			OwnedBy: h.config.LLM.Provider,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"object": "list",
+		"data":   models,
+	})
+}
@@ -10,7 +10,9 @@ import (
 
 	"cnb.cool/zhiqiangwang/pkg/logx"
 	"github.com/eryajf/zenops/internal/mcpclient"
+	"github.com/eryajf/zenops/internal/middleware"
 	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider/kubernetes"
 	"github.com/eryajf/zenops/internal/service"
 	"github.com/gin-gonic/gin"
 )
@@ -178,6 +180,10 @@ func (h *ConfigHandler) ListLLMConfigs(c *gin.Context) {
 		return
 	}
 
+	for i := range configs {
+		maskSecrets(c, &configs[i])
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
@@ -214,6 +220,10 @@ func (h *ConfigHandler) GetLLMConfig(c *gin.Context) {
 		return
 	}
 
+	if config != nil {
+		maskSecrets(c, config)
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
@@ -381,6 +391,10 @@ func (h *ConfigHandler) ListProviderAccounts(c *gin.Context) {
 		return
 	}
 
+	for i := range accounts {
+		maskSecrets(c, &accounts[i])
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
@@ -409,6 +423,10 @@ func (h *ConfigHandler) GetProviderAccount(c *gin.Context) {
 		return
 	}
 
+	if account != nil {
+		maskSecrets(c, account)
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
@@ -521,6 +539,240 @@ func (h *ConfigHandler) DeleteProviderAccount(c *gin.Context) {
 	})
 }
 
+// ========== Kubernetes 集群配置 ==========
+
+// ListKubernetesClusters 列出 Kubernetes 集群
+func (h *ConfigHandler) ListKubernetesClusters(c *gin.Context) {
+	clusters, err := h.configService.ListKubernetesClusters()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	for i := range clusters {
+		maskSecrets(c, &clusters[i])
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    clusters,
+	})
+}
+
+// GetKubernetesCluster 获取 Kubernetes 集群详情
+func (h *ConfigHandler) GetKubernetesCluster(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return
+	}
+
+	cluster, err := h.configService.GetKubernetesCluster(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if cluster != nil {
+		maskSecrets(c, cluster)
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    cluster,
+	})
+}
+
+// CreateKubernetesCluster 创建 Kubernetes 集群
+func (h *ConfigHandler) CreateKubernetesCluster(c *gin.Context) {
+	var cluster model.KubernetesCluster
+	if err := c.ShouldBindJSON(&cluster); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := validateKubeconfig(cluster.Kubeconfig, cluster.Context); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.configService.CreateKubernetesCluster(&cluster); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Kubernetes cluster created successfully",
+		Data:    cluster,
+	})
+}
+
+// UpdateKubernetesCluster 更新 Kubernetes 集群
+// kubeconfig 为空时保留原有内容, 前端无需回传凭据
+func (h *ConfigHandler) UpdateKubernetesCluster(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return
+	}
+
+	existing, err := h.configService.GetKubernetesCluster(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "Kubernetes cluster not found",
+		})
+		return
+	}
+
+	var cluster model.KubernetesCluster
+	if err := c.ShouldBindJSON(&cluster); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if cluster.Kubeconfig == "" {
+		cluster.Kubeconfig = existing.Kubeconfig
+	}
+
+	if err := validateKubeconfig(cluster.Kubeconfig, cluster.Context); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	cluster.ID = existing.ID
+	cluster.CreatedAt = existing.CreatedAt
+	if err := h.configService.UpdateKubernetesCluster(&cluster); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Kubernetes cluster updated successfully",
+		Data:    cluster,
+	})
+}
+
+// DeleteKubernetesCluster 删除 Kubernetes 集群
+func (h *ConfigHandler) DeleteKubernetesCluster(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return
+	}
+
+	if err := h.configService.DeleteKubernetesCluster(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Kubernetes cluster deleted successfully",
+	})
+}
+
+// ListKubeconfigContexts 解析 kubeconfig 并返回其中的 context 列表, 供前端选择
+func (h *ConfigHandler) ListKubeconfigContexts(c *gin.Context) {
+	var req struct {
+		Kubeconfig string `json:"kubeconfig" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	contexts, current, err := kubernetes.ListContexts(req.Kubeconfig)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data: gin.H{
+			"contexts":        contexts,
+			"current_context": current,
+		},
+	})
+}
+
+// validateKubeconfig 校验 kubeconfig 可解析且包含指定的 context
+func validateKubeconfig(kubeconfig, contextName string) error {
+	if kubeconfig == "" {
+		return fmt.Errorf("kubeconfig is required")
+	}
+
+	contexts, current, err := kubernetes.ListContexts(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	if contextName == "" {
+		if current == "" {
+			return fmt.Errorf("kubeconfig has no current-context, please specify context")
+		}
+		return nil
+	}
+
+	for _, name := range contexts {
+		if name == contextName {
+			return nil
+		}
+	}
+	return fmt.Errorf("context %s not found in kubeconfig", contextName)
+}
+
 // ========== IM 配置 ==========
 
 // GetIMConfig 获取 IM 配置
@@ -536,6 +788,10 @@ func (h *ConfigHandler) GetIMConfig(c *gin.Context) {
 		return
 	}
 
+	if config != nil {
+		maskSecrets(c, config)
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
@@ -583,6 +839,10 @@ func (h *ConfigHandler) ListIMConfigs(c *gin.Context) {
 		return
 	}
 
+	for i := range configs {
+		maskSecrets(c, &configs[i])
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
@@ -605,6 +865,10 @@ func (h *ConfigHandler) GetCICDConfig(c *gin.Context) {
 		return
 	}
 
+	if config != nil {
+		maskSecrets(c, config)
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
@@ -652,6 +916,10 @@ func (h *ConfigHandler) ListCICDConfigs(c *gin.Context) {
 		return
 	}
 
+	for i := range configs {
+		maskSecrets(c, &configs[i])
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
@@ -672,6 +940,10 @@ func (h *ConfigHandler) ListMCPServers(c *gin.Context) {
 		return
 	}
 
+	for i := range servers {
+		maskSecrets(c, &servers[i])
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
@@ -702,6 +974,8 @@ func (h *ConfigHandler) GetMCPServerByName(c *gin.Context) {
 		return
 	}
 
+	maskSecrets(c, server)
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
@@ -1222,6 +1496,10 @@ func (h *ConfigHandler) ListIntegrationConfigs(c *gin.Context) {
 		return
 	}
 
+	for i := range configs {
+		maskSecrets(c, &configs[i])
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
@@ -1258,6 +1536,10 @@ func (h *ConfigHandler) GetIntegrationConfig(c *gin.Context) {
 		return
 	}
 
+	if config != nil {
+		maskSecrets(c, config)
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
@@ -1356,9 +1638,15 @@ func (h *ConfigHandler) DeleteIntegrationConfig(c *gin.Context) {
 
 // ========== Jenkins 配置便捷接口 ==========
 
-// GetJenkinsConfig 获取 Jenkins 配置
+// GetJenkinsConfig 获取 Jenkins 配置, 可通过 name 参数指定实例, 默认返回第一个实例
 func (h *ConfigHandler) GetJenkinsConfig(c *gin.Context) {
-	config, err := h.configService.GetCICDConfig("jenkins")
+	var config *model.CICDConfig
+	var err error
+	if name := c.Query("name"); name != "" {
+		config, err = h.configService.GetCICDConfigByName("jenkins", name)
+	} else {
+		config, err = h.configService.GetCICDConfig("jenkins")
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, Response{
 			Code:    500,
@@ -1367,6 +1655,10 @@ func (h *ConfigHandler) GetJenkinsConfig(c *gin.Context) {
 		return
 	}
 
+	if config != nil {
+		maskSecrets(c, config)
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
@@ -1505,6 +1797,10 @@ func (h *ConfigHandler) ListSystemConfigs(c *gin.Context) {
 		return
 	}
 
+	for i := range configs {
+		maskSecrets(c, &configs[i])
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
@@ -1525,6 +1821,10 @@ func (h *ConfigHandler) GetSystemConfig(c *gin.Context) {
 		return
 	}
 
+	if config != nil {
+		maskSecrets(c, config)
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
@@ -1586,14 +1886,23 @@ func (h *ConfigHandler) GetAllConfig(c *gin.Context) {
 		return
 	}
 
+	for i := range llmConfigs {
+		maskSecrets(c, &llmConfigs[i])
+	}
+	for i := range imConfigs {
+		maskSecrets(c, &imConfigs[i])
+	}
+
 	// 获取云厂商账号并转换为前端格式
 	aliyunAccounts, _ := h.configService.ListProviderAccounts("aliyun")
 	tencentAccounts, _ := h.configService.ListProviderAccounts("tencent")
+	awsAccounts, _ := h.configService.ListProviderAccounts("aws")
 
 	// 转换为前端期望的格式 (ak/sk)
 	convertAccounts := func(accounts []model.ProviderAccount) []gin.H {
 		result := make([]gin.H, len(accounts))
 		for i, acc := range accounts {
+			maskSecrets(c, &acc)
 			result[i] = gin.H{
 				"id":      acc.ID,
 				"name":    acc.Name,
@@ -1638,6 +1947,7 @@ func (h *ConfigHandler) GetAllConfig(c *gin.Context) {
 		"providers": gin.H{
 			"aliyun":  convertAccounts(aliyunAccounts),
 			"tencent": convertAccounts(tencentAccounts),
+			"aws":     convertAccounts(awsAccounts),
 		},
 		"auth": gin.H{
 			"enabled": false,
@@ -1688,3 +1998,153 @@ func (h *ConfigHandler) GetAllConfig(c *gin.Context) {
 		Data:    config,
 	})
 }
+
+// ListJenkinsInstances 列出所有 Jenkins 实例
+func (h *ConfigHandler) ListJenkinsInstances(c *gin.Context) {
+	configs, err := h.configService.ListCICDConfigsByPlatform("jenkins")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	for i := range configs {
+		maskSecrets(c, &configs[i])
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    configs,
+	})
+}
+
+// CreateJenkinsInstance 创建 Jenkins 实例
+func (h *ConfigHandler) CreateJenkinsInstance(c *gin.Context) {
+	var config model.CICDConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	config.Platform = "jenkins"
+	if err := h.configService.CreateCICDConfig(&config); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Jenkins instance created successfully",
+		Data:    config,
+	})
+}
+
+// UpdateJenkinsInstance 更新 Jenkins 实例
+func (h *ConfigHandler) UpdateJenkinsInstance(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return
+	}
+
+	existing, err := h.configService.GetCICDConfigByID(uint(id))
+	if err != nil || existing.Platform != "jenkins" {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "jenkins instance not found",
+		})
+		return
+	}
+
+	var config model.CICDConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	config.ID = existing.ID
+	config.Platform = "jenkins"
+	config.CreatedAt = existing.CreatedAt
+	if config.Name == "" {
+		config.Name = existing.Name
+	}
+	if err := h.configService.UpdateCICDConfig(&config); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Jenkins instance updated successfully",
+		Data:    config,
+	})
+}
+
+// DeleteJenkinsInstance 删除 Jenkins 实例
+func (h *ConfigHandler) DeleteJenkinsInstance(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return
+	}
+
+	existing, err := h.configService.GetCICDConfigByID(uint(id))
+	if err != nil || existing.Platform != "jenkins" {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "jenkins instance not found",
+		})
+		return
+	}
+
+	if err := h.configService.DeleteCICDConfig(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Jenkins instance deleted successfully",
+	})
+}
+
+// secretMasker 可隐藏密钥的配置
+type secretMasker interface {
+	MaskSecrets()
+}
+
+// maskSecrets 非管理员查看配置时隐藏其中的密钥
+func maskSecrets(c *gin.Context, items ...secretMasker) {
+	if middleware.IsAdmin(c) {
+		return
+	}
+	for _, item := range items {
+		item.MaskSecrets()
+	}
+}
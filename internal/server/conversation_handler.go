@@ -62,11 +62,8 @@ func (h *ConversationHandler) CreateConversation(c *gin.Context) {
 		return
 	}
 
-	// 获取用户名（从请求头或使用默认值）
-	username := c.GetHeader("X-Username")
-	if username == "" {
-		username = "api_user"
-	}
+	// 获取用户名（JWT Token 或 API Key 解析出的身份）
+	username := c.GetString("username")
 
 	conversation, err := h.conversationService.CreateConversation(username, req.Title)
 	if err != nil {
@@ -86,11 +83,8 @@ func (h *ConversationHandler) CreateConversation(c *gin.Context) {
 
 // ListConversations 列出用户的会话列表
 func (h *ConversationHandler) ListConversations(c *gin.Context) {
-	// 获取用户名（从请求头或使用默认值）
-	username := c.GetHeader("X-Username")
-	if username == "" {
-		username = "api_user"
-	}
+	// 获取用户名（JWT Token 或 API Key 解析出的身份）
+	username := c.GetString("username")
 
 	conversations, err := h.conversationService.ListConversations(username)
 	if err != nil {
@@ -161,6 +161,16 @@ func (h *DashboardHandler) GetHealth(c *gin.Context) {
 		})
 	}
 
+	var awsAccounts []model.ProviderAccount
+	db.Where("provider = ? AND enabled = ?", "aws", true).Find(&awsAccounts)
+	if len(awsAccounts) > 0 {
+		components = append(components, gin.H{
+			"label":  "AWS",
+			"status": "online",
+			"uptime": "99.9%",
+		})
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    0,
 		Message: "success",
@@ -363,7 +363,7 @@ func (h *DingTalkMessageHandler) processQueryAsync(ctx context.Context, msg *Din
 // callMCPTool 调用 MCP 工具
 func (h *DingTalkMessageHandler) callMCPTool(ctx context.Context, intent *DingTalkIntent) (string, error) {
 	// 使用 MCP Server 的公开 CallTool 方法
-	result, err := h.mcpServer.CallTool(ctx, intent.MCPTool, intent.Params)
+	result, err := h.mcpServer.CallTool(service.WithIMChannel(ctx), intent.MCPTool, intent.Params)
 	if err != nil {
 		return "", fmt.Errorf("failed to call MCP tool: %w", err)
 	}
@@ -4,13 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"regexp"
 	"strings"
 	"time"
 
 	"cnb.cool/zhiqiangwang/pkg/logx"
 	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/dingtalk"
 	"github.com/eryajf/zenops/internal/imcp"
+	"github.com/eryajf/zenops/internal/intent"
 	"github.com/eryajf/zenops/internal/llm"
 	"github.com/eryajf/zenops/internal/model"
 	"github.com/eryajf/zenops/internal/service"
@@ -20,35 +21,13 @@ import (
 	"github.com/open-dingtalk/dingtalk-stream-sdk-go/client"
 )
 
-// Intent 用户意图
-type Intent struct {
-	Action   string            // list, get, search
-	Provider string            // aliyun, tencent, jenkins
-	Resource string            // ecs, cvm, rds, cdb, job, build
-	Params   map[string]string // 参数
-	MCPTool  string            // 对应的 MCP 工具名称
-}
-
-// IntentParser 意图解析器
-type IntentParser struct {
-	patterns []intentPattern
-}
-
-type intentPattern struct {
-	regex     *regexp.Regexp
-	provider  string
-	resource  string
-	action    string
-	extractor func([]string) map[string]string
-}
-
 // DingTalkStreamHandler Stream模式处理器
 type DingTalkStreamHandler struct {
 	config         *config.Config
 	cardClient     *DingTalkStreamClient
 	mcpServer      *imcp.MCPServer
 	streamClient   *client.StreamClient
-	intentParser   *IntentParser
+	intentParser   *intent.Parser
 	llmClient      *llm.Client
 	chatLogService *service.ChatLogService
 }
@@ -59,40 +38,17 @@ func NewDingTalkStreamHandler(cfg *config.Config, cardClient *DingTalkStreamClie
 		config:         cfg,
 		cardClient:     cardClient,
 		mcpServer:      mcpServer,
-		intentParser:   newIntentParser(),
+		intentParser:   intent.NewParser(),
 		chatLogService: service.NewChatLogService(),
 	}
 
 	// 初始化 LLM 客户端
-	// 优先从数据库读取 LLM 配置，如果数据库没有配置则使用 config.yaml
-	configService := service.NewConfigService()
-	dbLLMConfig, err := configService.GetDefaultLLMConfig()
-
-	var llmEnabled bool
-	var llmCfg *llm.Config
-
-	if err == nil && dbLLMConfig != nil && dbLLMConfig.Enabled {
-		// 使用数据库配置
-		llmEnabled = true
-		llmCfg = &llm.Config{
-			Model:   dbLLMConfig.Model,
-			APIKey:  dbLLMConfig.APIKey,
-			BaseURL: dbLLMConfig.BaseURL,
-		}
-		logx.Info("⚗️ Using LLM Config from Database: %s (Model: %s)", dbLLMConfig.Name, dbLLMConfig.Model)
-	} else if cfg.LLM.Enabled {
-		// 降级使用 config.yaml 配置
-		llmEnabled = true
-		llmCfg = &llm.Config{
-			Model:   cfg.LLM.Model,
-			APIKey:  cfg.LLM.APIKey,
-			BaseURL: cfg.LLM.BaseURL,
-		}
-		logx.Info("⚗️ Using LLM Config from config.yaml (Model: %s)", cfg.LLM.Model)
-	}
-
-	if llmEnabled {
-		handler.llmClient = llm.NewClient(llmCfg, mcpServer)
+	// 优先使用钉钉指定的模型, 数据库中其余启用的模型作为备用; 数据库没有配置时使用 config.yaml
+	llmClient, err := llm.LoadClient(cfg.DingTalk.LLM, cfg.LLM, mcpServer)
+	if err != nil {
+		logx.Warn("⚗️ LLM Client Not Initialized For DingTalk Stream Handler: %v", err)
+	} else {
+		handler.llmClient = llmClient
 		logx.Info("⚗️ LLM Client Initialized For DingTalk Stream Handler")
 	}
 
@@ -141,7 +97,7 @@ func (h *DingTalkStreamHandler) onChatBotMessage(ctx context.Context, data *chat
 	}
 
 	// 如果启用了 LLM,使用 LLM 处理
-	if h.config.LLM.Enabled && h.llmClient != nil {
+	if h.llmClient != nil {
 		logx.Info("Using LLM to process message")
 		go h.processLLMMessage(ctx, data, content)
 		return []byte(""), nil
@@ -177,7 +133,7 @@ func (h *DingTalkStreamHandler) cleanAtMention(content, chatbotUserID string, at
 
 // sendHelpMessage 发送帮助信息
 func (h *DingTalkStreamHandler) sendHelpMessage(ctx context.Context, data *chatbot.BotCallbackDataModel) {
-	helpContent := getHelpMessage()
+	helpContent := dingtalk.GetHelpMessage()
 
 	// 确定消息来源（私聊/群聊）
 	source := "私聊"
@@ -266,7 +222,7 @@ func (h *DingTalkStreamHandler) sendErrorMessage(ctx context.Context, data *chat
 }
 
 // processQueryAsync 异步处理查询
-func (h *DingTalkStreamHandler) processQueryAsync(ctx context.Context, data *chatbot.BotCallbackDataModel, question string, intent *Intent) {
+func (h *DingTalkStreamHandler) processQueryAsync(ctx context.Context, data *chatbot.BotCallbackDataModel, question string, intent *intent.Intent) {
 	// 检查是否配置了卡片模板ID
 	useCard := h.config.DingTalk.CardTemplateID != ""
 
@@ -333,7 +289,7 @@ func (h *DingTalkStreamHandler) createCard(ctx context.Context, trackID string,
 }
 
 // streamResult 流式发送结果
-func (h *DingTalkStreamHandler) streamResult(ctx context.Context, trackID, question string, intent *Intent, result string) {
+func (h *DingTalkStreamHandler) streamResult(ctx context.Context, trackID, question string, intent *intent.Intent, result string) {
 	// 格式化结果头部
 	header := fmt.Sprintf("**%s**\n\n✅ **%s %s 查询完成**\n\n",
 		question,
@@ -373,7 +329,7 @@ func (h *DingTalkStreamHandler) streamResult(ctx context.Context, trackID, quest
 }
 
 // callMCPTool 调用MCP工具
-func (h *DingTalkStreamHandler) callMCPTool(ctx context.Context, intent *Intent) (string, error) {
+func (h *DingTalkStreamHandler) callMCPTool(ctx context.Context, intent *intent.Intent) (string, error) {
 	logx.Debug("Calling MCP tool, tool %s, params %v", intent.MCPTool, intent.Params)
 
 	// 转换参数
@@ -383,7 +339,7 @@ func (h *DingTalkStreamHandler) callMCPTool(ctx context.Context, intent *Intent)
 	}
 
 	// 使用MCP Server的CallTool方法
-	result, err := h.mcpServer.CallTool(ctx, intent.MCPTool, params)
+	result, err := h.mcpServer.CallTool(service.WithIMChannel(ctx), intent.MCPTool, params)
 	if err != nil {
 		return "", fmt.Errorf("failed to call MCP tool: %w", err)
 	}
@@ -432,301 +388,6 @@ func (h *DingTalkStreamHandler) generateTrackID(msgID string) string {
 	return fmt.Sprintf("track_%s_%s", msgID, uuid.New().String()[:8])
 }
 
-// newIntentParser 创建意图解析器
-func newIntentParser() *IntentParser {
-	parser := &IntentParser{
-		patterns: make([]intentPattern, 0),
-	}
-	parser.registerPatterns()
-	return parser
-}
-
-// registerPatterns 注册意图匹配模式
-func (p *IntentParser) registerPatterns() {
-	// ==================== 阿里云 ECS ====================
-
-	// 按 IP 搜索 ECS
-	p.patterns = append(p.patterns, intentPattern{
-		regex:    regexp.MustCompile(`(?i)(查询?|找|搜索?)(一?下?)?.*(阿里云?)?.*(IP|ip).*([\d\.]+)`),
-		provider: "aliyun",
-		resource: "ecs",
-		action:   "search_ip",
-		extractor: func(matches []string) map[string]string {
-			return map[string]string{"ip": matches[5]}
-		},
-	})
-
-	// 按名称搜索 ECS
-	p.patterns = append(p.patterns, intentPattern{
-		regex:    regexp.MustCompile(`(?i)(查询?|找|搜索?)(一?下?)?.*(阿里云?)?.*(名称?|名字|叫).*([\w\-]+)`),
-		provider: "aliyun",
-		resource: "ecs",
-		action:   "search_name",
-		extractor: func(matches []string) map[string]string {
-			return map[string]string{"name": matches[5]}
-		},
-	})
-
-	// 列出 ECS 实例
-	p.patterns = append(p.patterns, intentPattern{
-		regex:    regexp.MustCompile(`(?i)(列出|查询?|看).*(阿里云?).*(ECS|ecs|服务器|实例)`),
-		provider: "aliyun",
-		resource: "ecs",
-		action:   "list",
-		extractor: func(matches []string) map[string]string {
-			params := make(map[string]string)
-			if strings.Contains(matches[0], "杭州") {
-				params["region"] = "cn-hangzhou"
-			} else if strings.Contains(matches[0], "上海") {
-				params["region"] = "cn-shanghai"
-			} else if strings.Contains(matches[0], "北京") {
-				params["region"] = "cn-beijing"
-			}
-			return params
-		},
-	})
-
-	// ==================== 阿里云 RDS ====================
-
-	// 列出 RDS 实例
-	p.patterns = append(p.patterns, intentPattern{
-		regex:    regexp.MustCompile(`(?i)(列出|查询?|看).*(阿里云?).*(RDS|rds|数据库)`),
-		provider: "aliyun",
-		resource: "rds",
-		action:   "list",
-		extractor: func(matches []string) map[string]string {
-			params := make(map[string]string)
-			if strings.Contains(matches[0], "杭州") {
-				params["region"] = "cn-hangzhou"
-			} else if strings.Contains(matches[0], "上海") {
-				params["region"] = "cn-shanghai"
-			}
-			return params
-		},
-	})
-
-	// 按名称搜索 RDS
-	p.patterns = append(p.patterns, intentPattern{
-		regex:    regexp.MustCompile(`(?i)(查询?|找|搜索?).*(RDS|rds|数据库).*(名称?|名字|叫).*([\w\-]+)`),
-		provider: "aliyun",
-		resource: "rds",
-		action:   "search_name",
-		extractor: func(matches []string) map[string]string {
-			return map[string]string{"name": matches[4]}
-		},
-	})
-
-	// ==================== 腾讯云 CVM ====================
-
-	// 按 IP 搜索 CVM
-	p.patterns = append(p.patterns, intentPattern{
-		regex:    regexp.MustCompile(`(?i)(查询?|找|搜索?)(一?下?)?.*(腾讯云?).*(IP|ip).*([\d\.]+)`),
-		provider: "tencent",
-		resource: "cvm",
-		action:   "search_ip",
-		extractor: func(matches []string) map[string]string {
-			return map[string]string{"ip": matches[5]}
-		},
-	})
-
-	// 按名称搜索 CVM
-	p.patterns = append(p.patterns, intentPattern{
-		regex:    regexp.MustCompile(`(?i)(查询?|找|搜索?)(一?下?)?.*(腾讯云?).*(名称?|名字|叫).*([\w\-]+)`),
-		provider: "tencent",
-		resource: "cvm",
-		action:   "search_name",
-		extractor: func(matches []string) map[string]string {
-			return map[string]string{"name": matches[5]}
-		},
-	})
-
-	// 列出 CVM 实例
-	p.patterns = append(p.patterns, intentPattern{
-		regex:    regexp.MustCompile(`(?i)(列出|查询?|看).*(腾讯云?).*(CVM|cvm|服务器|实例)`),
-		provider: "tencent",
-		resource: "cvm",
-		action:   "list",
-		extractor: func(matches []string) map[string]string {
-			params := make(map[string]string)
-			if strings.Contains(matches[0], "广州") {
-				params["region"] = "ap-guangzhou"
-			} else if strings.Contains(matches[0], "上海") {
-				params["region"] = "ap-shanghai"
-			} else if strings.Contains(matches[0], "北京") {
-				params["region"] = "ap-beijing"
-			}
-			return params
-		},
-	})
-
-	// ==================== 腾讯云 CDB ====================
-
-	// 列出 CDB 实例
-	p.patterns = append(p.patterns, intentPattern{
-		regex:    regexp.MustCompile(`(?i)(列出|查询?|看).*(腾讯云?).*(CDB|cdb|数据库)`),
-		provider: "tencent",
-		resource: "cdb",
-		action:   "list",
-		extractor: func(matches []string) map[string]string {
-			params := make(map[string]string)
-			if strings.Contains(matches[0], "广州") {
-				params["region"] = "ap-guangzhou"
-			}
-			return params
-		},
-	})
-
-	// 按名称搜索 CDB
-	p.patterns = append(p.patterns, intentPattern{
-		regex:    regexp.MustCompile(`(?i)(查询?|找|搜索?).*(CDB|cdb|数据库).*(名称?|名字|叫).*([\w\-]+)`),
-		provider: "tencent",
-		resource: "cdb",
-		action:   "search_name",
-		extractor: func(matches []string) map[string]string {
-			return map[string]string{"name": matches[4]}
-		},
-	})
-
-	// ==================== Jenkins ====================
-
-	// 列出 Jenkins Job
-	p.patterns = append(p.patterns, intentPattern{
-		regex:    regexp.MustCompile(`(?i)(列出|查询?|看).*(jenkins|Jenkins).*(job|Job|任务)`),
-		provider: "jenkins",
-		resource: "job",
-		action:   "list",
-		extractor: func(matches []string) map[string]string {
-			return make(map[string]string)
-		},
-	})
-
-	// 获取 Job 详情
-	p.patterns = append(p.patterns, intentPattern{
-		regex:    regexp.MustCompile(`(?i)(查询?|看).*(job|Job|任务).*([\w\-]+).*(详情|信息)`),
-		provider: "jenkins",
-		resource: "job",
-		action:   "get",
-		extractor: func(matches []string) map[string]string {
-			return map[string]string{"job_name": matches[3]}
-		},
-	})
-
-	// 列出构建历史
-	p.patterns = append(p.patterns, intentPattern{
-		regex:    regexp.MustCompile(`(?i)(看|查).*([\w\-]+).*(任务|job).*(构建|build|历史)`),
-		provider: "jenkins",
-		resource: "build",
-		action:   "list",
-		extractor: func(matches []string) map[string]string {
-			return map[string]string{"job_name": matches[2]}
-		},
-	})
-
-	// 通用 Jenkins 查询
-	p.patterns = append(p.patterns, intentPattern{
-		regex:    regexp.MustCompile(`(?i)(jenkins|Jenkins)`),
-		provider: "jenkins",
-		resource: "job",
-		action:   "list",
-		extractor: func(matches []string) map[string]string {
-			return make(map[string]string)
-		},
-	})
-}
-
-// Parse 解析用户消息
-func (p *IntentParser) Parse(message string) (*Intent, error) {
-	logx.Debug("Parsing intent, message %s", message)
-
-	// 遍历所有模式
-	for _, pattern := range p.patterns {
-		if matches := pattern.regex.FindStringSubmatch(message); matches != nil {
-			logx.Debug("Pattern matched, pattern %s, matches %v", pattern.regex.String(), matches)
-
-			intent := &Intent{
-				Provider: pattern.provider,
-				Resource: pattern.resource,
-				Action:   pattern.action,
-				Params:   pattern.extractor(matches),
-			}
-
-			// 映射到 MCP 工具
-			intent.MCPTool = mapToMCPTool(intent)
-
-			logx.Info("Intent parsed, provider %s, resource %s, action %s, mcp_tool %s, params %v", intent.Provider, intent.Resource, intent.Action, intent.MCPTool, intent.Params)
-
-			return intent, nil
-		}
-	}
-
-	return nil, fmt.Errorf("无法识别您的请求,请尝试更明确的描述")
-}
-
-// mapToMCPTool 将意图映射到 MCP 工具
-func mapToMCPTool(intent *Intent) string {
-	key := fmt.Sprintf("%s_%s_%s", intent.Provider, intent.Resource, intent.Action)
-
-	mapping := map[string]string{
-		// 阿里云 ECS
-		"aliyun_ecs_search_ip":   "search_ecs_by_ip",
-		"aliyun_ecs_search_name": "search_ecs_by_name",
-		"aliyun_ecs_list":        "list_ecs",
-		"aliyun_ecs_get":         "get_ecs",
-
-		// 阿里云 RDS
-		"aliyun_rds_list":        "list_rds",
-		"aliyun_rds_search_name": "search_rds_by_name",
-
-		// 腾讯云 CVM
-		"tencent_cvm_search_ip":   "search_cvm_by_ip",
-		"tencent_cvm_search_name": "search_cvm_by_name",
-		"tencent_cvm_list":        "list_cvm",
-		"tencent_cvm_get":         "get_cvm",
-
-		// 腾讯云 CDB
-		"tencent_cdb_list":        "list_cdb",
-		"tencent_cdb_search_name": "search_cdb_by_name",
-
-		// Jenkins
-		"jenkins_job_list":   "list_jenkins_jobs",
-		"jenkins_job_get":    "get_jenkins_job",
-		"jenkins_build_list": "list_jenkins_builds",
-	}
-
-	if tool, ok := mapping[key]; ok {
-		return tool
-	}
-
-	return ""
-}
-
-// getHelpMessage 获取帮助消息
-func getHelpMessage() string {
-	return `👋 你好!我是 ZenOps 运维助手,可以帮你查询云资源和 CI/CD 信息。
-
-**支持的查询:**
-
-📦 **阿里云**
-• 列出 ECS 实例: "查询阿里云杭州的 ECS"
-• 搜索 IP: "找一下 IP 为 192.168.1.1 的服务器"
-• 搜索名称: "查询名为 web-server 的实例"
-• 数据库: "列出阿里云 RDS 数据库"
-
-📦 **腾讯云**
-• 列出 CVM: "查询腾讯云广州的 CVM"
-• 搜索 IP: "找腾讯云 IP 10.0.0.1 的机器"
-• 数据库: "列出腾讯云 CDB"
-
-🔧 **Jenkins**
-• 列出任务: "看一下 Jenkins 任务列表"
-• 构建历史: "查询 deploy-prod 的构建历史"
-
-**提示:**
-• 可以在群里 @我 或私聊我
-• 描述越详细,查询越准确
-• 支持中文和英文关键词`
-}
-
 // sendTextReply 发送文本回复(用于不使用卡片时的降级方案)
 func (h *DingTalkStreamHandler) sendTextReply(data *chatbot.BotCallbackDataModel, content string) {
 	replier := chatbot.NewChatbotReplier()
@@ -772,12 +433,13 @@ func (h *DingTalkStreamHandler) processLLMMessage(ctx context.Context, data *cha
 		source = "群聊"
 	}
 
-	// 保存用户消息到数据库
+	// 保存用户消息到数据库, 同一个钉钉会话 (单聊或群聊) 的消息关联到同一个会话
 	username := data.SenderNick
 	if username == "" {
 		username = data.SenderStaffId
 	}
-	userLog, err := h.chatLogService.CreateUserMessage(username, source, userMessage)
+	conversationID := service.ChannelConversation("dingtalk", data.ConversationId, username, "钉钉"+source)
+	userLog, err := h.chatLogService.CreateUserMessageWithConversation(username, source, userMessage, conversationID)
 	if err != nil {
 		logx.Error("Failed to save user message to database: %v", err)
 	}
@@ -805,8 +467,19 @@ func (h *DingTalkStreamHandler) processLLMMessage(ctx context.Context, data *cha
 		h.sendTextReply(data, "🤖 正在思考,请稍候...")
 	}
 
-	// 调用 LLM
-	responseCh, err := h.llmClient.ChatWithToolsAndStream(ctx, userMessage)
+	// 调用 LLM, 记录发起人用于工具调用审计, 群聊记录会话ID用于匹配提示词配置
+	ctx = service.WithCaller(ctx, username, "dingtalk")
+	ctx = service.WithIMChannel(ctx)
+	if data.ConversationType == "2" {
+		ctx = service.WithChatGroup(ctx, data.ConversationId)
+	}
+	if userLog != nil {
+		ctx = service.WithChatLog(ctx, userLog.ID)
+		ctx = service.WithConversation(ctx, userLog.ConversationID)
+	}
+	ctx = llm.WithToolProgress(ctx)
+	ctx, answer := llm.WithAnswer(ctx)
+	responseCh, err := h.llmClient.ChatWithToolsAndStreamMessages(ctx, llm.ConversationMessages(userLog, userMessage))
 	if err != nil {
 		logx.Error("Failed to call LLM: %v", err)
 		errorMsg := fmt.Sprintf("❌ LLM 调用失败: %v", err)
@@ -821,14 +494,14 @@ func (h *DingTalkStreamHandler) processLLMMessage(ctx context.Context, data *cha
 
 	// 流式接收响应
 	if useCard {
-		h.streamLLMResponseWithCard(ctx, trackID, userMessage, username, source, userLog, responseCh)
+		h.streamLLMResponseWithCard(ctx, trackID, userMessage, username, source, userLog, responseCh, answer)
 	} else {
-		h.streamLLMResponseWithText(data, userMessage, username, source, userLog, responseCh)
+		h.streamLLMResponseWithText(data, userMessage, username, source, userLog, responseCh, answer)
 	}
 }
 
 // streamLLMResponseWithCard 使用卡片流式显示 LLM 响应
-func (h *DingTalkStreamHandler) streamLLMResponseWithCard(ctx context.Context, trackID, question, username, source string, userLog *model.ChatLog, responseCh <-chan string) {
+func (h *DingTalkStreamHandler) streamLLMResponseWithCard(ctx context.Context, trackID, question, username, source string, userLog *model.ChatLog, responseCh <-chan string, answer *llm.Answer) {
 	questionHeader := fmt.Sprintf("**%s**\n\n", question)
 	fullContent := questionHeader
 
@@ -860,7 +533,7 @@ func (h *DingTalkStreamHandler) streamLLMResponseWithCard(ctx context.Context, t
 
 				// 保存AI响应到数据库
 				if userLog != nil && aiResponse.Len() > 0 {
-					_, err := h.chatLogService.CreateAIMessage(username, source, aiResponse.String(), userLog.ID)
+					_, err := h.chatLogService.CreateAIMessageWithModel(username, source, aiResponse.String(), answer.Model, userLog.ID, userLog.ConversationID)
 					if err != nil {
 						logx.Error("Failed to save AI response to database: %v", err)
 					}
@@ -890,7 +563,7 @@ func (h *DingTalkStreamHandler) streamLLMResponseWithCard(ctx context.Context, t
 }
 
 // streamLLMResponseWithText 使用文本消息显示 LLM 响应
-func (h *DingTalkStreamHandler) streamLLMResponseWithText(data *chatbot.BotCallbackDataModel, question, username, source string, userLog *model.ChatLog, responseCh <-chan string) {
+func (h *DingTalkStreamHandler) streamLLMResponseWithText(data *chatbot.BotCallbackDataModel, question, username, source string, userLog *model.ChatLog, responseCh <-chan string, answer *llm.Answer) {
 	// 累积所有响应
 	var fullResponse strings.Builder
 
@@ -910,7 +583,7 @@ func (h *DingTalkStreamHandler) streamLLMResponseWithText(data *chatbot.BotCallb
 
 	// 保存AI响应到数据库
 	if userLog != nil && aiResponseStr != "" {
-		_, err := h.chatLogService.CreateAIMessage(username, source, aiResponseStr, userLog.ID)
+		_, err := h.chatLogService.CreateAIMessageWithModel(username, source, aiResponseStr, answer.Model, userLog.ID, userLog.ConversationID)
 		if err != nil {
 			logx.Error("Failed to save AI response to database: %v", err)
 		}
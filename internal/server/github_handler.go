@@ -0,0 +1,161 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/provider"
+	"github.com/eryajf/zenops/internal/provider/github"
+	"github.com/eryajf/zenops/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ==================== GitHub Actions API ====================
+
+func (s *HTTPGinServer) handleGitHubWorkflowList(c *gin.Context) {
+	repo := c.Query("repo")
+	if repo == "" {
+		s.error(c, http.StatusBadRequest, "repo is required")
+		return
+	}
+
+	p, err := s.getGitHubProvider()
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts := &provider.QueryOptions{
+		PageSize: 100,
+		PageNum:  1,
+		Filters:  map[string]string{"repo": repo},
+	}
+
+	workflows, err := p.ListJobs(c.Request.Context(), opts)
+	if err != nil {
+		s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to list workflows: %v", err))
+		return
+	}
+
+	s.success(c, gin.H{
+		"total":     len(workflows),
+		"workflows": workflows,
+		"repo":      repo,
+	})
+}
+
+func (s *HTTPGinServer) handleGitHubWorkflowGet(c *gin.Context) {
+	workflow := c.Query("workflow")
+	if workflow == "" {
+		s.error(c, http.StatusBadRequest, "workflow is required")
+		return
+	}
+
+	p, err := s.getGitHubProvider()
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	job, err := p.GetJob(c.Request.Context(), workflow)
+	if err != nil {
+		s.error(c, http.StatusNotFound, fmt.Sprintf("Failed to get workflow: %v", err))
+		return
+	}
+
+	s.success(c, gin.H{
+		"workflow": job,
+	})
+}
+
+func (s *HTTPGinServer) handleGitHubRunList(c *gin.Context) {
+	target := c.Query("target")
+	if target == "" {
+		s.error(c, http.StatusBadRequest, "target is required")
+		return
+	}
+
+	limit := 20
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			s.error(c, http.StatusBadRequest, "Invalid limit parameter")
+			return
+		}
+		limit = n
+	}
+
+	p, err := s.getGitHubProvider()
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts := &provider.QueryOptions{
+		PageSize: limit,
+		PageNum:  1,
+		Filters: map[string]string{
+			"branch": c.Query("branch"),
+			"status": c.Query("status"),
+			"event":  c.Query("event"),
+		},
+	}
+
+	runs, err := p.ListRuns(c.Request.Context(), target, opts)
+	if err != nil {
+		s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to list runs: %v", err))
+		return
+	}
+
+	s.success(c, gin.H{
+		"total":  len(runs),
+		"runs":   runs,
+		"target": target,
+	})
+}
+
+// getGitHubProvider 获取并初始化 GitHub Provider, 优先使用数据库配置
+func (s *HTTPGinServer) getGitHubProvider() (*github.GitHubProvider, error) {
+	githubConfig := s.config.CICD.GitHub
+	dbConfig, err := service.NewConfigService().GetCICDConfig("github")
+	if err != nil {
+		logx.Warn("Failed to load github config from database: %v", err)
+	} else if dbConfig != nil {
+		githubConfig = config.GitHubConfig{
+			Enabled: dbConfig.Enabled,
+			URL:     dbConfig.URL,
+			Token:   dbConfig.Token,
+		}
+	}
+
+	if githubConfig.Token == "" {
+		return nil, fmt.Errorf("github is not configured")
+	}
+	if !githubConfig.Enabled {
+		return nil, fmt.Errorf("github is disabled")
+	}
+
+	p, err := provider.GetCICDProvider("github")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider: %w", err)
+	}
+
+	providerConfig := map[string]any{
+		"url":   githubConfig.URL,
+		"token": githubConfig.Token,
+	}
+
+	if err := p.Initialize(providerConfig); err != nil {
+		return nil, fmt.Errorf("failed to initialize provider: %w", err)
+	}
+
+	gp, ok := p.(*github.GitHubProvider)
+	if !ok {
+		return nil, fmt.Errorf("unexpected github provider type %T", p)
+	}
+
+	return gp, nil
+}
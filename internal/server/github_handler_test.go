@@ -0,0 +1,167 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "zenops-server-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("ZENOPS_DB_PATH", filepath.Join(dir, "zenops.db"))
+	gin.SetMode(gin.TestMode)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// newFakeGitHubAPI 模拟 GitHub Enterprise 的 Actions 接口
+func newFakeGitHubAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"Bad credentials"}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v3/repos/acme/app/actions/workflows":
+			w.Write([]byte(`{"total_count": 1, "workflows": [{"id": 11, "name": "CI", "path": ".github/workflows/ci.yml", "state": "active"}]}`))
+		case "/api/v3/repos/acme/app/actions/workflows/ci.yml":
+			w.Write([]byte(`{"id": 11, "name": "CI", "path": ".github/workflows/ci.yml", "state": "active"}`))
+		case "/api/v3/repos/acme/app/actions/workflows/ci.yml/runs", "/api/v3/repos/acme/app/actions/runs":
+			w.Write([]byte(`{"total_count": 1, "workflow_runs": [{"id": 1, "run_number": 7, "status": "completed", "conclusion": "success",
+				"created_at": "2024-06-01T10:00:00Z", "updated_at": "2024-06-01T10:01:00Z", "actor": {"login": "alice"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not Found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newGitHubTestRouter 注册 GitHub 路由 (不含认证中间件) 并使用给定的配置文件配置
+func newGitHubTestRouter(githubConfig config.GitHubConfig) *gin.Engine {
+	cfg := &config.Config{}
+	cfg.CICD.GitHub = githubConfig
+	s := &HTTPGinServer{config: cfg}
+
+	engine := gin.New()
+	engine.GET("/api/v1/github/workflow/list", s.handleGitHubWorkflowList)
+	engine.GET("/api/v1/github/workflow/get", s.handleGitHubWorkflowGet)
+	engine.GET("/api/v1/github/run/list", s.handleGitHubRunList)
+	return engine
+}
+
+// doRequest 发送请求并解析统一响应
+func doRequest(t *testing.T, engine *gin.Engine, target string) (int, Response) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response %q error = %v", w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+func TestGitHubHandlers(t *testing.T) {
+	github := newFakeGitHubAPI(t)
+	engine := newGitHubTestRouter(config.GitHubConfig{Enabled: true, URL: github.URL, Token: "test-token"})
+
+	tests := []struct {
+		name    string
+		target  string
+		status  int
+		message string
+		total   float64
+	}{
+		{name: "list workflows", target: "/api/v1/github/workflow/list?repo=acme/app", status: http.StatusOK, total: 1},
+		{name: "list workflows without repo", target: "/api/v1/github/workflow/list", status: http.StatusBadRequest, message: "repo is required"},
+		{name: "list workflows of unknown repo", target: "/api/v1/github/workflow/list?repo=acme/missing", status: http.StatusInternalServerError, message: "status 404"},
+		{name: "list workflows with invalid repo", target: "/api/v1/github/workflow/list?repo=acme", status: http.StatusInternalServerError, message: "expected owner/repo"},
+		{name: "get workflow", target: "/api/v1/github/workflow/get?workflow=acme/app/ci.yml", status: http.StatusOK},
+		{name: "get workflow without name", target: "/api/v1/github/workflow/get", status: http.StatusBadRequest, message: "workflow is required"},
+		{name: "get unknown workflow", target: "/api/v1/github/workflow/get?workflow=acme/app/missing.yml", status: http.StatusNotFound, message: "status 404"},
+		{name: "list runs of repository", target: "/api/v1/github/run/list?target=acme/app", status: http.StatusOK, total: 1},
+		{name: "list runs of workflow", target: "/api/v1/github/run/list?target=acme/app/ci.yml&limit=5&status=success", status: http.StatusOK, total: 1},
+		{name: "list runs without target", target: "/api/v1/github/run/list", status: http.StatusBadRequest, message: "target is required"},
+		{name: "list runs with invalid limit", target: "/api/v1/github/run/list?target=acme/app&limit=0", status: http.StatusBadRequest, message: "Invalid limit"},
+		{name: "list runs of unknown workflow", target: "/api/v1/github/run/list?target=acme/app/missing.yml", status: http.StatusInternalServerError, message: "status 404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := doRequest(t, engine, tt.target)
+			if status != tt.status || resp.Code != tt.status {
+				t.Fatalf("GET %s status = %d (code %d), want %d: %s", tt.target, status, resp.Code, tt.status, resp.Message)
+			}
+			if !strings.Contains(resp.Message, tt.message) {
+				t.Errorf("GET %s message = %q, want %q", tt.target, resp.Message, tt.message)
+			}
+			if tt.total > 0 {
+				data, _ := resp.Data.(map[string]any)
+				if data["total"] != tt.total {
+					t.Errorf("GET %s data = %v, want total %v", tt.target, resp.Data, tt.total)
+				}
+			}
+		})
+	}
+}
+
+func TestGitHubHandlersConfig(t *testing.T) {
+	github := newFakeGitHubAPI(t)
+
+	tests := []struct {
+		name    string
+		config  config.GitHubConfig
+		status  int
+		message string
+	}{
+		{name: "not configured", config: config.GitHubConfig{Enabled: true, URL: github.URL}, status: http.StatusBadRequest, message: "github is not configured"},
+		{name: "disabled", config: config.GitHubConfig{URL: github.URL, Token: "test-token"}, status: http.StatusBadRequest, message: "github is disabled"},
+		{name: "bad credentials", config: config.GitHubConfig{Enabled: true, URL: github.URL, Token: "wrong"}, status: http.StatusInternalServerError, message: "status 401"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := doRequest(t, newGitHubTestRouter(tt.config), "/api/v1/github/workflow/list?repo=acme/app")
+			if status != tt.status || !strings.Contains(resp.Message, tt.message) {
+				t.Errorf("status = %d, message = %q, want %d %q", status, resp.Message, tt.status, tt.message)
+			}
+		})
+	}
+}
+
+func TestGitHubHandlersPreferDatabaseConfig(t *testing.T) {
+	github := newFakeGitHubAPI(t)
+
+	configService := service.NewConfigService()
+	dbConfig := &model.CICDConfig{Platform: "github", Enabled: true, URL: github.URL, Token: "test-token"}
+	if err := configService.SaveCICDConfig(dbConfig); err != nil {
+		t.Fatalf("SaveCICDConfig() error = %v", err)
+	}
+	t.Cleanup(func() { configService.DeleteCICDConfig(dbConfig.ID) })
+
+	// 配置文件中未配置 GitHub, 使用数据库中的配置
+	status, resp := doRequest(t, newGitHubTestRouter(config.GitHubConfig{}), "/api/v1/github/workflow/list?repo=acme/app")
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, message = %q, want 200 with database config", status, resp.Message)
+	}
+}
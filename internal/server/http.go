@@ -6,6 +6,7 @@ import (
 	"io/fs"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -47,6 +48,9 @@ func NewHTTPGinServer(cfg *config.Config) *HTTPGinServer {
 
 	engine := gin.New()
 
+	// 检查 JWT 签名密钥配置
+	service.CheckJWTSecret()
+
 	s := &HTTPGinServer{
 		config:    cfg,
 		engine:    engine,
@@ -168,27 +172,71 @@ func (s *HTTPGinServer) registerRoutes() {
 		v1.GET("/version", GetVersionInfo)
 
 		// 用户认证路由
-		authHandler := NewAuthHandler()
+		authHandler := NewAuthHandler(s.config)
 		userHandler := NewUserHandler()
+		apiKeyHandler := NewAPIKeyHandler()
 
 		// 公开路由 (不需要认证)
 		auth := v1.Group("/auth")
 		{
 			auth.POST("/login", authHandler.Login)
-			auth.POST("/logout", authHandler.Logout)
+			auth.POST("/refresh", authHandler.Refresh)
+			auth.GET("/providers", authHandler.GetProviders)
+			auth.GET("/oidc/authorize", authHandler.OIDCAuthorize)
+			auth.POST("/oidc/callback", authHandler.OIDCCallback)
+			auth.POST("/logout", middleware.OptionalAuthMiddleware(), authHandler.Logout)
 		}
 
+		// 以下路由均需要登录, 并按角色校验资源权限 (见 middleware/rbac.go)
+		authRequired := middleware.AuthMiddleware()
+
 		// 需要认证的用户路由
 		user := v1.Group("/user")
-		user.Use(middleware.AuthMiddleware())
+		user.Use(authRequired)
 		{
 			user.GET("/info", authHandler.GetUserInfo)
 			user.GET("/menu/list", userHandler.GetMenuList)
-			user.POST("/change-password", authHandler.ChangePassword)
+			user.POST("/change-password", middleware.RequireSession(), authHandler.ChangePassword)
+
+			// 个人访问令牌
+			myAPIKeys := user.Group("/api-keys")
+			myAPIKeys.Use(middleware.RequireSession())
+			{
+				myAPIKeys.GET("", apiKeyHandler.ListMyKeys)
+				myAPIKeys.POST("", apiKeyHandler.CreateMyKey)
+				myAPIKeys.DELETE("/:id", apiKeyHandler.RevokeMyKey)
+			}
+		}
+
+		// API Key 管理路由 (全部用户的个人访问令牌和服务账号 Key)
+		apiKeys := v1.Group("/api-keys")
+		apiKeys.Use(authRequired, middleware.RequireSession(), middleware.RequirePermission(service.ResourceAPIKeys))
+		{
+			apiKeys.GET("", apiKeyHandler.ListKeys)
+			apiKeys.POST("", apiKeyHandler.CreateServiceKey)
+			apiKeys.DELETE("/:id", apiKeyHandler.RevokeKey)
+		}
+
+		// 用户管理路由
+		users := v1.Group("/users")
+		users.Use(authRequired, middleware.RequirePermission(service.ResourceUsers))
+		{
+			users.GET("", userHandler.ListUsers)
+			users.POST("", userHandler.CreateUser)
+			users.GET("/:id", userHandler.GetUser)
+			users.PUT("/:id", userHandler.UpdateUser)
+			users.DELETE("/:id", userHandler.DeleteUser)
+			users.PATCH("/:id/toggle", userHandler.ToggleUser)
+			users.POST("/:id/reset-password", userHandler.ResetPassword)
+			users.POST("/:id/unlock", userHandler.UnlockUser)
+			users.GET("/:id/sessions", userHandler.ListSessions)
+			users.DELETE("/:id/sessions", userHandler.RevokeSessions)
+			users.DELETE("/:id/sessions/:sid", userHandler.RevokeSession)
 		}
 
 		// 阿里云路由
 		aliyun := v1.Group("/aliyun")
+		aliyun.Use(authRequired, middleware.RequirePermission(service.ResourceCloud))
 		{
 			// ECS
 			aliyun.GET("/ecs/list", s.handleAliyunECSList)
@@ -206,6 +254,7 @@ func (s *HTTPGinServer) registerRoutes() {
 
 		// 腾讯云路由
 		tencent := v1.Group("/tencent")
+		tencent.Use(authRequired, middleware.RequirePermission(service.ResourceCloud))
 		{
 			// CVM
 			tencent.GET("/cvm/list", s.handleTencentCVMList)
@@ -221,18 +270,72 @@ func (s *HTTPGinServer) registerRoutes() {
 			tencent.GET("/cos/get", s.handleTencentCOSGet)
 		}
 
+		// AWS 路由
+		aws := v1.Group("/aws")
+		aws.Use(authRequired, middleware.RequirePermission(service.ResourceCloud))
+		{
+			// EC2
+			aws.GET("/ec2/list", s.handleAWSEC2List)
+			aws.GET("/ec2/search", s.handleAWSEC2Search)
+			aws.GET("/ec2/get", s.handleAWSEC2Get)
+
+			// RDS
+			aws.GET("/rds/list", s.handleAWSRDSList)
+			aws.GET("/rds/get", s.handleAWSRDSGet)
+
+			// S3
+			aws.GET("/s3/list", s.handleAWSS3List)
+			aws.GET("/s3/get", s.handleAWSS3Get)
+		}
+
+		// Kubernetes 路由
+		k8s := v1.Group("/k8s")
+		k8s.Use(authRequired, middleware.RequirePermission(service.ResourceKubernetes))
+		{
+			k8s.GET("/clusters", s.handleK8sClusterList)
+			k8s.GET("/namespaces", s.handleK8sNamespaceList)
+			k8s.GET("/deployments", s.handleK8sDeploymentList)
+			k8s.GET("/statefulsets", s.handleK8sStatefulSetList)
+			k8s.GET("/pods", s.handleK8sPodList)
+			k8s.GET("/pod/search", s.handleK8sPodSearch)
+			k8s.GET("/pod/logs", s.handleK8sPodLogs)
+		}
+
 		// Jenkins 路由
 		jenkins := v1.Group("/jenkins")
+		jenkins.Use(authRequired, middleware.RequirePermission(service.ResourceCICD))
 		{
+			jenkins.GET("/instances", s.handleJenkinsInstances)
 			jenkins.GET("/job/list", s.handleJenkinsJobList)
+			jenkins.GET("/job/search", s.handleJenkinsJobSearch)
+			jenkins.GET("/job/branches", s.handleJenkinsJobBranches)
+			jenkins.GET("/agents", s.handleJenkinsAgents)
 			jenkins.GET("/job/get", s.handleJenkinsJobGet)
 			jenkins.GET("/build/list", s.handleJenkinsBuildList)
+			jenkins.GET("/queue", s.handleJenkinsQueue)
+
+			// 写操作的操作人记录到 MCP 调用日志
+			jenkinsWrite := jenkins.Group("/build")
+			{
+				jenkinsWrite.POST("/trigger", s.handleJenkinsBuildTrigger)
+				jenkinsWrite.POST("/abort", s.handleJenkinsBuildAbort)
+			}
+		}
+
+		// GitHub Actions 路由
+		github := v1.Group("/github")
+		github.Use(authRequired, middleware.RequirePermission(service.ResourceCICD))
+		{
+			github.GET("/workflow/list", s.handleGitHubWorkflowList)
+			github.GET("/workflow/get", s.handleGitHubWorkflowGet)
+			github.GET("/run/list", s.handleGitHubRunList)
 		}
 
 		// MCP Server 管理路由 (独立路由组)
 		configHandler := NewConfigHandler()
 		mcpHandler := NewMCPHandler()
 		mcp := v1.Group("/mcp")
+		mcp.Use(authRequired, middleware.RequirePermission(service.ResourceMCP))
 		{
 			mcp.GET("/servers", configHandler.ListMCPServers)
 			mcp.POST("/servers", configHandler.CreateMCPServer)
@@ -245,11 +348,22 @@ func (s *HTTPGinServer) registerRoutes() {
 			mcp.POST("/servers/:name/tools/:toolName/test", configHandler.TestMCPTool)
 			// MCP 调试接口
 			mcp.POST("/debug/execute", mcpHandler.DebugExecute)
+
+			// MCP Server 访问令牌
+			mcpTokenHandler := NewMCPTokenHandler()
+			mcpTokens := mcp.Group("/tokens")
+			{
+				mcpTokens.GET("", mcpTokenHandler.ListTokens)
+				mcpTokens.POST("", mcpTokenHandler.CreateToken)
+				mcpTokens.PUT("/:id", mcpTokenHandler.UpdateToken)
+				mcpTokens.DELETE("/:id", mcpTokenHandler.DeleteToken)
+			}
 		}
 
 		// 仪表盘路由
 		dashboardHandler := NewDashboardHandler()
 		dashboard := v1.Group("/dashboard")
+		dashboard.Use(authRequired, middleware.RequirePermission(service.ResourceDashboard))
 		{
 			dashboard.GET("/stats", dashboardHandler.GetStats)
 			dashboard.GET("/health", dashboardHandler.GetHealth)
@@ -258,16 +372,42 @@ func (s *HTTPGinServer) registerRoutes() {
 		// 日志路由
 		logHandler := NewLogHandler()
 		logs := v1.Group("/logs")
+		logs.Use(authRequired, middleware.RequirePermission(service.ResourceLogs))
 		{
 			logs.GET("/mcp", logHandler.GetMCPLogs)
 			logs.GET("/mcp/stats", logHandler.GetMCPLogStats)
 		}
 
+		// 大模型用量与配额路由
+		usageHandler := NewUsageHandler()
+		usage := v1.Group("/usage")
+		usage.Use(authRequired, middleware.RequirePermission(service.ResourceUsage))
+		{
+			usage.GET("/stats", usageHandler.GetUsageStats)
+			usage.GET("/quotas", usageHandler.ListQuotas)
+			usage.POST("/quotas", usageHandler.SaveQuota)
+			usage.PUT("/quotas/:id", usageHandler.UpdateQuota)
+			usage.DELETE("/quotas/:id", usageHandler.DeleteQuota)
+		}
+
+		// 敏感信息脱敏规则路由
+		redactionHandler := NewRedactionHandler()
+		redaction := v1.Group("/redaction")
+		redaction.Use(authRequired, middleware.RequirePermission(service.ResourceRedaction))
+		{
+			redaction.GET("/rules", redactionHandler.ListRules)
+			redaction.POST("/rules", redactionHandler.CreateRule)
+			redaction.PUT("/rules/:id", redactionHandler.UpdateRule)
+			redaction.DELETE("/rules/:id", redactionHandler.DeleteRule)
+			redaction.POST("/test", redactionHandler.TestRedaction)
+		}
+
 		// AI 对话路由将在 SetMCPServer() 中注册（需要 mcpServer）
 
 		// 对话历史路由
 		historyHandler := NewHistoryHandler()
 		history := v1.Group("/history")
+		history.Use(authRequired, middleware.RequirePermission(service.ResourceHistory))
 		{
 			history.GET("/chats", historyHandler.GetChatLogs)
 			history.GET("/chats/:id/context", historyHandler.GetChatContext)
@@ -276,6 +416,7 @@ func (s *HTTPGinServer) registerRoutes() {
 		// 会话管理路由
 		conversationHandler := NewConversationHandler()
 		conversations := v1.Group("/conversations")
+		conversations.Use(authRequired, middleware.RequirePermission(service.ResourceConversations))
 		{
 			conversations.POST("", conversationHandler.CreateConversation)
 			conversations.GET("", conversationHandler.ListConversations)
@@ -286,6 +427,7 @@ func (s *HTTPGinServer) registerRoutes() {
 
 		// 配置管理路由
 		config := v1.Group("/config")
+		config.Use(authRequired, middleware.RequirePermission(service.ResourceConfig))
 		{
 			// 全量配置
 			config.GET("", configHandler.GetAllConfig)
@@ -304,6 +446,14 @@ func (s *HTTPGinServer) registerRoutes() {
 			config.PUT("/provider/:id", configHandler.UpdateProviderAccount)
 			config.DELETE("/provider/:id", configHandler.DeleteProviderAccount)
 
+			// Kubernetes 集群配置
+			config.GET("/kubernetes", configHandler.ListKubernetesClusters)
+			config.POST("/kubernetes", configHandler.CreateKubernetesCluster)
+			config.POST("/kubernetes/contexts", configHandler.ListKubeconfigContexts)
+			config.GET("/kubernetes/:id", configHandler.GetKubernetesCluster)
+			config.PUT("/kubernetes/:id", configHandler.UpdateKubernetesCluster)
+			config.DELETE("/kubernetes/:id", configHandler.DeleteKubernetesCluster)
+
 			// IM 配置 (添加 integration 别名)
 			config.GET("/integration", configHandler.ListIntegrationConfigs)
 			config.POST("/integration", configHandler.CreateIntegrationConfig)
@@ -319,6 +469,10 @@ func (s *HTTPGinServer) registerRoutes() {
 			// Jenkins 配置便捷路由
 			config.GET("/jenkins", configHandler.GetJenkinsConfig)
 			config.POST("/jenkins", configHandler.SaveJenkinsConfig)
+			config.GET("/jenkins/instances", configHandler.ListJenkinsInstances)
+			config.POST("/jenkins/instances", configHandler.CreateJenkinsInstance)
+			config.PUT("/jenkins/instances/:id", configHandler.UpdateJenkinsInstance)
+			config.DELETE("/jenkins/instances/:id", configHandler.DeleteJenkinsInstance)
 
 			// 服务器配置
 			config.GET("/server", configHandler.GetServerConfig)
@@ -328,6 +482,10 @@ func (s *HTTPGinServer) registerRoutes() {
 			config.GET("/system", configHandler.ListSystemConfigs)
 			config.GET("/system/:key", configHandler.GetSystemConfig)
 			config.POST("/system", configHandler.SetSystemConfig)
+
+			// JWT 签名密钥
+			config.GET("/jwt-keys", authHandler.ListSigningKeys)
+			config.POST("/jwt-keys/rotate", authHandler.RotateSigningKey)
 		}
 	}
 
@@ -343,11 +501,31 @@ func (s *HTTPGinServer) registerChatRoutes() {
 	}
 
 	// AI 对话路由
+	// 对话接口兼容 OpenAI, 其他工具可将 /api/v1 作为 base URL 接入, 使用 API Key 或登录后的 JWT Token 认证
+	apiKeyAuth := middleware.APIKeyMiddleware(s.chatHandler.APIKeys)
+	chatPermission := middleware.RequirePermission(service.ResourceChat)
 	v1 := s.engine.Group("/api/v1")
 	chat := v1.Group("/chat")
 	{
-		chat.POST("/completions", s.chatHandler.Completions)
-		chat.GET("/models", s.chatHandler.GetModels)
+		chat.POST("/completions", apiKeyAuth, chatPermission, s.chatHandler.Completions)
+		chat.GET("/models", middleware.AuthMiddleware(), chatPermission, s.chatHandler.GetModels)
+	}
+	v1.GET("/models", apiKeyAuth, chatPermission, s.chatHandler.ListModels)
+
+	// 系统提示词配置路由 (预览需要 mcpServer 获取工具列表)
+	promptHandler := NewPromptHandler(s.mcpServer)
+	prompts := v1.Group("/prompts")
+	prompts.Use(middleware.AuthMiddleware(), middleware.RequirePermission(service.ResourcePrompts))
+	{
+		prompts.GET("", promptHandler.ListProfiles)
+		prompts.POST("", promptHandler.CreateProfile)
+		prompts.GET("/:id", promptHandler.GetProfile)
+		prompts.PUT("/:id", promptHandler.UpdateProfile)
+		prompts.DELETE("/:id", promptHandler.DeleteProfile)
+		prompts.POST("/preview", promptHandler.Preview)
+		prompts.GET("/bindings", promptHandler.ListBindings)
+		prompts.POST("/bindings", promptHandler.SaveBinding)
+		prompts.DELETE("/bindings/:id", promptHandler.DeleteBinding)
 	}
 
 	logx.Info("✅ Chat routes registered successfully")
@@ -364,6 +542,7 @@ func (s *HTTPGinServer) registerServiceRoutes() {
 
 	// 服务管理路由
 	services := s.engine.Group("/api/v1/services")
+	services.Use(middleware.AuthMiddleware(), middleware.RequirePermission(service.ResourceServices))
 	{
 		services.GET("/status", serviceHandler.GetServiceStatus)
 		services.GET("/status/:platform", serviceHandler.GetPlatformStatus)
@@ -1322,26 +1501,25 @@ func (s *HTTPGinServer) handleTencentCOSGet(c *gin.Context) {
 // ==================== Jenkins API ====================
 
 func (s *HTTPGinServer) handleJenkinsJobList(c *gin.Context) {
-	p, err := provider.GetCICDProvider("jenkins")
+	p, jenkinsConfig, err := s.getJenkinsProvider(c.Query("instance"))
 	if err != nil {
-		s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to get provider: %v", err))
-		return
-	}
-
-	providerConfig := map[string]any{
-		"url":      s.config.CICD.Jenkins.URL,
-		"username": s.config.CICD.Jenkins.Username,
-		"token":    s.config.CICD.Jenkins.Token,
-	}
-
-	if err := p.Initialize(providerConfig); err != nil {
-		s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to initialize provider: %v", err))
+		s.error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	opts := &provider.QueryOptions{
 		PageSize: 100,
 		PageNum:  1,
+		Filters: map[string]string{
+			"folder":   c.Query("folder"),
+			"branches": c.Query("branches"),
+		},
+	}
+	if pageSize, err := strconv.Atoi(c.Query("page_size")); err == nil && pageSize > 0 {
+		opts.PageSize = pageSize
+	}
+	if pageNum, err := strconv.Atoi(c.Query("page_num")); err == nil && pageNum > 0 {
+		opts.PageNum = pageNum
 	}
 
 	jobs, err := p.ListJobs(c.Request.Context(), opts)
@@ -1351,8 +1529,9 @@ func (s *HTTPGinServer) handleJenkinsJobList(c *gin.Context) {
 	}
 
 	s.success(c, gin.H{
-		"total": len(jobs),
-		"jobs":  jobs,
+		"instance": jenkinsConfig.Name,
+		"total":    len(jobs),
+		"jobs":     jobs,
 	})
 }
 
@@ -1363,20 +1542,9 @@ func (s *HTTPGinServer) handleJenkinsJobGet(c *gin.Context) {
 		return
 	}
 
-	p, err := provider.GetCICDProvider("jenkins")
+	p, jenkinsConfig, err := s.getJenkinsProvider(c.Query("instance"))
 	if err != nil {
-		s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to get provider: %v", err))
-		return
-	}
-
-	providerConfig := map[string]any{
-		"url":      s.config.CICD.Jenkins.URL,
-		"username": s.config.CICD.Jenkins.Username,
-		"token":    s.config.CICD.Jenkins.Token,
-	}
-
-	if err := p.Initialize(providerConfig); err != nil {
-		s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to initialize provider: %v", err))
+		s.error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -1387,7 +1555,8 @@ func (s *HTTPGinServer) handleJenkinsJobGet(c *gin.Context) {
 	}
 
 	s.success(c, gin.H{
-		"job": job,
+		"instance": jenkinsConfig.Name,
+		"job":      job,
 	})
 }
 
@@ -1398,20 +1567,9 @@ func (s *HTTPGinServer) handleJenkinsBuildList(c *gin.Context) {
 		return
 	}
 
-	p, err := provider.GetCICDProvider("jenkins")
+	p, jenkinsConfig, err := s.getJenkinsProvider(c.Query("instance"))
 	if err != nil {
-		s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to get provider: %v", err))
-		return
-	}
-
-	providerConfig := map[string]any{
-		"url":      s.config.CICD.Jenkins.URL,
-		"username": s.config.CICD.Jenkins.Username,
-		"token":    s.config.CICD.Jenkins.Token,
-	}
-
-	if err := p.Initialize(providerConfig); err != nil {
-		s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to initialize provider: %v", err))
+		s.error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -1423,6 +1581,7 @@ func (s *HTTPGinServer) handleJenkinsBuildList(c *gin.Context) {
 	}
 
 	s.success(c, gin.H{
+		"instance": jenkinsConfig.Name,
 		"total":    len(builds),
 		"builds":   builds,
 		"job_name": jobName,
@@ -0,0 +1,312 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/provider/jenkins"
+	"github.com/eryajf/zenops/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ==================== Jenkins 写操作 API ====================
+
+// JenkinsTriggerRequest 触发构建请求
+type JenkinsTriggerRequest struct {
+	Instance    string            `json:"instance"` // Jenkins 实例名称, 为空时使用第一个启用的实例
+	JobName     string            `json:"job_name" binding:"required"`
+	Parameters  map[string]string `json:"parameters"`
+	WaitSeconds *int              `json:"wait_seconds"` // 等待分配构建号的秒数, 默认 10, 最大 60
+}
+
+// JenkinsAbortRequest 中止构建请求, 指定 queue_id 时取消排队中的构建
+type JenkinsAbortRequest struct {
+	Instance    string `json:"instance"`
+	JobName     string `json:"job_name"`
+	BuildNumber int    `json:"build_number"`
+	QueueID     int64  `json:"queue_id"`
+}
+
+func (s *HTTPGinServer) handleJenkinsBuildTrigger(c *gin.Context) {
+	startTime := time.Now()
+
+	var req JenkinsTriggerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.error(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	wait := jenkins.DefaultTriggerWait
+	if req.WaitSeconds != nil && *req.WaitSeconds >= 0 {
+		wait = min(time.Duration(*req.WaitSeconds)*time.Second, time.Minute)
+	}
+
+	p, jenkinsConfig, err := s.getJenkinsProvider(req.Instance)
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	item, err := p.TriggerBuild(c.Request.Context(), req.JobName, req.Parameters, wait)
+	recordJenkinsAudit(c, "trigger_jenkins_build", map[string]any{
+		"instance":   jenkinsConfig.Name,
+		"job_name":   req.JobName,
+		"parameters": req.Parameters,
+	}, item, err, startTime)
+	if err != nil {
+		s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to trigger build: %v", err))
+		return
+	}
+
+	s.success(c, gin.H{
+		"instance":     jenkinsConfig.Name,
+		"queue_item":   item,
+		"build_number": item.BuildNumber,
+	})
+}
+
+func (s *HTTPGinServer) handleJenkinsBuildAbort(c *gin.Context) {
+	startTime := time.Now()
+
+	var req JenkinsAbortRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.error(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	if req.QueueID <= 0 && (req.JobName == "" || req.BuildNumber <= 0) {
+		s.error(c, http.StatusBadRequest, "job_name and build_number, or queue_id is required")
+		return
+	}
+
+	p, jenkinsConfig, err := s.getJenkinsProvider(req.Instance)
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	auditRequest := map[string]any{
+		"instance":     jenkinsConfig.Name,
+		"job_name":     req.JobName,
+		"build_number": req.BuildNumber,
+		"queue_id":     req.QueueID,
+	}
+
+	if req.QueueID > 0 {
+		err := p.CancelQueueItem(ctx, req.QueueID)
+		recordJenkinsAudit(c, "abort_jenkins_build", auditRequest, gin.H{"queue_id": req.QueueID}, err, startTime)
+		if err != nil {
+			s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to cancel queue item: %v", err))
+			return
+		}
+		s.success(c, gin.H{
+			"queue_id":  req.QueueID,
+			"cancelled": true,
+		})
+		return
+	}
+
+	build, err := p.AbortBuild(ctx, req.JobName, req.BuildNumber)
+	recordJenkinsAudit(c, "abort_jenkins_build", auditRequest, build, err, startTime)
+	if err != nil {
+		s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to abort build: %v", err))
+		return
+	}
+
+	s.success(c, gin.H{
+		"build": build,
+	})
+}
+
+func (s *HTTPGinServer) handleJenkinsQueue(c *gin.Context) {
+	p, jenkinsConfig, err := s.getJenkinsProvider(c.Query("instance"))
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, err := p.GetQueue(c.Request.Context())
+	if err != nil {
+		s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to get queue: %v", err))
+		return
+	}
+
+	if jobName := c.Query("job_name"); jobName != "" {
+		filtered := items[:0]
+		for _, item := range items {
+			if item.JobName == jobName {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
+	s.success(c, gin.H{
+		"instance": jenkinsConfig.Name,
+		"total":    len(items),
+		"items":    items,
+	})
+}
+
+func (s *HTTPGinServer) handleJenkinsInstances(c *gin.Context) {
+	instances := service.NewConfigService().ListJenkinsInstances(s.config.CICD.Jenkins)
+
+	result := make([]gin.H, 0, len(instances))
+	for _, instance := range instances {
+		result = append(result, gin.H{
+			"name":    instance.Name,
+			"enabled": instance.Enabled,
+			"url":     instance.URL,
+		})
+	}
+
+	s.success(c, gin.H{
+		"total":     len(result),
+		"instances": result,
+	})
+}
+
+// handleJenkinsJobSearch 搜索 Job, 未指定 instance 时并发搜索所有启用的实例
+func (s *HTTPGinServer) handleJenkinsJobSearch(c *gin.Context) {
+	keyword := c.Query("keyword")
+	if keyword == "" {
+		s.error(c, http.StatusBadRequest, "keyword is required")
+		return
+	}
+
+	var instances []jenkins.Instance
+	if instance := c.Query("instance"); instance != "" {
+		p, jenkinsConfig, err := s.getJenkinsProvider(instance)
+		if err != nil {
+			s.error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		instances = append(instances, jenkins.Instance{Name: jenkinsConfig.Name, Provider: p})
+	} else {
+		for _, instance := range service.NewConfigService().ListJenkinsInstances(s.config.CICD.Jenkins) {
+			if !instance.Enabled {
+				continue
+			}
+			p, err := jenkins.NewInstanceProvider(instance.URL, instance.Username, instance.Token)
+			if err != nil {
+				logx.Warn("Failed to initialize jenkins provider, instance %s, error %v", instance.Name, err)
+				continue
+			}
+			instances = append(instances, jenkins.Instance{Name: instance.Name, Provider: p})
+		}
+		if len(instances) == 0 {
+			s.error(c, http.StatusBadRequest, "no enabled jenkins instance")
+			return
+		}
+	}
+
+	results := jenkins.SearchJobsInInstances(c.Request.Context(), instances, keyword)
+
+	total := 0
+	for _, r := range results {
+		total += len(r.Jobs)
+	}
+
+	s.success(c, gin.H{
+		"total":     total,
+		"instances": results,
+	})
+}
+
+func (s *HTTPGinServer) handleJenkinsJobBranches(c *gin.Context) {
+	jobName := c.Query("job_name")
+	if jobName == "" {
+		s.error(c, http.StatusBadRequest, "job_name is required")
+		return
+	}
+
+	p, jenkinsConfig, err := s.getJenkinsProvider(c.Query("instance"))
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	branches, err := p.ListBranches(c.Request.Context(), jobName)
+	if err != nil {
+		s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to list branches: %v", err))
+		return
+	}
+
+	s.success(c, gin.H{
+		"instance": jenkinsConfig.Name,
+		"job_name": jobName,
+		"total":    len(branches),
+		"branches": branches,
+	})
+}
+
+func (s *HTTPGinServer) handleJenkinsAgents(c *gin.Context) {
+	p, jenkinsConfig, err := s.getJenkinsProvider(c.Query("instance"))
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	agents, err := p.ListAgents(c.Request.Context())
+	if err != nil {
+		s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to list agents: %v", err))
+		return
+	}
+
+	if label := c.Query("label"); label != "" {
+		filtered := agents[:0]
+		for _, agent := range agents {
+			if slices.Contains(agent.Labels, label) {
+				filtered = append(filtered, agent)
+			}
+		}
+		agents = filtered
+	}
+
+	s.success(c, gin.H{
+		"instance": jenkinsConfig.Name,
+		"total":    len(agents),
+		"agents":   agents,
+	})
+}
+
+// recordJenkinsAudit 将 Jenkins 写操作记录到 MCP 调用日志, 操作人取自登录用户
+func recordJenkinsAudit(c *gin.Context, toolName string, request map[string]any, response any, err error, startTime time.Time) {
+	logParams := &service.MCPLogParams{
+		ServerName: "zenops",
+		ToolName:   toolName,
+		Username:   c.GetString("username"),
+		Source:     "api",
+		Request:    request,
+		Response:   response,
+		Latency:    time.Since(startTime).Milliseconds(),
+		Success:    err == nil,
+	}
+	if err != nil {
+		logParams.ErrorMessage = err.Error()
+	}
+	if _, logErr := service.NewMCPLogService().CreateMCPLog(logParams); logErr != nil {
+		logx.Warn("Failed to save MCP log: %v", logErr)
+	}
+}
+
+// getJenkinsProvider 获取指定 Jenkins 实例的 Provider
+// 实例名称为空时使用第一个启用的实例; 优先使用数据库中的 CICD 配置, 不存在时回退到配置文件
+func (s *HTTPGinServer) getJenkinsProvider(instance string) (*jenkins.JenkinsProvider, *config.JenkinsConfig, error) {
+	instances := service.NewConfigService().ListJenkinsInstances(s.config.CICD.Jenkins)
+	jenkinsConfig, err := config.FindJenkinsInstance(instances, instance)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, err := jenkins.NewInstanceProvider(jenkinsConfig.URL, jenkinsConfig.Username, jenkinsConfig.Token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize jenkins provider for instance %s: %w", jenkinsConfig.Name, err)
+	}
+
+	return p, jenkinsConfig, nil
+}
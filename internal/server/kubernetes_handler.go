@@ -0,0 +1,222 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/provider/kubernetes"
+	"github.com/eryajf/zenops/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ==================== Kubernetes API ====================
+
+func (s *HTTPGinServer) handleK8sClusterList(c *gin.Context) {
+	clusters, err := service.NewConfigService().ListKubernetesClusters()
+	if err != nil {
+		s.error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to list clusters: %v", err))
+		return
+	}
+
+	// 列表接口不返回 kubeconfig 内容
+	items := make([]gin.H, 0, len(clusters))
+	for _, cluster := range clusters {
+		items = append(items, gin.H{
+			"name":              cluster.Name,
+			"enabled":           cluster.Enabled,
+			"context":           cluster.Context,
+			"default_namespace": cluster.DefaultNamespace,
+			"description":       cluster.Description,
+		})
+	}
+
+	s.success(c, gin.H{
+		"total":    len(items),
+		"clusters": items,
+	})
+}
+
+func (s *HTTPGinServer) handleK8sNamespaceList(c *gin.Context) {
+	client, err := s.getK8sClient(c.Query("cluster"))
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	namespaces, err := client.ListNamespaces(c.Request.Context())
+	if err != nil {
+		s.error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.success(c, gin.H{
+		"total":      len(namespaces),
+		"namespaces": namespaces,
+		"cluster":    client.Cluster,
+	})
+}
+
+func (s *HTTPGinServer) handleK8sDeploymentList(c *gin.Context) {
+	client, err := s.getK8sClient(c.Query("cluster"))
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	deployments, err := client.ListDeployments(c.Request.Context(), c.Query("namespace"), c.Query("label_selector"))
+	if err != nil {
+		s.error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.success(c, gin.H{
+		"total":       len(deployments),
+		"deployments": deployments,
+		"cluster":     client.Cluster,
+	})
+}
+
+func (s *HTTPGinServer) handleK8sStatefulSetList(c *gin.Context) {
+	client, err := s.getK8sClient(c.Query("cluster"))
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	statefulSets, err := client.ListStatefulSets(c.Request.Context(), c.Query("namespace"), c.Query("label_selector"))
+	if err != nil {
+		s.error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.success(c, gin.H{
+		"total":        len(statefulSets),
+		"statefulsets": statefulSets,
+		"cluster":      client.Cluster,
+	})
+}
+
+func (s *HTTPGinServer) handleK8sPodList(c *gin.Context) {
+	client, err := s.getK8sClient(c.Query("cluster"))
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pods, err := client.ListPods(c.Request.Context(), c.Query("namespace"), c.Query("label_selector"))
+	if err != nil {
+		s.error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.success(c, gin.H{
+		"total":   len(pods),
+		"pods":    pods,
+		"cluster": client.Cluster,
+	})
+}
+
+func (s *HTTPGinServer) handleK8sPodSearch(c *gin.Context) {
+	ip := c.Query("ip")
+	if ip == "" {
+		s.error(c, http.StatusBadRequest, "ip parameter is required")
+		return
+	}
+
+	client, err := s.getK8sClient(c.Query("cluster"))
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pods, err := client.FindPodsByIP(c.Request.Context(), ip)
+	if err != nil {
+		s.error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if len(pods) == 0 {
+		s.error(c, http.StatusNotFound, fmt.Sprintf("Pod with IP %s not found", ip))
+		return
+	}
+
+	s.success(c, gin.H{
+		"total":   len(pods),
+		"pods":    pods,
+		"cluster": client.Cluster,
+	})
+}
+
+func (s *HTTPGinServer) handleK8sPodLogs(c *gin.Context) {
+	podName := c.Query("pod")
+	if podName == "" {
+		s.error(c, http.StatusBadRequest, "pod parameter is required")
+		return
+	}
+
+	tailLines := kubernetes.DefaultTailLines
+	if v := c.Query("tail_lines"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			s.error(c, http.StatusBadRequest, "Invalid tail_lines parameter")
+			return
+		}
+		tailLines = n
+	}
+	previous := c.Query("previous") == "true"
+
+	client, err := s.getK8sClient(c.Query("cluster"))
+	if err != nil {
+		s.error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	namespace := c.Query("namespace")
+	if namespace == "" && client.DefaultNamespace == "" {
+		s.error(c, http.StatusBadRequest, "namespace parameter is required")
+		return
+	}
+
+	logs, err := client.GetPodLogs(c.Request.Context(), namespace, podName, c.Query("container"), tailLines, previous)
+	if err != nil {
+		s.error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.success(c, gin.H{
+		"pod":     podName,
+		"cluster": client.Cluster,
+		"logs":    logs,
+	})
+}
+
+// getK8sClient 根据集群名称创建 Kubernetes 客户端, 未指定时使用第一个启用的集群
+func (s *HTTPGinServer) getK8sClient(clusterName string) (*kubernetes.Client, error) {
+	clusters, err := service.NewConfigService().ListKubernetesClusters()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubernetes clusters: %w", err)
+	}
+
+	var cluster *model.KubernetesCluster
+	for i := range clusters {
+		if clusterName == "" && clusters[i].Enabled || clusterName != "" && clusters[i].Name == clusterName {
+			cluster = &clusters[i]
+			break
+		}
+	}
+
+	if cluster == nil {
+		if clusterName != "" {
+			return nil, fmt.Errorf("kubernetes cluster '%s' not found", clusterName)
+		}
+		return nil, fmt.Errorf("no kubernetes cluster configured")
+	}
+
+	if !cluster.Enabled {
+		return nil, fmt.Errorf("kubernetes cluster '%s' is disabled", cluster.Name)
+	}
+
+	return kubernetes.NewClient(cluster.Name, cluster.Kubeconfig, cluster.Context, cluster.DefaultNamespace)
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// MCPTokenHandler MCP 访问令牌处理器
+type MCPTokenHandler struct {
+	tokenService *service.MCPTokenService
+}
+
+// NewMCPTokenHandler 创建 MCP 访问令牌处理器
+func NewMCPTokenHandler() *MCPTokenHandler {
+	return &MCPTokenHandler{
+		tokenService: service.NewMCPTokenService(),
+	}
+}
+
+// ListTokens 列出 MCP 访问令牌
+func (h *MCPTokenHandler) ListTokens(c *gin.Context) {
+	tokens, err := h.tokenService.ListTokens()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    tokens,
+	})
+}
+
+// CreateToken 创建 MCP 访问令牌, 令牌明文只在本次响应中返回
+func (h *MCPTokenHandler) CreateToken(c *gin.Context) {
+	var token model.MCPToken
+	if err := c.ShouldBindJSON(&token); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err := service.ValidateMCPToken(&token); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	token.CreatedBy = c.GetString("username")
+	secret, err := h.tokenService.CreateToken(&token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "MCP token created successfully, please save it now as it will not be shown again",
+		Data: gin.H{
+			"token":  token,
+			"secret": secret,
+		},
+	})
+}
+
+// UpdateToken 更新 MCP 访问令牌
+func (h *MCPTokenHandler) UpdateToken(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return
+	}
+
+	existing, err := h.tokenService.GetToken(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "mcp token not found",
+		})
+		return
+	}
+
+	var token model.MCPToken
+	if err := c.ShouldBindJSON(&token); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err := service.ValidateMCPToken(&token); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.tokenService.UpdateToken(existing, &token); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "MCP token updated successfully",
+		Data:    token,
+	})
+}
+
+// DeleteToken 删除 MCP 访问令牌
+func (h *MCPTokenHandler) DeleteToken(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return
+	}
+
+	if err := h.tokenService.DeleteToken(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "MCP token deleted successfully",
+	})
+}
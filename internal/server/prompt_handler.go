@@ -0,0 +1,367 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/eryajf/zenops/internal/imcp"
+	"github.com/eryajf/zenops/internal/llm"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PromptHandler 系统提示词配置处理器
+type PromptHandler struct {
+	promptService *service.PromptService
+	mcpServer     *imcp.MCPServer
+}
+
+// NewPromptHandler 创建提示词配置处理器
+func NewPromptHandler(mcpServer *imcp.MCPServer) *PromptHandler {
+	return &PromptHandler{
+		promptService: service.NewPromptService(),
+		mcpServer:     mcpServer,
+	}
+}
+
+// PromptPreviewRequest 提示词预览请求
+// 指定 profile_id 或 content 时预览该配置, 否则按 username、source、group_id 匹配生效的配置
+type PromptPreviewRequest struct {
+	ProfileID      uint   `json:"profile_id"`
+	Content        string `json:"content"` // 未保存的模板内容
+	DefaultAccount string `json:"default_account"`
+	DefaultRegion  string `json:"default_region"`
+	Username       string `json:"username"`
+	Source         string `json:"source"`
+	GroupID        string `json:"group_id"`
+}
+
+// ListProfiles 列出提示词配置
+func (h *PromptHandler) ListProfiles(c *gin.Context) {
+	profiles, err := h.promptService.ListProfiles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    profiles,
+	})
+}
+
+// GetProfile 获取提示词配置详情, 包含其绑定
+func (h *PromptHandler) GetProfile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return
+	}
+
+	profile, err := h.promptService.GetProfile(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+	if profile == nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "prompt profile not found",
+		})
+		return
+	}
+
+	bindings, err := h.promptService.ListBindings(profile.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data: gin.H{
+			"profile":  profile,
+			"bindings": bindings,
+		},
+	})
+}
+
+// CreateProfile 创建提示词配置
+func (h *PromptHandler) CreateProfile(c *gin.Context) {
+	var profile model.PromptProfile
+	if err := c.ShouldBindJSON(&profile); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err := validateProfile(&profile); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.promptService.CreateProfile(&profile); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Prompt profile created successfully",
+		Data:    profile,
+	})
+}
+
+// UpdateProfile 更新提示词配置
+func (h *PromptHandler) UpdateProfile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return
+	}
+
+	existing, err := h.promptService.GetProfile(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "prompt profile not found",
+		})
+		return
+	}
+
+	var profile model.PromptProfile
+	if err := c.ShouldBindJSON(&profile); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err := validateProfile(&profile); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	profile.ID = existing.ID
+	profile.CreatedAt = existing.CreatedAt
+	if err := h.promptService.UpdateProfile(&profile); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Prompt profile updated successfully",
+		Data:    profile,
+	})
+}
+
+// DeleteProfile 删除提示词配置及其绑定
+func (h *PromptHandler) DeleteProfile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return
+	}
+
+	if err := h.promptService.DeleteProfile(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Prompt profile deleted successfully",
+	})
+}
+
+// ListBindings 列出提示词绑定, 可通过 profile_id 过滤
+func (h *PromptHandler) ListBindings(c *gin.Context) {
+	profileID, _ := strconv.ParseUint(c.Query("profile_id"), 10, 32)
+
+	bindings, err := h.promptService.ListBindings(uint(profileID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    bindings,
+	})
+}
+
+// SaveBinding 将提示词配置绑定到平台、群聊或用户
+func (h *PromptHandler) SaveBinding(c *gin.Context) {
+	var binding model.PromptBinding
+	if err := c.ShouldBindJSON(&binding); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.promptService.SaveBinding(&binding); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Prompt binding saved successfully",
+		Data:    binding,
+	})
+}
+
+// DeleteBinding 删除提示词绑定
+func (h *PromptHandler) DeleteBinding(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return
+	}
+
+	if err := h.promptService.DeleteBinding(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Prompt binding deleted successfully",
+	})
+}
+
+// Preview 预览渲染后的系统提示词
+func (h *PromptHandler) Preview(c *gin.Context) {
+	var req PromptPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var profile *model.PromptProfile
+	var err error
+	switch {
+	case req.Content != "":
+		profile = &model.PromptProfile{
+			Name:           "preview",
+			Content:        req.Content,
+			DefaultAccount: req.DefaultAccount,
+			DefaultRegion:  req.DefaultRegion,
+		}
+	case req.ProfileID > 0:
+		profile, err = h.promptService.GetProfile(req.ProfileID)
+		if err == nil && profile == nil {
+			c.JSON(http.StatusNotFound, Response{
+				Code:    404,
+				Message: "prompt profile not found",
+			})
+			return
+		}
+	default:
+		profile, err = h.promptService.ResolveProfile(req.Source, req.GroupID, req.Username)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := service.WithCaller(context.Background(), req.Username, req.Source)
+	ctx = service.WithChatGroup(ctx, req.GroupID)
+	prompt, err := llm.PreviewPrompt(ctx, h.mcpServer, profile)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data: gin.H{
+			"profile": profile,
+			"prompt":  prompt,
+		},
+	})
+}
+
+// validateProfile 校验提示词配置, 模板需能正常解析
+func validateProfile(profile *model.PromptProfile) error {
+	if profile.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if profile.Content == "" {
+		return fmt.Errorf("content is required")
+	}
+	_, err := llm.RenderPrompt(profile, llm.PromptData{})
+	return err
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// RedactionHandler 敏感信息脱敏规则处理器
+type RedactionHandler struct {
+	redactionService *service.RedactionService
+}
+
+// NewRedactionHandler 创建脱敏规则处理器
+func NewRedactionHandler() *RedactionHandler {
+	return &RedactionHandler{
+		redactionService: service.NewRedactionService(),
+	}
+}
+
+// RedactionTestRequest 脱敏试运行请求
+// 指定 pattern 时只使用该正则, 否则使用当前启用的规则
+type RedactionTestRequest struct {
+	Text        string `json:"text" binding:"required"`
+	Stage       string `json:"stage"` // llm | storage, 默认 llm
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement"`
+}
+
+// ListRules 列出脱敏规则, 包含各规则的累计替换次数
+func (h *RedactionHandler) ListRules(c *gin.Context) {
+	rules, err := h.redactionService.ListRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    rules,
+	})
+}
+
+// CreateRule 创建自定义脱敏规则
+func (h *RedactionHandler) CreateRule(c *gin.Context) {
+	var rule model.RedactionRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := service.ValidateRedactionRule(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.redactionService.CreateRule(&rule); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Redaction rule created successfully",
+		Data:    rule,
+	})
+}
+
+// UpdateRule 更新脱敏规则, 内置规则只能修改生效阶段、替换内容和启用状态
+func (h *RedactionHandler) UpdateRule(c *gin.Context) {
+	existing, ok := h.loadRule(c)
+	if !ok {
+		return
+	}
+
+	var rule model.RedactionRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.redactionService.UpdateRule(existing, &rule); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Redaction rule updated successfully",
+		Data:    rule,
+	})
+}
+
+// DeleteRule 删除自定义脱敏规则
+func (h *RedactionHandler) DeleteRule(c *gin.Context) {
+	rule, ok := h.loadRule(c)
+	if !ok {
+		return
+	}
+
+	if err := h.redactionService.DeleteRule(rule); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Redaction rule deleted successfully",
+	})
+}
+
+// TestRedaction 试运行脱敏, 用于在保存规则前验证效果
+func (h *RedactionHandler) TestRedaction(c *gin.Context) {
+	var req RedactionTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+	if req.Stage == "" {
+		req.Stage = model.RedactionStageLLM
+	}
+
+	var rule *model.RedactionRule
+	if req.Pattern != "" {
+		rule = &model.RedactionRule{
+			Name:        "test",
+			Pattern:     req.Pattern,
+			Replacement: req.Replacement,
+		}
+	}
+
+	text, count, err := h.redactionService.TestRedaction(req.Stage, req.Text, rule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data: gin.H{
+			"text":  text,
+			"count": count,
+		},
+	})
+}
+
+// loadRule 按路径参数加载脱敏规则, 失败时写入错误响应
+func (h *RedactionHandler) loadRule(c *gin.Context) (*model.RedactionRule, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return nil, false
+	}
+
+	rule, err := h.redactionService.GetRule(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	if rule == nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "redaction rule not found",
+		})
+		return nil, false
+	}
+	return rule, true
+}
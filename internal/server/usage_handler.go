@@ -0,0 +1,207 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// UsageHandler 大模型用量与配额处理器
+type UsageHandler struct {
+	usageService *service.UsageService
+}
+
+// NewUsageHandler 创建用量处理器
+func NewUsageHandler() *UsageHandler {
+	return &UsageHandler{
+		usageService: service.NewUsageService(),
+	}
+}
+
+// GetUsageStats 获取按用户和按模型统计的 token 用量与费用
+// 查询参数 start_date / end_date 格式为 2006-01-02, 默认统计本月; username 只统计指定用户
+func (h *UsageHandler) GetUsageStats(c *gin.Context) {
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+
+	if startDate := c.Query("start_date"); startDate != "" {
+		t, err := time.ParseInLocation(time.DateOnly, startDate, now.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, Response{
+				Code:    400,
+				Message: "invalid start_date, expected format 2006-01-02",
+			})
+			return
+		}
+		start = t
+	}
+	if endDate := c.Query("end_date"); endDate != "" {
+		t, err := time.ParseInLocation(time.DateOnly, endDate, now.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, Response{
+				Code:    400,
+				Message: "invalid end_date, expected format 2006-01-02",
+			})
+			return
+		}
+		// 结束日期包含当天
+		end = t.AddDate(0, 0, 1)
+	}
+
+	stats, err := h.usageService.GetUsageStats(start, end, c.Query("username"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    stats,
+	})
+}
+
+// ListQuotas 列出所有配额配置
+func (h *UsageHandler) ListQuotas(c *gin.Context) {
+	quotas, err := h.usageService.ListQuotas()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    quotas,
+	})
+}
+
+// SaveQuota 保存配额配置, username 为空时设置所有用户的默认配额
+func (h *UsageHandler) SaveQuota(c *gin.Context) {
+	var quota model.LLMQuota
+	if err := c.ShouldBindJSON(&quota); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+	if quota.DailyTokens < 0 || quota.MonthlyTokens < 0 {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "quota must not be negative",
+		})
+		return
+	}
+
+	if err := h.usageService.SaveQuota(&quota); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Quota saved successfully",
+		Data:    quota,
+	})
+}
+
+// UpdateQuota 更新配额配置
+func (h *UsageHandler) UpdateQuota(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return
+	}
+
+	existing, err := h.usageService.GetQuota(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "quota not found",
+		})
+		return
+	}
+
+	var quota model.LLMQuota
+	if err := c.ShouldBindJSON(&quota); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+	if quota.DailyTokens < 0 || quota.MonthlyTokens < 0 {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "quota must not be negative",
+		})
+		return
+	}
+
+	// 配额所属用户不可修改
+	existing.DailyTokens = quota.DailyTokens
+	existing.MonthlyTokens = quota.MonthlyTokens
+	if err := h.usageService.UpdateQuota(existing); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Quota updated successfully",
+		Data:    existing,
+	})
+}
+
+// DeleteQuota 删除配额配置
+func (h *UsageHandler) DeleteQuota(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return
+	}
+
+	if err := h.usageService.DeleteQuota(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Quota deleted successfully",
+	})
+}
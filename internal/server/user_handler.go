@@ -1,43 +1,233 @@
 package server
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/eryajf/zenops/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
-// UserHandler 用户处理器
-type UserHandler struct{}
+// UserHandler 用户管理处理器
+type UserHandler struct {
+	userService    *service.UserService
+	sessionService *service.SessionService
+}
 
-// NewUserHandler 创建用户处理器
+// NewUserHandler 创建用户管理处理器
 func NewUserHandler() *UserHandler {
-	return &UserHandler{}
+	return &UserHandler{
+		userService:    service.NewUserService(),
+		sessionService: service.NewSessionService(),
+	}
+}
+
+// CreateUserRequest 创建用户请求
+type CreateUserRequest struct {
+	Username string   `json:"username" binding:"required"`
+	Password string   `json:"password" binding:"required,min=6"` // 初始密码, 用户首次登录后需修改
+	Nickname string   `json:"nickname"`
+	Email    string   `json:"email"`
+	Avatar   string   `json:"avatar"`
+	Roles    []string `json:"roles"`   // 为空时默认为 viewer
+	Enabled  *bool    `json:"enabled"` // 为空时默认启用
+}
+
+// UpdateUserRequest 更新用户请求, 为空的字段保持不变
+type UpdateUserRequest struct {
+	Nickname *string  `json:"nickname"`
+	Email    *string  `json:"email"`
+	Avatar   *string  `json:"avatar"`
+	Roles    []string `json:"roles"`
+	Enabled  *bool    `json:"enabled"`
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// ListUsers 列出所有用户
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	users, err := h.userService.ListUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    users,
+	})
 }
 
-// GetUserInfo 获取用户信息 (开发模式使用的模拟接口)
-func (h *UserHandler) GetUserInfo(c *gin.Context) {
-	// 返回模拟的管理员用户信息
+// GetUser 获取用户详情
+func (h *UserHandler) GetUser(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
-		Data: gin.H{
-			"id":       1,
-			"username": "admin",
-			"nickname": "管理员",
-			"email":    "admin@zenops.local",
-			"roles":    []string{"R_SUPER", "R_ADMIN"},
-			"avatar":   "",
-		},
+		Data:    user,
+	})
+}
+
+// CreateUser 创建用户
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user := model.User{
+		Username: strings.TrimSpace(req.Username),
+		Nickname: req.Nickname,
+		Email:    req.Email,
+		Avatar:   req.Avatar,
+		Roles:    joinRoles(req.Roles),
+		Enabled:  req.Enabled == nil || *req.Enabled,
+	}
+	if user.Roles == "" {
+		user.Roles = model.RoleViewer
+	}
+
+	if err := h.userService.CreateUser(&user, req.Password); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "User created successfully",
+		Data:    user,
+	})
+}
+
+// UpdateUser 更新用户资料、角色和启用状态
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if req.Nickname != nil {
+		user.Nickname = *req.Nickname
+	}
+	if req.Email != nil {
+		user.Email = *req.Email
+	}
+	if req.Avatar != nil {
+		user.Avatar = *req.Avatar
+	}
+	if req.Roles != nil {
+		user.Roles = joinRoles(req.Roles)
+	}
+	if req.Enabled != nil {
+		user.Enabled = *req.Enabled
+	}
+	if !user.Enabled && user.ID == c.GetUint("user_id") {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "不能禁用当前登录的用户",
+		})
+		return
+	}
+
+	if err := h.userService.UpdateUser(user); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "User updated successfully",
+		Data:    user,
+	})
+}
+
+// ToggleUser 切换用户启用状态
+func (h *UserHandler) ToggleUser(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+	if user.ID == c.GetUint("user_id") {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "不能禁用当前登录的用户",
+		})
+		return
+	}
+
+	user.Enabled = !user.Enabled
+	if err := h.userService.UpdateUser(user); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "User status toggled successfully",
+		Data:    user,
 	})
 }
 
-// Login 用户登录 (开发模式使用的模拟接口)
-func (h *UserHandler) Login(c *gin.Context) {
-	var req struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
+// DeleteUser 删除用户
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+	if user.ID == c.GetUint("user_id") {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "不能删除当前登录的用户",
+		})
+		return
+	}
+
+	if err := h.userService.DeleteUser(user); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "User deleted successfully",
+	})
+}
+
+// ResetPassword 管理员重置用户密码, 用户下次登录后需修改密码
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
 	}
 
+	var req ResetPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, Response{
 			Code:    400,
@@ -46,32 +236,101 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// 简单的模拟登录，任何用户名密码都可以登录
+	if err := h.userService.ResetPassword(user, req.Password); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Password reset successfully",
+	})
+}
+
+// UnlockUser 解除用户的登录锁定
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.Unlock(user); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "登录成功",
-		Data: gin.H{
-			"accessToken": "mock-access-token",
-			"userInfo": gin.H{
-				"id":       1,
-				"username": req.Username,
-				"nickname": "管理员",
-				"email":    "admin@zenops.local",
-				"roles":    []string{"R_SUPER", "R_ADMIN"},
-			},
-		},
+		Message: "User unlocked successfully",
+		Data:    user,
 	})
 }
 
-// Logout 用户登出
-func (h *UserHandler) Logout(c *gin.Context) {
+// ListSessions 列出用户的有效登录会话
+func (h *UserHandler) ListSessions(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(user.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "登出成功",
+		Message: "success",
+		Data:    sessions,
 	})
 }
 
-// GetMenuList 获取菜单列表 (开发模式使用的模拟接口)
+// RevokeSessions 吊销用户的全部登录会话, 用户需重新登录
+func (h *UserHandler) RevokeSessions(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.sessionService.RevokeUserSessions(user.ID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Sessions revoked successfully",
+	})
+}
+
+// RevokeSession 吊销用户的指定登录会话
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	err := h.sessionService.RevokeSession(user.ID, c.Param("sid"))
+	if errors.Is(err, service.ErrInvalidSession) {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "session not found",
+		})
+		return
+	}
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Session revoked successfully",
+	})
+}
+
+// GetMenuList 获取菜单列表
 func (h *UserHandler) GetMenuList(c *gin.Context) {
 	// 返回空菜单，让前端使用路由模块中定义的菜单
 	c.JSON(http.StatusOK, Response{
@@ -80,3 +339,59 @@ func (h *UserHandler) GetMenuList(c *gin.Context) {
 		Data:    []interface{}{},
 	})
 }
+
+// loadUser 根据路径参数加载用户, 失败时已写入响应
+func (h *UserHandler) loadUser(c *gin.Context) (*model.User, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "invalid id",
+		})
+		return nil, false
+	}
+
+	user, err := h.userService.GetUser(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "user not found",
+		})
+		return nil, false
+	}
+	return user, true
+}
+
+// respondError 将用户管理服务的错误转换为响应, 校验类错误返回 400
+func (h *UserHandler) respondError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrUserExists) || errors.Is(err, service.ErrLastAdmin) || errors.Is(err, service.ErrInvalidRole) ||
+		errors.Is(err, service.ErrExternalUser) {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, Response{
+		Code:    500,
+		Message: err.Error(),
+	})
+}
+
+// joinRoles 将角色列表转换为逗号分隔的字符串
+func joinRoles(roles []string) string {
+	var result []string
+	for _, role := range roles {
+		if role = strings.TrimSpace(role); role != "" {
+			result = append(result, role)
+		}
+	}
+	return strings.Join(result, ",")
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/database"
+	"github.com/eryajf/zenops/internal/model"
+	"gorm.io/gorm"
+)
+
+const (
+	// APIKeyPrefix API Key 明文的固定前缀, 认证中间件据此区分 API Key 和 JWT Token
+	APIKeyPrefix = "zk_"
+	// ServiceAccountUsernamePrefix 服务账号调用时使用的用户名前缀, 避免与用户重名
+	ServiceAccountUsernamePrefix = "service:"
+	// apiKeyDisplayLength 列表中展示的明文长度 (含前缀)
+	apiKeyDisplayLength = 12
+	// apiKeyTouchInterval 最后使用时间的更新间隔, 避免每个请求都写数据库
+	apiKeyTouchInterval = time.Minute
+)
+
+// ErrInvalidAPIKey Key 不存在、已吊销、已过期或所属用户已禁用
+var ErrInvalidAPIKey = errors.New("invalid or expired api key")
+
+var (
+	// scopePattern 权限范围格式, resource 为资源名或 *, action 为 read、write 或 *
+	scopePattern = regexp.MustCompile(`^([a-z_]+|\*):(read|write|\*)$`)
+	// serviceAccountPattern 服务账号名称格式
+	serviceAccountPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,99}$`)
+)
+
+// APIKeyIdentity API Key 解析出的调用方身份
+type APIKeyIdentity struct {
+	Key                *model.APIKey
+	UserID             uint   // 服务账号为 0
+	Username           string // 服务账号为 service:<名称>
+	Roles              string // 所属用户当前的角色, 服务账号为空, 权限只由 Key 的 scopes 决定
+	MustChangePassword bool
+}
+
+// APIKeyService API Key 服务
+type APIKeyService struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyService 创建 API Key 服务实例
+func NewAPIKeyService() *APIKeyService {
+	return &APIKeyService{
+		db: database.GetDB(),
+	}
+}
+
+// IsAPIKey 判断 Bearer Token 是否为 API Key
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// ServiceAccountUsername 服务账号调用时的用户名, 用于日志、配额和对话记录
+func ServiceAccountUsername(name string) string {
+	return ServiceAccountUsernamePrefix + name
+}
+
+// generateAPIKey 生成随机 Key 明文
+func generateAPIKey() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return APIKeyPrefix + hex.EncodeToString(buf), nil
+}
+
+// ListKeys 列出所有 Key
+func (s *APIKeyService) ListKeys() ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := s.db.Order("id desc").Find(&keys).Error
+	return keys, err
+}
+
+// ListUserKeys 列出用户的个人访问令牌
+func (s *APIKeyService) ListUserKeys(userID uint) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := s.db.Where("user_id = ?", userID).Order("id desc").Find(&keys).Error
+	return keys, err
+}
+
+// GetKey 获取指定ID的 Key, 不存在时返回 nil
+func (s *APIKeyService) GetKey(id uint) (*model.APIKey, error) {
+	var key model.APIKey
+	err := s.db.First(&key, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// CreateKey 创建 Key, 返回 Key 明文 (只在此时可见)
+func (s *APIKeyService) CreateKey(key *model.APIKey) (string, error) {
+	if err := ValidateAPIKey(key); err != nil {
+		return "", err
+	}
+
+	secret, err := generateAPIKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key.KeyHash = hashToken(secret)
+	key.KeyPrefix = secret[:apiKeyDisplayLength]
+	key.LastUsedAt = nil
+	key.RevokedAt = nil
+	if err := s.db.Create(key).Error; err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// RevokeKey 吊销 Key, 保留记录便于审计
+func (s *APIKeyService) RevokeKey(key *model.APIKey) error {
+	if key.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	return s.db.Model(key).UpdateColumn("revoked_at", now).Error
+}
+
+// DeleteUserKeys 删除用户的全部个人访问令牌, 用于删除用户时清理
+func (s *APIKeyService) DeleteUserKeys(userID uint) error {
+	return s.db.Where("user_id = ?", userID).Delete(&model.APIKey{}).Error
+}
+
+// Authenticate 校验 Key 明文, 返回调用方身份并更新最后使用时间和来源 IP
+// 个人访问令牌使用所属用户当前的角色, 用户被禁用或删除后 Key 随之失效
+func (s *APIKeyService) Authenticate(secret, clientIP string) (*APIKeyIdentity, error) {
+	var key model.APIKey
+	err := s.db.Where("key_hash = ?", hashToken(secret)).First(&key).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !key.Active(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	identity := &APIKeyIdentity{Key: &key}
+	if key.UserID != 0 {
+		user, err := NewUserService().GetUser(key.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil || !user.Enabled {
+			return nil, ErrInvalidAPIKey
+		}
+		identity.UserID = user.ID
+		identity.Username = user.Username
+		identity.Roles = user.Roles
+		identity.MustChangePassword = user.MustChangePassword
+	} else {
+		identity.Username = ServiceAccountUsername(key.ServiceAccount)
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval || key.LastUsedIP != clientIP {
+		err := s.db.Model(&model.APIKey{}).Where("id = ?", key.ID).UpdateColumns(map[string]any{
+			"last_used_at": now,
+			"last_used_ip": clientIP,
+		}).Error
+		if err != nil {
+			logx.Warn("Failed to update api key last used time, key %s, error %v", key.KeyPrefix, err)
+		}
+		key.LastUsedAt = &now
+		key.LastUsedIP = clientIP
+	}
+	return identity, nil
+}
+
+// ValidateAPIKey 校验 Key 配置
+// Key 必须且只能属于一个用户或一个服务账号, 权限范围至少包含一项, 允许全部权限时使用 "*"
+func ValidateAPIKey(key *model.APIKey) error {
+	if strings.TrimSpace(key.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if (key.UserID == 0) == (key.ServiceAccount == "") {
+		return fmt.Errorf("api key must belong to either a user or a service account")
+	}
+	if key.ServiceAccount != "" && !serviceAccountPattern.MatchString(key.ServiceAccount) {
+		return fmt.Errorf("invalid service account %q, only letters, digits, '_', '.' and '-' are allowed", key.ServiceAccount)
+	}
+	if len(key.Scopes) == 0 {
+		return fmt.Errorf("scopes is required, use \"*\" to allow all permissions")
+	}
+	for _, scope := range key.Scopes {
+		if scope != "*" && !scopePattern.MatchString(scope) {
+			return fmt.Errorf("invalid scope %q, must be resource:action such as cloud:read or *:read", scope)
+		}
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("expires_at must be in the future")
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/model"
+)
+
+// PasswordBackend 用户名密码登录的外部认证源, 如 LDAP
+type PasswordBackend interface {
+	// Source 认证源对应的用户来源, 取值同 model.User.Source
+	Source() string
+	// Authenticate 校验用户名和密码, 用户不存在或密码错误时返回 ErrInvalidCredentials
+	Authenticate(username, password string) (*ExternalIdentity, error)
+}
+
+// ExternalIdentity 外部认证源返回的用户身份
+type ExternalIdentity struct {
+	Source    string
+	Username  string
+	Nickname  string
+	Email     string
+	Groups    []string
+	Roles     string // 按用户组映射得到的角色, 逗号分隔
+	SyncRoles bool   // 是否配置了用户组映射, 配置后每次登录同步角色
+}
+
+// authenticateExternal 依次尝试外部认证源, 已存在的外部用户只交给其来源对应的认证源
+func authenticateExternal(user *model.User, username, password string, backends []PasswordBackend) (*ExternalIdentity, error) {
+	for _, backend := range backends {
+		if user != nil && user.Source != backend.Source() {
+			continue
+		}
+		identity, err := backend.Authenticate(username, password)
+		if errors.Is(err, ErrInvalidCredentials) {
+			continue
+		}
+		return identity, err
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// mapGroupRoles 按用户组映射角色, 组名不区分大小写; 未匹配任何用户组时使用 defaultRole
+func mapGroupRoles(identity *ExternalIdentity, mappings []config.GroupRole, defaultRole string) {
+	var roles []string
+	for _, mapping := range mappings {
+		for _, group := range identity.Groups {
+			if strings.EqualFold(group, mapping.Group) && !slices.Contains(roles, mapping.Role) {
+				roles = append(roles, mapping.Role)
+			}
+		}
+	}
+	if len(roles) == 0 && defaultRole != "" {
+		roles = append(roles, defaultRole)
+	}
+	identity.Roles = strings.Join(roles, ",")
+	identity.SyncRoles = len(mappings) > 0
+}
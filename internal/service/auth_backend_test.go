@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/model"
+)
+
+// cleanupUser 测试结束后删除外部认证源自动创建的用户
+func cleanupUser(t *testing.T, username string) {
+	t.Helper()
+	t.Cleanup(func() {
+		NewUserService().db.Unscoped().Where("username = ?", username).Delete(&model.User{})
+	})
+}
+
+func TestMapGroupRoles(t *testing.T) {
+	mappings := []config.GroupRole{
+		{Group: "cn=admins,ou=groups,dc=example,dc=com", Role: model.RoleAdmin},
+		{Group: "ops", Role: model.RoleOperator},
+		{Group: "sre", Role: model.RoleOperator},
+	}
+
+	tests := []struct {
+		name        string
+		groups      []string
+		mappings    []config.GroupRole
+		defaultRole string
+		wantRoles   string
+		wantSync    bool
+	}{
+		{name: "group dn is case insensitive", groups: []string{"CN=Admins,ou=groups,dc=example,dc=com"}, mappings: mappings, wantRoles: "admin", wantSync: true},
+		{name: "roles are not duplicated", groups: []string{"ops", "sre", "cn=admins,ou=groups,dc=example,dc=com"}, mappings: mappings, wantRoles: "admin,operator", wantSync: true},
+		{name: "default role when nothing matches", groups: []string{"dev"}, mappings: mappings, defaultRole: model.RoleViewer, wantRoles: "viewer", wantSync: true},
+		{name: "no role when nothing matches", groups: []string{"dev"}, mappings: mappings, wantRoles: "", wantSync: true},
+		{name: "no mappings uses default role", groups: []string{"ops"}, defaultRole: model.RoleViewer, wantRoles: "viewer", wantSync: false},
+		{name: "no mappings and no default role", groups: []string{"ops"}, wantRoles: "", wantSync: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity := &ExternalIdentity{Groups: tt.groups}
+			mapGroupRoles(identity, tt.mappings, tt.defaultRole)
+			if identity.Roles != tt.wantRoles || identity.SyncRoles != tt.wantSync {
+				t.Errorf("mapGroupRoles() = roles %q, sync %v, want %q, %v", identity.Roles, identity.SyncRoles, tt.wantRoles, tt.wantSync)
+			}
+		})
+	}
+}
+
+func TestAuthenticateExternalProvisionsUser(t *testing.T) {
+	s := NewUserService()
+	cleanupUser(t, "oidc-dave")
+
+	// 首次登录未映射到任何角色时拒绝创建用户
+	_, err := s.AuthenticateExternal(&ExternalIdentity{Source: model.UserSourceOIDC, Username: "oidc-dave", SyncRoles: true})
+	if !errors.Is(err, ErrNoRoleMapped) {
+		t.Fatalf("AuthenticateExternal() without roles error = %v, want ErrNoRoleMapped", err)
+	}
+	if user, _ := s.GetUserByUsername("oidc-dave"); user != nil {
+		t.Fatalf("user created without roles: %+v", user)
+	}
+
+	user, err := s.AuthenticateExternal(&ExternalIdentity{
+		Source: model.UserSourceOIDC, Username: "oidc-dave", Nickname: "Dave", Email: "dave@example.com",
+		Roles: model.RoleOperator, SyncRoles: true,
+	})
+	if err != nil {
+		t.Fatalf("AuthenticateExternal() error = %v", err)
+	}
+	if user.Source != model.UserSourceOIDC || user.Roles != model.RoleOperator || user.Nickname != "Dave" || !user.Enabled {
+		t.Errorf("provisioned user = %+v", user)
+	}
+	// 外部用户的占位密码不能用于本地登录
+	if user.CheckPassword("") {
+		t.Errorf("provisioned user accepts an empty local password")
+	}
+}
+
+func TestAuthenticateExternalSyncRoles(t *testing.T) {
+	s := NewUserService()
+	cleanupUser(t, "oidc-erin")
+
+	identity := func(roles string, sync bool) *ExternalIdentity {
+		return &ExternalIdentity{Source: model.UserSourceOIDC, Username: "oidc-erin", Roles: roles, SyncRoles: sync}
+	}
+	if _, err := s.AuthenticateExternal(identity(model.RoleViewer, true)); err != nil {
+		t.Fatalf("AuthenticateExternal() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		identity  *ExternalIdentity
+		wantErr   error
+		wantRoles string
+	}{
+		{name: "mapped roles are synced", identity: identity("admin,operator", true), wantRoles: "admin,operator"},
+		{name: "no mapped role is rejected", identity: identity("", true), wantErr: ErrNoRoleMapped, wantRoles: "admin,operator"},
+		{name: "roles are kept without mappings", identity: identity(model.RoleViewer, false), wantRoles: "admin,operator"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.AuthenticateExternal(tt.identity)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AuthenticateExternal() error = %v, want %v", err, tt.wantErr)
+			}
+			saved, err := s.GetUserByUsername("oidc-erin")
+			if err != nil {
+				t.Fatalf("GetUserByUsername() error = %v", err)
+			}
+			if saved.Roles != tt.wantRoles {
+				t.Errorf("saved roles = %q, want %q", saved.Roles, tt.wantRoles)
+			}
+		})
+	}
+}
+
+func TestAuthenticateExternalSourceConflict(t *testing.T) {
+	s := NewUserService()
+	local := createLocalUser(t, "conflict-user", "local-password")
+
+	_, err := s.AuthenticateExternal(&ExternalIdentity{Source: model.UserSourceOIDC, Username: "conflict-user", Roles: model.RoleAdmin, SyncRoles: true})
+	if !errors.Is(err, ErrUserSourceConflict) {
+		t.Fatalf("AuthenticateExternal() error = %v, want ErrUserSourceConflict", err)
+	}
+
+	// 本地用户不受外部认证源影响
+	saved, err := s.GetUser(local.ID)
+	if err != nil {
+		t.Fatalf("GetUser() error = %v", err)
+	}
+	if saved.Source != local.Source || saved.Roles != model.RoleViewer {
+		t.Errorf("local user = source %q, roles %q, want unchanged", saved.Source, saved.Roles)
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import "context"
+
+type callerContextKey struct{}
+
+type auditedContextKey struct{}
+
+type conversationContextKey struct{}
+
+type chatGroupContextKey struct{}
+
+type chatLogContextKey struct{}
+
+type permissionsContextKey struct{}
+
+// Caller 工具调用的发起人, 用于 MCP 调用日志审计
+type Caller struct {
+	Username string
+	Source   string // 与 MCPLogParams.Source 取值一致, 如 "dingtalk", "feishu", "wecom", "api"
+}
+
+// WithCaller 在上下文中记录发起人
+func WithCaller(ctx context.Context, username, source string) context.Context {
+	return context.WithValue(ctx, callerContextKey{}, Caller{Username: username, Source: source})
+}
+
+// CallerFromContext 从上下文中获取发起人
+func CallerFromContext(ctx context.Context) (Caller, bool) {
+	caller, ok := ctx.Value(callerContextKey{}).(Caller)
+	return caller, ok && caller.Username != ""
+}
+
+// WithAudited 标记本次工具调用已由调用方记录 MCP 日志
+// 工具内部的审计逻辑据此跳过, 避免同一次调用记录两条日志
+func WithAudited(ctx context.Context) context.Context {
+	return context.WithValue(ctx, auditedContextKey{}, true)
+}
+
+// IsAudited 判断本次工具调用是否已由调用方记录 MCP 日志
+func IsAudited(ctx context.Context) bool {
+	audited, _ := ctx.Value(auditedContextKey{}).(bool)
+	return audited
+}
+
+// WithConversation 在上下文中记录所属会话, 用于按会话统计 token 用量
+func WithConversation(ctx context.Context, conversationID uint) context.Context {
+	return context.WithValue(ctx, conversationContextKey{}, conversationID)
+}
+
+// ConversationFromContext 从上下文中获取所属会话ID, 不存在时返回 0
+func ConversationFromContext(ctx context.Context) uint {
+	conversationID, _ := ctx.Value(conversationContextKey{}).(uint)
+	return conversationID
+}
+
+// WithChatGroup 在上下文中记录 IM 群聊会话ID, 用于匹配群聊的提示词配置
+func WithChatGroup(ctx context.Context, groupID string) context.Context {
+	return context.WithValue(ctx, chatGroupContextKey{}, groupID)
+}
+
+// ChatGroupFromContext 从上下文中获取 IM 群聊会话ID, 私聊时为空
+func ChatGroupFromContext(ctx context.Context) string {
+	groupID, _ := ctx.Value(chatGroupContextKey{}).(string)
+	return groupID
+}
+
+// WithChatLog 在上下文中记录触发本次对话的用户消息记录ID, 用于关联 MCP 调用日志
+func WithChatLog(ctx context.Context, chatLogID uint) context.Context {
+	return context.WithValue(ctx, chatLogContextKey{}, chatLogID)
+}
+
+// ChatLogFromContext 从上下文中获取用户消息记录ID, 不存在时返回 0
+func ChatLogFromContext(ctx context.Context) uint {
+	chatLogID, _ := ctx.Value(chatLogContextKey{}).(uint)
+	return chatLogID
+}
+
+// Identity 请求级别的调用身份, 由各接入渠道写入上下文, 随模型调用传递到工具调用
+type Identity struct {
+	Username       string
+	Source         string
+	ConversationID uint
+	ChatLogID      uint
+}
+
+// IdentityFromContext 汇总上下文中的发起人、所属会话和消息记录
+// 未记录发起人时使用 fallback, 如直接经由 MCP 协议调用的工具
+func IdentityFromContext(ctx context.Context, fallback Caller) Identity {
+	caller, ok := CallerFromContext(ctx)
+	if !ok {
+		caller = fallback
+	}
+	return Identity{
+		Username:       caller.Username,
+		Source:         caller.Source,
+		ConversationID: ConversationFromContext(ctx),
+		ChatLogID:      ChatLogFromContext(ctx),
+	}
+}
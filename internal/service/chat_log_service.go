@@ -25,7 +25,9 @@ func (s *ChatLogService) GetDB() *gorm.DB {
 }
 
 // CreateChatLog 创建对话日志
+// 写入前按脱敏规则处理对话内容
 func (s *ChatLogService) CreateChatLog(log *model.ChatLog) error {
+	log.Content, _ = Redact(model.RedactionStageStorage, log.Content)
 	return s.db.Create(log).Error
 }
 
@@ -58,6 +60,11 @@ func (s *ChatLogService) CreateAIMessage(username, source, content string, paren
 
 // CreateAIMessageWithConversation 创建AI回复日志（带会话ID）
 func (s *ChatLogService) CreateAIMessageWithConversation(username, source, content string, parentID, conversationID uint) (*model.ChatLog, error) {
+	return s.CreateAIMessageWithModel(username, source, content, "", parentID, conversationID)
+}
+
+// CreateAIMessageWithModel 创建AI回复日志, 记录实际回答的模型
+func (s *ChatLogService) CreateAIMessageWithModel(username, source, content, modelName string, parentID, conversationID uint) (*model.ChatLog, error) {
 	log := &model.ChatLog{
 		Username:       username,
 		Source:         source,
@@ -65,6 +72,7 @@ func (s *ChatLogService) CreateAIMessageWithConversation(username, source, conte
 		ParentContent:  parentID,
 		ConversationID: conversationID,
 		Content:        content,
+		Model:          modelName,
 	}
 	err := s.CreateChatLog(log)
 	if err != nil {
@@ -117,7 +125,8 @@ func (s *ChatLogService) ListChatLogs(username, source string, chatType int, lim
 	return logs, total, err
 }
 
-// GetConversationContext 获取对话上下文（包括父消息）
+// GetConversationContext 获取对话上下文, 按时间正序返回
+// 消息属于会话时返回会话中截至该消息的全部消息, 否则沿父消息链向上获取
 func (s *ChatLogService) GetConversationContext(messageID uint) ([]model.ChatLog, error) {
 	var messages []model.ChatLog
 
@@ -127,6 +136,13 @@ func (s *ChatLogService) GetConversationContext(messageID uint) ([]model.ChatLog
 		return nil, err
 	}
 
+	if currentMsg.ConversationID > 0 {
+		err := s.db.Where("conversation_id = ? AND id <= ?", currentMsg.ConversationID, currentMsg.ID).
+			Order("id ASC").
+			Find(&messages).Error
+		return messages, err
+	}
+
 	messages = append(messages, *currentMsg)
 
 	// 递归获取父消息
@@ -53,13 +53,19 @@ func (s *ConfigService) migrateLLMConfig(llmCfg config.LLMConfig) error {
 		return nil
 	}
 
+	provider := llmCfg.Provider
+	if provider == "" {
+		provider = "custom"
+	}
+
 	llm := &model.LLMConfig{
-		Name:    "默认 LLM",
-		Enabled: llmCfg.Enabled,
-		Provider: "custom",
-		Model:   llmCfg.Model,
-		APIKey:  llmCfg.APIKey,
-		BaseURL: llmCfg.BaseURL,
+		Name:          "默认 LLM",
+		Enabled:       llmCfg.Enabled,
+		Provider:      provider,
+		Model:         llmCfg.Model,
+		APIKey:        llmCfg.APIKey,
+		BaseURL:       llmCfg.BaseURL,
+		ContextTokens: llmCfg.ContextTokens,
 	}
 
 	return s.CreateLLMConfig(llm)
@@ -121,6 +127,34 @@ func (s *ConfigService) migrateProviders(providers config.ProvidersConfig) error
 		log.Printf("Migrated provider account: tencent/%s", p.Name)
 	}
 
+	// 迁移 AWS 账号
+	for _, p := range providers.AWS {
+		account := &model.ProviderAccount{
+			Provider:  "aws",
+			Name:      p.Name,
+			Enabled:   p.Enabled,
+			AccessKey: p.AK,
+			SecretKey: p.SK,
+			Regions:   p.Regions,
+			Endpoint:  p.Extra["endpoint"],
+		}
+
+		// 检查是否已存在
+		existing, err := s.GetProviderAccountByName("aws", p.Name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			log.Printf("Provider account already exists: aws/%s, skipping", p.Name)
+			continue
+		}
+
+		if err := s.CreateProviderAccount(account); err != nil {
+			return err
+		}
+		log.Printf("Migrated provider account: aws/%s", p.Name)
+	}
+
 	return nil
 }
 
@@ -155,6 +189,7 @@ func (s *ConfigService) migrateIMConfigs(cfg *config.Config) error {
 				AppKey:     cfg.DingTalk.AppKey,
 				AgentID:    cfg.DingTalk.AgentID,
 				TemplateID: cfg.DingTalk.CardTemplateID,
+				LLMName:    cfg.DingTalk.LLM,
 			}
 			if err := s.SaveIMConfig(imConfig); err != nil {
 				return err
@@ -177,6 +212,7 @@ func (s *ConfigService) migrateIMConfigs(cfg *config.Config) error {
 				Enabled:  cfg.Feishu.Enabled,
 				AppID:    cfg.Feishu.AppID,
 				AppKey:   cfg.Feishu.AppSecret,
+				LLMName:  cfg.Feishu.LLM,
 			}
 			if err := s.SaveIMConfig(imConfig); err != nil {
 				return err
@@ -199,6 +235,7 @@ func (s *ConfigService) migrateIMConfigs(cfg *config.Config) error {
 				Enabled:  cfg.Wecom.Enabled,
 				AppKey:   cfg.Wecom.Token,
 				AgentID:  cfg.Wecom.EncodingAESKey, // 临时存储 AES Key
+				LLMName:  cfg.Wecom.LLM,
 			}
 			if err := s.SaveIMConfig(imConfig); err != nil {
 				return err
@@ -212,26 +249,71 @@ func (s *ConfigService) migrateIMConfigs(cfg *config.Config) error {
 
 // migrateCICDConfigs 迁移CICD配置
 func (s *ConfigService) migrateCICDConfigs(cicdCfg config.CICDConfig) error {
-	// 迁移 Jenkins 配置
-	if cicdCfg.Jenkins.URL != "" {
-		existing, err := s.GetCICDConfig("jenkins")
+	// 迁移 Jenkins 配置, 每个实例单独一条记录
+	for _, instance := range cicdCfg.Jenkins.AllInstances() {
+		existing, err := s.GetCICDConfigByName("jenkins", instance.Name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			log.Printf("Jenkins instance %s already exists, skipping", instance.Name)
+			continue
+		}
+		jenkins := &model.CICDConfig{
+			Platform: "jenkins",
+			Name:     instance.Name,
+			Enabled:  instance.Enabled,
+			URL:      instance.URL,
+			Username: instance.Username,
+			Token:    instance.Token,
+		}
+		if err := s.SaveCICDConfig(jenkins); err != nil {
+			return err
+		}
+		log.Printf("Migrated Jenkins instance %s", instance.Name)
+	}
+
+	// 迁移 GitLab 配置
+	if cicdCfg.GitLab.URL != "" {
+		existing, err := s.GetCICDConfig("gitlab")
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			log.Println("GitLab config already exists, skipping")
+		} else {
+			gitlab := &model.CICDConfig{
+				Platform: "gitlab",
+				Enabled:  cicdCfg.GitLab.Enabled,
+				URL:      cicdCfg.GitLab.URL,
+				Token:    cicdCfg.GitLab.Token,
+			}
+			if err := s.SaveCICDConfig(gitlab); err != nil {
+				return err
+			}
+			log.Println("Migrated GitLab config")
+		}
+	}
+
+	// 迁移 GitHub 配置 (url 可为空, 以 token 判断是否配置)
+	if cicdCfg.GitHub.Token != "" {
+		existing, err := s.GetCICDConfig("github")
 		if err != nil {
 			return err
 		}
 		if existing != nil {
-			log.Println("Jenkins config already exists, skipping")
+			log.Println("GitHub config already exists, skipping")
 		} else {
-			jenkins := &model.CICDConfig{
-				Platform: "jenkins",
-				Enabled:  cicdCfg.Jenkins.Enabled,
-				URL:      cicdCfg.Jenkins.URL,
-				Username: cicdCfg.Jenkins.Username,
-				Token:    cicdCfg.Jenkins.Token,
+			github := &model.CICDConfig{
+				Platform: "github",
+				Enabled:  cicdCfg.GitHub.Enabled,
+				URL:      cicdCfg.GitHub.URL,
+				Token:    cicdCfg.GitHub.Token,
 			}
-			if err := s.SaveCICDConfig(jenkins); err != nil {
+			if err := s.SaveCICDConfig(github); err != nil {
 				return err
 			}
-			log.Println("Migrated Jenkins config")
+			log.Println("Migrated GitHub config")
 		}
 	}
 
@@ -297,10 +379,12 @@ func (s *ConfigService) LoadConfigFromDB() (*config.Config, error) {
 		for _, llm := range llmConfigs {
 			if llm.Enabled {
 				cfg.LLM = config.LLMConfig{
-					Enabled: llm.Enabled,
-					Model:   llm.Model,
-					APIKey:  llm.APIKey,
-					BaseURL: llm.BaseURL,
+					Enabled:       llm.Enabled,
+					Provider:      llm.Provider,
+					Model:         llm.Model,
+					APIKey:        llm.APIKey,
+					BaseURL:       llm.BaseURL,
+					ContextTokens: llm.ContextTokens,
 				}
 				break
 			}
@@ -309,10 +393,12 @@ func (s *ConfigService) LoadConfigFromDB() (*config.Config, error) {
 		if cfg.LLM.Model == "" && len(llmConfigs) > 0 {
 			firstLLM := llmConfigs[0]
 			cfg.LLM = config.LLMConfig{
-				Enabled: firstLLM.Enabled,
-				Model:   firstLLM.Model,
-				APIKey:  firstLLM.APIKey,
-				BaseURL: firstLLM.BaseURL,
+				Enabled:       firstLLM.Enabled,
+				Provider:      firstLLM.Provider,
+				Model:         firstLLM.Model,
+				APIKey:        firstLLM.APIKey,
+				BaseURL:       firstLLM.BaseURL,
+				ContextTokens: firstLLM.ContextTokens,
 			}
 		}
 	}
@@ -376,6 +462,22 @@ func (s *ConfigService) LoadProvidersFromDB() (config.ProvidersConfig, error) {
 		})
 	}
 
+	// 加载 AWS 账号
+	awsAccounts, err := s.ListProviderAccounts("aws")
+	if err != nil {
+		return providers, err
+	}
+	for _, acc := range awsAccounts {
+		providers.AWS = append(providers.AWS, config.ProviderConfig{
+			Name:    acc.Name,
+			Enabled: acc.Enabled,
+			AK:      acc.AccessKey,
+			SK:      acc.SecretKey,
+			Regions: acc.Regions,
+			Extra:   map[string]string{"endpoint": acc.Endpoint},
+		})
+	}
+
 	return providers, nil
 }
 
@@ -393,6 +495,7 @@ func (s *ConfigService) LoadIMConfigsFromDB(cfg *config.Config) error {
 			AppSecret:      dingtalk.AppKey, // 使用 AppKey 作为 Secret
 			AgentID:        dingtalk.AgentID,
 			CardTemplateID: dingtalk.TemplateID,
+			LLM:            dingtalk.LLMName,
 		}
 	}
 
@@ -406,6 +509,7 @@ func (s *ConfigService) LoadIMConfigsFromDB(cfg *config.Config) error {
 			Enabled:   feishu.Enabled,
 			AppID:     feishu.AppID,
 			AppSecret: feishu.AppKey,
+			LLM:       feishu.LLMName,
 		}
 	}
 
@@ -419,6 +523,7 @@ func (s *ConfigService) LoadIMConfigsFromDB(cfg *config.Config) error {
 			Enabled:        wecom.Enabled,
 			Token:          wecom.AppKey,
 			EncodingAESKey: wecom.AgentID,
+			LLM:            wecom.LLMName,
 		}
 	}
 
@@ -427,17 +532,48 @@ func (s *ConfigService) LoadIMConfigsFromDB(cfg *config.Config) error {
 
 // LoadCICDConfigsFromDB 从数据库加载CICD配置
 func (s *ConfigService) LoadCICDConfigsFromDB(cfg *config.Config) error {
-	jenkins, err := s.GetCICDConfig("jenkins")
+	// 第一个 Jenkins 实例作为顶层配置, 其余实例放入 Instances
+	jenkinsInstances, err := s.ListCICDConfigsByPlatform("jenkins")
 	if err != nil {
 		return err
 	}
-	if jenkins != nil {
-		cfg.CICD.Jenkins = config.JenkinsConfig{
+	for i, jenkins := range jenkinsInstances {
+		instance := config.JenkinsConfig{
+			Name:     jenkins.Name,
 			Enabled:  jenkins.Enabled,
 			URL:      jenkins.URL,
 			Username: jenkins.Username,
 			Token:    jenkins.Token,
 		}
+		if i == 0 {
+			cfg.CICD.Jenkins = instance
+			continue
+		}
+		cfg.CICD.Jenkins.Instances = append(cfg.CICD.Jenkins.Instances, instance)
+	}
+
+	gitlab, err := s.GetCICDConfig("gitlab")
+	if err != nil {
+		return err
+	}
+	if gitlab != nil {
+		cfg.CICD.GitLab = config.GitLabConfig{
+			Enabled: gitlab.Enabled,
+			URL:     gitlab.URL,
+			Token:   gitlab.Token,
+		}
+	}
+
+	github, err := s.GetCICDConfig("github")
+	if err != nil {
+		return err
+	}
+	if github != nil {
+		cfg.CICD.GitHub = config.GitHubConfig{
+			Enabled: github.Enabled,
+			URL:     github.URL,
+			Token:   github.Token,
+		}
 	}
 
 	return nil
@@ -3,11 +3,14 @@ package service
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
 
 	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/go-viper/mapstructure/v2"
 	"gorm.io/gorm"
 
+	"github.com/eryajf/zenops/internal/config"
 	"github.com/eryajf/zenops/internal/database"
 	"github.com/eryajf/zenops/internal/model"
 )
@@ -107,6 +110,29 @@ func (s *ConfigService) GetDefaultLLMConfig() (*model.LLMConfig, error) {
 	return &config, nil
 }
 
+// GetLLMChain 获取按调用顺序排列的已启用 LLM 配置
+// name 指定的配置 (按名称匹配, 兼容按模型名称匹配) 排在首位, 其余按 ID 顺序作为备用;
+// name 为空时按 ID 顺序返回, 没有启用的配置时返回空列表
+func (s *ConfigService) GetLLMChain(name string) ([]model.LLMConfig, error) {
+	configs, err := s.GetEnabledLLMConfigs()
+	if err != nil || name == "" || len(configs) == 0 {
+		return configs, err
+	}
+
+	index := slices.IndexFunc(configs, func(c model.LLMConfig) bool { return c.Name == name })
+	if index < 0 {
+		index = slices.IndexFunc(configs, func(c model.LLMConfig) bool { return c.Model == name })
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("LLM config '%s' not found or disabled", name)
+	}
+
+	chain := make([]model.LLMConfig, 0, len(configs))
+	chain = append(chain, configs[index])
+	chain = append(chain, configs[:index]...)
+	return append(chain, configs[index+1:]...), nil
+}
+
 // ========== 云厂商账号配置管理 ==========
 
 // ListProviderAccounts 列出云厂商账号
@@ -154,6 +180,60 @@ func (s *ConfigService) DeleteProviderAccount(id uint) error {
 	return s.db.Delete(&model.ProviderAccount{}, id).Error
 }
 
+// ========== Kubernetes 集群配置管理 ==========
+
+// ListKubernetesClusters 列出 Kubernetes 集群
+func (s *ConfigService) ListKubernetesClusters() ([]model.KubernetesCluster, error) {
+	var clusters []model.KubernetesCluster
+	err := s.db.Order("name").Find(&clusters).Error
+	return clusters, err
+}
+
+// GetKubernetesCluster 获取指定 Kubernetes 集群
+func (s *ConfigService) GetKubernetesCluster(id uint) (*model.KubernetesCluster, error) {
+	var cluster model.KubernetesCluster
+	err := s.db.First(&cluster, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cluster, nil
+}
+
+// GetKubernetesClusterByName 根据名称获取 Kubernetes 集群
+func (s *ConfigService) GetKubernetesClusterByName(name string) (*model.KubernetesCluster, error) {
+	var cluster model.KubernetesCluster
+	err := s.db.Where("name = ?", name).First(&cluster).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cluster, nil
+}
+
+// CreateKubernetesCluster 创建 Kubernetes 集群
+func (s *ConfigService) CreateKubernetesCluster(cluster *model.KubernetesCluster) error {
+	existing, err := s.GetKubernetesClusterByName(cluster.Name)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("kubernetes cluster already exists: %s", cluster.Name)
+	}
+	return s.db.Create(cluster).Error
+}
+
+// UpdateKubernetesCluster 更新 Kubernetes 集群
+func (s *ConfigService) UpdateKubernetesCluster(cluster *model.KubernetesCluster) error {
+	return s.db.Save(cluster).Error
+}
+
+// DeleteKubernetesCluster 删除 Kubernetes 集群
+func (s *ConfigService) DeleteKubernetesCluster(id uint) error {
+	return s.db.Delete(&model.KubernetesCluster{}, id).Error
+}
+
 // ========== IM 配置管理 ==========
 
 // GetIMConfig 获取IM配置
@@ -196,9 +276,10 @@ func (s *ConfigService) ListIMConfigs() ([]model.IMConfig, error) {
 // ========== CICD 配置管理 ==========
 
 // GetCICDConfig 获取CICD配置
+// 同一平台配置了多个实例时返回最早创建的实例
 func (s *ConfigService) GetCICDConfig(platform string) (*model.CICDConfig, error) {
 	var config model.CICDConfig
-	err := s.db.Where("platform = ?", platform).First(&config).Error
+	err := s.db.Where("platform = ?", platform).Order("id").First(&config).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -208,11 +289,39 @@ func (s *ConfigService) GetCICDConfig(platform string) (*model.CICDConfig, error
 	return &config, nil
 }
 
+// GetCICDConfigByName 获取指定平台下指定名称的实例配置
+func (s *ConfigService) GetCICDConfigByName(platform, name string) (*model.CICDConfig, error) {
+	var config model.CICDConfig
+	err := s.db.Where("platform = ? AND name = ?", platform, name).First(&config).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &config, nil
+}
+
+// GetCICDConfigByID 获取指定CICD实例配置
+func (s *ConfigService) GetCICDConfigByID(id uint) (*model.CICDConfig, error) {
+	var config model.CICDConfig
+	err := s.db.First(&config, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
 // SaveCICDConfig 保存CICD配置
+// 按平台和实例名称匹配, 名称为空时使用 default 实例
 func (s *ConfigService) SaveCICDConfig(config *model.CICDConfig) error {
+	if config.Name == "" {
+		config.Name = "default"
+	}
+
 	// 检查是否已存在配置
 	var existing model.CICDConfig
-	err := s.db.Where("platform = ?", config.Platform).First(&existing).Error
+	err := s.db.Where("platform = ? AND name = ?", config.Platform, config.Name).First(&existing).Error
 	if err == nil {
 		// 存在则更新
 		config.ID = existing.ID
@@ -228,10 +337,69 @@ func (s *ConfigService) SaveCICDConfig(config *model.CICDConfig) error {
 // ListCICDConfigs 列出所有CICD配置
 func (s *ConfigService) ListCICDConfigs() ([]model.CICDConfig, error) {
 	var configs []model.CICDConfig
-	err := s.db.Find(&configs).Error
+	err := s.db.Order("platform, id").Find(&configs).Error
 	return configs, err
 }
 
+// ListCICDConfigsByPlatform 列出指定平台的所有实例配置
+func (s *ConfigService) ListCICDConfigsByPlatform(platform string) ([]model.CICDConfig, error) {
+	var configs []model.CICDConfig
+	err := s.db.Where("platform = ?", platform).Order("id").Find(&configs).Error
+	return configs, err
+}
+
+// CreateCICDConfig 创建CICD实例配置
+func (s *ConfigService) CreateCICDConfig(config *model.CICDConfig) error {
+	if config.Name == "" {
+		config.Name = "default"
+	}
+
+	// 检查是否已存在同名实例
+	var existing model.CICDConfig
+	err := s.db.Where("platform = ? AND name = ?", config.Platform, config.Name).First(&existing).Error
+	if err == nil {
+		return fmt.Errorf("cicd instance already exists: %s/%s", config.Platform, config.Name)
+	}
+	if err != gorm.ErrRecordNotFound {
+		return err
+	}
+	return s.db.Create(config).Error
+}
+
+// UpdateCICDConfig 更新CICD实例配置
+func (s *ConfigService) UpdateCICDConfig(config *model.CICDConfig) error {
+	return s.db.Save(config).Error
+}
+
+// DeleteCICDConfig 删除CICD实例配置
+func (s *ConfigService) DeleteCICDConfig(id uint) error {
+	return s.db.Delete(&model.CICDConfig{}, id).Error
+}
+
+// ListJenkinsInstances 获取所有 Jenkins 实例配置
+// 优先使用数据库中的配置, 数据库中没有时回退到配置文件
+func (s *ConfigService) ListJenkinsInstances(fallback config.JenkinsConfig) []config.JenkinsConfig {
+	configs, err := s.ListCICDConfigsByPlatform("jenkins")
+	if err != nil {
+		logx.Warn("Failed to load jenkins instances from database: %v", err)
+	}
+	if len(configs) == 0 {
+		return fallback.AllInstances()
+	}
+
+	instances := make([]config.JenkinsConfig, 0, len(configs))
+	for _, c := range configs {
+		instances = append(instances, config.JenkinsConfig{
+			Name:     c.Name,
+			Enabled:  c.Enabled,
+			URL:      c.URL,
+			Username: c.Username,
+			Token:    c.Token,
+		})
+	}
+	return instances
+}
+
 // ========== MCP Server 配置管理 ==========
 
 // ListMCPServers 列出MCP服务器
@@ -404,6 +572,58 @@ func (s *ConfigService) ListSystemConfigs() ([]model.SystemConfig, error) {
 	return configs, err
 }
 
+// AuthTokens 返回 auth.tokens 中配置的访问令牌
+// 优先使用数据库系统配置, 未配置时使用 fallback (配置文件中的值)
+func (s *ConfigService) AuthTokens(fallback []string) []string {
+	systemConfig, err := s.GetSystemConfig(model.ConfigKeyAuthTokens)
+	if err == nil && systemConfig != nil {
+		var tokens []string
+		if err := json.Unmarshal([]byte(systemConfig.ConfigValue), &tokens); err == nil {
+			return tokens
+		}
+		logx.Warn("Invalid system config, key %s", model.ConfigKeyAuthTokens)
+	}
+	return fallback
+}
+
+// LDAPConfig 返回 LDAP 登录配置
+// 优先使用数据库系统配置 auth.ldap (JSON, 键名同配置文件), 未配置时使用 fallback (配置文件中的值)
+func (s *ConfigService) LDAPConfig(fallback config.LDAPConfig) config.LDAPConfig {
+	var ldapConfig config.LDAPConfig
+	if s.decodeSystemConfig(model.ConfigKeyAuthLDAP, &ldapConfig) {
+		return ldapConfig
+	}
+	return fallback
+}
+
+// OIDCConfig 返回 OIDC 单点登录配置
+// 优先使用数据库系统配置 auth.oidc (JSON, 键名同配置文件), 未配置时使用 fallback (配置文件中的值)
+func (s *ConfigService) OIDCConfig(fallback config.OIDCConfig) config.OIDCConfig {
+	var oidcConfig config.OIDCConfig
+	if s.decodeSystemConfig(model.ConfigKeyAuthOIDC, &oidcConfig) {
+		return oidcConfig
+	}
+	return fallback
+}
+
+// decodeSystemConfig 将 JSON 格式的系统配置按 mapstructure 标签解析到 target, 未配置或格式错误时返回 false
+func (s *ConfigService) decodeSystemConfig(key string, target any) bool {
+	systemConfig, err := s.GetSystemConfig(key)
+	if err != nil || systemConfig == nil {
+		return false
+	}
+	var value map[string]any
+	if err := json.Unmarshal([]byte(systemConfig.ConfigValue), &value); err != nil {
+		logx.Warn("Invalid system config, key %s", key)
+		return false
+	}
+	if err := mapstructure.Decode(value, target); err != nil {
+		logx.Warn("Invalid system config, key %s, error %v", key, err)
+		return false
+	}
+	return true
+}
+
 // GetIMConfigByID 根据ID获取IM配置
 func (s *ConfigService) GetIMConfigByID(id uint) (*model.IMConfig, error) {
 	var config model.IMConfig
@@ -3,6 +3,7 @@ package service
 import (
 	"time"
 
+	"cnb.cool/zhiqiangwang/pkg/logx"
 	"gorm.io/gorm"
 
 	"github.com/eryajf/zenops/internal/database"
@@ -72,6 +73,53 @@ func (s *ConversationService) UpdateLastMessageAt(id uint) error {
 		Update("last_message_at", time.Now()).Error
 }
 
+// UpdateSummary 更新会话摘要及其覆盖的最后一条对话日志ID
+func (s *ConversationService) UpdateSummary(id uint, summary string, summarizedUntil uint) error {
+	return s.db.Model(&model.Conversation{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"summary":          summary,
+			"summarized_until": summarizedUntil,
+		}).Error
+}
+
+// ChannelConversation 返回 IM 渠道会话ID, 用于关联多轮对话记录和会话摘要
+// chatID 私聊时为用户标识, 群聊时为群会话ID, 获取失败时返回 0, 对话按单轮处理
+func ChannelConversation(platform, chatID, username, title string) uint {
+	if chatID == "" {
+		return 0
+	}
+	conversation, err := NewConversationService().GetOrCreateChannelConversation(platform+":"+chatID, username, title)
+	if err != nil {
+		logx.Warn("Failed to get channel conversation, platform %s, chat %s, error %v", platform, chatID, err)
+		return 0
+	}
+	return conversation.ID
+}
+
+// GetOrCreateChannelConversation 获取 IM 渠道会话, 不存在时创建
+func (s *ConversationService) GetOrCreateChannelConversation(channelKey, username, title string) (*model.Conversation, error) {
+	var conversation model.Conversation
+	err := s.db.Where("channel_key = ?", channelKey).First(&conversation).Error
+	if err == nil {
+		return &conversation, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	conversation = model.Conversation{
+		Username:      username,
+		Title:         title,
+		ChannelKey:    channelKey,
+		LastMessageAt: time.Now(),
+	}
+	if err := s.db.Create(&conversation).Error; err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
 // DeleteConversation 删除会话（软删除）
 func (s *ConversationService) DeleteConversation(id uint) error {
 	// 删除会话下的所有消息
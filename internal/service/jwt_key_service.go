@@ -0,0 +1,209 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/database"
+	"github.com/eryajf/zenops/internal/model"
+	"gorm.io/gorm"
+)
+
+const (
+	// JWTSecretEnv 通过环境变量指定 JWT 签名密钥, 设置后不再使用数据库中的密钥, 也不支持轮换
+	JWTSecretEnv = "ZENOPS_JWT_SECRET"
+	// envJWTKeyID 环境变量密钥对应的 kid
+	envJWTKeyID = "env"
+	// minJWTSecretLength 环境变量密钥的建议最小长度
+	minJWTSecretLength = 32
+)
+
+var (
+	// ErrUnknownJWTKey 令牌的 kid 不存在或对应的密钥已过保留期
+	ErrUnknownJWTKey = errors.New("unknown jwt signing key")
+	// ErrEnvJWTKey 使用环境变量中的密钥时不支持轮换
+	ErrEnvJWTKey = errors.New("jwt signing key is provided by " + JWTSecretEnv + " and cannot be rotated")
+)
+
+// jwtKeyring 缓存启用的签名密钥和仍可用于校验的旧密钥, 轮换后重新加载
+var jwtKeyring struct {
+	mu     sync.RWMutex
+	loaded bool
+	active *model.JWTKey
+	keys   map[string][]byte
+}
+
+// JWTKeyService JWT 签名密钥服务
+type JWTKeyService struct {
+	db *gorm.DB
+}
+
+// NewJWTKeyService 创建 JWT 签名密钥服务实例
+func NewJWTKeyService() *JWTKeyService {
+	return &JWTKeyService{
+		db: database.GetDB(),
+	}
+}
+
+// envJWTSecret 获取环境变量中的签名密钥
+func envJWTSecret() string {
+	return os.Getenv(JWTSecretEnv)
+}
+
+// generateJWTKey 生成随机签名密钥
+func generateJWTKey() (*model.JWTKey, error) {
+	kid := make([]byte, 8)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(kid); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return &model.JWTKey{
+		KID:    hex.EncodeToString(kid),
+		Secret: hex.EncodeToString(secret),
+		Active: true,
+	}, nil
+}
+
+// SigningKey 获取当前用于签发令牌的密钥
+// 优先使用环境变量 ZENOPS_JWT_SECRET; 否则使用数据库中启用的密钥, 首次启动时自动生成
+func (s *JWTKeyService) SigningKey() (string, []byte, error) {
+	if secret := envJWTSecret(); secret != "" {
+		return envJWTKeyID, []byte(secret), nil
+	}
+
+	active, _, err := s.load()
+	if err != nil {
+		return "", nil, err
+	}
+	return active.KID, []byte(active.Secret), nil
+}
+
+// VerificationKey 获取 kid 对应的校验密钥
+func (s *JWTKeyService) VerificationKey(kid string) ([]byte, error) {
+	if secret := envJWTSecret(); secret != "" {
+		if kid != envJWTKeyID {
+			return nil, ErrUnknownJWTKey
+		}
+		return []byte(secret), nil
+	}
+
+	_, keys, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	secret, ok := keys[kid]
+	if !ok {
+		return nil, ErrUnknownJWTKey
+	}
+	return secret, nil
+}
+
+// ListKeys 列出数据库中的签名密钥, 不包含密钥内容
+func (s *JWTKeyService) ListKeys() ([]model.JWTKey, error) {
+	var keys []model.JWTKey
+	err := s.db.Order("id desc").Find(&keys).Error
+	return keys, err
+}
+
+// RotateKey 轮换签名密钥
+// 新密钥立即用于签发, 旧密钥在一个访问令牌有效期内仍可校验, 已登录用户不受影响
+func (s *JWTKeyService) RotateKey() (*model.JWTKey, error) {
+	if envJWTSecret() != "" {
+		return nil, ErrEnvJWTKey
+	}
+
+	key, err := generateJWTKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate jwt key: %w", err)
+	}
+
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("retired_at < ?", now.Add(-AccessTokenTTL)).Delete(&model.JWTKey{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.JWTKey{}).Where("active = ?", true).Updates(map[string]any{"active": false, "retired_at": now}).Error; err != nil {
+			return err
+		}
+		return tx.Create(key).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invalidateJWTKeyring()
+	logx.Info("JWT signing key rotated, new kid %s", key.KID)
+	return key, nil
+}
+
+// invalidateJWTKeyring 清除已缓存的密钥, 下次使用时从数据库重新加载
+func invalidateJWTKeyring() {
+	jwtKeyring.mu.Lock()
+	defer jwtKeyring.mu.Unlock()
+	jwtKeyring.loaded = false
+	jwtKeyring.active = nil
+	jwtKeyring.keys = nil
+}
+
+// load 加载启用的密钥和保留期内的旧密钥, 没有启用的密钥时生成一个
+func (s *JWTKeyService) load() (*model.JWTKey, map[string][]byte, error) {
+	jwtKeyring.mu.RLock()
+	if jwtKeyring.loaded {
+		defer jwtKeyring.mu.RUnlock()
+		return jwtKeyring.active, jwtKeyring.keys, nil
+	}
+	jwtKeyring.mu.RUnlock()
+
+	jwtKeyring.mu.Lock()
+	defer jwtKeyring.mu.Unlock()
+	if jwtKeyring.loaded {
+		return jwtKeyring.active, jwtKeyring.keys, nil
+	}
+
+	var keys []model.JWTKey
+	err := s.db.Where("active = ? OR retired_at >= ?", true, time.Now().Add(-AccessTokenTTL)).Find(&keys).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load jwt keys: %w", err)
+	}
+
+	var active *model.JWTKey
+	secrets := make(map[string][]byte, len(keys)+1)
+	for i := range keys {
+		secrets[keys[i].KID] = []byte(keys[i].Secret)
+		if keys[i].Active {
+			active = &keys[i]
+		}
+	}
+	if active == nil {
+		active, err = generateJWTKey()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate jwt key: %w", err)
+		}
+		if err := s.db.Create(active).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to save jwt key: %w", err)
+		}
+		secrets[active.KID] = []byte(active.Secret)
+		logx.Info("Generated JWT signing key %s", active.KID)
+	}
+
+	jwtKeyring.loaded = true
+	jwtKeyring.active = active
+	jwtKeyring.keys = secrets
+	return active, secrets, nil
+}
+
+// CheckJWTSecret 检查环境变量中的签名密钥长度, 过短时打印警告
+func CheckJWTSecret() {
+	if secret := envJWTSecret(); secret != "" && len(secret) < minJWTSecretLength {
+		logx.Warn("%s is shorter than %d characters, please use a longer random secret", JWTSecretEnv, minJWTSecretLength)
+	}
+}
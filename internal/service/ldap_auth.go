@@ -0,0 +1,182 @@
+package service
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ldapTimeout LDAP 连接和请求的超时时间
+const ldapTimeout = 10 * time.Second
+
+// LDAPBackend LDAP 认证源
+// 先以服务账号 (或匿名) 搜索用户, 再以用户 DN 和密码绑定校验密码, 最后查询用户所属的组
+type LDAPBackend struct {
+	config config.LDAPConfig
+}
+
+// NewLDAPBackend 创建 LDAP 认证源, 未配置的属性和搜索条件使用默认值
+func NewLDAPBackend(cfg config.LDAPConfig) *LDAPBackend {
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = "(uid=%s)"
+	}
+	if cfg.UsernameAttribute == "" {
+		cfg.UsernameAttribute = "uid"
+	}
+	if cfg.NicknameAttribute == "" {
+		cfg.NicknameAttribute = "cn"
+	}
+	if cfg.EmailAttribute == "" {
+		cfg.EmailAttribute = "mail"
+	}
+	if cfg.GroupFilter == "" {
+		cfg.GroupFilter = "(member=%s)"
+	}
+	return &LDAPBackend{config: cfg}
+}
+
+// Source 认证源对应的用户来源
+func (b *LDAPBackend) Source() string {
+	return model.UserSourceLDAP
+}
+
+// Authenticate 校验 LDAP 用户名和密码
+func (b *LDAPBackend) Authenticate(username, password string) (*ExternalIdentity, error) {
+	// 空密码会被 LDAP 服务器当作匿名绑定而成功, 必须拒绝
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := b.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := b.bindServiceAccount(conn); err != nil {
+		return nil, err
+	}
+
+	entry, err := b.searchUser(conn, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to bind ldap user %s: %w", entry.DN, err)
+	}
+
+	identity := &ExternalIdentity{
+		Source:   model.UserSourceLDAP,
+		Username: entry.GetAttributeValue(b.config.UsernameAttribute),
+		Nickname: entry.GetAttributeValue(b.config.NicknameAttribute),
+		Email:    entry.GetAttributeValue(b.config.EmailAttribute),
+	}
+	if identity.Username == "" {
+		identity.Username = username
+	}
+
+	identity.Groups, err = b.searchGroups(conn, entry)
+	if err != nil {
+		return nil, err
+	}
+	mapGroupRoles(identity, b.config.GroupRoles, b.config.DefaultRole)
+	return identity, nil
+}
+
+// dial 连接 LDAP 服务器, 按配置升级为 TLS 连接
+func (b *LDAPBackend) dial() (*ldap.Conn, error) {
+	if b.config.URL == "" {
+		return nil, fmt.Errorf("ldap url is not configured")
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: b.config.InsecureSkipVerify}
+	conn, err := ldap.DialURL(b.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect ldap server: %w", err)
+	}
+	conn.SetTimeout(ldapTimeout)
+
+	if b.config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// bindServiceAccount 以服务账号绑定, 未配置服务账号时使用匿名搜索
+func (b *LDAPBackend) bindServiceAccount(conn *ldap.Conn) error {
+	if b.config.BindDN == "" {
+		return nil
+	}
+	if err := conn.Bind(b.config.BindDN, b.config.BindPassword); err != nil {
+		return fmt.Errorf("failed to bind ldap service account: %w", err)
+	}
+	return nil
+}
+
+// searchUser 搜索登录名对应的用户, 找不到或不唯一时视为用户名错误
+func (b *LDAPBackend) searchUser(conn *ldap.Conn, username string) (*ldap.Entry, error) {
+	request := ldap.NewSearchRequest(
+		b.config.BaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(ldapTimeout.Seconds()), false,
+		fmt.Sprintf(b.config.UserFilter, ldap.EscapeFilter(username)),
+		[]string{b.config.UsernameAttribute, b.config.NicknameAttribute, b.config.EmailAttribute, "memberOf"},
+		nil,
+	)
+	result, err := conn.Search(request)
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to search ldap user: %w", err)
+	}
+	if len(result.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	return result.Entries[0], nil
+}
+
+// searchGroups 查询用户所属组的 DN
+// 配置了 group_base_dn 时按 group_filter 搜索, 否则使用用户的 memberOf 属性
+func (b *LDAPBackend) searchGroups(conn *ldap.Conn, entry *ldap.Entry) ([]string, error) {
+	if b.config.GroupBaseDN == "" {
+		return entry.GetAttributeValues("memberOf"), nil
+	}
+
+	// 用户绑定后可能没有搜索组的权限, 重新以服务账号绑定
+	if err := b.bindServiceAccount(conn); err != nil {
+		return nil, err
+	}
+	request := ldap.NewSearchRequest(
+		b.config.GroupBaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, int(ldapTimeout.Seconds()), false,
+		fmt.Sprintf(b.config.GroupFilter, ldap.EscapeFilter(entry.DN)),
+		[]string{"dn"},
+		nil,
+	)
+	result, err := conn.Search(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search ldap groups: %w", err)
+	}
+
+	groups := make([]string, 0, len(result.Entries))
+	for _, group := range result.Entries {
+		groups = append(groups, strings.TrimSpace(group.DN))
+	}
+	return groups, nil
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/model"
+	"github.com/jimlambrt/gldap"
+)
+
+// ldapTestUser 测试目录中的用户
+type ldapTestUser struct {
+	password string
+	attrs    map[string][]string
+}
+
+// ldapTestDirectory 测试目录: 服务账号 cn=svc, 用户 alice (ops 组) 和 bob (无组)
+// 在 ou=groups 下搜索 member 时, alice 属于 Admins 组
+var ldapTestDirectory = map[string]ldapTestUser{
+	"cn=svc,dc=example,dc=com": {password: "svcpw"},
+	"uid=alice,ou=people,dc=example,dc=com": {password: "alicepw", attrs: map[string][]string{
+		"uid": {"alice"}, "cn": {"Alice"}, "mail": {"alice@example.com"},
+		"memberOf": {"cn=ops,ou=groups,dc=example,dc=com"},
+	}},
+	"uid=bob,ou=people,dc=example,dc=com": {password: "bobpw", attrs: map[string][]string{
+		"uid": {"bob"}, "cn": {"Bob"}, "mail": {"bob@example.com"},
+	}},
+}
+
+// startLDAPServer 启动进程内 LDAP 服务器, 返回 ldap:// 地址
+func startLDAPServer(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen error = %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	server, err := gldap.NewServer()
+	if err != nil {
+		t.Fatalf("gldap.NewServer() error = %v", err)
+	}
+	mux, err := gldap.NewMux()
+	if err != nil {
+		t.Fatalf("gldap.NewMux() error = %v", err)
+	}
+	mux.Bind(func(w *gldap.ResponseWriter, r *gldap.Request) {
+		resp := r.NewBindResponse(gldap.WithResponseCode(gldap.ResultInvalidCredentials))
+		defer w.Write(resp)
+		m, err := r.GetSimpleBindMessage()
+		if err != nil {
+			return
+		}
+		if user, ok := ldapTestDirectory[m.UserName]; ok && user.password == string(m.Password) {
+			resp.SetResultCode(gldap.ResultSuccess)
+		}
+	})
+	mux.Search(func(w *gldap.ResponseWriter, r *gldap.Request) {
+		resp := r.NewSearchDoneResponse()
+		defer w.Write(resp)
+		m, err := r.GetSearchMessage()
+		if err != nil {
+			return
+		}
+		switch m.BaseDN {
+		case "ou=people,dc=example,dc=com":
+			for dn, user := range ldapTestDirectory {
+				if uid := user.attrs["uid"]; len(uid) > 0 && m.Filter == fmt.Sprintf("(uid=%s)", uid[0]) {
+					w.Write(r.NewSearchResponseEntry(dn, gldap.WithAttributes(user.attrs)))
+				}
+			}
+		case "ou=groups,dc=example,dc=com":
+			if m.Filter == "(member=uid=alice,ou=people,dc=example,dc=com)" {
+				w.Write(r.NewSearchResponseEntry("CN=Admins,ou=groups,dc=example,dc=com"))
+			}
+		}
+		resp.SetResultCode(gldap.ResultSuccess)
+	})
+	server.Router(mux)
+
+	go server.Run(addr)
+	t.Cleanup(func() { server.Stop() })
+	for i := 0; i < 100 && !server.Ready(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !server.Ready() {
+		t.Fatalf("ldap server is not ready")
+	}
+	return "ldap://" + addr
+}
+
+// newTestLDAPConfig 返回指向测试目录的 LDAP 配置, alice 所在的 ops 组映射为 operator
+func newTestLDAPConfig(url string) config.LDAPConfig {
+	return config.LDAPConfig{
+		Enabled:      true,
+		URL:          url,
+		BindDN:       "cn=svc,dc=example,dc=com",
+		BindPassword: "svcpw",
+		BaseDN:       "ou=people,dc=example,dc=com",
+		GroupRoles:   []config.GroupRole{{Group: "cn=ops,ou=groups,dc=example,dc=com", Role: model.RoleOperator}},
+	}
+}
+
+func TestLDAPAuthenticate(t *testing.T) {
+	cfg := newTestLDAPConfig(startLDAPServer(t))
+	backend := NewLDAPBackend(cfg)
+
+	identity, err := backend.Authenticate("alice", "alicepw")
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if identity.Source != model.UserSourceLDAP || identity.Username != "alice" || identity.Nickname != "Alice" || identity.Email != "alice@example.com" {
+		t.Errorf("identity = %+v", identity)
+	}
+	// 未配置 group_base_dn 时使用 memberOf
+	if len(identity.Groups) != 1 || identity.Groups[0] != "cn=ops,ou=groups,dc=example,dc=com" {
+		t.Errorf("groups = %v, want memberOf", identity.Groups)
+	}
+	if identity.Roles != model.RoleOperator || !identity.SyncRoles {
+		t.Errorf("roles = %q, sync %v, want operator", identity.Roles, identity.SyncRoles)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{name: "wrong password", username: "alice", password: "wrong"},
+		{name: "unknown user", username: "mallory", password: "alicepw"},
+		{name: "empty password", username: "alice", password: ""},
+		{name: "filter injection", username: "*", password: "alicepw"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := backend.Authenticate(tt.username, tt.password); !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("Authenticate(%q) error = %v, want ErrInvalidCredentials", tt.username, err)
+			}
+		})
+	}
+}
+
+func TestLDAPAuthenticateGroupSearch(t *testing.T) {
+	cfg := newTestLDAPConfig(startLDAPServer(t))
+	cfg.GroupBaseDN = "ou=groups,dc=example,dc=com"
+	cfg.GroupRoles = []config.GroupRole{{Group: "cn=admins,ou=groups,dc=example,dc=com", Role: model.RoleAdmin}}
+
+	identity, err := NewLDAPBackend(cfg).Authenticate("alice", "alicepw")
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if len(identity.Groups) != 1 || identity.Groups[0] != "CN=Admins,ou=groups,dc=example,dc=com" {
+		t.Errorf("groups = %v, want group search result", identity.Groups)
+	}
+	if identity.Roles != model.RoleAdmin {
+		t.Errorf("roles = %q, want admin mapped case-insensitively", identity.Roles)
+	}
+}
+
+func TestLDAPAuthenticateServiceAccountError(t *testing.T) {
+	cfg := newTestLDAPConfig(startLDAPServer(t))
+	cfg.BindPassword = "wrong"
+
+	// 服务账号错误是配置问题, 不能当作用户密码错误
+	_, err := NewLDAPBackend(cfg).Authenticate("alice", "alicepw")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) || !strings.Contains(err.Error(), "service account") {
+		t.Fatalf("Authenticate() error = %v, want service account bind error", err)
+	}
+}
+
+func TestUserServiceAuthenticateLDAP(t *testing.T) {
+	s := NewUserService()
+	cfg := newTestLDAPConfig(startLDAPServer(t))
+	cleanupUser(t, "alice")
+	cleanupUser(t, "bob")
+
+	// 首次登录按用户组映射创建用户
+	user, err := s.Authenticate("alice", "alicepw", NewLDAPBackend(cfg))
+	if err != nil {
+		t.Fatalf("Authenticate(alice) error = %v", err)
+	}
+	if user.Source != model.UserSourceLDAP || user.Roles != model.RoleOperator || user.Email != "alice@example.com" {
+		t.Errorf("provisioned user = %+v", user)
+	}
+
+	// 用户组映射变更后, 下次登录同步角色
+	cfg.GroupRoles = []config.GroupRole{{Group: "cn=ops,ou=groups,dc=example,dc=com", Role: model.RoleAdmin}}
+	if user, err = s.Authenticate("alice", "alicepw", NewLDAPBackend(cfg)); err != nil || user.Roles != model.RoleAdmin {
+		t.Fatalf("Authenticate(alice) after mapping changed = %+v, %v, want admin", user, err)
+	}
+
+	// 已存在的 LDAP 用户密码错误计入失败次数
+	if _, err := s.Authenticate("alice", "wrong", NewLDAPBackend(cfg)); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Authenticate(alice, wrong) error = %v, want ErrInvalidCredentials", err)
+	}
+	if saved, _ := s.GetUserByUsername("alice"); saved.FailedLogins != 1 {
+		t.Errorf("failed logins = %d, want 1", saved.FailedLogins)
+	}
+
+	// bob 不属于任何映射的组, 未配置默认角色时拒绝登录, 配置后使用默认角色
+	if _, err := s.Authenticate("bob", "bobpw", NewLDAPBackend(cfg)); !errors.Is(err, ErrNoRoleMapped) {
+		t.Fatalf("Authenticate(bob) error = %v, want ErrNoRoleMapped", err)
+	}
+	cfg.DefaultRole = model.RoleViewer
+	if user, err = s.Authenticate("bob", "bobpw", NewLDAPBackend(cfg)); err != nil || user.Roles != model.RoleViewer {
+		t.Fatalf("Authenticate(bob) with default role = %+v, %v, want viewer", user, err)
+	}
+}
+
+func TestUserServiceAuthenticateLDAPSourceConflict(t *testing.T) {
+	s := NewUserService()
+	cfg := newTestLDAPConfig(startLDAPServer(t))
+	cleanupUser(t, "alice")
+
+	// 同名的 OIDC 用户不交给 LDAP 认证源校验
+	if _, err := s.AuthenticateExternal(&ExternalIdentity{Source: model.UserSourceOIDC, Username: "alice", Roles: model.RoleViewer}); err != nil {
+		t.Fatalf("AuthenticateExternal() error = %v", err)
+	}
+	if _, err := s.Authenticate("alice", "alicepw", NewLDAPBackend(cfg)); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Authenticate() for oidc user error = %v, want ErrInvalidCredentials", err)
+	}
+
+	// 直接同步 LDAP 身份时返回来源冲突
+	identity, err := NewLDAPBackend(cfg).Authenticate("alice", "alicepw")
+	if err != nil {
+		t.Fatalf("LDAP Authenticate() error = %v", err)
+	}
+	if _, err := s.AuthenticateExternal(identity); !errors.Is(err, ErrUserSourceConflict) {
+		t.Fatalf("AuthenticateExternal() error = %v, want ErrUserSourceConflict", err)
+	}
+}
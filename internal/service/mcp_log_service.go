@@ -23,16 +23,17 @@ func NewMCPLogService() *MCPLogService {
 
 // MCPLogParams MCP 日志参数
 type MCPLogParams struct {
-	ServerName   string
-	ToolName     string
-	Username     string
-	Source       string // "admin_test", "dingtalk", "feishu", "wecom", "llm"
-	ChatLogID    uint   // 关联的对话记录ID（可选）
-	Request      map[string]interface{}
-	Response     interface{}
-	ErrorMessage string
-	Latency      int64 // 毫秒
-	Success      bool
+	ServerName     string
+	ToolName       string
+	Username       string
+	Source         string // "admin_test", "dingtalk", "feishu", "wecom", "llm"
+	ChatLogID      uint   // 关联的对话记录ID（可选）
+	ConversationID uint   // 关联的会话ID（可选）
+	Request        map[string]interface{}
+	Response       interface{}
+	ErrorMessage   string
+	Latency        int64 // 毫秒
+	Success        bool
 }
 
 // CreateMCPLog 创建 MCP 调用日志
@@ -49,23 +50,29 @@ func (s *MCPLogService) CreateMCPLog(params *MCPLogParams) (*model.MCPLog, error
 		responseJSON = []byte("{}")
 	}
 
+	// 请求参数和响应结果中可能包含密钥等敏感信息, 写入前按脱敏规则处理
+	request, _ := Redact(model.RedactionStageStorage, string(requestJSON))
+	response, _ := Redact(model.RedactionStageStorage, string(responseJSON))
+	errorMessage, _ := Redact(model.RedactionStageStorage, params.ErrorMessage)
+
 	status := "success"
 	if !params.Success {
 		status = "error"
 	}
 
 	log := &model.MCPLog{
-		Timestamp:    time.Now(),
-		ServerName:   params.ServerName,
-		ToolName:     params.ToolName,
-		Status:       status,
-		Latency:      params.Latency,
-		Username:     params.Username,
-		Source:       params.Source,
-		ChatLogID:    params.ChatLogID,
-		Request:      string(requestJSON),
-		Response:     string(responseJSON),
-		ErrorMessage: params.ErrorMessage,
+		Timestamp:      time.Now(),
+		ServerName:     params.ServerName,
+		ToolName:       params.ToolName,
+		Status:         status,
+		Latency:        params.Latency,
+		Username:       params.Username,
+		Source:         params.Source,
+		ChatLogID:      params.ChatLogID,
+		ConversationID: params.ConversationID,
+		Request:        request,
+		Response:       response,
+		ErrorMessage:   errorMessage,
 	}
 
 	if err := s.db.Create(log).Error; err != nil {
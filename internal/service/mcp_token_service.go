@@ -0,0 +1,216 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/database"
+	"github.com/eryajf/zenops/internal/model"
+	"gorm.io/gorm"
+)
+
+const (
+	// MCPTokenPrefix MCP 访问令牌明文的固定前缀
+	MCPTokenPrefix = "zmcp_"
+	// mcpTokenDisplayLength 列表中展示的明文长度 (含前缀)
+	mcpTokenDisplayLength = 12
+	// mcpTokenTouchInterval 最后使用时间的更新间隔, 避免每个请求都写数据库
+	mcpTokenTouchInterval = time.Minute
+	// mcpTokenCacheTTL 启用令牌缓存的有效期, 令牌变更时立即清除
+	mcpTokenCacheTTL = 30 * time.Second
+)
+
+// mcpTokenCache 按摘要缓存启用的令牌, 避免每个 MCP 请求都查询数据库
+var mcpTokenCache struct {
+	mu       sync.Mutex
+	loadedAt time.Time
+	tokens   map[string]model.MCPToken
+}
+
+// ErrInvalidMCPToken 令牌不存在、已禁用或已过期
+var ErrInvalidMCPToken = errors.New("invalid or expired mcp token")
+
+// MCPTokenService MCP 访问令牌服务
+type MCPTokenService struct {
+	db *gorm.DB
+}
+
+// NewMCPTokenService 创建 MCP 访问令牌服务实例
+func NewMCPTokenService() *MCPTokenService {
+	return &MCPTokenService{
+		db: database.GetDB(),
+	}
+}
+
+// HashMCPToken 计算令牌明文的摘要
+func HashMCPToken(token string) string {
+	return hashToken(token)
+}
+
+// generateMCPToken 生成随机令牌明文
+func generateMCPToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return MCPTokenPrefix + hex.EncodeToString(buf), nil
+}
+
+// ListTokens 列出所有令牌
+func (s *MCPTokenService) ListTokens() ([]model.MCPToken, error) {
+	var tokens []model.MCPToken
+	err := s.db.Order("id").Find(&tokens).Error
+	return tokens, err
+}
+
+// GetToken 获取指定ID的令牌, 不存在时返回 nil
+func (s *MCPTokenService) GetToken(id uint) (*model.MCPToken, error) {
+	var token model.MCPToken
+	err := s.db.First(&token, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// CreateToken 创建令牌, 返回令牌明文 (只在此时可见)
+func (s *MCPTokenService) CreateToken(token *model.MCPToken) (string, error) {
+	if err := ValidateMCPToken(token); err != nil {
+		return "", err
+	}
+
+	secret, err := generateMCPToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token.TokenHash = HashMCPToken(secret)
+	token.TokenPrefix = secret[:mcpTokenDisplayLength]
+	token.LastUsedAt = nil
+	if err := s.db.Create(token).Error; err != nil {
+		return "", err
+	}
+	invalidateMCPTokenCache()
+	return secret, nil
+}
+
+// UpdateToken 更新令牌的名称、描述、工具范围、过期时间和启用状态, 令牌明文不变
+func (s *MCPTokenService) UpdateToken(existing, token *model.MCPToken) error {
+	if err := ValidateMCPToken(token); err != nil {
+		return err
+	}
+
+	token.ID = existing.ID
+	token.TokenHash = existing.TokenHash
+	token.TokenPrefix = existing.TokenPrefix
+	token.LastUsedAt = existing.LastUsedAt
+	token.CreatedBy = existing.CreatedBy
+	token.CreatedAt = existing.CreatedAt
+	if err := s.db.Save(token).Error; err != nil {
+		return err
+	}
+	invalidateMCPTokenCache()
+	return nil
+}
+
+// DeleteToken 删除令牌
+func (s *MCPTokenService) DeleteToken(id uint) error {
+	if err := s.db.Delete(&model.MCPToken{}, id).Error; err != nil {
+		return err
+	}
+	invalidateMCPTokenCache()
+	return nil
+}
+
+// HasTokens 判断是否存在启用的令牌
+func (s *MCPTokenService) HasTokens() (bool, error) {
+	var count int64
+	err := s.db.Model(&model.MCPToken{}).Where("enabled = ?", true).Count(&count).Error
+	return count > 0, err
+}
+
+// Authenticate 校验令牌明文, 返回对应的令牌并更新最后使用时间
+// 令牌从内存缓存中查找, 禁用或删除的令牌在变更后立即失效
+func (s *MCPTokenService) Authenticate(secret string) (*model.MCPToken, error) {
+	hash := HashMCPToken(secret)
+	token, ok, err := s.cachedToken(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !ok || token.Expired(now) {
+		return nil, ErrInvalidMCPToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= mcpTokenTouchInterval {
+		if err := s.db.Model(&model.MCPToken{}).Where("id = ?", token.ID).UpdateColumn("last_used_at", now).Error; err != nil {
+			logx.Warn("Failed to update mcp token last used time, token %s, error %v", token.Name, err)
+		}
+		token.LastUsedAt = &now
+		touchCachedMCPToken(hash, now)
+	}
+	return &token, nil
+}
+
+// cachedToken 从缓存中查找启用的令牌, 缓存过期时从数据库重新加载
+func (s *MCPTokenService) cachedToken(hash string) (model.MCPToken, bool, error) {
+	mcpTokenCache.mu.Lock()
+	defer mcpTokenCache.mu.Unlock()
+
+	if mcpTokenCache.tokens == nil || time.Since(mcpTokenCache.loadedAt) >= mcpTokenCacheTTL {
+		var tokens []model.MCPToken
+		if err := s.db.Where("enabled = ?", true).Find(&tokens).Error; err != nil {
+			return model.MCPToken{}, false, err
+		}
+		mcpTokenCache.tokens = make(map[string]model.MCPToken, len(tokens))
+		for _, token := range tokens {
+			mcpTokenCache.tokens[token.TokenHash] = token
+		}
+		mcpTokenCache.loadedAt = time.Now()
+	}
+
+	token, ok := mcpTokenCache.tokens[hash]
+	return token, ok, nil
+}
+
+// touchCachedMCPToken 更新缓存中令牌的最后使用时间
+func touchCachedMCPToken(hash string, usedAt time.Time) {
+	mcpTokenCache.mu.Lock()
+	defer mcpTokenCache.mu.Unlock()
+	if token, ok := mcpTokenCache.tokens[hash]; ok {
+		token.LastUsedAt = &usedAt
+		mcpTokenCache.tokens[hash] = token
+	}
+}
+
+// invalidateMCPTokenCache 清除令牌缓存, 下次认证时从数据库重新加载
+func invalidateMCPTokenCache() {
+	mcpTokenCache.mu.Lock()
+	defer mcpTokenCache.mu.Unlock()
+	mcpTokenCache.tokens = nil
+}
+
+// ValidateMCPToken 校验令牌配置, 工具范围至少包含一项, 允许全部工具时使用 "*"
+func ValidateMCPToken(token *model.MCPToken) error {
+	if token.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(token.Tools) == 0 {
+		return fmt.Errorf("tools is required, use \"*\" to allow all tools")
+	}
+	for _, pattern := range token.Tools {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
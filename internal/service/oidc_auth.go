@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/model"
+	"golang.org/x/oauth2"
+)
+
+// oidcStateTTL 发起授权到回调之间允许的最长时间
+const oidcStateTTL = 10 * time.Minute
+
+// ErrInvalidOIDCState 回调的 state 不存在、已使用或已过期
+var ErrInvalidOIDCState = errors.New("invalid or expired oidc state")
+
+// oidcAuthRequest 发起授权时生成的 PKCE verifier 和 nonce, 回调时按 state 取出
+type oidcAuthRequest struct {
+	verifier  string
+	nonce     string
+	expiresAt time.Time
+}
+
+// oidcAuthRequests 等待回调的授权请求, 每个 state 只能使用一次
+var oidcAuthRequests struct {
+	mu      sync.Mutex
+	pending map[string]oidcAuthRequest
+}
+
+// oidcProviders 缓存各 issuer 的发现文档和公钥
+var oidcProviders struct {
+	mu        sync.Mutex
+	providers map[string]*oidc.Provider
+}
+
+// OIDCBackend OIDC 认证源, 使用授权码模式 + PKCE
+type OIDCBackend struct {
+	config config.OIDCConfig
+}
+
+// NewOIDCBackend 创建 OIDC 认证源, 未配置的 scope 和声明名称使用默认值
+func NewOIDCBackend(cfg config.OIDCConfig) *OIDCBackend {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = "preferred_username"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	return &OIDCBackend{config: cfg}
+}
+
+// AuthCodeURL 生成跳转到认证服务的授权地址
+func (b *OIDCBackend) AuthCodeURL(ctx context.Context) (string, error) {
+	oauth2Config, _, err := b.oauth2Config(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+	saveOIDCAuthRequest(state, oidcAuthRequest{
+		verifier:  verifier,
+		nonce:     nonce,
+		expiresAt: time.Now().Add(oidcStateTTL),
+	})
+
+	return oauth2Config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier), oidc.Nonce(nonce)), nil
+}
+
+// Exchange 使用回调中的授权码换取并校验 ID Token, 返回用户身份
+func (b *OIDCBackend) Exchange(ctx context.Context, code, state string) (*ExternalIdentity, error) {
+	request, ok := takeOIDCAuthRequest(state)
+	if !ok {
+		return nil, ErrInvalidOIDCState
+	}
+
+	oauth2Config, provider, err := b.oauth2Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := oauth2Config.Exchange(ctx, code, oauth2.VerifierOption(request.verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("id_token is missing in token response")
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: b.config.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify id_token: %w", err)
+	}
+	if idToken.Nonce != request.nonce {
+		return nil, fmt.Errorf("id_token nonce mismatch")
+	}
+
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to parse id_token claims: %w", err)
+	}
+
+	identity := &ExternalIdentity{
+		Source:   model.UserSourceOIDC,
+		Username: claimString(claims, b.config.UsernameClaim),
+		Nickname: claimString(claims, "name"),
+		Email:    claimString(claims, "email"),
+		Groups:   claimStrings(claims, b.config.GroupsClaim),
+	}
+	if identity.Username == "" {
+		return nil, fmt.Errorf("claim %s is missing in id_token", b.config.UsernameClaim)
+	}
+	mapGroupRoles(identity, b.config.GroupRoles, b.config.DefaultRole)
+	return identity, nil
+}
+
+// oauth2Config 根据 issuer 的发现文档生成 OAuth2 客户端配置
+func (b *OIDCBackend) oauth2Config(ctx context.Context) (*oauth2.Config, *oidc.Provider, error) {
+	if b.config.Issuer == "" || b.config.ClientID == "" || b.config.RedirectURL == "" {
+		return nil, nil, fmt.Errorf("oidc issuer, client_id and redirect_url are required")
+	}
+	provider, err := oidcProvider(ctx, b.config.Issuer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &oauth2.Config{
+		ClientID:     b.config.ClientID,
+		ClientSecret: b.config.ClientSecret,
+		RedirectURL:  b.config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       b.config.Scopes,
+	}, provider, nil
+}
+
+// oidcProvider 获取 issuer 对应的 Provider, 首次使用时请求发现文档
+func oidcProvider(ctx context.Context, issuer string) (*oidc.Provider, error) {
+	oidcProviders.mu.Lock()
+	defer oidcProviders.mu.Unlock()
+	if provider, ok := oidcProviders.providers[issuer]; ok {
+		return provider, nil
+	}
+
+	// Provider 会在之后的请求中按需刷新公钥, 不能绑定到单个请求的 ctx
+	provider, err := oidc.NewProvider(context.WithoutCancel(ctx), issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover oidc issuer %s: %w", issuer, err)
+	}
+	if oidcProviders.providers == nil {
+		oidcProviders.providers = make(map[string]*oidc.Provider)
+	}
+	oidcProviders.providers[issuer] = provider
+	return provider, nil
+}
+
+// saveOIDCAuthRequest 保存授权请求, 顺便清理已过期的请求
+func saveOIDCAuthRequest(state string, request oidcAuthRequest) {
+	oidcAuthRequests.mu.Lock()
+	defer oidcAuthRequests.mu.Unlock()
+	if oidcAuthRequests.pending == nil {
+		oidcAuthRequests.pending = make(map[string]oidcAuthRequest)
+	}
+	now := time.Now()
+	for key, pending := range oidcAuthRequests.pending {
+		if now.After(pending.expiresAt) {
+			delete(oidcAuthRequests.pending, key)
+		}
+	}
+	oidcAuthRequests.pending[state] = request
+}
+
+// takeOIDCAuthRequest 取出并删除 state 对应的授权请求
+func takeOIDCAuthRequest(state string) (oidcAuthRequest, bool) {
+	oidcAuthRequests.mu.Lock()
+	defer oidcAuthRequests.mu.Unlock()
+	request, ok := oidcAuthRequests.pending[state]
+	if !ok {
+		return oidcAuthRequest{}, false
+	}
+	delete(oidcAuthRequests.pending, state)
+	return request, time.Now().Before(request.expiresAt)
+}
+
+// claimString 读取字符串类型的声明
+func claimString(claims map[string]any, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// claimStrings 读取字符串数组类型的声明, 单个字符串视为只有一个元素
+func claimStrings(claims map[string]any, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
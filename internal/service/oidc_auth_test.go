@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eryajf/zenops/internal/config"
+	"github.com/eryajf/zenops/internal/model"
+)
+
+// fakeOIDCIssuer 模拟 OIDC 认证服务: 发现文档、JWKS 和授权码换取 Token, 校验 PKCE
+type fakeOIDCIssuer struct {
+	*httptest.Server
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	codes map[string]fakeOIDCCode
+}
+
+// fakeOIDCCode 签发授权码时记录的 code_challenge 和要放入 ID Token 的声明
+type fakeOIDCCode struct {
+	challenge string
+	claims    map[string]any
+}
+
+func newFakeOIDCIssuer(t *testing.T) *fakeOIDCIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key error = %v", err)
+	}
+	f := &fakeOIDCIssuer{key: key, codes: make(map[string]fakeOIDCCode)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"issuer":                                f.URL,
+			"authorization_endpoint":                f.URL + "/authorize",
+			"token_endpoint":                        f.URL + "/token",
+			"jwks_uri":                              f.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]any{{
+			"kty": "RSA",
+			"kid": "test",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		f.mu.Lock()
+		code, ok := f.codes[r.PostForm.Get("code")]
+		f.mu.Unlock()
+
+		if r.PostForm.Get("grant_type") != "authorization_code" || !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_grant"})
+			return
+		}
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != code.challenge {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+			return
+		}
+		// 换取成功后授权码失效; 失败时保留, oauth2 客户端会换一种客户端认证方式重试
+		f.mu.Lock()
+		delete(f.codes, r.PostForm.Get("code"))
+		f.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]any{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     f.signIDToken(t, code.claims),
+		})
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+// authorize 模拟用户在认证服务登录后回调: 按授权地址签发授权码, 返回 code 和 state
+// claims 中未设置的 iss、aud、nonce 等从授权地址中补全
+func (f *fakeOIDCIssuer) authorize(t *testing.T, authURL string, claims map[string]any) (string, string) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth url error = %v", err)
+	}
+	query := u.Query()
+
+	now := time.Now()
+	defaults := map[string]any{
+		"iss":   f.URL,
+		"aud":   query.Get("client_id"),
+		"sub":   "user-1",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": query.Get("nonce"),
+	}
+	for key, value := range claims {
+		defaults[key] = value
+	}
+
+	code := "code-" + query.Get("state")
+	f.mu.Lock()
+	f.codes[code] = fakeOIDCCode{challenge: query.Get("code_challenge"), claims: defaults}
+	f.mu.Unlock()
+	return code, query.Get("state")
+}
+
+// signIDToken 使用 RS256 签发 ID Token
+func (f *fakeOIDCIssuer) signIDToken(t *testing.T, claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "test", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, f.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Errorf("sign id_token error = %v", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// newTestOIDCBackend 创建指向模拟认证服务的 OIDC 认证源, ops 组映射为 operator
+func newTestOIDCBackend(issuer string) *OIDCBackend {
+	return NewOIDCBackend(config.OIDCConfig{
+		Enabled:      true,
+		Issuer:       issuer,
+		ClientID:     "zenops",
+		ClientSecret: "secret",
+		RedirectURL:  "https://zenops.example.com/oidc/callback",
+		GroupRoles:   []config.GroupRole{{Group: "ops", Role: model.RoleOperator}},
+	})
+}
+
+func TestOIDCAuthCodeURL(t *testing.T) {
+	issuer := newFakeOIDCIssuer(t)
+
+	authURL, err := newTestOIDCBackend(issuer.URL).AuthCodeURL(context.Background())
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth url error = %v", err)
+	}
+	query := u.Query()
+
+	if u.Path != "/authorize" || query.Get("client_id") != "zenops" || query.Get("response_type") != "code" ||
+		query.Get("redirect_uri") != "https://zenops.example.com/oidc/callback" {
+		t.Errorf("auth url = %s", authURL)
+	}
+	if query.Get("scope") != "openid profile email" {
+		t.Errorf("scope = %q, want default scopes", query.Get("scope"))
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Errorf("auth url without S256 PKCE challenge: %s", authURL)
+	}
+	if query.Get("state") == "" || query.Get("nonce") == "" || query.Get("state") == query.Get("nonce") {
+		t.Errorf("state = %q, nonce = %q, want distinct random values", query.Get("state"), query.Get("nonce"))
+	}
+}
+
+func TestOIDCExchange(t *testing.T) {
+	issuer := newFakeOIDCIssuer(t)
+	backend := newTestOIDCBackend(issuer.URL)
+	ctx := context.Background()
+
+	authURL, err := backend.AuthCodeURL(ctx)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	code, state := issuer.authorize(t, authURL, map[string]any{
+		"preferred_username": "frank",
+		"name":               "Frank",
+		"email":              "frank@example.com",
+		"groups":             []string{"dev", "OPS"},
+	})
+
+	identity, err := backend.Exchange(ctx, code, state)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if identity.Source != model.UserSourceOIDC || identity.Username != "frank" || identity.Nickname != "Frank" || identity.Email != "frank@example.com" {
+		t.Errorf("identity = %+v", identity)
+	}
+	if len(identity.Groups) != 2 || identity.Roles != model.RoleOperator || !identity.SyncRoles {
+		t.Errorf("groups = %v, roles = %q, sync %v, want operator from OPS", identity.Groups, identity.Roles, identity.SyncRoles)
+	}
+
+	// state 只能使用一次
+	if _, err := backend.Exchange(ctx, code, state); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("Exchange() with used state error = %v, want ErrInvalidOIDCState", err)
+	}
+}
+
+func TestOIDCExchangeRejects(t *testing.T) {
+	issuer := newFakeOIDCIssuer(t)
+	backend := newTestOIDCBackend(issuer.URL)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// prepare 发起授权并返回回调参数
+		prepare func(t *testing.T) (string, string)
+		wantErr error
+		want    string
+	}{
+		{
+			name: "unknown state",
+			prepare: func(t *testing.T) (string, string) {
+				return "code", "unknown-state"
+			},
+			wantErr: ErrInvalidOIDCState,
+		},
+		{
+			name: "expired state",
+			prepare: func(t *testing.T) (string, string) {
+				saveOIDCAuthRequest("expired-state", oidcAuthRequest{verifier: "v", nonce: "n", expiresAt: time.Now().Add(-time.Second)})
+				return "code", "expired-state"
+			},
+			wantErr: ErrInvalidOIDCState,
+		},
+		{
+			name: "pkce verifier mismatch",
+			prepare: func(t *testing.T) (string, string) {
+				authURL, _ := backend.AuthCodeURL(ctx)
+				// 授权码绑定的是另一次授权的 code_challenge
+				otherURL, _ := backend.AuthCodeURL(ctx)
+				code, _ := issuer.authorize(t, otherURL, map[string]any{"preferred_username": "frank"})
+				_, state := issuer.authorize(t, authURL, map[string]any{"preferred_username": "frank"})
+				return code, state
+			},
+			want: "PKCE verification failed",
+		},
+		{
+			name: "nonce mismatch",
+			prepare: func(t *testing.T) (string, string) {
+				authURL, _ := backend.AuthCodeURL(ctx)
+				return issuer.authorize(t, authURL, map[string]any{"preferred_username": "frank", "nonce": "replayed"})
+			},
+			want: "nonce mismatch",
+		},
+		{
+			name: "wrong audience",
+			prepare: func(t *testing.T) (string, string) {
+				authURL, _ := backend.AuthCodeURL(ctx)
+				return issuer.authorize(t, authURL, map[string]any{"preferred_username": "frank", "aud": "other-client"})
+			},
+			want: "failed to verify id_token",
+		},
+		{
+			name: "missing username claim",
+			prepare: func(t *testing.T) (string, string) {
+				authURL, _ := backend.AuthCodeURL(ctx)
+				return issuer.authorize(t, authURL, map[string]any{"email": "frank@example.com"})
+			},
+			want: "claim preferred_username is missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, state := tt.prepare(t)
+			_, err := backend.Exchange(ctx, code, state)
+			if err == nil {
+				t.Fatalf("Exchange() error = nil, want %v%s", tt.wantErr, tt.want)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Exchange() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Exchange() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOIDCLoginProvisionsUser(t *testing.T) {
+	issuer := newFakeOIDCIssuer(t)
+	backend := newTestOIDCBackend(issuer.URL)
+	s := NewUserService()
+	ctx := context.Background()
+	cleanupUser(t, "grace")
+
+	login := func(groups []string) (*model.User, error) {
+		authURL, err := backend.AuthCodeURL(ctx)
+		if err != nil {
+			t.Fatalf("AuthCodeURL() error = %v", err)
+		}
+		code, state := issuer.authorize(t, authURL, map[string]any{"preferred_username": "grace", "groups": groups})
+		identity, err := backend.Exchange(ctx, code, state)
+		if err != nil {
+			t.Fatalf("Exchange() error = %v", err)
+		}
+		return s.AuthenticateExternal(identity)
+	}
+
+	// 不属于映射的组且未配置默认角色, 拒绝首次登录
+	if _, err := login([]string{"dev"}); !errors.Is(err, ErrNoRoleMapped) {
+		t.Fatalf("login without mapped group error = %v, want ErrNoRoleMapped", err)
+	}
+
+	user, err := login([]string{"ops"})
+	if err != nil {
+		t.Fatalf("login error = %v", err)
+	}
+	if user.Source != model.UserSourceOIDC || user.Roles != model.RoleOperator {
+		t.Errorf("provisioned user = source %q, roles %q, want oidc operator", user.Source, user.Roles)
+	}
+
+	// 移出映射的组后, 已存在的用户也不能再登录
+	if _, err := login([]string{"dev"}); !errors.Is(err, ErrNoRoleMapped) {
+		t.Fatalf("login after leaving mapped group error = %v, want ErrNoRoleMapped", err)
+	}
+}
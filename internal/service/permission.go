@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/eryajf/zenops/internal/model"
+)
+
+// 受权限控制的资源, 与 /api/v1 下的路由组对应
+const (
+	ResourceCloud         = "cloud"         // /aliyun /tencent /aws
+	ResourceKubernetes    = "kubernetes"    // /k8s
+	ResourceCICD          = "cicd"          // /jenkins /github
+	ResourceMCP           = "mcp"           // /mcp
+	ResourceDashboard     = "dashboard"     // /dashboard
+	ResourceLogs          = "logs"          // /logs
+	ResourceUsage         = "usage"         // /usage
+	ResourceRedaction     = "redaction"     // /redaction
+	ResourceHistory       = "history"       // /history
+	ResourceConversations = "conversations" // /conversations
+	ResourceConfig        = "config"        // /config
+	ResourceChat          = "chat"          // /chat /models
+	ResourcePrompts       = "prompts"       // /prompts
+	ResourceServices      = "services"      // /services
+	ResourceUsers         = "users"         // /users
+	ResourceAPIKeys       = "api_keys"      // /api-keys
+)
+
+// 操作类型, GET/HEAD 请求为 read, 其余为 write
+const (
+	ActionRead  = "read"
+	ActionWrite = "write"
+)
+
+// rolePermissions 角色拥有的权限, 格式为 resource:action, * 为通配符
+var rolePermissions = map[string][]string{
+	model.RoleAdmin: {"*"},
+	model.RoleOperator: {
+		"*:read",
+		"cicd:write",
+		"conversations:write",
+		"chat:write",
+	},
+	model.RoleViewer: {
+		"cloud:read",
+		"kubernetes:read",
+		"cicd:read",
+		"dashboard:read",
+		"conversations:read",
+		"conversations:write",
+		"chat:read",
+		"chat:write",
+	},
+}
+
+// roleList 解析逗号分隔的角色列表
+func roleList(roles string) []string {
+	var result []string
+	for _, role := range strings.Split(roles, ",") {
+		role = strings.TrimSpace(role)
+		if role == model.RoleUser {
+			role = model.RoleViewer
+		}
+		if role != "" {
+			result = append(result, role)
+		}
+	}
+	return result
+}
+
+// ScopesAllow 判断权限列表是否包含资源的指定操作, 格式同 rolePermissions
+func ScopesAllow(scopes []string, resource, action string) bool {
+	for _, scope := range scopes {
+		if scope == "*" || scope == "*:"+action || scope == resource+":*" || scope == resource+":"+action {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission 判断角色列表是否拥有资源的指定操作权限
+func HasPermission(roles, resource, action string) bool {
+	for _, role := range roleList(roles) {
+		if ScopesAllow(rolePermissions[role], resource, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole 判断角色列表是否包含指定角色
+func HasRole(roles, role string) bool {
+	return slices.Contains(roleList(roles), role)
+}
+
+// Permissions 调用方的角色和 API Key 权限范围, 由各接入渠道写入上下文, 用于校验对话中的写操作工具
+type Permissions struct {
+	Roles          string   // 用户角色, 服务账号和共享 API Key 为空
+	Scopes         []string // zk_ API Key 或共享 API Key 的权限范围, 其余为 nil
+	ServiceAccount bool     // 服务账号和共享 API Key 的权限只由 Scopes 决定
+	IMChannel      bool     // IM 渠道的对话, 可用范围由渠道自身的配置控制, 不按角色校验
+}
+
+// WithPermissions 在上下文中记录调用方的权限
+func WithPermissions(ctx context.Context, permissions Permissions) context.Context {
+	return context.WithValue(ctx, permissionsContextKey{}, permissions)
+}
+
+// WithIMChannel 标记本次调用来自 IM 渠道 (钉钉、飞书、企业微信), 写操作工具不按角色校验
+func WithIMChannel(ctx context.Context) context.Context {
+	return WithPermissions(ctx, Permissions{IMChannel: true})
+}
+
+// PermissionsFromContext 从上下文中获取调用方的权限, 未记录时返回 false
+func PermissionsFromContext(ctx context.Context) (Permissions, bool) {
+	permissions, ok := ctx.Value(permissionsContextKey{}).(Permissions)
+	return permissions, ok
+}
+
+// PermissionsAllow 判断调用方权限是否允许资源的指定操作
+// 使用 API Key 时需 Key 的权限范围允许, 服务账号只看权限范围, 其余还需角色拥有该权限
+func PermissionsAllow(permissions Permissions, resource, action string) bool {
+	if permissions.IMChannel {
+		return true
+	}
+	if permissions.Scopes != nil {
+		if !ScopesAllow(permissions.Scopes, resource, action) {
+			return false
+		}
+		if permissions.ServiceAccount {
+			return true
+		}
+	}
+	return HasPermission(permissions.Roles, resource, action)
+}
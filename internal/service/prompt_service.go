@@ -0,0 +1,167 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/eryajf/zenops/internal/database"
+	"github.com/eryajf/zenops/internal/model"
+)
+
+// PromptService 系统提示词配置服务
+type PromptService struct {
+	db *gorm.DB
+}
+
+// NewPromptService 创建提示词服务实例
+func NewPromptService() *PromptService {
+	return &PromptService{
+		db: database.GetDB(),
+	}
+}
+
+// ========== 提示词配置管理 ==========
+
+// ListProfiles 列出所有提示词配置
+func (s *PromptService) ListProfiles() ([]model.PromptProfile, error) {
+	var profiles []model.PromptProfile
+	err := s.db.Order("id").Find(&profiles).Error
+	return profiles, err
+}
+
+// GetProfile 获取指定ID的提示词配置, 不存在时返回 nil
+func (s *PromptService) GetProfile(id uint) (*model.PromptProfile, error) {
+	var profile model.PromptProfile
+	err := s.db.First(&profile, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// CreateProfile 创建提示词配置
+func (s *PromptService) CreateProfile(profile *model.PromptProfile) error {
+	return s.db.Create(profile).Error
+}
+
+// UpdateProfile 更新提示词配置
+func (s *PromptService) UpdateProfile(profile *model.PromptProfile) error {
+	return s.db.Save(profile).Error
+}
+
+// DeleteProfile 删除提示词配置及其绑定
+func (s *PromptService) DeleteProfile(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("profile_id = ?", id).Delete(&model.PromptBinding{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.PromptProfile{}, id).Error
+	})
+}
+
+// ========== 提示词绑定管理 ==========
+
+// ListBindings 列出提示词绑定, profileID 为 0 时列出全部
+func (s *PromptService) ListBindings(profileID uint) ([]model.PromptBinding, error) {
+	var bindings []model.PromptBinding
+	query := s.db.Order("scope, target")
+	if profileID > 0 {
+		query = query.Where("profile_id = ?", profileID)
+	}
+	err := query.Find(&bindings).Error
+	return bindings, err
+}
+
+// SaveBinding 保存提示词绑定, 同一范围和目标已存在绑定时改为绑定到新的配置
+func (s *PromptService) SaveBinding(binding *model.PromptBinding) error {
+	switch binding.Scope {
+	case model.PromptScopePlatform, model.PromptScopeGroup, model.PromptScopeUser:
+	default:
+		return fmt.Errorf("invalid scope %q, must be one of platform, group, user", binding.Scope)
+	}
+	if binding.Target == "" {
+		return fmt.Errorf("target is required")
+	}
+	if binding.Scope == model.PromptScopeUser {
+		// 不同来源可能有同名用户, 用户绑定需指定来源
+		if source, username, ok := strings.Cut(binding.Target, ":"); !ok || source == "" || username == "" {
+			return fmt.Errorf("invalid user target %q, must be source:username, e.g. dingtalk:alice", binding.Target)
+		}
+	}
+
+	profile, err := s.GetProfile(binding.ProfileID)
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		return fmt.Errorf("prompt profile %d not found", binding.ProfileID)
+	}
+
+	var existing model.PromptBinding
+	err = s.db.Where("scope = ? AND target = ?", binding.Scope, binding.Target).First(&existing).Error
+	if err == nil {
+		binding.ID = existing.ID
+		binding.CreatedAt = existing.CreatedAt
+		return s.db.Save(binding).Error
+	}
+	if err == gorm.ErrRecordNotFound {
+		return s.db.Create(binding).Error
+	}
+	return err
+}
+
+// DeleteBinding 删除提示词绑定
+func (s *PromptService) DeleteBinding(id uint) error {
+	return s.db.Delete(&model.PromptBinding{}, id).Error
+}
+
+// userBindingTarget 返回用户绑定的目标 source:username, 来源或用户名为空时返回空
+func userBindingTarget(source, username string) string {
+	if source == "" || username == "" {
+		return ""
+	}
+	return source + ":" + username
+}
+
+// ResolveProfile 按 用户 > 群聊 > 平台 的优先级查找生效的提示词配置
+// 未绑定或绑定的配置已禁用时继续查找下一优先级, 均未匹配时返回 nil
+func (s *PromptService) ResolveProfile(source, groupID, username string) (*model.PromptProfile, error) {
+	candidates := []struct {
+		scope  string
+		target string
+	}{
+		{model.PromptScopeUser, userBindingTarget(source, username)},
+		{model.PromptScopeGroup, groupID},
+		{model.PromptScopePlatform, source},
+	}
+
+	for _, candidate := range candidates {
+		if candidate.target == "" {
+			continue
+		}
+
+		var binding model.PromptBinding
+		err := s.db.Where("scope = ? AND target = ?", candidate.scope, candidate.target).First(&binding).Error
+		if err == gorm.ErrRecordNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		profile, err := s.GetProfile(binding.ProfileID)
+		if err != nil {
+			return nil, err
+		}
+		if profile != nil && profile.Enabled {
+			return profile, nil
+		}
+	}
+
+	return nil, nil
+}
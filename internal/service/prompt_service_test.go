@@ -0,0 +1,90 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/eryajf/zenops/internal/model"
+)
+
+// createPromptProfile 创建提示词配置, 测试结束后删除其配置和绑定
+func createPromptProfile(t *testing.T, s *PromptService, name string) *model.PromptProfile {
+	t.Helper()
+	profile := &model.PromptProfile{Name: name, Content: name, Enabled: true}
+	if err := s.CreateProfile(profile); err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+	t.Cleanup(func() { s.DeleteProfile(profile.ID) })
+	return profile
+}
+
+func TestSaveBindingUserTarget(t *testing.T) {
+	s := NewPromptService()
+	profile := createPromptProfile(t, s, "user-target")
+
+	tests := []struct {
+		name    string
+		target  string
+		wantErr bool
+	}{
+		{name: "source and username", target: "dingtalk:alice"},
+		{name: "username only", target: "alice", wantErr: true},
+		{name: "empty source", target: ":alice", wantErr: true},
+		{name: "empty username", target: "feishu:", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.SaveBinding(&model.PromptBinding{ProfileID: profile.ID, Scope: model.PromptScopeUser, Target: tt.target})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SaveBinding(%q) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveProfile(t *testing.T) {
+	s := NewPromptService()
+	dingtalkUser := createPromptProfile(t, s, "dingtalk-alice")
+	group := createPromptProfile(t, s, "ops-group")
+	platform := createPromptProfile(t, s, "api-platform")
+
+	bindings := []model.PromptBinding{
+		{ProfileID: dingtalkUser.ID, Scope: model.PromptScopeUser, Target: "dingtalk:alice"},
+		{ProfileID: group.ID, Scope: model.PromptScopeGroup, Target: "cid-ops"},
+		{ProfileID: platform.ID, Scope: model.PromptScopePlatform, Target: "api"},
+	}
+	for i := range bindings {
+		if err := s.SaveBinding(&bindings[i]); err != nil {
+			t.Fatalf("SaveBinding() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		source   string
+		groupID  string
+		username string
+		want     string
+	}{
+		{name: "user binding of the same source", source: "dingtalk", groupID: "cid-ops", username: "alice", want: "dingtalk-alice"},
+		{name: "same username from another source", source: "api", username: "alice", want: "api-platform"},
+		{name: "group binding", source: "feishu", groupID: "cid-ops", username: "alice", want: "ops-group"},
+		{name: "nothing matches", source: "wecom", username: "bob"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := s.ResolveProfile(tt.source, tt.groupID, tt.username)
+			if err != nil {
+				t.Fatalf("ResolveProfile() error = %v", err)
+			}
+			got := ""
+			if profile != nil {
+				got = profile.Name
+			}
+			if got != tt.want {
+				t.Errorf("ResolveProfile(%q, %q, %q) = %q, want %q", tt.source, tt.groupID, tt.username, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/database"
+	"github.com/eryajf/zenops/internal/model"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultRedactionReplacement 规则未设置替换内容时使用的占位符
+	defaultRedactionReplacement = "[REDACTED]"
+	// redactionHitsFlushInterval 替换次数在内存中累计的时间, 到期后批量写入数据库
+	redactionHitsFlushInterval = 10 * time.Second
+)
+
+// Redactor 脱敏器, 返回脱敏后的文本和替换次数
+// 除数据库中的正则规则外, 其他包可通过 RegisterRedactor 注册自定义脱敏器
+type Redactor interface {
+	Name() string
+	Redact(text string) (string, int)
+}
+
+// regexRedactor 基于正则表达式的脱敏器
+type regexRedactor struct {
+	id          uint
+	name        string
+	stage       string
+	re          *regexp.Regexp
+	replacement string
+}
+
+// Name 返回规则名称
+func (r *regexRedactor) Name() string {
+	return r.name
+}
+
+// Redact 替换所有匹配的内容
+func (r *regexRedactor) Redact(text string) (string, int) {
+	matches := len(r.re.FindAllStringIndex(text, -1))
+	if matches == 0 {
+		return text, 0
+	}
+	return r.re.ReplaceAllString(text, r.replacement), matches
+}
+
+// registeredRedactor 通过 RegisterRedactor 注册的脱敏器
+type registeredRedactor struct {
+	stage    string
+	redactor Redactor
+}
+
+// redactionPipeline 缓存已编译的脱敏规则, 规则变更后重新加载
+var redactionPipeline struct {
+	mu         sync.RWMutex
+	loaded     bool
+	rules      []*regexRedactor
+	registered []registeredRedactor
+}
+
+// RegisterRedactor 注册自定义脱敏器, stage 取值同 RedactionRule.Stage
+func RegisterRedactor(stage string, redactor Redactor) {
+	redactionPipeline.mu.Lock()
+	defer redactionPipeline.mu.Unlock()
+	redactionPipeline.registered = append(redactionPipeline.registered, registeredRedactor{stage: stage, redactor: redactor})
+}
+
+// InvalidateRedactionRules 清除已缓存的规则, 下次脱敏时从数据库重新加载
+func InvalidateRedactionRules() {
+	redactionPipeline.mu.Lock()
+	defer redactionPipeline.mu.Unlock()
+	redactionPipeline.loaded = false
+	redactionPipeline.rules = nil
+}
+
+// compileRedactionRule 编译脱敏规则
+func compileRedactionRule(rule *model.RedactionRule) (*regexRedactor, error) {
+	re, err := regexp.Compile(rule.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern for rule %s: %w", rule.Name, err)
+	}
+	replacement := rule.Replacement
+	if replacement == "" {
+		replacement = defaultRedactionReplacement
+	}
+	return &regexRedactor{
+		id:          rule.ID,
+		name:        rule.Name,
+		stage:       rule.Stage,
+		re:          re,
+		replacement: replacement,
+	}, nil
+}
+
+// loadRedactionRules 加载并编译启用的脱敏规则, 已加载时直接返回缓存
+func loadRedactionRules() ([]*regexRedactor, []registeredRedactor) {
+	redactionPipeline.mu.RLock()
+	if redactionPipeline.loaded {
+		defer redactionPipeline.mu.RUnlock()
+		return redactionPipeline.rules, redactionPipeline.registered
+	}
+	redactionPipeline.mu.RUnlock()
+
+	redactionPipeline.mu.Lock()
+	defer redactionPipeline.mu.Unlock()
+	if redactionPipeline.loaded {
+		return redactionPipeline.rules, redactionPipeline.registered
+	}
+
+	var rules []model.RedactionRule
+	if err := database.GetDB().Where("enabled = ?", true).Order("id").Find(&rules).Error; err != nil {
+		// 加载失败时不缓存, 下次调用重试
+		logx.Warn("Failed to load redaction rules: %v", err)
+		return nil, redactionPipeline.registered
+	}
+
+	compiled := make([]*regexRedactor, 0, len(rules))
+	for i := range rules {
+		redactor, err := compileRedactionRule(&rules[i])
+		if err != nil {
+			logx.Warn("Skipping redaction rule: %v", err)
+			continue
+		}
+		compiled = append(compiled, redactor)
+	}
+
+	redactionPipeline.rules = compiled
+	redactionPipeline.loaded = true
+	return compiled, redactionPipeline.registered
+}
+
+// stageMatches 判断规则的生效阶段是否包含当前阶段
+func stageMatches(ruleStage, stage string) bool {
+	return ruleStage == model.RedactionStageBoth || ruleStage == stage
+}
+
+// Redact 对文本执行指定阶段的脱敏, 返回脱敏后的文本和替换次数
+// stage 为 model.RedactionStageLLM 或 model.RedactionStageStorage, 各规则的替换次数异步累计到数据库
+func Redact(stage, text string) (string, int) {
+	if text == "" {
+		return text, 0
+	}
+
+	rules, registered := loadRedactionRules()
+	total := 0
+	hits := make(map[uint]int)
+	for _, rule := range rules {
+		if !stageMatches(rule.stage, stage) {
+			continue
+		}
+		var n int
+		text, n = rule.Redact(text)
+		if n > 0 {
+			hits[rule.id] += n
+			total += n
+		}
+	}
+	for _, r := range registered {
+		if !stageMatches(r.stage, stage) {
+			continue
+		}
+		var n int
+		text, n = r.redactor.Redact(text)
+		total += n
+	}
+
+	if total > 0 {
+		logx.Info("Redacted %d sensitive values before %s", total, stage)
+		recordRedactionHits(hits)
+	}
+	return text, total
+}
+
+// redactionHits 尚未写入数据库的各规则替换次数
+// 每个工具结果、对话记录和 MCP 日志都会脱敏, 替换次数先在内存中累计, 避免每次脱敏都更新数据库
+var redactionHits struct {
+	mu      sync.Mutex
+	pending map[uint]int
+	timer   *time.Timer
+}
+
+// recordRedactionHits 累计各规则的替换次数, redactionHitsFlushInterval 后批量写入数据库
+func recordRedactionHits(hits map[uint]int) {
+	redactionHits.mu.Lock()
+	defer redactionHits.mu.Unlock()
+	if redactionHits.pending == nil {
+		redactionHits.pending = make(map[uint]int)
+	}
+	for id, n := range hits {
+		redactionHits.pending[id] += n
+	}
+	if redactionHits.timer == nil {
+		redactionHits.timer = time.AfterFunc(redactionHitsFlushInterval, FlushRedactionHits)
+	}
+}
+
+// FlushRedactionHits 将内存中累计的替换次数写入数据库, 服务退出前调用以免丢失
+func FlushRedactionHits() {
+	redactionHits.mu.Lock()
+	pending := redactionHits.pending
+	redactionHits.pending = nil
+	if redactionHits.timer != nil {
+		redactionHits.timer.Stop()
+		redactionHits.timer = nil
+	}
+	redactionHits.mu.Unlock()
+
+	db := database.GetDB()
+	for id, n := range pending {
+		err := db.Model(&model.RedactionRule{}).Where("id = ?", id).
+			UpdateColumn("hit_count", gorm.Expr("hit_count + ?", n)).Error
+		if err != nil {
+			logx.Warn("Failed to record redaction hits, rule %d, error %v", id, err)
+		}
+	}
+}
+
+// RedactionService 脱敏规则管理服务
+type RedactionService struct {
+	db *gorm.DB
+}
+
+// NewRedactionService 创建脱敏规则服务实例
+func NewRedactionService() *RedactionService {
+	return &RedactionService{
+		db: database.GetDB(),
+	}
+}
+
+// ListRules 列出所有脱敏规则, 先写入内存中累计的替换次数
+func (s *RedactionService) ListRules() ([]model.RedactionRule, error) {
+	FlushRedactionHits()
+	var rules []model.RedactionRule
+	err := s.db.Order("id").Find(&rules).Error
+	return rules, err
+}
+
+// GetRule 获取指定ID的脱敏规则, 不存在时返回 nil
+func (s *RedactionService) GetRule(id uint) (*model.RedactionRule, error) {
+	var rule model.RedactionRule
+	err := s.db.First(&rule, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreateRule 创建自定义脱敏规则
+func (s *RedactionService) CreateRule(rule *model.RedactionRule) error {
+	if err := ValidateRedactionRule(rule); err != nil {
+		return err
+	}
+	rule.Builtin = false
+	rule.HitCount = 0
+	if err := s.db.Create(rule).Error; err != nil {
+		return err
+	}
+	InvalidateRedactionRules()
+	return nil
+}
+
+// UpdateRule 更新脱敏规则, 内置规则的名称和正则表达式不可修改
+func (s *RedactionService) UpdateRule(existing, rule *model.RedactionRule) error {
+	if existing.Builtin {
+		rule.Name = existing.Name
+		rule.Pattern = existing.Pattern
+		rule.Description = existing.Description
+	}
+	if err := ValidateRedactionRule(rule); err != nil {
+		return err
+	}
+
+	rule.ID = existing.ID
+	rule.Builtin = existing.Builtin
+	rule.HitCount = existing.HitCount
+	rule.CreatedAt = existing.CreatedAt
+	if err := s.db.Save(rule).Error; err != nil {
+		return err
+	}
+	InvalidateRedactionRules()
+	return nil
+}
+
+// DeleteRule 删除自定义脱敏规则, 内置规则只能禁用
+func (s *RedactionService) DeleteRule(rule *model.RedactionRule) error {
+	if rule.Builtin {
+		return fmt.Errorf("builtin rule %s cannot be deleted, disable it instead", rule.Name)
+	}
+	if err := s.db.Delete(&model.RedactionRule{}, rule.ID).Error; err != nil {
+		return err
+	}
+	InvalidateRedactionRules()
+	return nil
+}
+
+// TestRedaction 使用指定正则或当前启用的规则对文本试运行脱敏, 不累计替换次数
+func (s *RedactionService) TestRedaction(stage, text string, rule *model.RedactionRule) (string, int, error) {
+	if rule != nil {
+		redactor, err := compileRedactionRule(rule)
+		if err != nil {
+			return "", 0, err
+		}
+		result, n := redactor.Redact(text)
+		return result, n, nil
+	}
+
+	rules, _ := loadRedactionRules()
+	total := 0
+	for _, redactor := range rules {
+		if !stageMatches(redactor.stage, stage) {
+			continue
+		}
+		var n int
+		text, n = redactor.Redact(text)
+		total += n
+	}
+	return text, total, nil
+}
+
+// ValidateRedactionRule 校验脱敏规则, 未设置生效阶段时默认两个阶段都生效
+func ValidateRedactionRule(rule *model.RedactionRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if rule.Pattern == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	switch rule.Stage {
+	case "":
+		rule.Stage = model.RedactionStageBoth
+	case model.RedactionStageLLM, model.RedactionStageStorage, model.RedactionStageBoth:
+	default:
+		return fmt.Errorf("invalid stage %q, must be one of llm, storage, both", rule.Stage)
+	}
+	_, err := compileRedactionRule(rule)
+	return err
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/database"
+	"github.com/eryajf/zenops/internal/model"
+	"gorm.io/gorm"
+)
+
+const (
+	// AccessTokenTTL 访问令牌有效期
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL 刷新令牌有效期, 每次刷新后顺延
+	RefreshTokenTTL = 7 * 24 * time.Hour
+	// sessionCheckInterval 会话状态在内存中缓存的时间, 同时也是最后使用时间的更新间隔
+	// 本进程内吊销会话时会立即清除缓存, 超过该时间的缓存在写入新缓存时清理
+	sessionCheckInterval = time.Minute
+)
+
+// ErrInvalidSession 会话不存在、已吊销或已过期
+var ErrInvalidSession = errors.New("session is invalid or expired")
+
+// sessionCache 最近校验通过的会话及校验时间
+var sessionCache struct {
+	mu      sync.Mutex
+	checked map[string]time.Time
+	sweptAt time.Time // 上次清理过期缓存的时间
+}
+
+// SessionService 用户登录会话服务
+type SessionService struct {
+	db *gorm.DB
+}
+
+// NewSessionService 创建用户登录会话服务实例
+func NewSessionService() *SessionService {
+	return &SessionService{
+		db: database.GetDB(),
+	}
+}
+
+// hashToken 计算令牌明文的 SHA-256 摘要
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken 生成随机字符串
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// CreateSession 为登录用户创建会话, 返回会话和刷新令牌明文
+func (s *SessionService) CreateSession(user *model.User, clientIP, userAgent string) (*model.UserSession, string, error) {
+	sessionID, err := randomToken(16)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	now := time.Now()
+	session := &model.UserSession{
+		SessionID:        sessionID,
+		UserID:           user.ID,
+		Username:         user.Username,
+		RefreshTokenHash: hashToken(refreshToken),
+		ClientIP:         clientIP,
+		UserAgent:        userAgent,
+		ExpiresAt:        now.Add(RefreshTokenTTL),
+		LastUsedAt:       now,
+	}
+	if err := s.db.Create(session).Error; err != nil {
+		return nil, "", err
+	}
+
+	// 顺便清理该用户已失效的会话
+	if err := s.db.Where("user_id = ? AND (expires_at < ? OR revoked_at IS NOT NULL)", user.ID, now).Delete(&model.UserSession{}).Error; err != nil {
+		logx.Warn("Failed to clean up sessions, user %s, error %v", user.Username, err)
+	}
+	return session, refreshToken, nil
+}
+
+// Refresh 使用刷新令牌续期会话, 旧的刷新令牌立即失效, 返回会话和新的刷新令牌明文
+func (s *SessionService) Refresh(refreshToken string) (*model.UserSession, string, error) {
+	var session model.UserSession
+	err := s.db.Where("refresh_token_hash = ?", hashToken(refreshToken)).First(&session).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, "", ErrInvalidSession
+		}
+		return nil, "", err
+	}
+
+	now := time.Now()
+	if !session.Active(now) {
+		return nil, "", ErrInvalidSession
+	}
+
+	newToken, err := randomToken(32)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	session.RefreshTokenHash = hashToken(newToken)
+	session.ExpiresAt = now.Add(RefreshTokenTTL)
+	session.LastUsedAt = now
+	// 以旧摘要为条件更新, 并发使用同一个刷新令牌时只有一个请求成功
+	result := s.db.Model(&model.UserSession{}).
+		Where("id = ? AND refresh_token_hash = ?", session.ID, hashToken(refreshToken)).
+		Updates(map[string]any{
+			"refresh_token_hash": session.RefreshTokenHash,
+			"expires_at":         session.ExpiresAt,
+			"last_used_at":       now,
+		})
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, "", ErrInvalidSession
+	}
+	return &session, newToken, nil
+}
+
+// ValidateSession 校验访问令牌所属的会话是否仍然有效
+// 校验结果在内存中缓存 sessionCheckInterval, 避免每个请求都查询数据库
+func (s *SessionService) ValidateSession(sessionID string, userID uint) error {
+	if sessionID == "" {
+		return ErrInvalidSession
+	}
+
+	now := time.Now()
+	sessionCache.mu.Lock()
+	checkedAt, ok := sessionCache.checked[sessionID]
+	sessionCache.mu.Unlock()
+	if ok && now.Sub(checkedAt) < sessionCheckInterval {
+		return nil
+	}
+
+	var session model.UserSession
+	err := s.db.Where("session_id = ? AND user_id = ?", sessionID, userID).First(&session).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			forgetSessions(sessionID)
+			return ErrInvalidSession
+		}
+		return err
+	}
+	if !session.Active(now) {
+		forgetSessions(sessionID)
+		return ErrInvalidSession
+	}
+
+	if err := s.db.Model(&session).UpdateColumn("last_used_at", now).Error; err != nil {
+		logx.Warn("Failed to update session last used time, session %s, error %v", sessionID, err)
+	}
+	rememberSession(sessionID, now)
+	return nil
+}
+
+// ListSessions 列出用户的有效会话
+func (s *SessionService) ListSessions(userID uint) ([]model.UserSession, error) {
+	var sessions []model.UserSession
+	err := s.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at desc").Find(&sessions).Error
+	return sessions, err
+}
+
+// RevokeSession 吊销用户的指定会话
+func (s *SessionService) RevokeSession(userID uint, sessionID string) error {
+	result := s.db.Model(&model.UserSession{}).
+		Where("user_id = ? AND session_id = ? AND revoked_at IS NULL", userID, sessionID).
+		UpdateColumn("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	forgetSessions(sessionID)
+	if result.RowsAffected == 0 {
+		return ErrInvalidSession
+	}
+	return nil
+}
+
+// RevokeUserSessions 吊销用户的全部会话, 用于禁用、删除用户和重置密码
+func (s *SessionService) RevokeUserSessions(userID uint) error {
+	var sessionIDs []string
+	err := s.db.Model(&model.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Pluck("session_id", &sessionIDs).Error
+	if err != nil {
+		return err
+	}
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+
+	err = s.db.Model(&model.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		UpdateColumn("revoked_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+	forgetSessions(sessionIDs...)
+	return nil
+}
+
+// rememberSession 缓存会话的校验时间
+// 每隔 sessionCheckInterval 顺便清除已过期的缓存, 不再使用的会话不会一直占用内存
+func rememberSession(sessionID string, now time.Time) {
+	sessionCache.mu.Lock()
+	defer sessionCache.mu.Unlock()
+	if sessionCache.checked == nil {
+		sessionCache.checked = make(map[string]time.Time)
+	}
+	if now.Sub(sessionCache.sweptAt) >= sessionCheckInterval {
+		for id, checkedAt := range sessionCache.checked {
+			if now.Sub(checkedAt) >= sessionCheckInterval {
+				delete(sessionCache.checked, id)
+			}
+		}
+		sessionCache.sweptAt = now
+	}
+	sessionCache.checked[sessionID] = now
+}
+
+// forgetSessions 清除会话的校验缓存, 下次请求时重新查询数据库
+func forgetSessions(sessionIDs ...string) {
+	sessionCache.mu.Lock()
+	defer sessionCache.mu.Unlock()
+	for _, sessionID := range sessionIDs {
+		delete(sessionCache.checked, sessionID)
+	}
+}
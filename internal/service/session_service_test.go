@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// cachedSession 判断会话是否在校验缓存中
+func cachedSession(sessionID string) bool {
+	sessionCache.mu.Lock()
+	defer sessionCache.mu.Unlock()
+	_, ok := sessionCache.checked[sessionID]
+	return ok
+}
+
+func TestRememberSessionSweepsStaleEntries(t *testing.T) {
+	now := time.Now()
+	rememberSession("stale-session", now.Add(-2*sessionCheckInterval))
+	rememberSession("recent-session", now.Add(-sessionCheckInterval/2))
+	sessionCache.mu.Lock()
+	sessionCache.sweptAt = time.Time{}
+	sessionCache.mu.Unlock()
+	t.Cleanup(func() { forgetSessions("stale-session", "recent-session", "new-session") })
+
+	rememberSession("new-session", now)
+	if cachedSession("stale-session") {
+		t.Errorf("stale session is still cached")
+	}
+	if !cachedSession("recent-session") || !cachedSession("new-session") {
+		t.Errorf("recent sessions were swept")
+	}
+}
+
+func TestValidateSessionCache(t *testing.T) {
+	user := createLocalUser(t, "session-user", "correct-password")
+	s := NewSessionService()
+
+	session, _, err := s.CreateSession(user, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	if err := s.ValidateSession(session.SessionID, user.ID); err != nil {
+		t.Fatalf("ValidateSession() error = %v", err)
+	}
+	if !cachedSession(session.SessionID) {
+		t.Fatalf("valid session is not cached")
+	}
+
+	// 登出吊销会话后立即清除缓存
+	if err := s.RevokeSession(user.ID, session.SessionID); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	if cachedSession(session.SessionID) {
+		t.Errorf("revoked session is still cached")
+	}
+	if err := s.ValidateSession(session.SessionID, user.ID); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("ValidateSession() after revoke error = %v, want ErrInvalidSession", err)
+	}
+}
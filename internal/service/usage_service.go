@@ -0,0 +1,237 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/eryajf/zenops/internal/database"
+	"github.com/eryajf/zenops/internal/model"
+)
+
+// UsageService 大模型 token 用量与配额服务
+type UsageService struct {
+	db *gorm.DB
+}
+
+// NewUsageService 创建用量服务实例
+func NewUsageService() *UsageService {
+	return &UsageService{
+		db: database.GetDB(),
+	}
+}
+
+// UsageParams 用量记录参数
+type UsageParams struct {
+	Username         string
+	Source           string
+	ConversationID   uint
+	LLMName          string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	InputPrice       float64 // 每百万输入 token 价格
+	OutputPrice      float64 // 每百万输出 token 价格
+}
+
+// UsageSummary 用量汇总
+type UsageSummary struct {
+	Username         string  `json:"username,omitempty"`
+	Model            string  `json:"model,omitempty"`
+	Calls            int64   `json:"calls"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// UsageStats 指定时间范围内的用量统计
+type UsageStats struct {
+	StartTime time.Time      `json:"start_time"`
+	EndTime   time.Time      `json:"end_time"`
+	Total     UsageSummary   `json:"total"`
+	ByUser    []UsageSummary `json:"by_user"`
+	ByModel   []UsageSummary `json:"by_model"`
+}
+
+// QuotaExceededError 用户 token 用量超出配额
+type QuotaExceededError struct {
+	Username string
+	Period   string // "daily" | "monthly"
+	Limit    int64
+	Used     int64
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s token quota exceeded for user %s: %d/%d", e.Period, e.Username, e.Used, e.Limit)
+}
+
+// Message 返回展示给用户的提示信息
+func (e *QuotaExceededError) Message() string {
+	if e.Period == "daily" {
+		return fmt.Sprintf("⚠️ 今日 token 用量已达上限 (%d/%d), 请明天再试或联系管理员调整配额", e.Used, e.Limit)
+	}
+	return fmt.Sprintf("⚠️ 本月 token 用量已达上限 (%d/%d), 请下月再试或联系管理员调整配额", e.Used, e.Limit)
+}
+
+// RecordUsage 记录一次模型调用的 token 用量
+func (s *UsageService) RecordUsage(params *UsageParams) error {
+	usage := &model.LLMUsage{
+		Username:         params.Username,
+		Source:           params.Source,
+		ConversationID:   params.ConversationID,
+		LLMName:          params.LLMName,
+		Model:            params.Model,
+		PromptTokens:     params.PromptTokens,
+		CompletionTokens: params.CompletionTokens,
+		TotalTokens:      params.PromptTokens + params.CompletionTokens,
+		Cost: (float64(params.PromptTokens)*params.InputPrice +
+			float64(params.CompletionTokens)*params.OutputPrice) / 1_000_000,
+	}
+	return s.db.Create(usage).Error
+}
+
+// GetUserTokens 获取用户从指定时间起的 token 用量
+func (s *UsageService) GetUserTokens(username string, since time.Time) (int64, error) {
+	var total int64
+	err := s.db.Model(&model.LLMUsage{}).
+		Select("COALESCE(SUM(total_tokens), 0)").
+		Where("username = ? AND created_at >= ?", username, since).
+		Scan(&total).Error
+	return total, err
+}
+
+// CheckQuota 检查用户是否超出每日/每月配额, 超出时返回 *QuotaExceededError
+func (s *UsageService) CheckQuota(username string) error {
+	quota, err := s.GetEffectiveQuota(username)
+	if err != nil || quota == nil {
+		return err
+	}
+
+	now := time.Now()
+	periods := []struct {
+		name  string
+		limit int64
+		since time.Time
+	}{
+		{"daily", quota.DailyTokens, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())},
+		{"monthly", quota.MonthlyTokens, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())},
+	}
+
+	for _, period := range periods {
+		if period.limit <= 0 {
+			continue
+		}
+		used, err := s.GetUserTokens(username, period.since)
+		if err != nil {
+			return err
+		}
+		if used >= period.limit {
+			return &QuotaExceededError{Username: username, Period: period.name, Limit: period.limit, Used: used}
+		}
+	}
+
+	return nil
+}
+
+// GetEffectiveQuota 获取用户生效的配额, 没有单独配置时使用默认配额, 均未配置时返回 nil
+func (s *UsageService) GetEffectiveQuota(username string) (*model.LLMQuota, error) {
+	var quotas []model.LLMQuota
+	if err := s.db.Where("username IN ?", []string{username, ""}).Find(&quotas).Error; err != nil {
+		return nil, err
+	}
+
+	var result *model.LLMQuota
+	for i := range quotas {
+		if quotas[i].Username == username {
+			return &quotas[i], nil
+		}
+		result = &quotas[i]
+	}
+	return result, nil
+}
+
+// ListQuotas 列出所有配额配置
+func (s *UsageService) ListQuotas() ([]model.LLMQuota, error) {
+	var quotas []model.LLMQuota
+	err := s.db.Order("username").Find(&quotas).Error
+	return quotas, err
+}
+
+// GetQuota 获取指定ID的配额配置
+func (s *UsageService) GetQuota(id uint) (*model.LLMQuota, error) {
+	var quota model.LLMQuota
+	err := s.db.First(&quota, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// SaveQuota 保存配额配置, 同一用户已存在配额时更新
+func (s *UsageService) SaveQuota(quota *model.LLMQuota) error {
+	var existing model.LLMQuota
+	err := s.db.Where("username = ?", quota.Username).First(&existing).Error
+	if err == nil {
+		quota.ID = existing.ID
+		quota.CreatedAt = existing.CreatedAt
+		return s.db.Save(quota).Error
+	}
+	if err == gorm.ErrRecordNotFound {
+		return s.db.Create(quota).Error
+	}
+	return err
+}
+
+// UpdateQuota 更新配额配置
+func (s *UsageService) UpdateQuota(quota *model.LLMQuota) error {
+	return s.db.Save(quota).Error
+}
+
+// DeleteQuota 删除配额配置
+func (s *UsageService) DeleteQuota(id uint) error {
+	return s.db.Delete(&model.LLMQuota{}, id).Error
+}
+
+// GetUsageStats 统计指定时间范围内按用户和按模型的用量与费用
+// username 不为空时只统计该用户
+func (s *UsageService) GetUsageStats(start, end time.Time, username string) (*UsageStats, error) {
+	const columns = "COUNT(*) AS calls, " +
+		"COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens, " +
+		"COALESCE(SUM(completion_tokens), 0) AS completion_tokens, " +
+		"COALESCE(SUM(total_tokens), 0) AS total_tokens, " +
+		"COALESCE(SUM(cost), 0) AS cost"
+
+	query := func() *gorm.DB {
+		q := s.db.Model(&model.LLMUsage{}).Where("created_at >= ? AND created_at < ?", start, end)
+		if username != "" {
+			q = q.Where("username = ?", username)
+		}
+		return q
+	}
+
+	stats := &UsageStats{
+		StartTime: start,
+		EndTime:   end,
+		ByUser:    []UsageSummary{},
+		ByModel:   []UsageSummary{},
+	}
+
+	if err := query().Select(columns).Scan(&stats.Total).Error; err != nil {
+		return nil, err
+	}
+	if err := query().Select("username, " + columns).Group("username").
+		Order("total_tokens DESC").Scan(&stats.ByUser).Error; err != nil {
+		return nil, err
+	}
+	if err := query().Select("model, " + columns).Group("model").
+		Order("total_tokens DESC").Scan(&stats.ByModel).Error; err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"cnb.cool/zhiqiangwang/pkg/logx"
+	"github.com/eryajf/zenops/internal/database"
+	"github.com/eryajf/zenops/internal/model"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxLoginFailures 连续登录失败达到该次数后锁定账号
+	maxLoginFailures = 5
+	// loginLockDuration 账号锁定时长
+	loginLockDuration = 15 * time.Minute
+)
+
+// 登录和修改密码失败的原因
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrUserDisabled       = errors.New("user is disabled")
+	ErrUserLocked         = errors.New("user is locked")
+	ErrPasswordUnchanged  = errors.New("new password must be different from the old one")
+	ErrNoRoleMapped       = errors.New("no role is mapped for the user's groups")
+	ErrUserSourceConflict = errors.New("username is already used by a user from another source")
+)
+
+// 用户管理操作失败的原因
+var (
+	ErrUserExists   = errors.New("username already exists")
+	ErrLastAdmin    = errors.New("at least one enabled admin user is required")
+	ErrInvalidRole  = errors.New("invalid role")
+	ErrExternalUser = errors.New("password of ldap or oidc users is managed by the identity provider")
+)
+
+// validRoles 可分配的角色
+var validRoles = []string{model.RoleAdmin, model.RoleOperator, model.RoleViewer, model.RoleUser}
+
+// UserService 用户管理服务
+type UserService struct {
+	db *gorm.DB
+}
+
+// NewUserService 创建用户管理服务实例
+func NewUserService() *UserService {
+	return &UserService{
+		db: database.GetDB(),
+	}
+}
+
+// ListUsers 列出所有用户
+func (s *UserService) ListUsers() ([]model.User, error) {
+	var users []model.User
+	err := s.db.Order("id").Find(&users).Error
+	return users, err
+}
+
+// GetUser 获取指定ID的用户, 不存在时返回 nil
+func (s *UserService) GetUser(id uint) (*model.User, error) {
+	var user model.User
+	err := s.db.First(&user, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserByUsername 根据用户名获取用户, 不存在时返回 nil
+func (s *UserService) GetUserByUsername(username string) (*model.User, error) {
+	var user model.User
+	err := s.db.Where("username = ?", username).First(&user).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateUser 创建用户, 新用户首次登录后需修改初始密码
+func (s *UserService) CreateUser(user *model.User, password string) error {
+	if err := ValidateRoles(user.Roles); err != nil {
+		return err
+	}
+	existing, err := s.GetUserByUsername(user.Username)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrUserExists
+	}
+
+	if err := user.SetPassword(password); err != nil {
+		return fmt.Errorf("failed to set password: %w", err)
+	}
+	user.MustChangePassword = true
+	if err := s.db.Create(user).Error; err != nil {
+		return err
+	}
+	// Enabled 字段默认值为 true, 创建时为 false 的值会被忽略
+	if !user.Enabled {
+		return s.db.Model(user).UpdateColumn("enabled", false).Error
+	}
+	return nil
+}
+
+// UpdateUser 更新用户资料、角色和启用状态
+// 禁用用户或角色变更时吊销其全部会话, 角色记录在访问令牌中, 需重新登录后才能生效
+func (s *UserService) UpdateUser(user *model.User) error {
+	if err := ValidateRoles(user.Roles); err != nil {
+		return err
+	}
+	if err := s.ensureAdminRemains(user); err != nil {
+		return err
+	}
+	var current model.User
+	if err := s.db.Select("roles").First(&current, user.ID).Error; err != nil {
+		return err
+	}
+	if err := s.db.Model(user).Select("nickname", "email", "avatar", "roles", "enabled").Updates(user).Error; err != nil {
+		return err
+	}
+	if !user.Enabled || current.Roles != user.Roles {
+		return NewSessionService().RevokeUserSessions(user.ID)
+	}
+	return nil
+}
+
+// DeleteUser 删除用户并吊销其全部会话和个人访问令牌, 不允许删除最后一个启用的管理员
+func (s *UserService) DeleteUser(user *model.User) error {
+	removed := *user
+	removed.Enabled = false
+	if err := s.ensureAdminRemains(&removed); err != nil {
+		return err
+	}
+	if err := NewSessionService().RevokeUserSessions(user.ID); err != nil {
+		return err
+	}
+	if err := NewAPIKeyService().DeleteUserKeys(user.ID); err != nil {
+		return err
+	}
+	// 物理删除, 以便之后可以重新创建同名用户
+	return s.db.Unscoped().Delete(&model.User{}, user.ID).Error
+}
+
+// ResetPassword 管理员重置密码, 同时解除登录锁定并吊销全部会话, 用户下次登录后需修改密码
+func (s *UserService) ResetPassword(user *model.User, password string) error {
+	if user.External() {
+		return ErrExternalUser
+	}
+	if err := user.SetPassword(password); err != nil {
+		return fmt.Errorf("failed to set password: %w", err)
+	}
+	user.MustChangePassword = true
+	user.FailedLogins = 0
+	user.LockedUntil = nil
+	if err := s.db.Model(user).Select("password", "must_change_password", "failed_logins", "locked_until").Updates(user).Error; err != nil {
+		return err
+	}
+	return NewSessionService().RevokeUserSessions(user.ID)
+}
+
+// ChangePassword 用户修改自己的密码
+func (s *UserService) ChangePassword(user *model.User, oldPassword, newPassword string) error {
+	if user.External() {
+		return ErrExternalUser
+	}
+	if !user.CheckPassword(oldPassword) {
+		return ErrInvalidCredentials
+	}
+	if oldPassword == newPassword {
+		return ErrPasswordUnchanged
+	}
+	if err := user.SetPassword(newPassword); err != nil {
+		return fmt.Errorf("failed to set password: %w", err)
+	}
+	user.MustChangePassword = false
+	return s.db.Model(user).Select("password", "must_change_password").Updates(user).Error
+}
+
+// Unlock 解除登录锁定
+func (s *UserService) Unlock(user *model.User) error {
+	user.FailedLogins = 0
+	user.LockedUntil = nil
+	return s.db.Model(user).Select("failed_logins", "locked_until").Updates(user).Error
+}
+
+// Authenticate 校验用户名和密码
+// 本地用户校验本地密码; 外部认证源的用户和尚不存在的用户依次交给 backends 校验, 通过后自动创建或同步用户
+// 连续失败 maxLoginFailures 次后锁定 loginLockDuration, 锁定期间即使密码正确也拒绝登录
+func (s *UserService) Authenticate(username, password string, backends ...PasswordBackend) (*model.User, error) {
+	user, err := s.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if user != nil && user.Locked(now) {
+		return user, ErrUserLocked
+	}
+
+	if user == nil || user.External() {
+		identity, err := authenticateExternal(user, username, password, backends)
+		if errors.Is(err, ErrInvalidCredentials) && user != nil {
+			return s.recordFailure(user, now)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return s.AuthenticateExternal(identity)
+	}
+
+	if !user.CheckPassword(password) {
+		return s.recordFailure(user, now)
+	}
+	if !user.Enabled {
+		return nil, ErrUserDisabled
+	}
+	s.recordLogin(user, now)
+	return user, nil
+}
+
+// AuthenticateExternal 外部认证源完成认证后, 创建或同步对应的用户并记录登录
+func (s *UserService) AuthenticateExternal(identity *ExternalIdentity) (*model.User, error) {
+	user, err := s.ProvisionUser(identity)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Enabled {
+		return nil, ErrUserDisabled
+	}
+	s.recordLogin(user, time.Now())
+	return user, nil
+}
+
+// ProvisionUser 创建或同步外部认证源的用户
+// 首次登录时创建用户; 配置了用户组映射时每次登录同步角色, 否则保留管理员分配的角色
+func (s *UserService) ProvisionUser(identity *ExternalIdentity) (*model.User, error) {
+	user, err := s.GetUserByUsername(identity.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		if identity.Roles == "" {
+			return nil, ErrNoRoleMapped
+		}
+		if err := ValidateRoles(identity.Roles); err != nil {
+			return nil, err
+		}
+		password, err := randomToken(32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate password: %w", err)
+		}
+		user = &model.User{
+			Username: identity.Username,
+			Nickname: identity.Nickname,
+			Email:    identity.Email,
+			Roles:    identity.Roles,
+			Enabled:  true,
+			Source:   identity.Source,
+		}
+		// 外部用户不使用本地密码, 设置一个随机密码占位
+		if err := user.SetPassword(password); err != nil {
+			return nil, fmt.Errorf("failed to set password: %w", err)
+		}
+		if err := s.db.Create(user).Error; err != nil {
+			return nil, err
+		}
+		logx.Info("Provisioned %s user %s with roles %s", identity.Source, user.Username, user.Roles)
+		return user, nil
+	}
+
+	if user.Source != identity.Source {
+		return nil, ErrUserSourceConflict
+	}
+	if identity.Nickname != "" {
+		user.Nickname = identity.Nickname
+	}
+	if identity.Email != "" {
+		user.Email = identity.Email
+	}
+	rolesChanged := false
+	if identity.SyncRoles {
+		if identity.Roles == "" {
+			return nil, ErrNoRoleMapped
+		}
+		if err := ValidateRoles(identity.Roles); err != nil {
+			return nil, err
+		}
+		rolesChanged = user.Roles != identity.Roles
+		user.Roles = identity.Roles
+	}
+	if err := s.db.Model(user).Select("nickname", "email", "roles").Updates(user).Error; err != nil {
+		return nil, err
+	}
+	// 同步后角色变更时, 之前签发的访问令牌中的角色已过时
+	if rolesChanged {
+		if err := NewSessionService().RevokeUserSessions(user.ID); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// recordFailure 记录一次登录失败, 达到次数上限时锁定账号
+// 之前的锁定已过期时先清除, 只有本次失败触发锁定时才返回 ErrUserLocked
+func (s *UserService) recordFailure(user *model.User, now time.Time) (*model.User, error) {
+	updates := map[string]any{}
+	if user.LockedUntil != nil && !user.Locked(now) {
+		user.LockedUntil = nil
+		updates["locked_until"] = nil
+	}
+
+	user.FailedLogins++
+	updates["failed_logins"] = user.FailedLogins
+	if user.FailedLogins >= maxLoginFailures {
+		lockedUntil := now.Add(loginLockDuration)
+		user.LockedUntil = &lockedUntil
+		user.FailedLogins = 0
+		updates["failed_logins"] = 0
+		updates["locked_until"] = lockedUntil
+		logx.Warn("User %s locked until %s after %d failed logins", user.Username, lockedUntil.Format(time.DateTime), maxLoginFailures)
+	}
+	if err := s.db.Model(user).UpdateColumns(updates).Error; err != nil {
+		logx.Warn("Failed to record failed login, user %s, error %v", user.Username, err)
+	}
+	if user.Locked(now) {
+		return user, ErrUserLocked
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// recordLogin 记录登录成功, 清除失败次数和锁定
+func (s *UserService) recordLogin(user *model.User, now time.Time) {
+	user.FailedLogins = 0
+	user.LockedUntil = nil
+	user.LastLoginAt = &now
+	err := s.db.Model(user).UpdateColumns(map[string]any{
+		"failed_logins": 0,
+		"locked_until":  nil,
+		"last_login_at": now,
+	}).Error
+	if err != nil {
+		logx.Warn("Failed to record login, user %s, error %v", user.Username, err)
+	}
+}
+
+// ensureAdminRemains 确保变更后仍至少有一个启用的管理员
+func (s *UserService) ensureAdminRemains(changed *model.User) error {
+	if changed.Enabled && slices.Contains(changed.RoleList(), model.RoleAdmin) {
+		return nil
+	}
+
+	users, err := s.ListUsers()
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if user.ID != changed.ID && user.Enabled && slices.Contains(user.RoleList(), model.RoleAdmin) {
+			return nil
+		}
+	}
+	return ErrLastAdmin
+}
+
+// ValidateRoles 校验逗号分隔的角色列表
+func ValidateRoles(roles string) error {
+	if strings.TrimSpace(roles) == "" {
+		return fmt.Errorf("%w: roles is required", ErrInvalidRole)
+	}
+	for _, role := range strings.Split(roles, ",") {
+		if !slices.Contains(validRoles, strings.TrimSpace(role)) {
+			return fmt.Errorf("%w %q, must be one of %s", ErrInvalidRole, role, strings.Join(validRoles, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eryajf/zenops/internal/model"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "zenops-service-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("ZENOPS_DB_PATH", filepath.Join(dir, "zenops.db"))
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// createLocalUser 创建本地用户, 测试结束后删除
+func createLocalUser(t *testing.T, username, password string) *model.User {
+	t.Helper()
+	s := NewUserService()
+	user := &model.User{Username: username, Roles: model.RoleViewer, Enabled: true}
+	if err := s.CreateUser(user, password); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	t.Cleanup(func() { s.db.Unscoped().Delete(&model.User{}, user.ID) })
+	return user
+}
+
+func TestAuthenticateLocksAfterMaxFailures(t *testing.T) {
+	s := NewUserService()
+	createLocalUser(t, "lock-user", "correct-password")
+
+	for i := 1; i < maxLoginFailures; i++ {
+		if _, err := s.Authenticate("lock-user", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("failure %d error = %v, want ErrInvalidCredentials", i, err)
+		}
+	}
+	if _, err := s.Authenticate("lock-user", "wrong"); !errors.Is(err, ErrUserLocked) {
+		t.Fatalf("failure %d error = %v, want ErrUserLocked", maxLoginFailures, err)
+	}
+	if _, err := s.Authenticate("lock-user", "correct-password"); !errors.Is(err, ErrUserLocked) {
+		t.Fatalf("correct password while locked error = %v, want ErrUserLocked", err)
+	}
+}
+
+func TestAuthenticateClearsExpiredLock(t *testing.T) {
+	s := NewUserService()
+	user := createLocalUser(t, "expired-lock-user", "correct-password")
+
+	expired := time.Now().Add(-time.Minute)
+	if err := s.db.Model(user).UpdateColumn("locked_until", expired).Error; err != nil {
+		t.Fatalf("set locked_until error = %v", err)
+	}
+
+	// 锁定过期后的一次密码错误只计为普通失败, 不能再返回已过期的锁定
+	if _, err := s.Authenticate("expired-lock-user", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password after lock expired error = %v, want ErrInvalidCredentials", err)
+	}
+
+	saved, err := s.GetUser(user.ID)
+	if err != nil {
+		t.Fatalf("GetUser() error = %v", err)
+	}
+	if saved.LockedUntil != nil {
+		t.Errorf("locked_until = %v, want cleared", saved.LockedUntil)
+	}
+	if saved.FailedLogins != 1 {
+		t.Errorf("failed_logins = %d, want 1", saved.FailedLogins)
+	}
+
+	if _, err := s.Authenticate("expired-lock-user", "correct-password"); err != nil {
+		t.Fatalf("correct password after lock expired error = %v", err)
+	}
+}
+
+func TestUpdateUserRevokesSessions(t *testing.T) {
+	s := NewUserService()
+	sessions := NewSessionService()
+	user := createLocalUser(t, "update-user", "correct-password")
+
+	tests := []struct {
+		name       string
+		update     func(user *model.User)
+		wantRevoke bool
+	}{
+		{name: "profile change keeps sessions", update: func(user *model.User) { user.Nickname = "Updated" }},
+		{name: "role change revokes sessions", update: func(user *model.User) { user.Roles = model.RoleOperator }, wantRevoke: true},
+		{name: "disable revokes sessions", update: func(user *model.User) { user.Enabled = false }, wantRevoke: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, _, err := sessions.CreateSession(user, "127.0.0.1", "test")
+			if err != nil {
+				t.Fatalf("CreateSession() error = %v", err)
+			}
+			// 先校验一次, 确认角色变更后不会命中校验缓存
+			if err := sessions.ValidateSession(session.SessionID, user.ID); err != nil {
+				t.Fatalf("ValidateSession() error = %v", err)
+			}
+
+			saved, err := s.GetUser(user.ID)
+			if err != nil {
+				t.Fatalf("GetUser() error = %v", err)
+			}
+			tt.update(saved)
+			if err := s.UpdateUser(saved); err != nil {
+				t.Fatalf("UpdateUser() error = %v", err)
+			}
+
+			err = sessions.ValidateSession(session.SessionID, user.ID)
+			if revoked := errors.Is(err, ErrInvalidSession); revoked != tt.wantRevoke {
+				t.Errorf("ValidateSession() after update error = %v, want revoked %v", err, tt.wantRevoke)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ type UserReq struct {
 	Msgid    string `json:"msgid"`
 	Aibotid  string `json:"aibotid"`
 	Chattype string `json:"chattype"`
+	Chatid   string `json:"chatid"` // 群聊会话ID, 仅群聊消息返回
 	From     struct {
 		Userid string `json:"userid"`
 	} `json:"from"`
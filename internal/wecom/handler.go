@@ -45,15 +45,12 @@ func NewMessageHandler(cfg *config.Config, mcpServer *imcp.MCPServer) (*MessageH
 	}
 
 	// 初始化 LLM 客户端
-	var llmClient *llm.Client
-	if cfg.LLM.Enabled {
-		llmConfig := &llm.Config{
-			Model:   cfg.LLM.Model,
-			APIKey:  cfg.LLM.APIKey,
-			BaseURL: cfg.LLM.BaseURL,
-		}
-		llmClient = llm.NewClient(llmConfig, mcpServer)
-		logx.Info("LLM client initialized for Wecom, model %s", cfg.LLM.Model)
+	// 优先使用企业微信指定的模型, 数据库中其余启用的模型作为备用; 数据库没有配置时使用 config.yaml
+	llmClient, err := llm.LoadClient(cfg.Wecom.LLM, cfg.LLM, mcpServer)
+	if err != nil {
+		logx.Warn("LLM client not initialized for Wecom: %v", err)
+	} else {
+		logx.Info("LLM client initialized for Wecom")
 	}
 
 	handler := &MessageHandler{
@@ -147,8 +144,13 @@ func (h *MessageHandler) processMessage(ctx context.Context, req *UserReq, conve
 		source = "群聊"
 	}
 
-	// 保存用户消息到数据库
-	userLog, err := h.chatLogService.CreateUserMessage(req.From.Userid, source, userMessage)
+	// 保存用户消息到数据库, 私聊按用户、群聊按群关联到同一个会话
+	chatID := req.From.Userid
+	if req.Chatid != "" {
+		chatID = req.Chatid
+	}
+	channelConversationID := service.ChannelConversation("wecom", chatID, req.From.Userid, "企业微信"+source)
+	userLog, err := h.chatLogService.CreateUserMessageWithConversation(req.From.Userid, source, userMessage, channelConversationID)
 	if err != nil {
 		logx.Error("Failed to save user message to database: %v", err)
 	}
@@ -159,8 +161,11 @@ func (h *MessageHandler) processMessage(ctx context.Context, req *UserReq, conve
 		return
 	}
 
-	// 如果启用了 LLM,使用 LLM 处理
-	if h.config.LLM.Enabled && h.llmClient != nil {
+	// 如果启用了 LLM,使用 LLM 处理, 群聊记录会话ID用于匹配提示词配置
+	if h.llmClient != nil {
+		if req.Chatid != "" {
+			ctx = service.WithChatGroup(ctx, req.Chatid)
+		}
 		h.processLLMMessage(ctx, userMessage, state, req.From.Userid, source, userLog)
 		return
 	}
@@ -175,8 +180,16 @@ func (h *MessageHandler) processMessage(ctx context.Context, req *UserReq, conve
 
 // processLLMMessage 使用 LLM 处理消息
 func (h *MessageHandler) processLLMMessage(ctx context.Context, userMessage string, state *ConversationState, username, source string, userLog *model.ChatLog) {
-	// 调用 LLM 流式对话
-	responseCh, err := h.llmClient.ChatWithToolsAndStream(ctx, userMessage)
+	// 调用 LLM 流式对话, 记录发起人用于工具调用审计
+	ctx = service.WithCaller(ctx, username, "wecom")
+	ctx = service.WithIMChannel(ctx)
+	if userLog != nil {
+		ctx = service.WithChatLog(ctx, userLog.ID)
+		ctx = service.WithConversation(ctx, userLog.ConversationID)
+	}
+	ctx = llm.WithToolProgress(ctx)
+	ctx, answer := llm.WithAnswer(ctx)
+	responseCh, err := h.llmClient.ChatWithToolsAndStreamMessages(ctx, llm.ConversationMessages(userLog, userMessage))
 	if err != nil {
 		logx.Error("Failed to call LLM: %v", err)
 		state.Mutex.Lock()
@@ -207,7 +220,7 @@ func (h *MessageHandler) processLLMMessage(ctx context.Context, userMessage stri
 	if userLog != nil && aiResponse.Len() > 0 {
 		var parentID uint
 		parentID = userLog.ID
-		_, err := h.chatLogService.CreateAIMessage(username, source, aiResponse.String(), parentID)
+		_, err := h.chatLogService.CreateAIMessageWithModel(username, source, aiResponse.String(), answer.Model, parentID, userLog.ConversationID)
 		if err != nil {
 			logx.Error("Failed to save AI response to database: %v", err)
 		}
@@ -232,7 +245,7 @@ func (h *MessageHandler) sendHelpMessage(state *ConversationState, username, sou
 
 	// 保存帮助消息到数据库
 	if userLog != nil {
-		_, err := h.chatLogService.CreateAIMessage(username, source, helpText, userLog.ID)
+		_, err := h.chatLogService.CreateAIMessageWithConversation(username, source, helpText, userLog.ID, userLog.ConversationID)
 		if err != nil {
 			logx.Error("Failed to save help message to database: %v", err)
 		}